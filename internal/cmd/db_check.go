@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/kong/koko/internal/store"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var dbCheckRepair bool
+
+// dbCheckCmd is the 'koko db check' command.
+var dbCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "checks the entities of the database against their indexes",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		opts, err := setup()
+		if err != nil {
+			return err
+		}
+		logger := opts.Logger
+		logger.Debug("setup successful")
+
+		persister, err := setupDB(logger, opts.Config.Database)
+		if err != nil {
+			return fmt.Errorf("database: %v", err)
+		}
+		defer persister.Close()
+
+		objectStore, err := setupStore(logger, persister, opts.Config.Database)
+		if err != nil {
+			return fmt.Errorf("store: %v", err)
+		}
+		s := objectStore.ForCluster(store.DefaultCluster)
+		report, err := s.CheckIntegrity(cmd.Context(), dbCheckRepair)
+		if err != nil {
+			return err
+		}
+		unrepaired := 0
+		for _, issue := range report.Issues {
+			logger.With(
+				zap.String("kind", string(issue.Kind)),
+				zap.String("cluster", issue.Cluster),
+				zap.String("key", issue.Key),
+				zap.String("object_type", string(issue.Type)),
+				zap.String("object_id", issue.ID),
+				zap.Bool("repaired", issue.Repaired),
+			).Warn(issue.Message)
+			if !issue.Repaired {
+				unrepaired++
+			}
+		}
+		logger.Sugar().Infof("checked %d entities, found %d issues",
+			report.Objects, len(report.Issues))
+		if unrepaired > 0 {
+			return fmt.Errorf("%d issues left unrepaired", unrepaired)
+		}
+		return nil
+	},
+}
+
+func init() {
+	dbCheckCmd.Flags().BoolVar(&dbCheckRepair, "repair", false,
+		"repair the indexes of the entities, except for dangling references and index conflicts")
+	dbCmd.AddCommand(dbCheckCmd)
+}
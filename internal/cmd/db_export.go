@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/kong/koko/internal/persistence/archive"
+	"github.com/spf13/cobra"
+)
+
+var (
+	dbExportCluster string
+	dbExportOutput  string
+)
+
+// dbExportCmd is the 'koko db export' command.
+var dbExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "exports the database to an archive that can be imported into any database",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		opts, err := setup()
+		if err != nil {
+			return err
+		}
+		logger := opts.Logger
+		logger.Debug("setup successful")
+
+		persister, err := setupDB(logger, opts.Config.Database)
+		if err != nil {
+			return fmt.Errorf("database: %v", err)
+		}
+		defer persister.Close()
+
+		var w io.Writer = os.Stdout
+		if dbExportOutput != "-" {
+			f, err := os.Create(dbExportOutput)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			w = f
+		}
+		summary, err := archive.Export(cmd.Context(), persister, w,
+			archive.ExportOpts{Cluster: dbExportCluster})
+		if err != nil {
+			return fmt.Errorf("export database: %w", err)
+		}
+		logger.Sugar().Infof("exported %d keys, checksum: %s", summary.Count,
+			summary.Checksum)
+		return nil
+	},
+}
+
+func init() {
+	dbExportCmd.Flags().StringVar(&dbExportCluster, "cluster", "",
+		"cluster to export, all clusters are exported if empty")
+	dbExportCmd.Flags().StringVarP(&dbExportOutput, "output", "o", "-",
+		"file to write the archive to, '-' for stdout")
+	dbCmd.AddCommand(dbExportCmd)
+}
@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/kong/koko/internal/config"
+	"github.com/kong/koko/internal/db"
+	"github.com/kong/koko/internal/persistence/archive"
+	"github.com/kong/koko/internal/store"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var (
+	dbImportInput  string
+	dbImportVerify bool
+)
+
+// dbImportCmd is the 'koko db import' command.
+var dbImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "imports an archive exported by 'koko db export' into an empty database",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		opts, err := setup()
+		if err != nil {
+			return err
+		}
+		logger := opts.Logger
+		logger.Debug("setup successful")
+
+		dbConfig, err := config.ToDBConfig(opts.Config.Database, logger)
+		if err != nil {
+			logger.Fatal(err.Error())
+		}
+
+		dbConfig.Logger = logger
+
+		m, err := db.NewMigrator(dbConfig)
+		if err != nil {
+			return err
+		}
+		c, l, err := m.Status()
+		if err != nil {
+			return err
+		}
+		if c != l {
+			logger.Sugar().Infof("migrating database schema from version %d "+
+				"to %d", c, l)
+			if err := runMigrations(m); err != nil {
+				return err
+			}
+		}
+
+		persister, err := db.NewPersister(dbConfig)
+		if err != nil {
+			return fmt.Errorf("database: %v", err)
+		}
+		defer persister.Close()
+
+		var r io.Reader = os.Stdin
+		if dbImportInput != "-" {
+			f, err := os.Open(dbImportInput)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			r = f
+		}
+		summary, err := archive.Import(cmd.Context(), persister, r)
+		if err != nil {
+			return fmt.Errorf("import database: %w", err)
+		}
+		logger.Sugar().Infof("imported %d keys, checksum: %s", summary.Count,
+			summary.Checksum)
+
+		if !dbImportVerify {
+			return nil
+		}
+		objectStore, err := setupStore(logger, persister, opts.Config.Database)
+		if err != nil {
+			return fmt.Errorf("store: %v", err)
+		}
+		s := objectStore.ForCluster(store.DefaultCluster)
+		report, err := s.CheckIntegrity(cmd.Context(), false)
+		if err != nil {
+			return err
+		}
+		for _, issue := range report.Issues {
+			logger.With(
+				zap.String("kind", string(issue.Kind)),
+				zap.String("cluster", issue.Cluster),
+				zap.String("key", issue.Key),
+			).Warn(issue.Message)
+		}
+		if len(report.Issues) > 0 {
+			return fmt.Errorf("imported database has %d integrity issues, "+
+				"run 'koko db check' for details", len(report.Issues))
+		}
+		logger.Sugar().Infof("verified the indexes of %d entities", report.Objects)
+		return nil
+	},
+}
+
+func init() {
+	dbImportCmd.Flags().StringVarP(&dbImportInput, "input", "i", "-",
+		"file to read the archive from, '-' for stdin")
+	dbImportCmd.Flags().BoolVar(&dbImportVerify, "verify", false,
+		"check the indexes of the imported entities, like 'koko db check'")
+	dbCmd.AddCommand(dbImportCmd)
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// dbRotateKeyCmd is the 'koko db rotate-key' command.
+var dbRotateKeyCmd = &cobra.Command{
+	Use:   "rotate-key",
+	Short: "encrypts the sensitive fields of all entities with the current encryption key",
+	Long: `Encrypts the sensitive fields of all entities with the current encryption key.
+
+Fields stored in plain text are encrypted and fields encrypted with the previous
+key are re-encrypted, after which the previous key can be removed.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		opts, err := setup()
+		if err != nil {
+			return err
+		}
+		logger := opts.Logger
+		logger.Debug("setup successful")
+
+		if opts.Config.Database.Encryption.Key == "" &&
+			opts.Config.Database.Encryption.KeyFile == "" {
+			return errors.New("no encryption key configured")
+		}
+		persister, err := setupDB(logger, opts.Config.Database)
+		if err != nil {
+			return fmt.Errorf("database: %v", err)
+		}
+		defer persister.Close()
+
+		s, err := setupStore(logger, persister, opts.Config.Database)
+		if err != nil {
+			return fmt.Errorf("store: %v", err)
+		}
+		count, err := s.RotateKeys(cmd.Context())
+		if err != nil {
+			return err
+		}
+		logger.Sugar().Infof("re-encrypted %d values", count)
+		return nil
+	},
+}
+
+func init() {
+	dbCmd.AddCommand(dbRotateKeyCmd)
+}
@@ -5,6 +5,7 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"fmt"
+	"net"
 
 	"github.com/google/uuid"
 	"github.com/hbagdi/gang"
@@ -46,8 +47,11 @@ type ServerConfig struct {
 	KongCPCert tls.Certificate
 
 	Logger                  *zap.Logger
+	Admin                   config.AdminServer
+	Control                 config.ControlServer
 	Metrics                 config.Metrics
 	Database                config.Database
+	Quotas                  config.Quotas
 	DisableAnonymousReports bool
 }
 
@@ -96,15 +100,37 @@ func Run(ctx context.Context, config ServerConfig) error {
 	}
 	defer persister.Close()
 
-	store := store.New(persister, logger.With(zap.String("component",
-		"store"))).ForCluster(store.DefaultCluster)
-	storeLoader := serverUtil.DefaultStoreLoader{Store: store}
+	objectStore, err := setupStore(logger, persister, config.Database)
+	if err != nil {
+		return fmt.Errorf("store: %v", err)
+	}
+	quotas, err := setupQuotas(config.Quotas)
+	if err != nil {
+		return fmt.Errorf("quotas: %v", err)
+	}
+	objectStore = objectStore.WithHistoryLimit(config.Database.HistoryLimit).
+		WithQuotas(quotas)
+	g.AddWithCtxE(func(ctx context.Context) error {
+		return objectStore.RunHistoryRetention(ctx, config.Database.HistoryRetention)
+	})
+	storeLoader := serverUtil.ClusterStoreLoader{Store: objectStore}
+	if config.Database.Cache.Enable {
+		cache := store.NewCache(config.Database.Cache.TTL)
+		storeLoader.Cache = cache
+		g.AddWithCtxE(func(ctx context.Context) error {
+			return cache.Run(ctx, objectStore, config.Database.Cache.PollInterval)
+		})
+	}
+	store := objectStore.ForCluster(store.DefaultCluster)
 
 	instID, err := registerInstallation(ctx, store, logger)
 	if err != nil {
 		return fmt.Errorf("failed to register installation: %w", err)
 	}
 	logger.Info("running with installation-id", zap.String("id", instID))
+	if err := registerCluster(ctx, store); err != nil {
+		return fmt.Errorf("failed to register default cluster: %w", err)
+	}
 
 	validator, err := validators.NewLuaValidator(validators.Opts{
 		Logger:      logger,
@@ -120,9 +146,10 @@ func Run(ctx context.Context, config ServerConfig) error {
 	resource.SetValidator(validator)
 
 	adminOpts := admin.HandlerOpts{
-		Logger:      logger.With(zap.String("component", "admin-server")),
-		StoreLoader: storeLoader,
-		Validator:   validator,
+		Logger:          logger.With(zap.String("component", "admin-server")),
+		StoreLoader:     storeLoader,
+		Validator:       validator,
+		MaxDPConfigSize: config.Quotas.MaxDPConfigSize,
 	}
 
 	// Validate the handler options & set up the admin API handler.
@@ -130,12 +157,23 @@ func Run(ctx context.Context, config ServerConfig) error {
 	if err != nil {
 		return err
 	}
+	var actorVerifier *serverUtil.JwtService
+	if config.Admin.JWTPublicKey != "" {
+		actorVerifier, err = serverUtil.New(serverUtil.NewJwtServiceOpts{
+			JwtPublicKey: config.Admin.JWTPublicKey,
+		})
+		if err != nil {
+			return fmt.Errorf("admin server JWT public key: %w", err)
+		}
+	}
 
 	// setup Admin API server
 	s, err := server.NewHTTP(server.HTTPOpts{
 		Address: ":3000",
 		Logger:  adminOpts.Logger,
-		Handler: serverUtil.HandlerWithRecovery(serverUtil.HandlerWithLogger(h, adminOpts.Logger), adminOpts.Logger),
+		Handler: serverUtil.HandlerWithRecovery(serverUtil.HandlerWithLogger(serverUtil.HandlerWithActor(
+			serverUtil.HandlerWithSession(serverUtil.HandlerWithRevealSecrets(h,
+				config.Admin.AllowRevealSecrets)), actorVerifier), adminOpts.Logger), adminOpts.Logger),
 	})
 	if err != nil {
 		return err
@@ -146,6 +184,9 @@ func Run(ctx context.Context, config ServerConfig) error {
 	rawGRPCServer := grpc.NewServer(
 		grpc.ChainUnaryInterceptor(
 			serverUtil.LoggerInterceptor(adminOpts.Logger),
+			serverUtil.ActorInterceptor(actorVerifier),
+			serverUtil.SessionInterceptor(),
+			serverUtil.RevealSecretsInterceptor(config.Admin.AllowRevealSecrets),
 			serverUtil.PanicInterceptor(adminOpts.Logger)),
 		grpc.ChainStreamInterceptor(serverUtil.PanicStreamInterceptor(adminOpts.Logger)))
 	admin.RegisterAdminService(rawGRPCServer, adminOpts)
@@ -161,8 +202,9 @@ func Run(ctx context.Context, config ServerConfig) error {
 
 	eventService := relayImpl.NewEventService(ctx,
 		relayImpl.EventServiceOpts{
-			Store:  store,
-			Logger: logger.With(zap.String("component", "relay-server")),
+			Store:       store,
+			StoreLoader: storeLoader,
+			Logger:      logger.With(zap.String("component", "relay-server")),
 		})
 	relay.RegisterEventServiceServer(rawGRPCServer, eventService)
 	statusService := relayImpl.NewStatusService(relayImpl.StatusServiceOpts{
@@ -172,16 +214,35 @@ func Run(ctx context.Context, config ServerConfig) error {
 	relay.RegisterStatusServiceServer(rawGRPCServer, statusService)
 	g.AddWithCtxE(grpcServer.Run)
 
+	// The control plane calls the admin API within the process, which
+	// reveals the values of sensitive fields to it.
+	inProcessListener := serverUtil.NewInProcessListener()
+	inProcessServer, err := server.NewGRPC(server.GRPCOpts{
+		Address:    "in-process",
+		Listener:   inProcessListener,
+		GRPCServer: rawGRPCServer,
+		Logger:     logger.With(zap.String("component", "relay-server")),
+	})
+	if err != nil {
+		return err
+	}
+	g.AddWithCtxE(inProcessServer.Run)
+
 	// setup relay client
 	const grpcMaxSendMsgSize = 1024 * 1024 * 8
 	dialOpts := []grpc.DialOption{
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return inProcessListener.DialContext(ctx)
+		}),
+		// The configuration of data planes is built out of the latest writes.
+		grpc.WithChainUnaryInterceptor(serverUtil.PrimaryReadsClientInterceptor()),
 		grpc.WithDefaultCallOptions(
 			grpc.MaxCallSendMsgSize(grpcMaxSendMsgSize),
 			grpc.MaxCallRecvMsgSize(grpcMaxSendMsgSize),
 		),
 	}
-	cc, err := grpc.Dial("localhost:3001", dialOpts...)
+	cc, err := grpc.Dial("in-process", dialOpts...)
 	if err != nil {
 		return err
 	}
@@ -191,7 +252,9 @@ func Run(ctx context.Context, config ServerConfig) error {
 		return err
 	}
 
-	loader := &kongConfigWS.KongConfigurationLoader{}
+	loader := &kongConfigWS.KongConfigurationLoader{
+		MaxPayloadSize: config.Quotas.MaxDPConfigSize,
+	}
 	err = loader.Register(&kongConfigWS.KongServiceLoader{Client: grpcClients.
 		Service})
 	if err != nil {
@@ -224,6 +287,12 @@ func Run(ctx context.Context, config ServerConfig) error {
 			": %w", err)
 	}
 
+	err = loader.Register(&kongConfigWS.KongConsumerGroupLoader{Client: grpcClients.ConsumerGroup})
+	if err != nil {
+		return fmt.Errorf("failed to register consumer-group configuration"+
+			" loader: %w", err)
+	}
+
 	err = loader.Register(&kongConfigWS.KongCertificateLoader{Client: grpcClients.Certificate})
 	if err != nil {
 		return fmt.Errorf("failed to register certificate configuration"+
@@ -291,33 +360,46 @@ func Run(ctx context.Context, config ServerConfig) error {
 
 	// setup control server
 	controlLogger := logger.With(zap.String("component", "control-server"))
-	m, err := ws.NewManager(ws.ManagerOpts{
-		Ctx:                    ctx,
-		Logger:                 controlLogger,
-		DPConfigLoader:         loader,
-		DPVersionCompatibility: vc,
-		Client: ws.ConfigClient{
-			Node:   grpcClients.Node,
-			Status: grpcClients.Status,
-			Event:  grpcClients.Event,
-		},
-		Cluster: ws.DefaultCluster{},
-		// TODO(hbagdi): make this configurable
-		Config: ws.ManagerConfig{
-			DataPlaneRequisites: []*grpcKongUtil.DataPlanePrerequisite{
-				{
-					Config: &grpcKongUtil.DataPlanePrerequisite_RequiredPlugins{
-						RequiredPlugins: &grpcKongUtil.RequiredPluginsFilter{
-							RequiredPlugins: []string{"rate-limiting"},
+	managers, err := ws.NewClusterManagers(ws.ClusterManagersOpts{
+		Ctx:    ctx,
+		Client: grpcClients.Cluster,
+		Logger: controlLogger,
+		NewManager: func(ctx context.Context, cluster ws.Cluster) (*ws.Manager, error) {
+			return ws.NewManager(ws.ManagerOpts{
+				Ctx:                    ctx,
+				Logger:                 controlLogger.With(zap.String("cluster", cluster.Get())),
+				DPConfigLoader:         loader,
+				DPVersionCompatibility: vc,
+				Client: ws.ConfigClient{
+					Node:   grpcClients.Node,
+					Status: grpcClients.Status,
+					Event:  grpcClients.Event,
+				},
+				Cluster: cluster,
+				// TODO(hbagdi): make this configurable
+				Config: ws.ManagerConfig{
+					DataPlaneRequisites: []*grpcKongUtil.DataPlanePrerequisite{
+						{
+							Config: &grpcKongUtil.DataPlanePrerequisite_RequiredPlugins{
+								RequiredPlugins: &grpcKongUtil.RequiredPluginsFilter{
+									RequiredPlugins: []string{"rate-limiting"},
+								},
+							},
 						},
 					},
 				},
-			},
+			})
 		},
 	})
 	if err != nil {
+		return err
+	}
+	// The manager of the default cluster is created upfront to catch
+	// configuration errors early.
+	if _, err := managers.Get(ctx, store.Cluster()); err != nil {
 		return fmt.Errorf("failed to create manager: %w", err)
 	}
+	g.AddWithCtx(managers.Run)
 	var authFn ws.AuthFn
 	switch config.DPAuthMode {
 	case DPAuthSharedMTLS:
@@ -334,10 +416,12 @@ func Run(ctx context.Context, config ServerConfig) error {
 		return fmt.Errorf("unknown auth mode: %v", config.DPAuthMode)
 	}
 
-	authenticator := &ws.DefaultAuthenticator{
-		Manager: m,
-		Context: ctx,
-		AuthFn:  authFn,
+	authenticator := &ws.ClusterAuthenticator{
+		Managers: managers,
+		AuthFn:   authFn,
+	}
+	if config.Control.TrustClusterHeader {
+		authenticator.ClusterFn = ws.ClusterFromTrustedProxy
 	}
 
 	negotiator, err := ws.NewNegotiationRegisterer(controlLogger.With(
@@ -426,12 +510,14 @@ type grpcClients struct {
 	Upstream      v1.UpstreamServiceClient
 	Target        v1.TargetServiceClient
 	Consumer      v1.ConsumerServiceClient
+	ConsumerGroup v1.ConsumerGroupServiceClient
 	Certificate   v1.CertificateServiceClient
 	CACertificate v1.CACertificateServiceClient
 	Key           v1.KeyServiceClient
 	KeySet        v1.KeySetServiceClient
 	SNI           v1.SNIServiceClient
 	Vault         v1.VaultServiceClient
+	Cluster       v1.ClusterServiceClient
 
 	Status relay.StatusServiceClient
 	Node   v1.NodeServiceClient
@@ -447,12 +533,14 @@ func setupGRPCClients(cc *grpc.ClientConn) grpcClients {
 		Upstream:      v1.NewUpstreamServiceClient(cc),
 		Target:        v1.NewTargetServiceClient(cc),
 		Consumer:      v1.NewConsumerServiceClient(cc),
+		ConsumerGroup: v1.NewConsumerGroupServiceClient(cc),
 		Certificate:   v1.NewCertificateServiceClient(cc),
 		CACertificate: v1.NewCACertificateServiceClient(cc),
 		Key:           v1.NewKeyServiceClient(cc),
 		KeySet:        v1.NewKeySetServiceClient(cc),
 		SNI:           v1.NewSNIServiceClient(cc),
 		Vault:         v1.NewVaultServiceClient(cc),
+		Cluster:       v1.NewClusterServiceClient(cc),
 
 		Node:   v1.NewNodeServiceClient(cc),
 		Event:  relay.NewEventServiceClient(cc),
@@ -460,6 +548,59 @@ func setupGRPCClients(cc *grpc.ClientConn) grpcClients {
 	}
 }
 
+// setupStore returns the store of the persister, which encrypts the sensitive
+// fields of entities when an encryption key is configured.
+func setupStore(logger *zap.Logger, persister persistence.Persister,
+	configDB config.Database,
+) (*store.ObjectStore, error) {
+	keyring, err := configDB.Encryption.Keyring()
+	if err != nil {
+		return nil, err
+	}
+	return store.New(persister, logger.With(zap.String("component",
+		"store"))).WithKeyring(keyring), nil
+}
+
+// setupQuotas returns the quotas of the store.
+func setupQuotas(configQuotas config.Quotas) (*store.Quotas, error) {
+	if configQuotas.MaxDPConfigSize < 0 {
+		return nil, fmt.Errorf("invalid max_dp_config_size: %d", configQuotas.MaxDPConfigSize)
+	}
+	entities, err := entityQuotas(configQuotas.Entities)
+	if err != nil {
+		return nil, err
+	}
+	quotas := &store.Quotas{
+		Entities: entities,
+		Clusters: map[string]map[model.Type]int{},
+	}
+	for cluster, limits := range configQuotas.Clusters {
+		if err := store.ValidateCluster(cluster); err != nil {
+			return nil, err
+		}
+		quotas.Clusters[cluster], err = entityQuotas(limits)
+		if err != nil {
+			return nil, fmt.Errorf("cluster '%s': %w", cluster, err)
+		}
+	}
+	return quotas, nil
+}
+
+func entityQuotas(limits map[string]int) (map[model.Type]int, error) {
+	res := make(map[model.Type]int, len(limits))
+	for name, limit := range limits {
+		typ := model.Type(name)
+		if !model.ValidType(typ) {
+			return nil, fmt.Errorf("unknown entity type: '%s'", name)
+		}
+		if limit < 0 {
+			return nil, fmt.Errorf("invalid limit for '%s': %d", name, limit)
+		}
+		res[typ] = limit
+	}
+	return res, nil
+}
+
 func setupDB(logger *zap.Logger, configDB config.Database) (persistence.Persister, error) {
 	config, err := config.ToDBConfig(configDB, logger)
 	if err != nil {
@@ -518,8 +659,21 @@ func registerInstallation(ctx context.Context, st store.Store, logger *zap.Logge
 	return id, nil
 }
 
+// registerCluster records the cluster of the store, which is the default
+// cluster, so that it is listed along with the clusters created through the
+// admin API.
+func registerCluster(ctx context.Context, st store.Store) error {
+	err := st.CreateCluster(ctx, &store.ClusterInfo{ID: st.Cluster()})
+	if _, ok := err.(store.ErrConstraint); ok {
+		// another node has already recorded the cluster
+		return nil
+	}
+	return err
+}
+
 func getInstallationID(ctx context.Context, st store.Store, inst resource.Installation) (string, error) {
-	if err := st.Read(ctx, inst, store.GetByID(inst.ID())); err != nil {
+	// The ID may just have been created by another node.
+	if err := st.Read(ctx, inst, store.GetByID(inst.ID()), store.ReadFromPrimary()); err != nil {
 		return "", err
 	}
 	return inst.Installation.Value, nil
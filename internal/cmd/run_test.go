@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/google/uuid"
+	"github.com/kong/koko/internal/config"
 	"github.com/kong/koko/internal/resource"
 	"github.com/kong/koko/internal/store"
 	"github.com/kong/koko/internal/test/util"
@@ -74,6 +75,43 @@ func TestRun_SetInstallationID(t *testing.T) {
 	})
 }
 
+func TestRun_SetupQuotas(t *testing.T) {
+	t.Run("converts configured quotas", func(t *testing.T) {
+		quotas, err := setupQuotas(config.Quotas{
+			Entities: map[string]int{"consumer": 10},
+			Clusters: map[string]map[string]int{"east": {"consumer": 5, "route": 1}},
+		})
+		require.NoError(t, err)
+		require.Equal(t, 10, quotas.Limit(store.DefaultCluster, resource.TypeConsumer))
+		require.Equal(t, 5, quotas.Limit("east", resource.TypeConsumer))
+		require.Equal(t, 1, quotas.Limit("east", resource.TypeRoute))
+		require.Equal(t, 0, quotas.Limit(store.DefaultCluster, resource.TypeRoute))
+	})
+
+	t.Run("no quotas means no limits", func(t *testing.T) {
+		quotas, err := setupQuotas(config.Quotas{})
+		require.NoError(t, err)
+		require.Equal(t, 0, quotas.Limit(store.DefaultCluster, resource.TypeConsumer))
+	})
+
+	t.Run("rejects invalid quotas", func(t *testing.T) {
+		_, err := setupQuotas(config.Quotas{Entities: map[string]int{"consumers": 10}})
+		require.EqualError(t, err, "unknown entity type: 'consumers'")
+		_, err = setupQuotas(config.Quotas{Entities: map[string]int{"consumer": -1}})
+		require.EqualError(t, err, "invalid limit for 'consumer': -1")
+		_, err = setupQuotas(config.Quotas{Clusters: map[string]map[string]int{
+			"east": {"foo": 1},
+		}})
+		require.EqualError(t, err, "cluster 'east': unknown entity type: 'foo'")
+		_, err = setupQuotas(config.Quotas{Clusters: map[string]map[string]int{
+			"East!": {"consumer": 1},
+		}})
+		require.EqualError(t, err, "invalid cluster ID: 'East!'")
+		_, err = setupQuotas(config.Quotas{MaxDPConfigSize: -1})
+		require.EqualError(t, err, "invalid max_dp_config_size: -1")
+	})
+}
+
 func setupTestDB(t *testing.T, logger *zap.Logger) store.Store {
 	p, err := util.GetPersister(t)
 	require.NoError(t, err)
@@ -53,8 +53,11 @@ func serveMain(ctx context.Context) error {
 		DPAuthCert:              cert,
 		KongCPCert:              cert,
 		Logger:                  logger,
+		Admin:                   opts.Config.Admin,
+		Control:                 opts.Config.Control,
 		Database:                opts.Config.Database,
 		Metrics:                 opts.Config.Metrics,
+		Quotas:                  opts.Config.Quotas,
 		DisableAnonymousReports: opts.Config.DisableAnonymousReports,
 	})
 }
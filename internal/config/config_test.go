@@ -7,6 +7,7 @@ import (
 	"github.com/kong/koko/internal/db"
 	"github.com/kong/koko/internal/persistence"
 	"github.com/kong/koko/internal/persistence/postgres"
+	"github.com/kong/koko/internal/store"
 	"github.com/stretchr/testify/require"
 )
 
@@ -20,11 +21,14 @@ var defaultConfig = Config{
 	},
 	Control: ControlServer{},
 	Database: Database{
-		Dialect:      db.DialectSQLite3,
-		QueryTimeout: "5s",
+		Dialect:          db.DialectSQLite3,
+		QueryTimeout:     "5s",
+		HistoryLimit:     50,
+		HistoryRetention: store.DefaultHistoryRetention,
 		Postgres: Postgres{
 			Pool: defaultPostgresPool,
 		},
+		Cache: defaultCache,
 	},
 	Metrics: Metrics{
 		ClientType: "noop",
@@ -45,6 +49,11 @@ var defaultPostgresPool = PostgresPool{
 	HealthCheckPeriod: persistence.DefaultHealthCheckPeriod,
 }
 
+var defaultCache = Cache{
+	TTL:          store.DefaultCacheTTL,
+	PollInterval: store.DefaultCachePollInterval,
+}
+
 func TestGet(t *testing.T) {
 	type args struct {
 		filename string
@@ -115,7 +124,10 @@ func TestGet(t *testing.T) {
 							HealthCheckPeriod: duration30s,
 						},
 					},
-					QueryTimeout: "2s",
+					QueryTimeout:     "2s",
+					HistoryLimit:     50,
+					HistoryRetention: store.DefaultHistoryRetention,
+					Cache:            defaultCache,
 				},
 				Metrics: Metrics{
 					ClientType: "noop",
@@ -165,7 +177,10 @@ func TestGet(t *testing.T) {
 						},
 						Pool: defaultPostgresPool,
 					},
-					QueryTimeout: "2s",
+					QueryTimeout:     "2s",
+					HistoryLimit:     50,
+					HistoryRetention: store.DefaultHistoryRetention,
+					Cache:            defaultCache,
 				},
 				Metrics: Metrics{
 					ClientType: "noop",
@@ -216,7 +231,10 @@ func TestGet(t *testing.T) {
 							HealthCheckPeriod: persistence.DefaultHealthCheckPeriod,
 						},
 					},
-					QueryTimeout: "5s",
+					QueryTimeout:     "5s",
+					HistoryLimit:     50,
+					HistoryRetention: store.DefaultHistoryRetention,
+					Cache:            defaultCache,
 				},
 				Metrics: Metrics{
 					ClientType: "noop",
@@ -270,7 +288,10 @@ func TestGet(t *testing.T) {
 						},
 						Pool: defaultPostgresPool,
 					},
-					QueryTimeout: "2s",
+					QueryTimeout:     "2s",
+					HistoryLimit:     50,
+					HistoryRetention: store.DefaultHistoryRetention,
+					Cache:            defaultCache,
 				},
 				Metrics: Metrics{
 					ClientType: "noop",
@@ -1,10 +1,15 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"strings"
 	"time"
 
+	"github.com/kong/koko/internal/crypto"
 	"github.com/kong/koko/internal/db"
+	"github.com/kong/koko/internal/persistence/bolt"
 	"github.com/kong/koko/internal/persistence/mysql"
 	"github.com/kong/koko/internal/persistence/postgres"
 	"github.com/kong/koko/internal/persistence/sqlite"
@@ -19,9 +24,97 @@ type Database struct {
 
 	QueryTimeout string `yaml:"query_timeout" json:"query_timeout" env:"QUERY_TIMEOUT" env-default:"5s"`
 
+	// HistoryLimit is the number of past revisions kept for every entity.
+	// A negative value disables the revision history.
+	HistoryLimit int `yaml:"history_limit" json:"history_limit" env:"HISTORY_LIMIT" env-default:"50"`
+	// HistoryRetention is how long past revisions are kept. The latest
+	// revision of an entity is kept for as long as the entity exists, while
+	// the revisions of deleted entities are all removed once past the
+	// retention. Zero keeps revisions until HistoryLimit is reached.
+	HistoryRetention time.Duration `yaml:"history_retention" json:"history_retention" env:"HISTORY_RETENTION" env-default:"720h"`
+
 	MySQL    MySQL    `yaml:"mysql" json:"mysql" env-prefix:"MYSQL_"`
 	SQLite   SQLite   `yaml:"sqlite" json:"sqlite" env-prefix:"SQLITE_"`
 	Postgres Postgres `yaml:"postgres" json:"postgres" env-prefix:"POSTGRES_"`
+	Bolt     Bolt     `yaml:"bolt" json:"bolt" env-prefix:"BOLT_"`
+
+	Encryption Encryption `yaml:"encryption" json:"encryption" env-prefix:"ENCRYPTION_"`
+
+	Cache Cache `yaml:"cache" json:"cache" env-prefix:"CACHE_"`
+}
+
+// Cache configures the in-process cache of the entities read from the
+// database. The cache of a cluster is invalidated as soon as an entity of the
+// cluster is written, by this instance or, once the write is observed, by
+// another one.
+type Cache struct {
+	Enable bool `yaml:"enable" json:"enable" env:"ENABLE"`
+	// TTL bounds how long entities are cached, and thus how stale they get
+	// when the writes of other instances are not observed yet.
+	TTL time.Duration `yaml:"ttl" json:"ttl" env:"TTL" env-default:"30s"`
+	// PollInterval is the duration between polls of the writes of other
+	// instances. Writes are observed right away when the database pushes
+	// them, e.g.: with Postgres.
+	PollInterval time.Duration `yaml:"poll_interval" json:"poll_interval" env:"POLL_INTERVAL" env-default:"5s"`
+}
+
+// Encryption defines the master keys encrypting the sensitive fields of
+// entities at rest, e.g.: the private keys of certificates. Keys are base64
+// encoded 32 bytes long keys, provided either as is or within a file.
+// Sensitive fields are stored in plain text unless a key is set.
+//
+// To rotate the master key, set the current key as the previous key and the
+// new key as the key, then run 'koko db rotate-key'.
+type Encryption struct {
+	Key             string `yaml:"key" json:"key" env:"KEY"`
+	KeyFile         string `yaml:"key_file" json:"key_file" env:"KEY_FILE"`
+	PreviousKey     string `yaml:"previous_key" json:"previous_key" env:"PREVIOUS_KEY"`
+	PreviousKeyFile string `yaml:"previous_key_file" json:"previous_key_file" env:"PREVIOUS_KEY_FILE"`
+}
+
+// Keyring returns the keyring encrypting sensitive fields, or nil if no key
+// is set.
+func (c *Encryption) Keyring() (*crypto.Keyring, error) {
+	key, err := readKey("key", c.Key, c.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+	previousKey, err := readKey("previous key", c.PreviousKey, c.PreviousKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	if key == "" {
+		if previousKey != "" {
+			return nil, errors.New("encryption previous key set without a key")
+		}
+		return nil, nil
+	}
+	masterKey, err := crypto.NewMasterKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("encryption key: %w", err)
+	}
+	if previousKey == "" {
+		return crypto.NewKeyring(masterKey), nil
+	}
+	previousMasterKey, err := crypto.NewMasterKey(previousKey)
+	if err != nil {
+		return nil, fmt.Errorf("encryption previous key: %w", err)
+	}
+	return crypto.NewKeyring(masterKey, previousMasterKey), nil
+}
+
+func readKey(name, key, filename string) (string, error) {
+	if filename == "" {
+		return key, nil
+	}
+	if key != "" {
+		return "", fmt.Errorf("cannot set both encryption %s file & raw string", name)
+	}
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		return "", fmt.Errorf("unable to read encryption %s file contents: %w", name, err)
+	}
+	return string(b), nil
 }
 
 // MySQL defines configuration for using MySQL as the persistent store.
@@ -38,10 +131,14 @@ type MySQL struct {
 	Params map[string]string `yaml:"params" json:"params" env:"PARAMS"`
 }
 
-// MySQLReadReplica defines configuration for specifying a single read-replica.
+// MySQLReadReplica defines configuration for specifying read-replicas.
 // This configuration overrides fields set in config.MySQL.
 type MySQLReadReplica struct {
 	Hostname string `yaml:"hostname" json:"hostname" env:"HOSTNAME"`
+	// Hostnames is a comma-separated list of additional read replicas.
+	Hostnames string `yaml:"hostnames" json:"hostnames" env:"HOSTNAMES"`
+	// HealthCheckPeriod is the duration between health checks of the replicas.
+	HealthCheckPeriod time.Duration `yaml:"health_check_period" json:"health_check_period" env:"HEALTH_CHECK_PERIOD"`
 }
 
 // Postgres defines configuration for using Postgres as the persistent store.
@@ -65,10 +162,14 @@ type PostgresTLS struct {
 	Enable       bool   `yaml:"enable" json:"enable" env:"ENABLE"`
 }
 
-// PostgresReadReplica allows for using a read replica in addition to the
-// primary which shares the same connection settings as the primary DB.
+// PostgresReadReplica allows for using read replicas in addition to the
+// primary which share the same connection settings as the primary DB.
 type PostgresReadReplica struct {
 	Hostname string `yaml:"hostname" json:"hostname" env:"HOSTNAME"`
+	// Hostnames is a comma-separated list of additional read replicas.
+	Hostnames string `yaml:"hostnames" json:"hostnames" env:"HOSTNAMES"`
+	// HealthCheckPeriod is the duration between health checks of the replicas.
+	HealthCheckPeriod time.Duration `yaml:"health_check_period" json:"health_check_period" env:"HEALTH_CHECK_PERIOD"`
 }
 
 // PostgresPool defines configuration for connections pooling.
@@ -87,6 +188,14 @@ type SQLite struct {
 	InMemory bool   `yaml:"in_memory" json:"in_memory" env:"IN_MEMORY"`
 }
 
+// Bolt defines configuration for using an embedded bbolt database file as the
+// persistent store. The file is locked by a single process at a time.
+type Bolt struct {
+	Filename string `yaml:"filename" json:"filename" env:"FILENAME"`
+	// Timeout to wait for the lock of the file, bolt.DefaultTimeout if zero.
+	Timeout time.Duration `yaml:"timeout" json:"timeout" env:"TIMEOUT"`
+}
+
 // Opts returns the options required to instantiate a MySQL persistence.Persister.
 func (c *MySQL) Opts() (mysql.Opts, error) {
 	if err := c.TLS.fetchFileContents(); err != nil {
@@ -102,6 +211,10 @@ func (c *MySQL) Opts() (mysql.Opts, error) {
 		User:             c.User,
 		Password:         c.Password,
 
+		// Read replica options.
+		ReadOnlyHostnames:        splitList(c.ReadReplica.Hostnames),
+		ReplicaHealthCheckPeriod: c.ReadReplica.HealthCheckPeriod,
+
 		// TLS options.
 		EnableTLS:                c.TLS.Enable,
 		RootCA:                   c.TLS.RootCA,
@@ -130,6 +243,10 @@ func (c *Postgres) Opts() (postgres.Opts, error) {
 		ReadOnlyHostname: c.ReadReplica.Hostname,
 		User:             c.User,
 		Password:         c.Password,
+
+		// Read replica options.
+		ReadOnlyHostnames:        splitList(c.ReadReplica.Hostnames),
+		ReplicaHealthCheckPeriod: c.ReadReplica.HealthCheckPeriod,
 	}, nil
 }
 
@@ -152,6 +269,14 @@ func (c *SQLite) Opts() sqlite.Opts {
 	}
 }
 
+// Opts returns the options required to instantiate a bolt persistence.Persister.
+func (c *Bolt) Opts() bolt.Opts {
+	return bolt.Opts{
+		Filename: c.Filename,
+		Timeout:  c.Timeout,
+	}
+}
+
 // ToDBConfig maps the provided DB application config to the internal representation of the DB config.
 // The resulting config will have its DB config set based on the passed in Database.Dialect.
 func ToDBConfig(config Database, logger *zap.Logger) (db.Config, error) {
@@ -181,5 +306,17 @@ func ToDBConfig(config Database, logger *zap.Logger) (db.Config, error) {
 		MySQL:        mysqlOpts,
 		Postgres:     postgresOpts,
 		SQLite:       config.SQLite.Opts(),
+		Bolt:         config.Bolt.Opts(),
 	}, nil
 }
+
+// splitList splits a comma-separated list, leaving out empty elements.
+func splitList(s string) []string {
+	var res []string
+	for _, e := range strings.Split(s, ",") {
+		if e = strings.TrimSpace(e); e != "" {
+			res = append(res, e)
+		}
+	}
+	return res
+}
@@ -12,11 +12,26 @@ type Log struct {
 
 type AdminServer struct {
 	Address string `yaml:"address" json:"address" env:"ADDRESS" env-default:":3000"`
+	// AllowRevealSecrets allows clients to read the values of sensitive fields,
+	// e.g.: private keys, by setting the Koko-Reveal-Secrets header, or gRPC
+	// metadata, to true. Sensitive fields are otherwise redacted. Access to the header must then
+	// be restricted to privileged clients, e.g.: by a proxy in front of Koko.
+	AllowRevealSecrets bool `yaml:"allow_reveal_secrets" json:"allow_reveal_secrets" env:"ALLOW_REVEAL_SECRETS"`
+	// JWTPublicKey is the PEM-encoded RSA public key verifying the bearer
+	// tokens of requests. The writes of requests holding a valid token are
+	// attributed to the subject of the token in the history of entities, and
+	// to no one when the key is not set.
+	JWTPublicKey string `yaml:"jwt_public_key" json:"jwt_public_key" env:"JWT_PUBLIC_KEY"`
 }
 
 type ControlServer struct {
 	TLSCertPath string `yaml:"tls_cert_path" json:"tls_cert_path" env:"TLS_CERT_PATH"`
 	TLSKeyPath  string `yaml:"tls_key_path" json:"tls_key_path" env:"TLS_KEY_PATH"`
+	// TrustClusterHeader selects the cluster of data planes from the
+	// Koko-Cluster header rather than from their client certificates. It must
+	// only be set when a proxy in front of Koko authenticates data planes and
+	// sets the header.
+	TrustClusterHeader bool `yaml:"trust_cluster_header" json:"trust_cluster_header" env:"TRUST_CLUSTER_HEADER"`
 }
 
 // Metrics config.
@@ -36,6 +51,21 @@ type PrometheusMetrics struct {
 	Address string `yaml:"address" json:"address" env:"ADDRESS" env-default:":9090"`
 }
 
+// Quotas limits the entities of clusters and the configuration of their data
+// planes. A limit of zero means there is no limit.
+type Quotas struct {
+	// Entities maps entity types to the maximum number of entities of the
+	// type that each cluster holds, e.g.: `consumer: 100000`.
+	Entities map[string]int `yaml:"entities" json:"entities" env:"ENTITIES"`
+	// Clusters maps cluster IDs to limits overriding Entities for the
+	// cluster.
+	Clusters map[string]map[string]int `yaml:"clusters" json:"clusters"`
+	// MaxDPConfigSize is the maximum size in bytes of the uncompressed
+	// configuration of data planes. Data planes keep their current
+	// configuration when a larger one is built.
+	MaxDPConfigSize int `yaml:"max_dp_config_size" json:"max_dp_config_size" env:"MAX_DP_CONFIG_SIZE"`
+}
+
 // TLS defines re-usable TLS configuration used in tls.Config.
 //
 // If `TLS.Enable` is true and all other fields are empty, peer certificate
@@ -68,6 +98,7 @@ type Config struct {
 	Control                 ControlServer `yaml:"control_server" json:"control_server" env-prefix:"KOKO_CONTROL_SERVER_"`
 	Database                Database      `yaml:"database" json:"database" env-prefix:"KOKO_DATABASE_"`
 	Metrics                 Metrics       `yaml:"metrics" json:"metrics" env-prefix:"KOKO_METRICS_"`
+	Quotas                  Quotas        `yaml:"quotas" json:"quotas" env-prefix:"KOKO_QUOTAS_"`
 	DisableAnonymousReports bool          `yaml:"disable_anonymous_reports" json:"disable_anonymous_reports" env-prefix:"KOKO_DISABLE_ANONYMOUS_REPORTS"` //nolint:lll
 }
 
@@ -0,0 +1,226 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+const (
+	// MasterKeySize is the size in bytes of a master key, for AES-256.
+	MasterKeySize = 32
+
+	envelopeVersion = "v1"
+	envelopePrefix  = "$koko-enc$" + envelopeVersion + "$"
+	masterKeyIDSize = 8
+)
+
+// envelopeRegex matches the envelopes written by Keyring.Encrypt.
+var envelopeRegex = regexp.MustCompile(
+	`\$koko-enc\$v1\$([0-9a-f]{16})\$([A-Za-z0-9_-]+)\$([A-Za-z0-9_-]+)`)
+
+var encoding = base64.RawURLEncoding
+
+// MasterKey wraps the data keys encrypting values.
+type MasterKey struct {
+	// ID is derived from the key and is recorded in every envelope, in order
+	// to pick the key decrypting it.
+	ID  string
+	key []byte
+}
+
+// NewMasterKey decodes a base64 encoded master key of MasterKeySize bytes.
+func NewMasterKey(encoded string) (MasterKey, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return MasterKey{}, fmt.Errorf("decode master key: %w", err)
+	}
+	if len(key) != MasterKeySize {
+		return MasterKey{}, fmt.Errorf("master key must be %d bytes long, got %d",
+			MasterKeySize, len(key))
+	}
+	sum := sha256.Sum256(key)
+	return MasterKey{ID: hex.EncodeToString(sum[:masterKeyIDSize]), key: key}, nil
+}
+
+// Keyring implements envelope encryption: every value is encrypted with its
+// own random data key, which is itself encrypted (wrapped) with the primary
+// master key and stored along with the value.
+//
+// Values wrapped by any master key of the keyring can be decrypted, so that
+// a previous master key can be kept while values are rotated to a new one.
+type Keyring struct {
+	primary MasterKey
+	keys    map[string]MasterKey
+}
+
+// NewKeyring returns a Keyring encrypting values with primary and decrypting
+// values encrypted with primary or any of previous.
+func NewKeyring(primary MasterKey, previous ...MasterKey) *Keyring {
+	keys := map[string]MasterKey{primary.ID: primary}
+	for _, key := range previous {
+		keys[key.ID] = key
+	}
+	return &Keyring{primary: primary, keys: keys}
+}
+
+// PrimaryKeyID returns the ID of the master key encrypting new values.
+func (k *Keyring) PrimaryKeyID() string {
+	return k.primary.ID
+}
+
+// IsEncrypted returns true if value is an envelope written by a Keyring, as
+// a whole.
+func IsEncrypted(value string) bool {
+	if !strings.HasPrefix(value, envelopePrefix) {
+		return false
+	}
+	_, _, _, err := parseEnvelope(value)
+	return err == nil
+}
+
+// Encrypt encrypts plaintext into a printable envelope, which holds the ID of
+// the primary master key, the wrapped data key and the ciphertext.
+func (k *Keyring) Encrypt(plaintext []byte) (string, error) {
+	dataKey := make([]byte, MasterKeySize)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return "", fmt.Errorf("generate data key: %w", err)
+	}
+	wrappedKey, err := seal(k.primary.key, dataKey)
+	if err != nil {
+		return "", err
+	}
+	ciphertext, err := seal(dataKey, plaintext)
+	if err != nil {
+		return "", err
+	}
+	return envelopePrefix + k.primary.ID + "$" + encoding.EncodeToString(wrappedKey) +
+		"$" + encoding.EncodeToString(ciphertext), nil
+}
+
+// Decrypt decrypts an envelope written by Encrypt with any master key of the
+// keyring.
+func (k *Keyring) Decrypt(envelope string) ([]byte, error) {
+	keyID, wrappedKey, ciphertext, err := parseEnvelope(envelope)
+	if err != nil {
+		return nil, err
+	}
+	dataKey, err := k.unwrap(keyID, wrappedKey)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := open(dataKey, ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt value: %w", err)
+	}
+	return plaintext, nil
+}
+
+// Rewrap re-encrypts the data keys of the envelopes found in data that are
+// wrapped by a master key other than the primary one. The encrypted values
+// themselves are left untouched. It returns the number of envelopes rewrapped.
+func (k *Keyring) Rewrap(data []byte) ([]byte, int, error) {
+	var (
+		count int
+		err   error
+	)
+	res := envelopeRegex.ReplaceAllFunc(data, func(match []byte) []byte {
+		if err != nil {
+			return match
+		}
+		var (
+			keyID                  string
+			wrappedKey, ciphertext []byte
+			dataKey                []byte
+		)
+		keyID, wrappedKey, ciphertext, err = parseEnvelope(string(match))
+		if err != nil || keyID == k.primary.ID {
+			return match
+		}
+		dataKey, err = k.unwrap(keyID, wrappedKey)
+		if err != nil {
+			return match
+		}
+		wrappedKey, err = seal(k.primary.key, dataKey)
+		if err != nil {
+			return match
+		}
+		count++
+		return []byte(envelopePrefix + k.primary.ID + "$" +
+			encoding.EncodeToString(wrappedKey) + "$" + encoding.EncodeToString(ciphertext))
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return res, count, nil
+}
+
+func (k *Keyring) unwrap(keyID string, wrappedKey []byte) ([]byte, error) {
+	masterKey, ok := k.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("value is encrypted with unknown master key '%s'", keyID)
+	}
+	dataKey, err := open(masterKey.key, wrappedKey)
+	if err != nil {
+		return nil, fmt.Errorf("unwrap data key: %w", err)
+	}
+	return dataKey, nil
+}
+
+func parseEnvelope(envelope string) (string, []byte, []byte, error) {
+	match := envelopeRegex.FindStringSubmatch(envelope)
+	if match == nil || match[0] != envelope {
+		return "", nil, nil, errors.New("invalid encrypted value")
+	}
+	wrappedKey, err := encoding.DecodeString(match[2])
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("decode data key: %w", err)
+	}
+	ciphertext, err := encoding.DecodeString(match[3])
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("decode ciphertext: %w", err)
+	}
+	return match[1], wrappedKey, ciphertext, nil
+}
+
+// seal encrypts plaintext with AES-GCM and prepends the random nonce to the
+// ciphertext.
+func seal(key, plaintext []byte) ([]byte, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("generate nonce: %w", err)
+	}
+	return aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func open(key, ciphertext []byte) ([]byte, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	nonce, ciphertext := ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, nil)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,112 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestMasterKey(t *testing.T) MasterKey {
+	b := make([]byte, MasterKeySize)
+	_, err := rand.Read(b)
+	require.Nil(t, err)
+	key, err := NewMasterKey(base64.StdEncoding.EncodeToString(b))
+	require.Nil(t, err)
+	return key
+}
+
+func TestNewMasterKey(t *testing.T) {
+	t.Run("decodes a base64 key", func(t *testing.T) {
+		encoded := base64.StdEncoding.EncodeToString(make([]byte, MasterKeySize))
+		key, err := NewMasterKey(encoded + "\n")
+		require.Nil(t, err)
+		require.Len(t, key.ID, 2*masterKeyIDSize)
+		other, err := NewMasterKey(encoded)
+		require.Nil(t, err)
+		require.Equal(t, key.ID, other.ID)
+	})
+	t.Run("fails with an invalid key", func(t *testing.T) {
+		_, err := NewMasterKey("not base64!")
+		require.NotNil(t, err)
+		_, err = NewMasterKey(base64.StdEncoding.EncodeToString([]byte("short")))
+		require.EqualError(t, err, "master key must be 32 bytes long, got 5")
+	})
+}
+
+func TestKeyring(t *testing.T) {
+	key1, key2 := newTestMasterKey(t), newTestMasterKey(t)
+	keyring1 := NewKeyring(key1)
+
+	t.Run("encrypts and decrypts a value", func(t *testing.T) {
+		envelope, err := keyring1.Encrypt([]byte("secret"))
+		require.Nil(t, err)
+		require.True(t, IsEncrypted(envelope))
+		require.NotContains(t, envelope, "secret")
+		other, err := keyring1.Encrypt([]byte("secret"))
+		require.Nil(t, err)
+		require.NotEqual(t, envelope, other)
+
+		plaintext, err := keyring1.Decrypt(envelope)
+		require.Nil(t, err)
+		require.Equal(t, "secret", string(plaintext))
+	})
+	t.Run("only whole envelopes are encrypted", func(t *testing.T) {
+		envelope, err := keyring1.Encrypt([]byte("secret"))
+		require.Nil(t, err)
+		require.False(t, IsEncrypted("$koko-enc$v1$"))
+		require.False(t, IsEncrypted("$koko-enc$v1$not-an-envelope"))
+		require.False(t, IsEncrypted(envelope+" "))
+		require.False(t, IsEncrypted("prefix "+envelope))
+	})
+	t.Run("decrypts values of previous keys", func(t *testing.T) {
+		envelope, err := keyring1.Encrypt([]byte("secret"))
+		require.Nil(t, err)
+		keyring2 := NewKeyring(key2, key1)
+		plaintext, err := keyring2.Decrypt(envelope)
+		require.Nil(t, err)
+		require.Equal(t, "secret", string(plaintext))
+
+		_, err = NewKeyring(key2).Decrypt(envelope)
+		require.EqualError(t, err, "value is encrypted with unknown master key '"+key1.ID+"'")
+	})
+	t.Run("fails to decrypt tampered values", func(t *testing.T) {
+		envelope, err := keyring1.Encrypt([]byte("secret"))
+		require.Nil(t, err)
+		i := strings.LastIndex(envelope, "$") + 1
+		tampered := envelope[:i] + "A" + envelope[i+1:]
+		if tampered == envelope {
+			tampered = envelope[:i] + "B" + envelope[i+1:]
+		}
+		_, err = keyring1.Decrypt(tampered)
+		require.NotNil(t, err)
+		_, err = keyring1.Decrypt("secret")
+		require.EqualError(t, err, "invalid encrypted value")
+	})
+	t.Run("rewraps the values of previous keys", func(t *testing.T) {
+		keyring2 := NewKeyring(key2, key1)
+		old, err := keyring1.Encrypt([]byte("old"))
+		require.Nil(t, err)
+		current, err := keyring2.Encrypt([]byte("current"))
+		require.Nil(t, err)
+		data := []byte(`{"a":"` + old + `","b":"` + current + `","c":"plain"}`)
+
+		res, count, err := keyring2.Rewrap(data)
+		require.Nil(t, err)
+		require.Equal(t, 1, count)
+		require.Contains(t, string(res), current)
+		require.NotContains(t, string(res), old)
+		require.Contains(t, string(res), `"c":"plain"`)
+
+		keyring3 := NewKeyring(key2)
+		for _, envelope := range envelopeRegex.FindAllString(string(res), -1) {
+			_, err := keyring3.Decrypt(envelope)
+			require.Nil(t, err)
+		}
+
+		_, _, err = NewKeyring(key2).Rewrap(data)
+		require.NotNil(t, err)
+	})
+}
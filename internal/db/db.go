@@ -2,6 +2,7 @@ package db
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 
 	"github.com/kong/koko/internal/persistence/mysql"
@@ -15,8 +16,13 @@ const (
 	DialectMySQL    = "mysql"
 	DialectPostgres = "postgres"
 	DialectSQLite3  = "sqlite3"
+	DialectBolt     = "bolt"
 )
 
+// ErrNotSQL is returned when a SQL DB instance is requested for a dialect
+// that is not backed by a SQL database.
+var ErrNotSQL = errors.New("database is not a SQL database")
+
 // Dialects defines all supported DB dialects.
 //
 // This is internally used in unit tests to ensure support for all dialects have been implemented.
@@ -25,6 +31,7 @@ var Dialects = []string{
 	DialectMySQL,
 	DialectPostgres,
 	DialectSQLite3,
+	DialectBolt,
 }
 
 // NewSQLDBFromConfig returns the relevant *sql.DB instance based on the given dialect set on the config.
@@ -42,6 +49,8 @@ func NewSQLDBFromConfig(config Config) (*sql.DB, error) {
 		db, err = postgres.NewSQLClient(config.Postgres, config.Logger)
 	case DialectSQLite3:
 		db, err = sqlite.NewSQLClient(config.SQLite, config.Logger)
+	case DialectBolt:
+		err = ErrNotSQL
 	default:
 		err = fmt.Errorf("unsupported database '%v'", config.Dialect)
 	}
@@ -14,6 +14,7 @@ import (
 	"github.com/golang-migrate/migrate/v4/database/sqlite3"
 	"github.com/golang-migrate/migrate/v4/source"
 	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/kong/koko/internal/persistence/bolt"
 	mysql2 "github.com/kong/koko/internal/persistence/mysql"
 	postgres2 "github.com/kong/koko/internal/persistence/postgres"
 	"github.com/kong/koko/internal/persistence/sqlite"
@@ -30,12 +31,16 @@ type Config struct {
 	MySQL    mysql2.Opts
 	Postgres postgres2.Opts
 	SQLite   sqlite.Opts
+	Bolt     bolt.Opts
 
 	Logger       *zap.Logger
 	QueryTimeout time.Duration
 }
 
+// Migrator migrates the schema of SQL databases. Other databases have no
+// schema, and are always reported as up-to-date.
 type Migrator struct {
+	// m is nil for databases without a schema.
 	m      *migrate.Migrate
 	logger *zap.Logger
 	config Config
@@ -69,6 +74,8 @@ func driverForDialect(dialect string, db *sql.DB) (database.Driver, error) {
 		dbDriver, err = postgres.WithInstance(db, &postgres.Config{
 			MigrationsTable: postgres.DefaultMigrationsTable,
 		})
+	case DialectBolt:
+		err = ErrNotSQL
 	default:
 		return nil, fmt.Errorf("unsupported database '%v'", dialect)
 	}
@@ -77,6 +84,12 @@ func driverForDialect(dialect string, db *sql.DB) (database.Driver, error) {
 }
 
 func NewMigrator(config Config) (*Migrator, error) {
+	if config.Dialect == DialectBolt {
+		return &Migrator{config: config, logger: config.Logger}, nil
+	}
+	// Migrations may hold multiple statements, e.g.: to create and populate
+	// a table within the same migration.
+	config.MySQL.MultiStatements = true
 	sqlDB, err := NewSQLDBFromConfig(config)
 	if err != nil {
 		return nil, fmt.Errorf("unable to resolve SQL DB instance from the given config: %w", err)
@@ -119,6 +132,9 @@ func (m *Migrator) NeedsMigration() (bool, error) {
 }
 
 func (m *Migrator) Status() (current uint, latest uint, err error) {
+	if m.m == nil {
+		return 0, 0, nil
+	}
 	current, _, err = m.m.Version()
 	if err != nil {
 		if err != migrate.ErrNilVersion {
@@ -157,13 +173,22 @@ func latestVersion(source source.Driver) (uint, error) {
 }
 
 func (m *Migrator) Up() error {
+	if m.m == nil {
+		return migrate.ErrNoChange
+	}
 	return m.m.Up()
 }
 
 func (m *Migrator) Reset() error {
+	if m.m == nil {
+		return migrate.ErrNoChange
+	}
 	return m.m.Down()
 }
 
 func (m *Migrator) Close() (error, error) {
+	if m.m == nil {
+		return nil, nil
+	}
 	return m.m.Close()
 }
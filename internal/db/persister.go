@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/kong/koko/internal/persistence"
+	"github.com/kong/koko/internal/persistence/bolt"
 	"github.com/kong/koko/internal/persistence/mysql"
 	"github.com/kong/koko/internal/persistence/postgres"
 	"github.com/kong/koko/internal/persistence/sqlite"
@@ -24,6 +25,8 @@ func NewPersister(config Config) (persistence.Persister, error) {
 		persister, err = sqlite.New(config.SQLite, config.QueryTimeout, config.Logger)
 	case DialectPostgres:
 		persister, err = postgres.New(config.Postgres, config.QueryTimeout, config.Logger)
+	case DialectBolt:
+		persister, err = bolt.New(config.Bolt, config.QueryTimeout, config.Logger)
 	default:
 		err = fmt.Errorf("unsupported database: %v", config.Dialect)
 	}
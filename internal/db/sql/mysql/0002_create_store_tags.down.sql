@@ -0,0 +1,2 @@
+ALTER TABLE store ADD INDEX tags_idx ((CAST(value -> '$.object.tags' AS CHAR(128) ARRAY)));
+DROP TABLE store_tags;
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS store_tags (
+  `key` VARCHAR(512) NOT NULL,
+  tag VARCHAR(255) NOT NULL COLLATE utf8mb4_bin,
+  PRIMARY KEY (tag, `key`),
+  INDEX store_tags_key_idx (`key`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_unicode_520_ci;
+INSERT IGNORE INTO store_tags (`key`, tag)
+  SELECT s.`key`, t.tag
+  FROM store s, JSON_TABLE(s.value, '$.object.tags[*]' COLUMNS (tag VARCHAR(255) PATH '$')) t
+  WHERE JSON_TYPE(JSON_EXTRACT(s.value, '$.object.tags')) = 'ARRAY' AND t.tag IS NOT NULL;
+ALTER TABLE store DROP INDEX tags_idx;
@@ -0,0 +1,2 @@
+create index if not exists store_value_tags_idx on store USING gin ((value->'object'->'tags') jsonb_path_ops);
+drop table if exists store_tags;
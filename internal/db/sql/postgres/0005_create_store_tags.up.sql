@@ -0,0 +1,10 @@
+create table if not exists store_tags(key text not null, tag text not null, primary key (tag, key));
+create index if not exists store_tags_key_idx on store_tags(key);
+insert into store_tags(key, tag)
+  select s.key, t.value #>> '{}'
+  from store s, jsonb_array_elements(
+    case when jsonb_typeof(s.value->'object'->'tags') = 'array' then s.value->'object'->'tags' else '[]' end
+  ) t
+  where jsonb_typeof(t.value) = 'string'
+on conflict do nothing;
+drop index if exists store_value_tags_idx;
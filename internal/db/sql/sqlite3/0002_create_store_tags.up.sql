@@ -0,0 +1,7 @@
+create table if not exists store_tags(key text not null, tag text not null, primary key (tag, key));
+create index if not exists store_tags_key_idx on store_tags(key);
+insert or ignore into store_tags(key, tag)
+  select s.key, t.value
+  from store s, json_each(case when json_valid(s.value) then s.value else '{}' end, '$.object.tags') t
+  where json_type(case when json_valid(s.value) then s.value else '{}' end, '$.object.tags') = 'array'
+    and t.type = 'text';
@@ -0,0 +1,786 @@
+// Package declarative implements synchronization of the store against a
+// declarative configuration in Kong's `_format_version` 3.0 format.
+package declarative
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/kong/koko/internal/json"
+	"github.com/kong/koko/internal/model"
+	"github.com/kong/koko/internal/model/json/validation"
+	"github.com/kong/koko/internal/resource"
+	"github.com/kong/koko/internal/store"
+	"golang.org/x/exp/slices"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// FormatVersion is the only supported `_format_version`.
+const FormatVersion = "3.0"
+
+// entityType describes how entities of a type appear in a declarative
+// configuration.
+type entityType struct {
+	typ model.Type
+	// key is the key of the entities in the configuration.
+	key string
+	// refs maps reference fields of the entity to the referenced type.
+	refs map[string]model.Type
+	// children maps keys of nested entities to the type of the nested
+	// entities.
+	children map[string]model.Type
+}
+
+// entityTypes are all entity types that can be synchronized, ordered such
+// that referenced types come before the types referencing them.
+var entityTypes = []entityType{
+	{typ: resource.TypeCACertificate, key: "ca_certificates"},
+	{
+		typ:      resource.TypeCertificate,
+		key:      "certificates",
+		children: map[string]model.Type{"snis": resource.TypeSNI},
+	},
+	{
+		typ:  resource.TypeSNI,
+		key:  "snis",
+		refs: map[string]model.Type{"certificate": resource.TypeCertificate},
+	},
+	{typ: resource.TypeVault, key: "vaults"},
+	{
+		typ:      resource.TypeKeySet,
+		key:      "key_sets",
+		children: map[string]model.Type{"keys": resource.TypeKey},
+	},
+	{
+		typ:  resource.TypeKey,
+		key:  "keys",
+		refs: map[string]model.Type{"set": resource.TypeKeySet},
+	},
+	{
+		typ: resource.TypeService,
+		key: "services",
+		children: map[string]model.Type{
+			"routes":  resource.TypeRoute,
+			"plugins": resource.TypePlugin,
+		},
+	},
+	{
+		typ:      resource.TypeUpstream,
+		key:      "upstreams",
+		children: map[string]model.Type{"targets": resource.TypeTarget},
+	},
+	{
+		typ:  resource.TypeTarget,
+		key:  "targets",
+		refs: map[string]model.Type{"upstream": resource.TypeUpstream},
+	},
+	{typ: resource.TypeConsumerGroup, key: "consumer_groups"},
+	{
+		typ:      resource.TypeConsumer,
+		key:      "consumers",
+		children: map[string]model.Type{"plugins": resource.TypePlugin},
+	},
+	{
+		typ:      resource.TypeRoute,
+		key:      "routes",
+		refs:     map[string]model.Type{"service": resource.TypeService},
+		children: map[string]model.Type{"plugins": resource.TypePlugin},
+	},
+	{
+		typ: resource.TypePlugin,
+		key: "plugins",
+		refs: map[string]model.Type{
+			"service":  resource.TypeService,
+			"route":    resource.TypeRoute,
+			"consumer": resource.TypeConsumer,
+		},
+	},
+}
+
+// nameIndexes are the unique indexes used to resolve references by name.
+var nameIndexes = map[model.Type]string{
+	resource.TypeConsumer: "username",
+	resource.TypeVault:    "prefix",
+}
+
+// Types returns the entity types that can be synchronized, ordered such that
+// referenced types come before the types referencing them.
+func Types() []model.Type {
+	res := make([]model.Type, 0, len(entityTypes))
+	for _, et := range entityTypes {
+		res = append(res, et.typ)
+	}
+	return res
+}
+
+func entityTypeOf(typ model.Type) entityType {
+	for _, et := range entityTypes {
+		if et.typ == typ {
+			return et
+		}
+	}
+	panic(fmt.Sprintf("unknown declarative type: %v", typ))
+}
+
+// ErrInvalid is returned when a declarative configuration is invalid.
+type ErrInvalid struct {
+	// Path is the location of the invalid entity in the configuration.
+	// It is empty for errors that are not specific to an entity.
+	Path string
+	Err  error
+}
+
+func (e ErrInvalid) Error() string {
+	if e.Path == "" {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("%s: %v", e.Path, e.Err)
+}
+
+func (e ErrInvalid) Unwrap() error {
+	return e.Err
+}
+
+func invalid(path string, format string, a ...interface{}) error {
+	return ErrInvalid{Path: path, Err: fmt.Errorf(format, a...)}
+}
+
+// Opts configure a synchronization.
+type Opts struct {
+	// SelectTags scope the synchronization to the entities tagged with all
+	// of the tags. Declared entities are tagged with them.
+	SelectTags []string
+	// RestoreRedacted, when set, is called with every declared object before
+	// it is compared with the store, to restore the values of sensitive
+	// fields sent back redacted.
+	RestoreRedacted func(ctx context.Context, object model.Object) error
+}
+
+// Change is a single difference between the store and a declarative
+// configuration.
+type Change struct {
+	Type model.Type
+	ID   string
+	// Path is the location of the declared entity in the configuration,
+	// empty for deletes.
+	Path string
+	// Old is the object in the store, nil for creates.
+	Old model.Object
+	// New is the declared object, nil for deletes.
+	New model.Object
+	// revision is the revision of Old when the changes were computed.
+	revision uint64
+}
+
+// Changes are the differences between the store and a declarative
+// configuration.
+type Changes struct {
+	Creates []Change
+	Updates []Change
+	Deletes []Change
+}
+
+// Empty returns true if the store matches the declarative configuration.
+func (c Changes) Empty() bool {
+	return len(c.Creates) == 0 && len(c.Updates) == 0 && len(c.Deletes) == 0
+}
+
+// Apply applies the changes to db in a single batch. It fails with
+// store.ErrRevisionMismatch if an entity was written since the changes were
+// computed, and with store.ErrHasDependents if a deleted entity is still
+// referenced by entities that are not deleted along with it, e.g.: entities
+// outside of the selected tags.
+func Apply(ctx context.Context, db store.Store, changes Changes) error {
+	ops, opChanges := changes.operations()
+	err := db.ApplyBatch(ctx, ops)
+	var opErr store.ErrBatchOperation
+	if !errors.As(err, &opErr) {
+		return err
+	}
+	var validationErr validation.Error
+	var constraintErr store.ErrConstraint
+	switch {
+	case errors.As(opErr.Err, &validationErr),
+		errors.As(opErr.Err, &constraintErr),
+		errors.Is(opErr.Err, store.ErrNotFound):
+		change := opChanges[opErr.Index]
+		path := change.Path
+		if path == "" {
+			path = fmt.Sprintf("%v '%s'", change.Type, change.ID)
+		}
+		return ErrInvalid{Path: path, Err: opErr.Err}
+	default:
+		return opErr.Err
+	}
+}
+
+// operations returns the batch operations that apply the changes along with
+// the change of each operation.
+// Creates and updates are ordered before deletes, so that entities that no
+// longer reference a deleted entity do not restrict its deletion. Writes
+// taking a unique value of a deleted entity, along with the writes referencing
+// them, are ordered after deletes so that they do not conflict. Deleting an
+// entity still referenced by such writes fails, and takes two syncs.
+func (c Changes) operations() ([]store.BatchOperation, []Change) {
+	writes := make([]Change, 0, len(c.Creates)+len(c.Updates))
+	writes = append(writes, c.Creates...)
+	writes = append(writes, c.Updates...)
+	sort.SliceStable(writes, func(i, j int) bool {
+		return typeOrder(writes[i].Type) < typeOrder(writes[j].Type)
+	})
+
+	deletedValues := map[model.Type]map[string]bool{}
+	for _, change := range c.Deletes {
+		for _, value := range uniqueValues(change.Old) {
+			if deletedValues[change.Type] == nil {
+				deletedValues[change.Type] = map[string]bool{}
+			}
+			deletedValues[change.Type][value] = true
+		}
+	}
+	deferredIDs := map[model.Type]map[string]bool{}
+	var early, deferred []Change
+	for _, change := range writes {
+		if !takesAny(change.New, deletedValues) && !referencesAny(change.New, deferredIDs) {
+			early = append(early, change)
+			continue
+		}
+		if deferredIDs[change.Type] == nil {
+			deferredIDs[change.Type] = map[string]bool{}
+		}
+		deferredIDs[change.Type][change.ID] = true
+		deferred = append(deferred, change)
+	}
+	changes := append(append(early, c.Deletes...), deferred...)
+
+	ops := make([]store.BatchOperation, len(changes))
+	for i, change := range changes {
+		switch {
+		case change.New == nil:
+			ops[i] = store.BatchOperation{
+				Type:             store.BatchOperationDelete,
+				ObjectType:       change.Type,
+				ID:               change.ID,
+				ExpectedRevision: change.revision,
+				DeleteMode:       store.DeleteModeRestrict,
+			}
+		case change.Old == nil:
+			ops[i] = store.BatchOperation{
+				Type:   store.BatchOperationCreate,
+				Object: change.New,
+			}
+		default:
+			ops[i] = store.BatchOperation{
+				Type:             store.BatchOperationUpsert,
+				Object:           change.New,
+				ExpectedRevision: change.revision,
+			}
+		}
+	}
+	return ops, changes
+}
+
+// uniqueValues returns the values of the unique indexes of object, prefixed
+// with the name of their index.
+func uniqueValues(object model.Object) []string {
+	var res []string
+	for _, index := range object.Indexes() {
+		if index.Type == model.IndexUnique && index.Value != "" {
+			res = append(res, index.Name+":"+index.Value)
+		}
+	}
+	return res
+}
+
+// takesAny returns true if object has any of the unique values by type.
+func takesAny(object model.Object, values map[model.Type]map[string]bool) bool {
+	for _, value := range uniqueValues(object) {
+		if values[object.Type()][value] {
+			return true
+		}
+	}
+	return false
+}
+
+// referencesAny returns true if object references any of the objects by type
+// and ID.
+func referencesAny(object model.Object, objects map[model.Type]map[string]bool) bool {
+	for _, index := range object.Indexes() {
+		if index.Type == model.IndexForeign && objects[index.ForeignType][index.Value] {
+			return true
+		}
+	}
+	return false
+}
+
+func typeOrder(typ model.Type) int {
+	for i, et := range entityTypes {
+		if et.typ == typ {
+			return i
+		}
+	}
+	return len(entityTypes)
+}
+
+// Diff computes the changes required for the store to match config.
+func Diff(ctx context.Context, db store.Store, config map[string]interface{},
+	opts Opts,
+) (Changes, error) {
+	selectTags, err := parseHeader(config)
+	if err != nil {
+		return Changes{}, err
+	}
+	opts.SelectTags = append(opts.SelectTags, selectTags...)
+
+	entities, err := parseEntities(config)
+	if err != nil {
+		return Changes{}, err
+	}
+	d := &differ{db: db, opts: opts}
+	if err := d.loadExisting(ctx); err != nil {
+		return Changes{}, err
+	}
+	for _, e := range entities {
+		if err := d.resolve(ctx, e); err != nil {
+			return Changes{}, err
+		}
+	}
+	return d.changes(ctx, entities)
+}
+
+func parseHeader(config map[string]interface{}) ([]string, error) {
+	version, ok := config["_format_version"].(string)
+	if !ok {
+		return nil, invalid("", "'_format_version' is required")
+	}
+	if version != FormatVersion {
+		return nil, invalid("", "unsupported '_format_version': '%s'", version)
+	}
+	info, ok := config["_info"].(map[string]interface{})
+	if !ok {
+		return nil, nil
+	}
+	rawTags, ok := info["select_tags"].([]interface{})
+	if !ok {
+		return nil, nil
+	}
+	tags := make([]string, 0, len(rawTags))
+	for _, rawTag := range rawTags {
+		tag, ok := rawTag.(string)
+		if !ok {
+			return nil, invalid("", "'_info.select_tags' must be a list of strings")
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+// entity is an entity declared in a configuration.
+type entity struct {
+	typ model.Type
+	// path is the location of the entity in the configuration.
+	path string
+	raw  map[string]interface{}
+	// parents maps reference fields to the entity the nested entity is
+	// declared in.
+	parents map[string]*entity
+	object  model.Object
+}
+
+// parseEntities returns all entities declared in config, ordered such that
+// referenced entities come first.
+func parseEntities(config map[string]interface{}) ([]*entity, error) {
+	byType := map[model.Type][]*entity{}
+	for _, key := range sortedKeys(config) {
+		if key == "_format_version" || key == "_info" {
+			continue
+		}
+		et, ok := entityTypeByKey(key)
+		if !ok {
+			return nil, invalid("", "unknown key: '%s'", key)
+		}
+		if err := parseList(byType, et, key, config[key], nil); err != nil {
+			return nil, err
+		}
+	}
+	var res []*entity
+	for _, et := range entityTypes {
+		res = append(res, byType[et.typ]...)
+	}
+	return res, nil
+}
+
+func entityTypeByKey(key string) (entityType, bool) {
+	for _, et := range entityTypes {
+		if et.key == key {
+			return et, true
+		}
+	}
+	return entityType{}, false
+}
+
+func parseList(byType map[model.Type][]*entity, et entityType, path string,
+	value interface{}, parent *entity,
+) error {
+	list, ok := value.([]interface{})
+	if !ok {
+		return invalid(path, "must be a list")
+	}
+	for i, rawEntity := range list {
+		raw, ok := rawEntity.(map[string]interface{})
+		if !ok {
+			return invalid(fmt.Sprintf("%s[%d]", path, i), "must be an object")
+		}
+		e := &entity{
+			typ:     et.typ,
+			path:    fmt.Sprintf("%s[%d]", path, i),
+			raw:     raw,
+			parents: map[string]*entity{},
+		}
+		if parent != nil {
+			field, ok := parentField(et, parent.typ)
+			if !ok {
+				return invalid(e.path, "cannot be nested in %v", parent.typ)
+			}
+			e.parents[field] = parent
+		}
+		byType[et.typ] = append(byType[et.typ], e)
+
+		for _, key := range sortedKeys(et.children) {
+			children, ok := raw[key]
+			if !ok {
+				continue
+			}
+			delete(raw, key)
+			err := parseList(byType, entityTypeOf(et.children[key]),
+				e.path+"."+key, children, e)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func parentField(et entityType, parentType model.Type) (string, bool) {
+	for field, typ := range et.refs {
+		if typ == parentType {
+			return field, true
+		}
+	}
+	return "", false
+}
+
+type differ struct {
+	db   store.Store
+	opts Opts
+	// existing are all objects in the store by type and ID.
+	existing map[model.Type]map[string]model.Object
+	// revisions are the revisions of the existing objects by type and ID.
+	revisions map[model.Type]map[string]uint64
+	// declared are the IDs of all declared entities by type.
+	declared map[model.Type]map[string]*entity
+}
+
+func (d *differ) loadExisting(ctx context.Context) error {
+	d.existing = map[model.Type]map[string]model.Object{}
+	d.revisions = map[model.Type]map[string]uint64{}
+	d.declared = map[model.Type]map[string]*entity{}
+	for _, et := range entityTypes {
+		d.revisions[et.typ] = map[string]uint64{}
+		objects, err := listAll(ctx, d.db, et.typ, d.revisions[et.typ])
+		if err != nil {
+			return err
+		}
+		d.existing[et.typ] = map[string]model.Object{}
+		for _, object := range objects {
+			d.existing[et.typ][object.ID()] = object
+		}
+		d.declared[et.typ] = map[string]*entity{}
+	}
+	return nil
+}
+
+// listAll lists all objects of typ, and fills revisions with the revision of
+// every object.
+func listAll(ctx context.Context, db store.Store, typ model.Type,
+	revisions map[string]uint64,
+) ([]model.Object, error) {
+	var res []model.Object
+	page := 1
+	for page != 0 {
+		list := resource.NewList(typ)
+		if err := db.List(ctx, list, store.ListWithPageSize(store.MaxPageSize),
+			store.ListWithPageNum(page), store.ListRevisions(revisions)); err != nil {
+			return nil, err
+		}
+		res = append(res, list.GetAll()...)
+		page = list.GetNextPage()
+	}
+	return res, nil
+}
+
+// resolve builds the object of e, resolving references and the ID of the
+// object.
+func (d *differ) resolve(ctx context.Context, e *entity) error {
+	et := entityTypeOf(e.typ)
+	for field, parent := range e.parents {
+		e.raw[field] = map[string]interface{}{"id": parent.object.ID()}
+	}
+	for field, refType := range et.refs {
+		ref, ok := e.raw[field].(string)
+		if !ok {
+			continue
+		}
+		id, err := d.resolveRef(ctx, refType, ref)
+		if err != nil {
+			return err
+		}
+		if id == "" {
+			return invalid(e.path+"."+field, "%v '%s' not found", refType, ref)
+		}
+		e.raw[field] = map[string]interface{}{"id": id}
+	}
+	if len(d.opts.SelectTags) > 0 {
+		tags, err := rawTags(e.raw)
+		if err != nil {
+			return ErrInvalid{Path: e.path, Err: err}
+		}
+		for _, tag := range d.opts.SelectTags {
+			if !slices.Contains(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+		e.raw["tags"] = tags
+	}
+
+	object, err := model.NewObject(e.typ)
+	if err != nil {
+		return err
+	}
+	rawJSON, err := json.Marshal(e.raw)
+	if err != nil {
+		return err
+	}
+	if err := json.ProtoJSONUnmarshal(rawJSON, object.Resource()); err != nil {
+		return ErrInvalid{Path: e.path, Err: err}
+	}
+	e.object = object
+
+	if object.ID() == "" {
+		id, err := d.matchExisting(ctx, object)
+		if err != nil {
+			return err
+		}
+		if id == "" {
+			id = uuid.NewString()
+		}
+		setID(object, id)
+	}
+	if _, ok := d.declared[e.typ][object.ID()]; ok {
+		return invalid(e.path, "%v '%s' is declared more than once",
+			e.typ, object.ID())
+	}
+	if old, ok := d.existing[e.typ][object.ID()]; ok && !d.inScope(old) {
+		return invalid(e.path, "%v '%s' exists but is not tagged with "+
+			"the selected tags", e.typ, object.ID())
+	}
+	d.declared[e.typ][object.ID()] = e
+	return nil
+}
+
+// matchExisting returns the ID of the stored object that has the same value
+// for any unique index of object, or an empty string if there is none.
+func (d *differ) matchExisting(ctx context.Context, object model.Object) (string, error) {
+	for _, index := range object.Indexes() {
+		if index.Type != model.IndexUnique || index.Value == "" {
+			continue
+		}
+		existing, err := model.NewObject(object.Type())
+		if err != nil {
+			return "", err
+		}
+		err = d.db.Read(ctx, existing, store.GetByIndex(index.Name, index.Value))
+		if err == nil {
+			return existing.ID(), nil
+		}
+		if err != store.ErrNotFound {
+			return "", err
+		}
+	}
+	return "", nil
+}
+
+// resolveRef returns the ID of the entity referenced by ref, which is either
+// an ID or a name. An empty ID is returned if no such entity exists.
+func (d *differ) resolveRef(ctx context.Context, typ model.Type, ref string) (string, error) {
+	if _, err := uuid.Parse(ref); err == nil {
+		return ref, nil
+	}
+	indexName, ok := nameIndexes[typ]
+	if !ok {
+		indexName = "name"
+	}
+	for id, e := range d.declared[typ] {
+		for _, index := range e.object.Indexes() {
+			if index.Name == indexName && index.Value == ref {
+				return id, nil
+			}
+		}
+	}
+	object, err := model.NewObject(typ)
+	if err != nil {
+		return "", err
+	}
+	err = d.db.Read(ctx, object, store.GetByIndex(indexName, ref))
+	if err != nil {
+		if err == store.ErrNotFound {
+			return "", nil
+		}
+		return "", err
+	}
+	return object.ID(), nil
+}
+
+func (d *differ) inScope(object model.Object) bool {
+	if len(d.opts.SelectTags) == 0 {
+		return true
+	}
+	tags := objectTags(object)
+	for _, tag := range d.opts.SelectTags {
+		if !slices.Contains(tags, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+func (d *differ) changes(ctx context.Context, entities []*entity) (Changes, error) {
+	var res Changes
+	for _, e := range entities {
+		if err := e.object.ProcessDefaults(ctx); err != nil {
+			return Changes{}, ErrInvalid{Path: e.path, Err: err}
+		}
+		if d.opts.RestoreRedacted != nil {
+			if err := d.opts.RestoreRedacted(ctx, e.object); err != nil {
+				return Changes{}, ErrInvalid{Path: e.path, Err: err}
+			}
+		}
+		change := Change{
+			Type: e.typ,
+			ID:   e.object.ID(),
+			Path: e.path,
+			New:  e.object,
+		}
+		old, ok := d.existing[e.typ][e.object.ID()]
+		if ok && equalObjects(old, e.object) {
+			continue
+		}
+		if err := validate(ctx, e.object); err != nil {
+			return Changes{}, ErrInvalid{Path: e.path, Err: err}
+		}
+		if !ok {
+			res.Creates = append(res.Creates, change)
+			continue
+		}
+		change.Old, change.revision = old, d.revisions[e.typ][e.object.ID()]
+		res.Updates = append(res.Updates, change)
+	}
+	for i := len(entityTypes) - 1; i >= 0; i-- {
+		typ := entityTypes[i].typ
+		ids := make([]string, 0, len(d.existing[typ]))
+		for id, object := range d.existing[typ] {
+			if _, ok := d.declared[typ][id]; ok || !d.inScope(object) {
+				continue
+			}
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		for _, id := range ids {
+			res.Deletes = append(res.Deletes, Change{
+				Type:     typ,
+				ID:       id,
+				Old:      d.existing[typ][id],
+				revision: d.revisions[typ][id],
+			})
+		}
+	}
+	return res, nil
+}
+
+func validate(ctx context.Context, object model.Object) error {
+	if err := object.Validate(ctx); err != nil {
+		return err
+	}
+	return model.Indexes(object.Indexes()).Validate()
+}
+
+// equalObjects returns true if the declared object matches the stored one.
+// Timestamps are ignored.
+func equalObjects(stored, declared model.Object) bool {
+	a, b := proto.Clone(stored.Resource()), proto.Clone(declared.Resource())
+	clearTimestamps(a)
+	clearTimestamps(b)
+	return proto.Equal(a, b)
+}
+
+func clearTimestamps(m proto.Message) {
+	r := m.ProtoReflect()
+	for _, name := range []protoreflect.Name{"created_at", "updated_at"} {
+		if field := r.Descriptor().Fields().ByName(name); field != nil {
+			r.Clear(field)
+		}
+	}
+}
+
+func setID(object model.Object, id string) {
+	r := object.Resource().ProtoReflect()
+	r.Set(r.Descriptor().Fields().ByName("id"), protoreflect.ValueOfString(id))
+}
+
+func objectTags(object model.Object) []string {
+	r := object.Resource().ProtoReflect()
+	field := r.Descriptor().Fields().ByName("tags")
+	if field == nil {
+		return nil
+	}
+	list := r.Get(field).List()
+	tags := make([]string, list.Len())
+	for i := 0; i < list.Len(); i++ {
+		tags[i] = list.Get(i).String()
+	}
+	return tags
+}
+
+func rawTags(raw map[string]interface{}) ([]string, error) {
+	value, ok := raw["tags"]
+	if !ok || value == nil {
+		return nil, nil
+	}
+	list, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("'tags' must be a list of strings")
+	}
+	tags := make([]string, 0, len(list))
+	for _, rawTag := range list {
+		tag, ok := rawTag.(string)
+		if !ok {
+			return nil, fmt.Errorf("'tags' must be a list of strings")
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
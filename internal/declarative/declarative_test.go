@@ -0,0 +1,284 @@
+package declarative
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	v1 "github.com/kong/koko/internal/gen/grpc/kong/admin/model/v1"
+	"github.com/kong/koko/internal/json"
+	"github.com/kong/koko/internal/log"
+	"github.com/kong/koko/internal/model"
+	"github.com/kong/koko/internal/model/json/validation"
+	"github.com/kong/koko/internal/resource"
+	"github.com/kong/koko/internal/store"
+	"github.com/kong/koko/internal/test/util"
+	"github.com/stretchr/testify/require"
+)
+
+func parseConfig(t *testing.T, config string) map[string]interface{} {
+	var res map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(config), &res))
+	return res
+}
+
+func changeIDs(changes []Change) map[model.Type][]string {
+	res := map[model.Type][]string{}
+	for _, change := range changes {
+		res[change.Type] = append(res[change.Type], change.ID)
+	}
+	return res
+}
+
+func sync(t *testing.T, db store.Store, config string, opts Opts) Changes {
+	ctx := context.Background()
+	changes, err := Diff(ctx, db, parseConfig(t, config), opts)
+	require.NoError(t, err)
+	require.NoError(t, Apply(ctx, db, changes))
+	return changes
+}
+
+func TestDiff(t *testing.T) {
+	persister, err := util.GetPersister(t)
+	require.NoError(t, err)
+	db := store.New(persister, log.Logger).ForCluster(store.DefaultCluster)
+	ctx := context.Background()
+
+	serviceID := uuid.NewString()
+	config := `{
+  "_format_version": "3.0",
+  "services": [
+    {
+      "id": "` + serviceID + `",
+      "name": "foo",
+      "host": "foo.example.com",
+      "routes": [
+        {"name": "foo-route", "paths": ["/foo"]}
+      ]
+    }
+  ],
+  "routes": [
+    {"name": "bar-route", "paths": ["/bar"], "service": "foo"}
+  ]
+}`
+
+	t.Run("creates declared entities", func(t *testing.T) {
+		changes := sync(t, db, config, Opts{})
+		require.Len(t, changes.Creates, 3)
+		require.Empty(t, changes.Updates)
+		require.Empty(t, changes.Deletes)
+
+		route := resource.NewRoute()
+		require.NoError(t, db.Read(ctx, route, store.GetByName("foo-route")))
+		require.Equal(t, serviceID, route.Route.Service.Id)
+		route = resource.NewRoute()
+		require.NoError(t, db.Read(ctx, route, store.GetByName("bar-route")))
+		require.Equal(t, serviceID, route.Route.Service.Id)
+	})
+	t.Run("syncing the same configuration is a no-op", func(t *testing.T) {
+		changes, err := Diff(ctx, db, parseConfig(t, config), Opts{})
+		require.NoError(t, err)
+		require.True(t, changes.Empty())
+	})
+	t.Run("entities are matched by name", func(t *testing.T) {
+		changes := sync(t, db, `{
+  "_format_version": "3.0",
+  "services": [
+    {
+      "name": "foo",
+      "host": "bar.example.com",
+      "routes": [
+        {"name": "foo-route", "paths": ["/foo"]}
+      ]
+    }
+  ]
+}`, Opts{})
+		require.Empty(t, changes.Creates)
+		require.Equal(t, map[model.Type][]string{
+			resource.TypeService: {serviceID},
+		}, changeIDs(changes.Updates))
+		require.Len(t, changes.Deletes, 1)
+		require.Equal(t, resource.TypeRoute, changes.Deletes[0].Type)
+
+		service := resource.NewService()
+		require.NoError(t, db.Read(ctx, service, store.GetByID(serviceID)))
+		require.Equal(t, "bar.example.com", service.Service.Host)
+		require.Equal(t, store.ErrNotFound, db.Read(ctx, resource.NewRoute(),
+			store.GetByName("bar-route")))
+	})
+	t.Run("invalid entities are reported with their path", func(t *testing.T) {
+		_, err := Diff(ctx, db, parseConfig(t, `{
+  "_format_version": "3.0",
+  "services": [
+    {"name": "foo", "host": "foo.example.com", "routes": [{"name": "r"}]}
+  ]
+}`), Opts{})
+		var invalidErr ErrInvalid
+		require.ErrorAs(t, err, &invalidErr)
+		require.Equal(t, "services[0].routes[0]", invalidErr.Path)
+		require.IsType(t, validation.Error{}, invalidErr.Err)
+	})
+	t.Run("unknown references fail", func(t *testing.T) {
+		_, err := Diff(ctx, db, parseConfig(t, `{
+  "_format_version": "3.0",
+  "routes": [{"name": "r", "paths": ["/"], "service": "unknown"}]
+}`), Opts{})
+		require.EqualError(t, err, "routes[0].service: service 'unknown' not found")
+	})
+	t.Run("unsupported format version fails", func(t *testing.T) {
+		_, err := Diff(ctx, db, parseConfig(t, `{"_format_version": "2.1"}`), Opts{})
+		require.EqualError(t, err, "unsupported '_format_version': '2.1'")
+	})
+	t.Run("unknown keys fail", func(t *testing.T) {
+		_, err := Diff(ctx, db, parseConfig(t, `{
+  "_format_version": "3.0",
+  "nodes": []
+}`), Opts{})
+		require.EqualError(t, err, "unknown key: 'nodes'")
+	})
+}
+
+func TestDiffSelectTags(t *testing.T) {
+	persister, err := util.GetPersister(t)
+	require.NoError(t, err)
+	db := store.New(persister, log.Logger).ForCluster(store.DefaultCluster)
+	ctx := context.Background()
+
+	sync(t, db, `{
+  "_format_version": "3.0",
+  "services": [{"name": "a", "host": "a.example.com"}]
+}`, Opts{SelectTags: []string{"team-a"}})
+	sync(t, db, `{
+  "_format_version": "3.0",
+  "_info": {"select_tags": ["team-b"]},
+  "services": [{"name": "b", "host": "b.example.com"}]
+}`, Opts{})
+
+	t.Run("declared entities are tagged", func(t *testing.T) {
+		service := resource.NewService()
+		require.NoError(t, db.Read(ctx, service, store.GetByName("a")))
+		require.Equal(t, []string{"team-a"}, service.Service.Tags)
+	})
+	t.Run("entities of other tags are not deleted", func(t *testing.T) {
+		changes := sync(t, db, `{"_format_version": "3.0"}`,
+			Opts{SelectTags: []string{"team-a"}})
+		require.Len(t, changes.Deletes, 1)
+		require.Equal(t, store.ErrNotFound, db.Read(ctx, resource.NewService(),
+			store.GetByName("a")))
+		require.NoError(t, db.Read(ctx, resource.NewService(),
+			store.GetByName("b")))
+	})
+	t.Run("entities of other tags cannot be declared", func(t *testing.T) {
+		_, err := Diff(ctx, db, parseConfig(t, `{
+  "_format_version": "3.0",
+  "services": [{"name": "b", "host": "b.example.com"}]
+}`), Opts{SelectTags: []string{"team-a"}})
+		var invalidErr ErrInvalid
+		require.ErrorAs(t, err, &invalidErr)
+		require.Equal(t, "services[0]", invalidErr.Path)
+	})
+}
+
+func TestApply(t *testing.T) {
+	persister, err := util.GetPersister(t)
+	require.NoError(t, err)
+	db := store.New(persister, log.Logger).ForCluster(store.DefaultCluster)
+	ctx := context.Background()
+
+	t.Run("errors of the batch are reported with the entity path", func(t *testing.T) {
+		changes, err := Diff(ctx, db, parseConfig(t, `{
+  "_format_version": "3.0",
+  "services": [{"name": "a", "host": "a.example.com"}]
+}`), Opts{})
+		require.NoError(t, err)
+
+		service := resource.NewService()
+		service.Service = &v1.Service{Name: "a", Host: "a.example.com"}
+		require.NoError(t, db.Create(ctx, service))
+
+		err = Apply(ctx, db, changes)
+		var invalidErr ErrInvalid
+		require.ErrorAs(t, err, &invalidErr)
+		require.Equal(t, "services[0]", invalidErr.Path)
+		require.IsType(t, store.ErrConstraint{}, invalidErr.Err)
+	})
+	t.Run("entities written since the diff fail", func(t *testing.T) {
+		sync(t, db, `{
+  "_format_version": "3.0",
+  "services": [{"name": "c", "host": "c.example.com"}]
+}`, Opts{})
+		changes, err := Diff(ctx, db, parseConfig(t, `{
+  "_format_version": "3.0",
+  "services": [{"name": "c", "host": "c2.example.com"}]
+}`), Opts{})
+		require.NoError(t, err)
+		require.Len(t, changes.Updates, 1)
+
+		service := resource.NewService()
+		require.NoError(t, db.Read(ctx, service, store.GetByName("c")))
+		service.Service.Port = 8080
+		require.NoError(t, db.Upsert(ctx, service))
+
+		err = Apply(ctx, db, changes)
+		require.ErrorAs(t, err, &store.ErrRevisionMismatch{})
+		require.NoError(t, db.Read(ctx, service, store.GetByName("c")))
+		require.Equal(t, "c.example.com", service.Service.Host)
+	})
+	t.Run("entities re-pointed from deleted entities are updated first", func(t *testing.T) {
+		sync(t, db, `{
+  "_format_version": "3.0",
+  "services": [
+    {"name": "d", "host": "d.example.com", "routes": [{"name": "r", "paths": ["/"]}]}
+  ]
+}`, Opts{})
+		changes := sync(t, db, `{
+  "_format_version": "3.0",
+  "services": [
+    {"name": "e", "host": "e.example.com", "routes": [{"name": "r", "paths": ["/"]}]}
+  ]
+}`, Opts{})
+		require.Len(t, changes.Updates, 1)
+		route := resource.NewRoute()
+		require.NoError(t, db.Read(ctx, route, store.GetByName("r")))
+		service := resource.NewService()
+		require.NoError(t, db.Read(ctx, service, store.GetByName("e")))
+		require.Equal(t, service.ID(), route.Route.Service.Id)
+	})
+	t.Run("entities taking the name of deleted entities are written last", func(t *testing.T) {
+		id := uuid.NewString()
+		changes := sync(t, db, `{
+  "_format_version": "3.0",
+  "services": [{"id": "`+id+`", "name": "e", "host": "e.example.com"}]
+}`, Opts{})
+		require.Len(t, changes.Creates, 1)
+		require.Len(t, changes.Deletes, 2)
+		service := resource.NewService()
+		require.NoError(t, db.Read(ctx, service, store.GetByName("e")))
+		require.Equal(t, id, service.ID())
+	})
+	t.Run("deleting entities referenced outside of the selected tags fails", func(t *testing.T) {
+		sync(t, db, `{
+  "_format_version": "3.0",
+  "services": [{"name": "f", "host": "f.example.com"}]
+}`, Opts{SelectTags: []string{"team-f"}})
+		service := resource.NewService()
+		require.NoError(t, db.Read(ctx, service, store.GetByName("f")))
+		route := resource.NewRoute()
+		route.Route = &v1.Route{
+			Id:      uuid.NewString(),
+			Paths:   []string{"/f"},
+			Service: &v1.Service{Id: service.ID()},
+		}
+		require.NoError(t, db.Create(ctx, route))
+
+		changes, err := Diff(ctx, db, parseConfig(t, `{"_format_version": "3.0"}`),
+			Opts{SelectTags: []string{"team-f"}})
+		require.NoError(t, err)
+		err = Apply(ctx, db, changes)
+		var dependentsErr store.ErrHasDependents
+		require.ErrorAs(t, err, &dependentsErr)
+		require.Equal(t, []store.Dependent{{Type: resource.TypeRoute, ID: route.ID()}},
+			dependentsErr.Dependents)
+		require.NoError(t, db.Read(ctx, service, store.GetByName("f")))
+	})
+}
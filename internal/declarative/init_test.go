@@ -0,0 +1,7 @@
+package declarative
+
+import "github.com/kong/koko/internal/test/util"
+
+func init() {
+	util.RegisterSchemasFromFS()
+}
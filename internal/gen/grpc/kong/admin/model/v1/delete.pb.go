@@ -0,0 +1,219 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.28.1-devel
+// 	protoc        (unknown)
+// source: kong/admin/model/v1/delete.proto
+
+package v1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Dictates how the dependents of a deleted resource are handled, being the
+// resources referencing it, e.g.: the routes and plugins of a service.
+type DeleteMode int32
+
+const (
+	// Same as `DELETE_MODE_CASCADE`.
+	DeleteMode_DELETE_MODE_UNSPECIFIED DeleteMode = 0
+	// Delete the dependents recursively, along with the resource.
+	DeleteMode_DELETE_MODE_CASCADE DeleteMode = 1
+	// Refuse to delete the resource while it has dependents, listing them.
+	DeleteMode_DELETE_MODE_RESTRICT DeleteMode = 2
+)
+
+// Enum value maps for DeleteMode.
+var (
+	DeleteMode_name = map[int32]string{
+		0: "DELETE_MODE_UNSPECIFIED",
+		1: "DELETE_MODE_CASCADE",
+		2: "DELETE_MODE_RESTRICT",
+	}
+	DeleteMode_value = map[string]int32{
+		"DELETE_MODE_UNSPECIFIED": 0,
+		"DELETE_MODE_CASCADE":     1,
+		"DELETE_MODE_RESTRICT":    2,
+	}
+)
+
+func (x DeleteMode) Enum() *DeleteMode {
+	p := new(DeleteMode)
+	*p = x
+	return p
+}
+
+func (x DeleteMode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (DeleteMode) Descriptor() protoreflect.EnumDescriptor {
+	return file_kong_admin_model_v1_delete_proto_enumTypes[0].Descriptor()
+}
+
+func (DeleteMode) Type() protoreflect.EnumType {
+	return &file_kong_admin_model_v1_delete_proto_enumTypes[0]
+}
+
+func (x DeleteMode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use DeleteMode.Descriptor instead.
+func (DeleteMode) EnumDescriptor() ([]byte, []int) {
+	return file_kong_admin_model_v1_delete_proto_rawDescGZIP(), []int{0}
+}
+
+type EntityReference struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Type string `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	Id   string `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+}
+
+func (x *EntityReference) Reset() {
+	*x = EntityReference{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_kong_admin_model_v1_delete_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *EntityReference) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EntityReference) ProtoMessage() {}
+
+func (x *EntityReference) ProtoReflect() protoreflect.Message {
+	mi := &file_kong_admin_model_v1_delete_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EntityReference.ProtoReflect.Descriptor instead.
+func (*EntityReference) Descriptor() ([]byte, []int) {
+	return file_kong_admin_model_v1_delete_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *EntityReference) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *EntityReference) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+var File_kong_admin_model_v1_delete_proto protoreflect.FileDescriptor
+
+var file_kong_admin_model_v1_delete_proto_rawDesc = []byte{
+	0x0a, 0x20, 0x6b, 0x6f, 0x6e, 0x67, 0x2f, 0x61, 0x64, 0x6d, 0x69, 0x6e, 0x2f, 0x6d, 0x6f, 0x64,
+	0x65, 0x6c, 0x2f, 0x76, 0x31, 0x2f, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x12, 0x13, 0x6b, 0x6f, 0x6e, 0x67, 0x2e, 0x61, 0x64, 0x6d, 0x69, 0x6e, 0x2e, 0x6d,
+	0x6f, 0x64, 0x65, 0x6c, 0x2e, 0x76, 0x31, 0x22, 0x35, 0x0a, 0x0f, 0x45, 0x6e, 0x74, 0x69, 0x74,
+	0x79, 0x52, 0x65, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x79,
+	0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x0e,
+	0x0a, 0x02, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x2a, 0x5c,
+	0x0a, 0x0a, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x4d, 0x6f, 0x64, 0x65, 0x12, 0x1b, 0x0a, 0x17,
+	0x44, 0x45, 0x4c, 0x45, 0x54, 0x45, 0x5f, 0x4d, 0x4f, 0x44, 0x45, 0x5f, 0x55, 0x4e, 0x53, 0x50,
+	0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x17, 0x0a, 0x13, 0x44, 0x45, 0x4c,
+	0x45, 0x54, 0x45, 0x5f, 0x4d, 0x4f, 0x44, 0x45, 0x5f, 0x43, 0x41, 0x53, 0x43, 0x41, 0x44, 0x45,
+	0x10, 0x01, 0x12, 0x18, 0x0a, 0x14, 0x44, 0x45, 0x4c, 0x45, 0x54, 0x45, 0x5f, 0x4d, 0x4f, 0x44,
+	0x45, 0x5f, 0x52, 0x45, 0x53, 0x54, 0x52, 0x49, 0x43, 0x54, 0x10, 0x02, 0x42, 0x3f, 0x5a, 0x3d,
+	0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6b, 0x6f, 0x6e, 0x67, 0x2f,
+	0x6b, 0x6f, 0x6b, 0x6f, 0x2f, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x2f, 0x67, 0x65,
+	0x6e, 0x2f, 0x67, 0x72, 0x70, 0x63, 0x2f, 0x6b, 0x6f, 0x6e, 0x67, 0x2f, 0x61, 0x64, 0x6d, 0x69,
+	0x6e, 0x2f, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x2f, 0x76, 0x31, 0x3b, 0x76, 0x31, 0x62, 0x06, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_kong_admin_model_v1_delete_proto_rawDescOnce sync.Once
+	file_kong_admin_model_v1_delete_proto_rawDescData = file_kong_admin_model_v1_delete_proto_rawDesc
+)
+
+func file_kong_admin_model_v1_delete_proto_rawDescGZIP() []byte {
+	file_kong_admin_model_v1_delete_proto_rawDescOnce.Do(func() {
+		file_kong_admin_model_v1_delete_proto_rawDescData = protoimpl.X.CompressGZIP(file_kong_admin_model_v1_delete_proto_rawDescData)
+	})
+	return file_kong_admin_model_v1_delete_proto_rawDescData
+}
+
+var file_kong_admin_model_v1_delete_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_kong_admin_model_v1_delete_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_kong_admin_model_v1_delete_proto_goTypes = []interface{}{
+	(DeleteMode)(0),         // 0: kong.admin.model.v1.DeleteMode
+	(*EntityReference)(nil), // 1: kong.admin.model.v1.EntityReference
+}
+var file_kong_admin_model_v1_delete_proto_depIdxs = []int32{
+	0, // [0:0] is the sub-list for method output_type
+	0, // [0:0] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_kong_admin_model_v1_delete_proto_init() }
+func file_kong_admin_model_v1_delete_proto_init() {
+	if File_kong_admin_model_v1_delete_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_kong_admin_model_v1_delete_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*EntityReference); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_kong_admin_model_v1_delete_proto_rawDesc,
+			NumEnums:      1,
+			NumMessages:   1,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_kong_admin_model_v1_delete_proto_goTypes,
+		DependencyIndexes: file_kong_admin_model_v1_delete_proto_depIdxs,
+		EnumInfos:         file_kong_admin_model_v1_delete_proto_enumTypes,
+		MessageInfos:      file_kong_admin_model_v1_delete_proto_msgTypes,
+	}.Build()
+	File_kong_admin_model_v1_delete_proto = out.File
+	file_kong_admin_model_v1_delete_proto_rawDesc = nil
+	file_kong_admin_model_v1_delete_proto_goTypes = nil
+	file_kong_admin_model_v1_delete_proto_depIdxs = nil
+}
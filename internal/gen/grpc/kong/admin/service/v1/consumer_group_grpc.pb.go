@@ -35,6 +35,8 @@ type ConsumerGroupServiceClient interface {
 	UpsertConsumerGroupRateLimitingAdvancedConfig(ctx context.Context, in *UpsertConsumerGroupRateLimitingAdvancedConfigRequest, opts ...grpc.CallOption) (*UpsertConsumerGroupRateLimitingAdvancedConfigResponse, error)
 	DeleteConsumerGroupRateLimitingAdvancedConfig(ctx context.Context, in *DeleteConsumerGroupRateLimitingAdvancedConfigRequest, opts ...grpc.CallOption) (*DeleteConsumerGroupRateLimitingAdvancedConfigResponse, error)
 	ListConsumerGroupRateLimitingAdvancedConfig(ctx context.Context, in *ListConsumerGroupRateLimitingAdvancedConfigRequest, opts ...grpc.CallOption) (*ListConsumerGroupRateLimitingAdvancedConfigResponse, error)
+	// Lists the members of every consumer group at once.
+	ListConsumerGroupMemberships(ctx context.Context, in *ListConsumerGroupMembershipsRequest, opts ...grpc.CallOption) (*ListConsumerGroupMembershipsResponse, error)
 }
 
 type consumerGroupServiceClient struct {
@@ -162,6 +164,15 @@ func (c *consumerGroupServiceClient) ListConsumerGroupRateLimitingAdvancedConfig
 	return out, nil
 }
 
+func (c *consumerGroupServiceClient) ListConsumerGroupMemberships(ctx context.Context, in *ListConsumerGroupMembershipsRequest, opts ...grpc.CallOption) (*ListConsumerGroupMembershipsResponse, error) {
+	out := new(ListConsumerGroupMembershipsResponse)
+	err := c.cc.Invoke(ctx, "/kong.admin.service.v1.ConsumerGroupService/ListConsumerGroupMemberships", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ConsumerGroupServiceServer is the server API for ConsumerGroupService service.
 // All implementations must embed UnimplementedConsumerGroupServiceServer
 // for forward compatibility
@@ -179,6 +190,8 @@ type ConsumerGroupServiceServer interface {
 	UpsertConsumerGroupRateLimitingAdvancedConfig(context.Context, *UpsertConsumerGroupRateLimitingAdvancedConfigRequest) (*UpsertConsumerGroupRateLimitingAdvancedConfigResponse, error)
 	DeleteConsumerGroupRateLimitingAdvancedConfig(context.Context, *DeleteConsumerGroupRateLimitingAdvancedConfigRequest) (*DeleteConsumerGroupRateLimitingAdvancedConfigResponse, error)
 	ListConsumerGroupRateLimitingAdvancedConfig(context.Context, *ListConsumerGroupRateLimitingAdvancedConfigRequest) (*ListConsumerGroupRateLimitingAdvancedConfigResponse, error)
+	// Lists the members of every consumer group at once.
+	ListConsumerGroupMemberships(context.Context, *ListConsumerGroupMembershipsRequest) (*ListConsumerGroupMembershipsResponse, error)
 	mustEmbedUnimplementedConsumerGroupServiceServer()
 }
 
@@ -225,6 +238,9 @@ func (UnimplementedConsumerGroupServiceServer) DeleteConsumerGroupRateLimitingAd
 func (UnimplementedConsumerGroupServiceServer) ListConsumerGroupRateLimitingAdvancedConfig(context.Context, *ListConsumerGroupRateLimitingAdvancedConfigRequest) (*ListConsumerGroupRateLimitingAdvancedConfigResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ListConsumerGroupRateLimitingAdvancedConfig not implemented")
 }
+func (UnimplementedConsumerGroupServiceServer) ListConsumerGroupMemberships(context.Context, *ListConsumerGroupMembershipsRequest) (*ListConsumerGroupMembershipsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListConsumerGroupMemberships not implemented")
+}
 func (UnimplementedConsumerGroupServiceServer) mustEmbedUnimplementedConsumerGroupServiceServer() {}
 
 // UnsafeConsumerGroupServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -472,6 +488,24 @@ func _ConsumerGroupService_ListConsumerGroupRateLimitingAdvancedConfig_Handler(s
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ConsumerGroupService_ListConsumerGroupMemberships_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListConsumerGroupMembershipsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConsumerGroupServiceServer).ListConsumerGroupMemberships(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/kong.admin.service.v1.ConsumerGroupService/ListConsumerGroupMemberships",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConsumerGroupServiceServer).ListConsumerGroupMemberships(ctx, req.(*ListConsumerGroupMembershipsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // ConsumerGroupService_ServiceDesc is the grpc.ServiceDesc for ConsumerGroupService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -531,6 +565,10 @@ var ConsumerGroupService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ListConsumerGroupRateLimitingAdvancedConfig",
 			Handler:    _ConsumerGroupService_ListConsumerGroupRateLimitingAdvancedConfig_Handler,
 		},
+		{
+			MethodName: "ListConsumerGroupMemberships",
+			Handler:    _ConsumerGroupService_ListConsumerGroupMemberships_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "kong/admin/service/v1/consumer_group.proto",
@@ -0,0 +1,400 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.28.1-devel
+// 	protoc        (unknown)
+// source: kong/admin/service/v1/declarative.proto
+
+package v1
+
+import (
+	v1 "github.com/kong/koko/internal/gen/grpc/kong/admin/model/v1"
+	_ "google.golang.org/genproto/googleapis/api/annotations"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type SyncConfigRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Declarative configuration in Kong's `_format_version` 3.0 format.
+	Config *structpb.Struct `protobuf:"bytes,1,opt,name=config,proto3" json:"config,omitempty"`
+	// Compute the changes without applying them.
+	DryRun bool `protobuf:"varint,2,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	// Scope the sync to the entities tagged with all of these tags.
+	SelectTags []string           `protobuf:"bytes,3,rep,name=select_tags,json=selectTags,proto3" json:"select_tags,omitempty"`
+	Cluster    *v1.RequestCluster `protobuf:"bytes,4,opt,name=cluster,proto3" json:"cluster,omitempty"`
+}
+
+func (x *SyncConfigRequest) Reset() {
+	*x = SyncConfigRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_kong_admin_service_v1_declarative_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SyncConfigRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SyncConfigRequest) ProtoMessage() {}
+
+func (x *SyncConfigRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_kong_admin_service_v1_declarative_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SyncConfigRequest.ProtoReflect.Descriptor instead.
+func (*SyncConfigRequest) Descriptor() ([]byte, []int) {
+	return file_kong_admin_service_v1_declarative_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *SyncConfigRequest) GetConfig() *structpb.Struct {
+	if x != nil {
+		return x.Config
+	}
+	return nil
+}
+
+func (x *SyncConfigRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+func (x *SyncConfigRequest) GetSelectTags() []string {
+	if x != nil {
+		return x.SelectTags
+	}
+	return nil
+}
+
+func (x *SyncConfigRequest) GetCluster() *v1.RequestCluster {
+	if x != nil {
+		return x.Cluster
+	}
+	return nil
+}
+
+type DeclarativeChange struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Type string `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	Id   string `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	// Entity in the store, unset for creates.
+	OldItem *structpb.Struct `protobuf:"bytes,3,opt,name=old_item,json=oldItem,proto3" json:"old_item,omitempty"`
+	// Declared entity, unset for deletes.
+	NewItem *structpb.Struct `protobuf:"bytes,4,opt,name=new_item,json=newItem,proto3" json:"new_item,omitempty"`
+}
+
+func (x *DeclarativeChange) Reset() {
+	*x = DeclarativeChange{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_kong_admin_service_v1_declarative_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DeclarativeChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeclarativeChange) ProtoMessage() {}
+
+func (x *DeclarativeChange) ProtoReflect() protoreflect.Message {
+	mi := &file_kong_admin_service_v1_declarative_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeclarativeChange.ProtoReflect.Descriptor instead.
+func (*DeclarativeChange) Descriptor() ([]byte, []int) {
+	return file_kong_admin_service_v1_declarative_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *DeclarativeChange) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *DeclarativeChange) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *DeclarativeChange) GetOldItem() *structpb.Struct {
+	if x != nil {
+		return x.OldItem
+	}
+	return nil
+}
+
+func (x *DeclarativeChange) GetNewItem() *structpb.Struct {
+	if x != nil {
+		return x.NewItem
+	}
+	return nil
+}
+
+type SyncConfigResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Creates []*DeclarativeChange `protobuf:"bytes,1,rep,name=creates,proto3" json:"creates,omitempty"`
+	Updates []*DeclarativeChange `protobuf:"bytes,2,rep,name=updates,proto3" json:"updates,omitempty"`
+	Deletes []*DeclarativeChange `protobuf:"bytes,3,rep,name=deletes,proto3" json:"deletes,omitempty"`
+}
+
+func (x *SyncConfigResponse) Reset() {
+	*x = SyncConfigResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_kong_admin_service_v1_declarative_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SyncConfigResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SyncConfigResponse) ProtoMessage() {}
+
+func (x *SyncConfigResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_kong_admin_service_v1_declarative_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SyncConfigResponse.ProtoReflect.Descriptor instead.
+func (*SyncConfigResponse) Descriptor() ([]byte, []int) {
+	return file_kong_admin_service_v1_declarative_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *SyncConfigResponse) GetCreates() []*DeclarativeChange {
+	if x != nil {
+		return x.Creates
+	}
+	return nil
+}
+
+func (x *SyncConfigResponse) GetUpdates() []*DeclarativeChange {
+	if x != nil {
+		return x.Updates
+	}
+	return nil
+}
+
+func (x *SyncConfigResponse) GetDeletes() []*DeclarativeChange {
+	if x != nil {
+		return x.Deletes
+	}
+	return nil
+}
+
+var File_kong_admin_service_v1_declarative_proto protoreflect.FileDescriptor
+
+var file_kong_admin_service_v1_declarative_proto_rawDesc = []byte{
+	0x0a, 0x27, 0x6b, 0x6f, 0x6e, 0x67, 0x2f, 0x61, 0x64, 0x6d, 0x69, 0x6e, 0x2f, 0x73, 0x65, 0x72,
+	0x76, 0x69, 0x63, 0x65, 0x2f, 0x76, 0x31, 0x2f, 0x64, 0x65, 0x63, 0x6c, 0x61, 0x72, 0x61, 0x74,
+	0x69, 0x76, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x15, 0x6b, 0x6f, 0x6e, 0x67, 0x2e,
+	0x61, 0x64, 0x6d, 0x69, 0x6e, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x76, 0x31,
+	0x1a, 0x1c, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x61, 0x6e, 0x6e,
+	0x6f, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1c,
+	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f,
+	0x73, 0x74, 0x72, 0x75, 0x63, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x21, 0x6b, 0x6f,
+	0x6e, 0x67, 0x2f, 0x61, 0x64, 0x6d, 0x69, 0x6e, 0x2f, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x2f, 0x76,
+	0x31, 0x2f, 0x63, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22,
+	0xbd, 0x01, 0x0a, 0x11, 0x53, 0x79, 0x6e, 0x63, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x2f, 0x0a, 0x06, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x53, 0x74, 0x72, 0x75, 0x63, 0x74, 0x52, 0x06,
+	0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x17, 0x0a, 0x07, 0x64, 0x72, 0x79, 0x5f, 0x72, 0x75,
+	0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x64, 0x72, 0x79, 0x52, 0x75, 0x6e, 0x12,
+	0x1f, 0x0a, 0x0b, 0x73, 0x65, 0x6c, 0x65, 0x63, 0x74, 0x5f, 0x74, 0x61, 0x67, 0x73, 0x18, 0x03,
+	0x20, 0x03, 0x28, 0x09, 0x52, 0x0a, 0x73, 0x65, 0x6c, 0x65, 0x63, 0x74, 0x54, 0x61, 0x67, 0x73,
+	0x12, 0x3d, 0x0a, 0x07, 0x63, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x23, 0x2e, 0x6b, 0x6f, 0x6e, 0x67, 0x2e, 0x61, 0x64, 0x6d, 0x69, 0x6e, 0x2e, 0x6d,
+	0x6f, 0x64, 0x65, 0x6c, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x43,
+	0x6c, 0x75, 0x73, 0x74, 0x65, 0x72, 0x52, 0x07, 0x63, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72, 0x22,
+	0x9f, 0x01, 0x0a, 0x11, 0x44, 0x65, 0x63, 0x6c, 0x61, 0x72, 0x61, 0x74, 0x69, 0x76, 0x65, 0x43,
+	0x68, 0x61, 0x6e, 0x67, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x32, 0x0a, 0x08, 0x6f, 0x6c, 0x64,
+	0x5f, 0x69, 0x74, 0x65, 0x6d, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x53, 0x74,
+	0x72, 0x75, 0x63, 0x74, 0x52, 0x07, 0x6f, 0x6c, 0x64, 0x49, 0x74, 0x65, 0x6d, 0x12, 0x32, 0x0a,
+	0x08, 0x6e, 0x65, 0x77, 0x5f, 0x69, 0x74, 0x65, 0x6d, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x17, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2e, 0x53, 0x74, 0x72, 0x75, 0x63, 0x74, 0x52, 0x07, 0x6e, 0x65, 0x77, 0x49, 0x74, 0x65,
+	0x6d, 0x22, 0xe0, 0x01, 0x0a, 0x12, 0x53, 0x79, 0x6e, 0x63, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x42, 0x0a, 0x07, 0x63, 0x72, 0x65, 0x61,
+	0x74, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x28, 0x2e, 0x6b, 0x6f, 0x6e, 0x67,
+	0x2e, 0x61, 0x64, 0x6d, 0x69, 0x6e, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x76,
+	0x31, 0x2e, 0x44, 0x65, 0x63, 0x6c, 0x61, 0x72, 0x61, 0x74, 0x69, 0x76, 0x65, 0x43, 0x68, 0x61,
+	0x6e, 0x67, 0x65, 0x52, 0x07, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x73, 0x12, 0x42, 0x0a, 0x07,
+	0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x28, 0x2e,
+	0x6b, 0x6f, 0x6e, 0x67, 0x2e, 0x61, 0x64, 0x6d, 0x69, 0x6e, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x69,
+	0x63, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x63, 0x6c, 0x61, 0x72, 0x61, 0x74, 0x69, 0x76,
+	0x65, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x52, 0x07, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x73,
+	0x12, 0x42, 0x0a, 0x07, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x28, 0x2e, 0x6b, 0x6f, 0x6e, 0x67, 0x2e, 0x61, 0x64, 0x6d, 0x69, 0x6e, 0x2e, 0x73,
+	0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x63, 0x6c, 0x61, 0x72,
+	0x61, 0x74, 0x69, 0x76, 0x65, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x52, 0x07, 0x64, 0x65, 0x6c,
+	0x65, 0x74, 0x65, 0x73, 0x32, 0x99, 0x01, 0x0a, 0x12, 0x44, 0x65, 0x63, 0x6c, 0x61, 0x72, 0x61,
+	0x74, 0x69, 0x76, 0x65, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x82, 0x01, 0x0a, 0x0a,
+	0x53, 0x79, 0x6e, 0x63, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x28, 0x2e, 0x6b, 0x6f, 0x6e,
+	0x67, 0x2e, 0x61, 0x64, 0x6d, 0x69, 0x6e, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e,
+	0x76, 0x31, 0x2e, 0x53, 0x79, 0x6e, 0x63, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x29, 0x2e, 0x6b, 0x6f, 0x6e, 0x67, 0x2e, 0x61, 0x64, 0x6d, 0x69,
+	0x6e, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x79, 0x6e,
+	0x63, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22,
+	0x1f, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x19, 0x3a, 0x01, 0x2a, 0x22, 0x14, 0x2f, 0x76, 0x31, 0x2f,
+	0x64, 0x65, 0x63, 0x6c, 0x61, 0x72, 0x61, 0x74, 0x69, 0x76, 0x65, 0x2f, 0x73, 0x79, 0x6e, 0x63,
+	0x42, 0x3c, 0x5a, 0x3a, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6b,
+	0x6f, 0x6e, 0x67, 0x2f, 0x6b, 0x6f, 0x6b, 0x6f, 0x2f, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61,
+	0x6c, 0x2f, 0x67, 0x65, 0x6e, 0x2f, 0x6b, 0x6f, 0x6e, 0x67, 0x2f, 0x61, 0x64, 0x6d, 0x69, 0x6e,
+	0x2f, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2f, 0x76, 0x31, 0x3b, 0x76, 0x31, 0x62, 0x06,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_kong_admin_service_v1_declarative_proto_rawDescOnce sync.Once
+	file_kong_admin_service_v1_declarative_proto_rawDescData = file_kong_admin_service_v1_declarative_proto_rawDesc
+)
+
+func file_kong_admin_service_v1_declarative_proto_rawDescGZIP() []byte {
+	file_kong_admin_service_v1_declarative_proto_rawDescOnce.Do(func() {
+		file_kong_admin_service_v1_declarative_proto_rawDescData = protoimpl.X.CompressGZIP(file_kong_admin_service_v1_declarative_proto_rawDescData)
+	})
+	return file_kong_admin_service_v1_declarative_proto_rawDescData
+}
+
+var file_kong_admin_service_v1_declarative_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_kong_admin_service_v1_declarative_proto_goTypes = []interface{}{
+	(*SyncConfigRequest)(nil),  // 0: kong.admin.service.v1.SyncConfigRequest
+	(*DeclarativeChange)(nil),  // 1: kong.admin.service.v1.DeclarativeChange
+	(*SyncConfigResponse)(nil), // 2: kong.admin.service.v1.SyncConfigResponse
+	(*structpb.Struct)(nil),    // 3: google.protobuf.Struct
+	(*v1.RequestCluster)(nil),  // 4: kong.admin.model.v1.RequestCluster
+}
+var file_kong_admin_service_v1_declarative_proto_depIdxs = []int32{
+	3, // 0: kong.admin.service.v1.SyncConfigRequest.config:type_name -> google.protobuf.Struct
+	4, // 1: kong.admin.service.v1.SyncConfigRequest.cluster:type_name -> kong.admin.model.v1.RequestCluster
+	3, // 2: kong.admin.service.v1.DeclarativeChange.old_item:type_name -> google.protobuf.Struct
+	3, // 3: kong.admin.service.v1.DeclarativeChange.new_item:type_name -> google.protobuf.Struct
+	1, // 4: kong.admin.service.v1.SyncConfigResponse.creates:type_name -> kong.admin.service.v1.DeclarativeChange
+	1, // 5: kong.admin.service.v1.SyncConfigResponse.updates:type_name -> kong.admin.service.v1.DeclarativeChange
+	1, // 6: kong.admin.service.v1.SyncConfigResponse.deletes:type_name -> kong.admin.service.v1.DeclarativeChange
+	0, // 7: kong.admin.service.v1.DeclarativeService.SyncConfig:input_type -> kong.admin.service.v1.SyncConfigRequest
+	2, // 8: kong.admin.service.v1.DeclarativeService.SyncConfig:output_type -> kong.admin.service.v1.SyncConfigResponse
+	8, // [8:9] is the sub-list for method output_type
+	7, // [7:8] is the sub-list for method input_type
+	7, // [7:7] is the sub-list for extension type_name
+	7, // [7:7] is the sub-list for extension extendee
+	0, // [0:7] is the sub-list for field type_name
+}
+
+func init() { file_kong_admin_service_v1_declarative_proto_init() }
+func file_kong_admin_service_v1_declarative_proto_init() {
+	if File_kong_admin_service_v1_declarative_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_kong_admin_service_v1_declarative_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SyncConfigRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_kong_admin_service_v1_declarative_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DeclarativeChange); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_kong_admin_service_v1_declarative_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SyncConfigResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_kong_admin_service_v1_declarative_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_kong_admin_service_v1_declarative_proto_goTypes,
+		DependencyIndexes: file_kong_admin_service_v1_declarative_proto_depIdxs,
+		MessageInfos:      file_kong_admin_service_v1_declarative_proto_msgTypes,
+	}.Build()
+	File_kong_admin_service_v1_declarative_proto = out.File
+	file_kong_admin_service_v1_declarative_proto_rawDesc = nil
+	file_kong_admin_service_v1_declarative_proto_goTypes = nil
+	file_kong_admin_service_v1_declarative_proto_depIdxs = nil
+}
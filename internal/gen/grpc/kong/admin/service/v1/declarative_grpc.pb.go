@@ -0,0 +1,105 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             (unknown)
+// source: kong/admin/service/v1/declarative.proto
+
+package v1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// DeclarativeServiceClient is the client API for DeclarativeService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type DeclarativeServiceClient interface {
+	SyncConfig(ctx context.Context, in *SyncConfigRequest, opts ...grpc.CallOption) (*SyncConfigResponse, error)
+}
+
+type declarativeServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewDeclarativeServiceClient(cc grpc.ClientConnInterface) DeclarativeServiceClient {
+	return &declarativeServiceClient{cc}
+}
+
+func (c *declarativeServiceClient) SyncConfig(ctx context.Context, in *SyncConfigRequest, opts ...grpc.CallOption) (*SyncConfigResponse, error) {
+	out := new(SyncConfigResponse)
+	err := c.cc.Invoke(ctx, "/kong.admin.service.v1.DeclarativeService/SyncConfig", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DeclarativeServiceServer is the server API for DeclarativeService service.
+// All implementations must embed UnimplementedDeclarativeServiceServer
+// for forward compatibility
+type DeclarativeServiceServer interface {
+	SyncConfig(context.Context, *SyncConfigRequest) (*SyncConfigResponse, error)
+	mustEmbedUnimplementedDeclarativeServiceServer()
+}
+
+// UnimplementedDeclarativeServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedDeclarativeServiceServer struct {
+}
+
+func (UnimplementedDeclarativeServiceServer) SyncConfig(context.Context, *SyncConfigRequest) (*SyncConfigResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SyncConfig not implemented")
+}
+func (UnimplementedDeclarativeServiceServer) mustEmbedUnimplementedDeclarativeServiceServer() {}
+
+// UnsafeDeclarativeServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to DeclarativeServiceServer will
+// result in compilation errors.
+type UnsafeDeclarativeServiceServer interface {
+	mustEmbedUnimplementedDeclarativeServiceServer()
+}
+
+func RegisterDeclarativeServiceServer(s grpc.ServiceRegistrar, srv DeclarativeServiceServer) {
+	s.RegisterService(&DeclarativeService_ServiceDesc, srv)
+}
+
+func _DeclarativeService_SyncConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SyncConfigRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeclarativeServiceServer).SyncConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/kong.admin.service.v1.DeclarativeService/SyncConfig",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeclarativeServiceServer).SyncConfig(ctx, req.(*SyncConfigRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// DeclarativeService_ServiceDesc is the grpc.ServiceDesc for DeclarativeService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var DeclarativeService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "kong.admin.service.v1.DeclarativeService",
+	HandlerType: (*DeclarativeServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SyncConfig",
+			Handler:    _DeclarativeService_SyncConfig_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "kong/admin/service/v1/declarative.proto",
+}
@@ -0,0 +1,105 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             (unknown)
+// source: kong/admin/service/v1/dependent.proto
+
+package v1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// DependentServiceClient is the client API for DependentService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type DependentServiceClient interface {
+	GetDependents(ctx context.Context, in *GetDependentsRequest, opts ...grpc.CallOption) (*GetDependentsResponse, error)
+}
+
+type dependentServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewDependentServiceClient(cc grpc.ClientConnInterface) DependentServiceClient {
+	return &dependentServiceClient{cc}
+}
+
+func (c *dependentServiceClient) GetDependents(ctx context.Context, in *GetDependentsRequest, opts ...grpc.CallOption) (*GetDependentsResponse, error) {
+	out := new(GetDependentsResponse)
+	err := c.cc.Invoke(ctx, "/kong.admin.service.v1.DependentService/GetDependents", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DependentServiceServer is the server API for DependentService service.
+// All implementations must embed UnimplementedDependentServiceServer
+// for forward compatibility
+type DependentServiceServer interface {
+	GetDependents(context.Context, *GetDependentsRequest) (*GetDependentsResponse, error)
+	mustEmbedUnimplementedDependentServiceServer()
+}
+
+// UnimplementedDependentServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedDependentServiceServer struct {
+}
+
+func (UnimplementedDependentServiceServer) GetDependents(context.Context, *GetDependentsRequest) (*GetDependentsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDependents not implemented")
+}
+func (UnimplementedDependentServiceServer) mustEmbedUnimplementedDependentServiceServer() {}
+
+// UnsafeDependentServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to DependentServiceServer will
+// result in compilation errors.
+type UnsafeDependentServiceServer interface {
+	mustEmbedUnimplementedDependentServiceServer()
+}
+
+func RegisterDependentServiceServer(s grpc.ServiceRegistrar, srv DependentServiceServer) {
+	s.RegisterService(&DependentService_ServiceDesc, srv)
+}
+
+func _DependentService_GetDependents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDependentsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DependentServiceServer).GetDependents(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/kong.admin.service.v1.DependentService/GetDependents",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DependentServiceServer).GetDependents(ctx, req.(*GetDependentsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// DependentService_ServiceDesc is the grpc.ServiceDesc for DependentService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var DependentService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "kong.admin.service.v1.DependentService",
+	HandlerType: (*DependentServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetDependents",
+			Handler:    _DependentService_GetDependents_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "kong/admin/service/v1/dependent.proto",
+}
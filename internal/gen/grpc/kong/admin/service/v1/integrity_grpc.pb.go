@@ -0,0 +1,105 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             (unknown)
+// source: kong/admin/service/v1/integrity.proto
+
+package v1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// IntegrityServiceClient is the client API for IntegrityService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type IntegrityServiceClient interface {
+	CheckIntegrity(ctx context.Context, in *CheckIntegrityRequest, opts ...grpc.CallOption) (*CheckIntegrityResponse, error)
+}
+
+type integrityServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewIntegrityServiceClient(cc grpc.ClientConnInterface) IntegrityServiceClient {
+	return &integrityServiceClient{cc}
+}
+
+func (c *integrityServiceClient) CheckIntegrity(ctx context.Context, in *CheckIntegrityRequest, opts ...grpc.CallOption) (*CheckIntegrityResponse, error) {
+	out := new(CheckIntegrityResponse)
+	err := c.cc.Invoke(ctx, "/kong.admin.service.v1.IntegrityService/CheckIntegrity", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// IntegrityServiceServer is the server API for IntegrityService service.
+// All implementations must embed UnimplementedIntegrityServiceServer
+// for forward compatibility
+type IntegrityServiceServer interface {
+	CheckIntegrity(context.Context, *CheckIntegrityRequest) (*CheckIntegrityResponse, error)
+	mustEmbedUnimplementedIntegrityServiceServer()
+}
+
+// UnimplementedIntegrityServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedIntegrityServiceServer struct {
+}
+
+func (UnimplementedIntegrityServiceServer) CheckIntegrity(context.Context, *CheckIntegrityRequest) (*CheckIntegrityResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CheckIntegrity not implemented")
+}
+func (UnimplementedIntegrityServiceServer) mustEmbedUnimplementedIntegrityServiceServer() {}
+
+// UnsafeIntegrityServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to IntegrityServiceServer will
+// result in compilation errors.
+type UnsafeIntegrityServiceServer interface {
+	mustEmbedUnimplementedIntegrityServiceServer()
+}
+
+func RegisterIntegrityServiceServer(s grpc.ServiceRegistrar, srv IntegrityServiceServer) {
+	s.RegisterService(&IntegrityService_ServiceDesc, srv)
+}
+
+func _IntegrityService_CheckIntegrity_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckIntegrityRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IntegrityServiceServer).CheckIntegrity(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/kong.admin.service.v1.IntegrityService/CheckIntegrity",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IntegrityServiceServer).CheckIntegrity(ctx, req.(*CheckIntegrityRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// IntegrityService_ServiceDesc is the grpc.ServiceDesc for IntegrityService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var IntegrityService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "kong.admin.service.v1.IntegrityService",
+	HandlerType: (*IntegrityServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CheckIntegrity",
+			Handler:    _IntegrityService_CheckIntegrity_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "kong/admin/service/v1/integrity.proto",
+}
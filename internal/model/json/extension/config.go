@@ -9,6 +9,15 @@ var configSchema = jsonschema.MustCompileString((&Config{}).Name(), `{
 	"properties" : {
 		"hasValidateEndpoint": {
 			"type": "boolean"
+		},
+		"sensitive": {
+			"type": "boolean"
+		},
+		"sensitiveMembers": {
+			"type": "array",
+			"items": {
+				"type": "string"
+			}
 		}
 	}
 }`)
@@ -40,6 +49,17 @@ type Config struct {
 	//
 	// This should be set to true for any referenceable field, and otherwise omitted.
 	Referenceable bool `json:"referenceable,omitempty"`
+
+	// Sensitive fields hold secrets, e.g.: private keys. Their values are redacted from the
+	// responses of the admin API, unless the client explicitly asks for them to be revealed.
+	//
+	// This should be set to true for any sensitive field, and otherwise omitted.
+	Sensitive bool `json:"sensitive,omitempty"`
+
+	// SensitiveMembers are, for string fields holding a JSON object, the members of the object
+	// that are redacted like sensitive fields, e.g.: the private parameters of a JWK. The other
+	// members of the object are left as is.
+	SensitiveMembers []string `json:"sensitiveMembers,omitempty"`
 }
 
 // Name implements the Extension interface.
@@ -17,7 +17,9 @@ func TestConfig_Compile(t *testing.T) {
 	require.NoError(t, c.AddResource("schema.json", strings.NewReader(`{
 		"x-koko-config": {
 			"disableValidateEndpoint": true,
-			"resourceAPIPath": "something"
+			"resourceAPIPath": "something",
+			"sensitive": true,
+			"sensitiveMembers": ["d"]
 		}
 	}`)))
 
@@ -28,6 +30,8 @@ func TestConfig_Compile(t *testing.T) {
 		ext.Name(): &Config{
 			DisableValidateEndpoint: true,
 			ResourceAPIPath:         "something",
+			Sensitive:               true,
+			SensitiveMembers:        []string{"d"},
 		},
 	}, sch.Extensions)
 }
@@ -3,6 +3,7 @@ package typedefs
 import (
 	"fmt"
 
+	"github.com/kong/koko/internal/model/json/extension"
 	"github.com/kong/koko/internal/model/json/generator"
 )
 
@@ -220,8 +221,11 @@ var Reference = &generator.Schema{
 var PEMKey = &generator.Schema{
 	Type: "object",
 	Properties: map[string]*generator.Schema{
-		"private_key": {Format: "pem-encoded-private-key"},
-		"public_key":  {Format: "pem-encoded-public-key"},
+		"private_key": {
+			Format:      "pem-encoded-private-key",
+			XKokoConfig: &extension.Config{Sensitive: true},
+		},
+		"public_key": {Format: "pem-encoded-public-key"},
 	},
 	Required: []string{"private_key"},
 }
@@ -229,6 +233,10 @@ var PEMKey = &generator.Schema{
 var JWKKey = &generator.Schema{
 	Type:   "string",
 	Format: "jwt-encoded-key",
+	XKokoConfig: &extension.Config{
+		// The private parameters of RSA, EC, OKP and symmetric keys (RFC 7518 and RFC 8037).
+		SensitiveMembers: []string{"d", "p", "q", "dp", "dq", "qi", "oth", "k"},
+	},
 }
 
 func intP(i int) *int { return &i }
@@ -74,6 +74,11 @@ type ObjectOptions struct {
 	// with no consumers associated to it. Likewise, when consumer is deleted that is associated
 	// to specific route(s), we do indeed want it to delete those route(s) as well.
 	CascadeOnDelete bool
+
+	// SensitiveFields are the paths, with dot-separated field names, of the fields of the
+	// stored JSON representation of this object that are encrypted at rest when the store
+	// is configured with an encryption key, e.g.: "pem.private_key".
+	SensitiveFields []string
 }
 
 // DefaultObjectOptions defines the configuration of a model object
@@ -155,10 +160,14 @@ type ObjectList interface {
 	Add(Object)
 	GetAll() []Object
 	// GetTotalCount returns the count of objects in the underlying store across all pages.
+	// When listing by page token, only the objects from the listed page onward are counted.
 	GetTotalCount() int
 	SetTotalCount(count int)
 	SetNextPage(pageNum int)
 	GetNextPage() int
+	// SetNextPageToken sets the opaque token used to list the next page.
+	SetNextPageToken(token string)
+	GetNextPageToken() string
 }
 
 // Actions returns all unique index actions used within the slice of Index objects.
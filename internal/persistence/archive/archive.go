@@ -0,0 +1,263 @@
+// Package archive exports the keys and values of a persistence.Persister to
+// an archive, and imports them back into any persistence.Persister, e.g.: to
+// move from SQLite to Postgres.
+//
+// An archive is a gzip-compressed stream of JSON lines. The first line is a
+// header holding the format and version of the archive. Every following line
+// holds a key and its value, sorted by key, up to a last line holding the
+// number of keys and the SHA-256 checksum of the preceding key lines.
+package archive
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"time"
+
+	"github.com/kong/koko/internal/json"
+	"github.com/kong/koko/internal/persistence"
+)
+
+const (
+	// Format identifies archives.
+	Format = "koko-db-export"
+	// Version is the version of the archives written by Export. Import reads
+	// archives up to this version.
+	Version = 1
+)
+
+// ErrNotEmpty is returned when importing an archive into a persister already
+// holding keys of the archived clusters.
+var ErrNotEmpty = errors.New("database holds keys of the archived clusters, " +
+	"archives must be imported into an empty database")
+
+// Header is the first line of an archive.
+type Header struct {
+	Format  string `json:"format"`
+	Version int    `json:"version"`
+	// Cluster is the archived cluster, all clusters are archived if empty.
+	Cluster   string `json:"cluster,omitempty"`
+	CreatedAt int64  `json:"created_at"`
+}
+
+// Summary describes an exported or imported archive.
+type Summary struct {
+	Header
+	// Count is the number of keys of the archive.
+	Count int
+	// Checksum is the hex-encoded SHA-256 checksum of the key lines.
+	Checksum string
+}
+
+// line is any line of an archive following the header. Key lines have a key,
+// the last line has none.
+type line struct {
+	Key      string `json:"key,omitempty"`
+	Value    []byte `json:"value,omitempty"`
+	Count    int    `json:"count,omitempty"`
+	Checksum string `json:"checksum,omitempty"`
+}
+
+// ExportOpts defines the options of Export.
+type ExportOpts struct {
+	// Cluster to export along with its record, every cluster is exported if
+	// empty.
+	Cluster string
+}
+
+// Export writes the keys of the persister to w as an archive, reading them
+// within a single transaction.
+func Export(ctx context.Context, persister persistence.Persister, w io.Writer,
+	opts ExportOpts,
+) (Summary, error) {
+	res := Summary{Header: Header{
+		Format:    Format,
+		Version:   Version,
+		Cluster:   opts.Cluster,
+		CreatedAt: time.Now().Unix(),
+	}}
+	gz := gzip.NewWriter(w)
+	if err := writeLine(gz, nil, res.Header); err != nil {
+		return Summary{}, err
+	}
+	checksum := sha256.New()
+	err := withTx(ctx, persister, func(tx persistence.Tx) error {
+		listOpts := persistence.NewDefaultListOpts()
+		listOpts.Limit = persistence.MaxLimit
+		for {
+			page, err := tx.List(ctx, prefix(opts.Cluster), listOpts)
+			if err != nil {
+				return err
+			}
+			for _, kv := range page.KVList {
+				if err := writeLine(gz, checksum, line{Key: string(kv.Key), Value: kv.Value}); err != nil {
+					return err
+				}
+				res.Count++
+			}
+			if len(page.KVList) == 0 || page.TotalCount == len(page.KVList) {
+				break
+			}
+			listOpts.After = string(page.KVList[len(page.KVList)-1].Key)
+		}
+		if opts.Cluster == "" {
+			return nil
+		}
+		// The record of the cluster is kept apart from its keys, and sorts
+		// after them.
+		value, err := tx.Get(ctx, clusterKey(opts.Cluster))
+		if err != nil {
+			if errors.As(err, &persistence.ErrNotFound{}) {
+				return nil
+			}
+			return err
+		}
+		res.Count++
+		return writeLine(gz, checksum, line{Key: clusterKey(opts.Cluster), Value: value})
+	})
+	if err != nil {
+		return Summary{}, err
+	}
+	res.Checksum = hex.EncodeToString(checksum.Sum(nil))
+	if err := writeLine(gz, nil, line{Count: res.Count, Checksum: res.Checksum}); err != nil {
+		return Summary{}, err
+	}
+	if err := gz.Close(); err != nil {
+		return Summary{}, err
+	}
+	return res, nil
+}
+
+// Import reads an archive from r and writes its keys to the persister within
+// a single transaction, which is rolled back unless the whole archive is read
+// and its checksum verified. ErrNotEmpty is returned if the persister already
+// holds keys of the archived clusters.
+func Import(ctx context.Context, persister persistence.Persister, r io.Reader) (Summary, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return Summary{}, fmt.Errorf("read archive: %w", err)
+	}
+	scanner := bufio.NewScanner(gz)
+	// Allow for lines as large as the largest values.
+	scanner.Buffer(nil, 64*1024*1024)
+
+	var res Summary
+	if !scanner.Scan() {
+		return Summary{}, archiveErr(scanner.Err(), "missing header")
+	}
+	if err := json.Unmarshal(scanner.Bytes(), &res.Header); err != nil {
+		return Summary{}, archiveErr(err, "invalid header")
+	}
+	if res.Format != Format {
+		return Summary{}, archiveErr(nil, "unknown format '%s'", res.Format)
+	}
+	if res.Version < 1 || res.Version > Version {
+		return Summary{}, archiveErr(nil, "unsupported version %d", res.Version)
+	}
+
+	checksum := sha256.New()
+	err = withTx(ctx, persister, func(tx persistence.Tx) error {
+		existing, err := tx.List(ctx, prefix(res.Cluster), &persistence.ListOpts{Limit: 1})
+		if err != nil {
+			return err
+		}
+		if existing.TotalCount > 0 {
+			return ErrNotEmpty
+		}
+		if res.Cluster != "" {
+			_, err := tx.Get(ctx, clusterKey(res.Cluster))
+			if err == nil {
+				return ErrNotEmpty
+			}
+			if !errors.As(err, &persistence.ErrNotFound{}) {
+				return err
+			}
+		}
+		for scanner.Scan() {
+			var l line
+			if err := json.Unmarshal(scanner.Bytes(), &l); err != nil {
+				return archiveErr(err, "invalid line %d", res.Count+2)
+			}
+			if l.Key == "" {
+				res.Checksum = hex.EncodeToString(checksum.Sum(nil))
+				if l.Count != res.Count || l.Checksum != res.Checksum {
+					return archiveErr(nil, "checksum mismatch")
+				}
+				if scanner.Scan() {
+					return archiveErr(nil, "unexpected line after checksum")
+				}
+				return scanner.Err()
+			}
+			if _, err := checksum.Write(append(scanner.Bytes(), '\n')); err != nil {
+				return err
+			}
+			if err := tx.Insert(ctx, l.Key, l.Value); err != nil {
+				return fmt.Errorf("import key '%s': %w", l.Key, err)
+			}
+			res.Count++
+		}
+		return archiveErr(scanner.Err(), "missing checksum, the archive is truncated")
+	})
+	if err != nil {
+		return Summary{}, err
+	}
+	return res, nil
+}
+
+func prefix(cluster string) string {
+	if cluster == "" {
+		return ""
+	}
+	return fmt.Sprintf("c/%s/", cluster)
+}
+
+// clusterKey is the key of the record of a cluster, see store.CreateCluster.
+func clusterKey(cluster string) string {
+	return "clusters/" + cluster
+}
+
+// writeLine writes v as a JSON line to w, and to checksum if not nil.
+func writeLine(w io.Writer, checksum hash.Hash, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+	if checksum != nil {
+		if _, err := checksum.Write(b); err != nil {
+			return err
+		}
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+func archiveErr(err error, format string, args ...interface{}) error {
+	message := fmt.Sprintf(format, args...)
+	if err != nil {
+		return fmt.Errorf("read archive: %s: %w", message, err)
+	}
+	return fmt.Errorf("read archive: %s", message)
+}
+
+func withTx(ctx context.Context, persister persistence.Persister,
+	fn func(tx persistence.Tx) error,
+) error {
+	tx, err := persister.Tx(ctx)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return rollbackErr
+		}
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,146 @@
+package archive
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/kong/koko/internal/persistence"
+	"github.com/kong/koko/internal/test/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportImport(t *testing.T) {
+	ctx := context.Background()
+	p, err := util.GetPersister(t)
+	require.NoError(t, err)
+
+	kvs := map[string]string{
+		"c/default/o/service/s1":           `{"object":{"id":"s1","tags":["foo"]}}`,
+		"c/default/o/service/s2":           `{"object":{"id":"s2"}}`,
+		"c/default/ix/u/service/name/s1":   `{"ref_id":"s1"}`,
+		"c/other/o/service/s3":             `{"object":{"id":"s3"}}`,
+		"c/other/ix/u/service/name/s3-foo": `{"ref_id":"s3"}`,
+		"clusters/other":                   `{"created_at":1}`,
+	}
+	populate := func(t *testing.T) {
+		for key, value := range kvs {
+			require.NoError(t, p.Put(ctx, key, []byte(value)))
+		}
+	}
+	deleteAll := func(t *testing.T) {
+		list, err := p.List(ctx, "", &persistence.ListOpts{Limit: persistence.MaxLimit})
+		require.NoError(t, err)
+		for _, kv := range list.KVList {
+			require.NoError(t, p.Delete(ctx, string(kv.Key)))
+		}
+	}
+	read := func(t *testing.T) map[string]string {
+		list, err := p.List(ctx, "", &persistence.ListOpts{Limit: persistence.MaxLimit})
+		require.NoError(t, err)
+		res := map[string]string{}
+		for _, kv := range list.KVList {
+			res[string(kv.Key)] = string(kv.Value)
+		}
+		return res
+	}
+	export := func(t *testing.T, opts ExportOpts) []byte {
+		var buf bytes.Buffer
+		_, err := Export(ctx, p, &buf, opts)
+		require.NoError(t, err)
+		return buf.Bytes()
+	}
+	// rewrite applies fn to the uncompressed lines of an archive.
+	rewrite := func(t *testing.T, archive []byte, fn func(lines []string) []string) []byte {
+		gz, err := gzip.NewReader(bytes.NewReader(archive))
+		require.NoError(t, err)
+		raw, err := io.ReadAll(gz)
+		require.NoError(t, err)
+		lines := fn(strings.Split(strings.TrimSuffix(string(raw), "\n"), "\n"))
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		_, err = w.Write([]byte(strings.Join(lines, "\n") + "\n"))
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+		return buf.Bytes()
+	}
+
+	deleteAll(t)
+	populate(t)
+
+	t.Run("exports and imports every cluster", func(t *testing.T) {
+		var buf bytes.Buffer
+		exported, err := Export(ctx, p, &buf, ExportOpts{})
+		require.NoError(t, err)
+		require.Equal(t, 6, exported.Count)
+		require.Equal(t, Version, exported.Version)
+		require.Len(t, exported.Checksum, 64)
+
+		deleteAll(t)
+		imported, err := Import(ctx, p, &buf)
+		require.NoError(t, err)
+		require.Equal(t, exported, imported)
+		require.Equal(t, kvs, read(t))
+	})
+	t.Run("exports and imports a single cluster", func(t *testing.T) {
+		archive := export(t, ExportOpts{Cluster: "other"})
+		for key := range kvs {
+			if strings.HasPrefix(key, "c/other/") || key == "clusters/other" {
+				require.NoError(t, p.Delete(ctx, key))
+			}
+		}
+		imported, err := Import(ctx, p, bytes.NewReader(archive))
+		require.NoError(t, err)
+		require.Equal(t, "other", imported.Cluster)
+		require.Equal(t, 3, imported.Count)
+		require.Equal(t, kvs, read(t))
+	})
+	t.Run("importing into a database holding archived keys fails", func(t *testing.T) {
+		archive := export(t, ExportOpts{Cluster: "default"})
+		_, err := Import(ctx, p, bytes.NewReader(archive))
+		require.ErrorIs(t, err, ErrNotEmpty)
+	})
+	t.Run("importing into a database holding the archived cluster fails", func(t *testing.T) {
+		archive := export(t, ExportOpts{Cluster: "other"})
+		for key := range kvs {
+			if strings.HasPrefix(key, "c/other/") {
+				require.NoError(t, p.Delete(ctx, key))
+			}
+		}
+		_, err := Import(ctx, p, bytes.NewReader(archive))
+		require.ErrorIs(t, err, ErrNotEmpty)
+		populate(t)
+	})
+	t.Run("importing a tampered archive fails", func(t *testing.T) {
+		archive := rewrite(t, export(t, ExportOpts{}), func(lines []string) []string {
+			lines[1] = strings.Replace(lines[1], `"key":"c/`, `"key":"c/x`, 1)
+			return lines
+		})
+		deleteAll(t)
+		_, err := Import(ctx, p, bytes.NewReader(archive))
+		require.EqualError(t, err, "read archive: checksum mismatch")
+		require.Empty(t, read(t))
+		populate(t)
+	})
+	t.Run("importing a truncated archive fails", func(t *testing.T) {
+		archive := rewrite(t, export(t, ExportOpts{}), func(lines []string) []string {
+			return lines[:len(lines)-1]
+		})
+		deleteAll(t)
+		_, err := Import(ctx, p, bytes.NewReader(archive))
+		require.EqualError(t, err, "read archive: missing checksum, the archive is truncated")
+		require.Empty(t, read(t))
+		populate(t)
+	})
+	t.Run("importing an archive of a newer version fails", func(t *testing.T) {
+		archive := rewrite(t, export(t, ExportOpts{}), func(lines []string) []string {
+			lines[0] = strings.Replace(lines[0], `"version":1`, `"version":2`, 1)
+			return lines
+		})
+		_, err := Import(ctx, p, bytes.NewReader(archive))
+		require.EqualError(t, err, "read archive: unsupported version 2")
+	})
+}
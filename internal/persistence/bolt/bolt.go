@@ -0,0 +1,432 @@
+// Package bolt implements a persistence.Persister on top of bbolt, an
+// embedded key/value store written in pure Go. It requires neither CGO nor a
+// database server, which makes it a fit for single-binary deployments.
+package bolt
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kong/koko/internal/persistence"
+	bolt "go.etcd.io/bbolt"
+	"go.uber.org/zap"
+)
+
+// DefaultTimeout is the default amount of time to wait for the lock of the
+// database file.
+const DefaultTimeout = 5 * time.Second
+
+// bucket holds every key of the store.
+var bucket = []byte("store")
+
+type Opts struct {
+	Filename string
+	// Timeout is the amount of time to wait for the lock of the database
+	// file, which is held by a single process at a time.
+	Timeout time.Duration
+}
+
+type Bolt struct {
+	db       *sharedDB
+	filename string
+	// closed is used to only release the database once.
+	closed sync.Once
+}
+
+// sharedDB is a database opened by one or more persisters of this process.
+// As bbolt locks the database file, persisters of the same file share the
+// same database, which is closed along with the last of them.
+type sharedDB struct {
+	*bolt.DB
+	refs int
+}
+
+var (
+	dbsLock sync.Mutex
+	dbs     = map[string]*sharedDB{}
+)
+
+func New(opts Opts, _ time.Duration, logger *zap.Logger) (persistence.Persister, error) {
+	logger.Info("using bolt Database")
+	if opts.Filename == "" {
+		return nil, fmt.Errorf("bolt: no database file name")
+	}
+	filename, err := filepath.Abs(opts.Filename)
+	if err != nil {
+		return nil, fmt.Errorf("bolt: %w", err)
+	}
+	if opts.Timeout == 0 {
+		opts.Timeout = DefaultTimeout
+	}
+
+	dbsLock.Lock()
+	defer dbsLock.Unlock()
+	db, ok := dbs[filename]
+	if !ok {
+		boltDB, err := bolt.Open(filename, 0o600, &bolt.Options{Timeout: opts.Timeout})
+		if err != nil {
+			return nil, fmt.Errorf("bolt: open '%s': %w", filename, err)
+		}
+		err = boltDB.Update(func(tx *bolt.Tx) error {
+			_, err := tx.CreateBucketIfNotExists(bucket)
+			return err
+		})
+		if err != nil {
+			_ = boltDB.Close()
+			return nil, fmt.Errorf("bolt: create bucket: %w", err)
+		}
+		db = &sharedDB{DB: boltDB}
+		dbs[filename] = db
+	}
+	db.refs++
+	return &Bolt{db: db, filename: filename}, nil
+}
+
+func (b *Bolt) Get(ctx context.Context, key string) ([]byte, error) {
+	var res []byte
+	err := b.view(ctx, func(q *boltQuery) error {
+		var err error
+		res, err = q.Get(key)
+		return err
+	})
+	return res, err
+}
+
+func (b *Bolt) Insert(ctx context.Context, key string, value []byte) error {
+	return b.update(ctx, func(q *boltQuery) error {
+		return q.Insert(key, value)
+	})
+}
+
+func (b *Bolt) Put(ctx context.Context, key string, value []byte) error {
+	return b.update(ctx, func(q *boltQuery) error {
+		return q.Put(key, value)
+	})
+}
+
+func (b *Bolt) Delete(ctx context.Context, key string) error {
+	return b.update(ctx, func(q *boltQuery) error {
+		return q.Delete(key)
+	})
+}
+
+func (b *Bolt) List(ctx context.Context, prefix string, opts *persistence.ListOpts) (persistence.ListResult, error) {
+	var res persistence.ListResult
+	err := b.view(ctx, func(q *boltQuery) error {
+		var err error
+		res, err = q.List(prefix, opts)
+		return err
+	})
+	return res, err
+}
+
+func (b *Bolt) view(ctx context.Context, fn func(q *boltQuery) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return b.db.View(func(tx *bolt.Tx) error {
+		return fn(&boltQuery{bucket: tx.Bucket(bucket)})
+	})
+}
+
+func (b *Bolt) update(ctx context.Context, fn func(q *boltQuery) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return fn(&boltQuery{bucket: tx.Bucket(bucket)})
+	})
+}
+
+// Tx begins a read-write transaction. As bbolt allows a single read-write
+// transaction at a time, it blocks until any other one is done.
+func (b *Bolt) Tx(ctx context.Context) (persistence.Tx, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	tx, err := b.db.Begin(true)
+	if err != nil {
+		return nil, err
+	}
+	return &boltTx{
+		tx:    tx,
+		query: boltQuery{bucket: tx.Bucket(bucket)},
+	}, nil
+}
+
+func (b *Bolt) Close() error {
+	var err error
+	b.closed.Do(func() {
+		dbsLock.Lock()
+		defer dbsLock.Unlock()
+		b.db.refs--
+		if b.db.refs == 0 {
+			delete(dbs, b.filename)
+			err = b.db.Close()
+		}
+	})
+	return err
+}
+
+type boltTx struct {
+	tx    *bolt.Tx
+	query boltQuery
+}
+
+func (t *boltTx) Commit() error {
+	return t.tx.Commit()
+}
+
+func (t *boltTx) Rollback() error {
+	return t.tx.Rollback()
+}
+
+func (t *boltTx) Get(ctx context.Context, key string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return t.query.Get(key)
+}
+
+func (t *boltTx) Insert(ctx context.Context, key string, value []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return t.query.Insert(key, value)
+}
+
+func (t *boltTx) Put(ctx context.Context, key string, value []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return t.query.Put(key, value)
+}
+
+func (t *boltTx) Delete(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return t.query.Delete(key)
+}
+
+func (t *boltTx) List(
+	ctx context.Context,
+	prefix string,
+	opts *persistence.ListOpts,
+) (persistence.ListResult, error) {
+	if err := ctx.Err(); err != nil {
+		return persistence.ListResult{}, err
+	}
+	return t.query.List(prefix, opts)
+}
+
+type boltQuery struct {
+	bucket *bolt.Bucket
+}
+
+// lookup returns the value of the key, and whether the key exists. The value
+// is only valid for the life of the transaction.
+func (q *boltQuery) lookup(key []byte) ([]byte, bool) {
+	k, v := q.bucket.Cursor().Seek(key)
+	if k == nil || !bytes.Equal(k, key) {
+		return nil, false
+	}
+	return v, true
+}
+
+func (q *boltQuery) Get(key string) ([]byte, error) {
+	value, ok := q.lookup([]byte(key))
+	if !ok {
+		return nil, persistence.ErrNotFound{Key: key}
+	}
+	return clone(value), nil
+}
+
+func (q *boltQuery) Insert(key string, value []byte) error {
+	if _, ok := q.lookup([]byte(key)); ok {
+		return persistence.ErrUniqueViolation
+	}
+	return q.Put(key, value)
+}
+
+func (q *boltQuery) Put(key string, value []byte) error {
+	if key == "" {
+		return errors.New("bolt: empty key")
+	}
+	// bbolt holds on to the value until the transaction is committed.
+	return q.bucket.Put([]byte(key), clone(value))
+}
+
+func (q *boltQuery) Delete(key string) error {
+	if _, ok := q.lookup([]byte(key)); !ok {
+		return persistence.ErrNotFound{Key: key}
+	}
+	return q.bucket.Delete([]byte(key))
+}
+
+// sortedKV is a listed key along with the values it is sorted by.
+type sortedKV struct {
+	persistence.KVResult
+	values []interface{}
+}
+
+// List scans the keys with the given prefix, in order, and applies the
+// options to them in the same way as the SQL persisters do.
+func (q *boltQuery) List(prefix string, opts *persistence.ListOpts) (persistence.ListResult, error) {
+	// Keys of prefixes containing wildcard operators start with the part
+	// before the first of them, and contain the following parts in order.
+	parts := strings.Split(prefix, persistence.WildcardOperator)
+	start, rest := persistence.ReferencedIDPosition(prefix)
+
+	var kvs []sortedKV
+	c := q.bucket.Cursor()
+	for k, v := c.Seek([]byte(parts[0])); k != nil && bytes.HasPrefix(k, []byte(parts[0])); k, v = c.Next() {
+		if !containsInOrder(k[len(parts[0]):], parts[1:]) {
+			continue
+		}
+		if opts.ReferencedPrefix != "" {
+			if len(k) < rest {
+				continue
+			}
+			id := k[start-1 : start-1+len(k)-rest]
+			var ok bool
+			if v, ok = q.lookup(append([]byte(opts.ReferencedPrefix), id...)); !ok {
+				continue
+			}
+		}
+		kv := sortedKV{KVResult: persistence.KVResult{Key: clone(k), Value: clone(v)}}
+		if len(opts.Sort) > 0 {
+			values, err := persistence.SortValues(opts.Sort, kv.Value)
+			if err != nil {
+				return persistence.ListResult{}, err
+			}
+			kv.values = values
+		}
+		if opts.After != "" && !sortsAfter(opts.Sort, kv.values, string(kv.Key), opts.AfterValues, opts.After) {
+			continue
+		}
+		kvs = append(kvs, kv)
+	}
+	if len(opts.Sort) > 0 {
+		// Keys are already sorted, which the stable sort preserves for
+		// equal values.
+		sort.SliceStable(kvs, func(i, j int) bool {
+			return compareValues(opts.Sort, kvs[i].values, kvs[j].values) < 0
+		})
+	}
+
+	kvList := make([]persistence.KVResult, len(kvs))
+	for i, kv := range kvs {
+		kvList[i] = kv.KVResult
+	}
+	if opts.Filter != nil {
+		return persistence.FilterKVList(kvList, opts)
+	}
+	// Like the SQL persisters, which count the keys along with each returned
+	// row, the total count is zero for pages past the last one.
+	res := persistence.ListResult{KVList: []persistence.KVResult{}}
+	if opts.Offset < len(kvList) && opts.Limit > 0 {
+		res.TotalCount = len(kvList)
+		kvList = kvList[opts.Offset:]
+		if opts.Limit < len(kvList) {
+			kvList = kvList[:opts.Limit]
+		}
+		res.KVList = kvList
+	}
+	return res, nil
+}
+
+// containsInOrder returns whether b contains all the parts, in order and
+// without overlapping.
+func containsInOrder(b []byte, parts []string) bool {
+	for _, part := range parts {
+		i := bytes.Index(b, []byte(part))
+		if i < 0 {
+			return false
+		}
+		b = b[i+len(part):]
+	}
+	return true
+}
+
+// sortsAfter returns whether the key and its values sort after the given key
+// and values. See persistence.SortAfter.
+func sortsAfter(fields []persistence.SortField, values []interface{}, key string,
+	afterValues []interface{}, after string,
+) bool {
+	if c := compareValues(fields, values, afterValues); c != 0 {
+		return c > 0
+	}
+	return key > after
+}
+
+// compareValues compares the values of the given fields, accounting for the
+// direction of each field.
+func compareValues(fields []persistence.SortField, a, b []interface{}) int {
+	for i, field := range fields {
+		c := compare(a[i], b[i])
+		if field.Descending {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// compare compares two values of the same kind, as returned by
+// persistence.SortValues.
+func compare(a, b interface{}) int {
+	switch a := a.(type) {
+	case bool:
+		b, _ := b.(bool)
+		switch {
+		case a == b:
+			return 0
+		case !a:
+			return -1
+		default:
+			return 1
+		}
+	case int64:
+		b, _ := b.(int64)
+		return compareOrdered(a, b)
+	case uint64:
+		b, _ := b.(uint64)
+		return compareOrdered(a, b)
+	case float64:
+		b, _ := b.(float64)
+		return compareOrdered(a, b)
+	case string:
+		b, _ := b.(string)
+		return strings.Compare(a, b)
+	default:
+		return 0
+	}
+}
+
+func compareOrdered[T int64 | uint64 | float64](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func clone(b []byte) []byte {
+	if b == nil {
+		return nil
+	}
+	return append(make([]byte, 0, len(b)), b...)
+}
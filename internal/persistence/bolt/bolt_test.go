@@ -0,0 +1,74 @@
+package bolt
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/kong/koko/internal/log"
+	"github.com/kong/koko/internal/persistence"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	ctx := context.Background()
+	opts := Opts{Filename: filepath.Join(t.TempDir(), "koko.db")}
+
+	t.Run("requires a file name", func(t *testing.T) {
+		_, err := New(Opts{}, 0, log.Logger)
+		require.EqualError(t, err, "bolt: no database file name")
+	})
+	t.Run("persisters of the same file share the database", func(t *testing.T) {
+		p1, err := New(opts, 0, log.Logger)
+		require.NoError(t, err)
+		p2, err := New(opts, 0, log.Logger)
+		require.NoError(t, err)
+
+		require.NoError(t, p1.Put(ctx, "foo", []byte("bar")))
+		value, err := p2.Get(ctx, "foo")
+		require.NoError(t, err)
+		require.Equal(t, []byte("bar"), value)
+
+		// The database is kept open until every persister is closed.
+		require.NoError(t, p1.Close())
+		require.NoError(t, p1.Close())
+		_, err = p2.Get(ctx, "foo")
+		require.NoError(t, err)
+		require.NoError(t, p2.Close())
+	})
+	t.Run("keys are persisted to the file", func(t *testing.T) {
+		p, err := New(opts, 0, log.Logger)
+		require.NoError(t, err)
+		defer p.Close()
+		value, err := p.Get(ctx, "foo")
+		require.NoError(t, err)
+		require.Equal(t, []byte("bar"), value)
+	})
+}
+
+func TestListWildcards(t *testing.T) {
+	ctx := context.Background()
+	p, err := New(Opts{Filename: filepath.Join(t.TempDir(), "koko.db")}, 0, log.Logger)
+	require.NoError(t, err)
+	defer p.Close()
+
+	for _, key := range []string{"a/1/b/1", "a/2/b/1", "a/2/b/2", "a/3/c/1"} {
+		require.NoError(t, p.Put(ctx, key, []byte("{}")))
+	}
+	keys := func(prefix string) []string {
+		res, err := p.List(ctx, prefix, &persistence.ListOpts{Limit: persistence.MaxLimit})
+		require.NoError(t, err)
+		keys := make([]string, 0, len(res.KVList))
+		for _, kv := range res.KVList {
+			keys = append(keys, string(kv.Key))
+		}
+		require.Equal(t, len(keys), res.TotalCount)
+		return keys
+	}
+	w := persistence.WildcardOperator
+
+	require.Equal(t, []string{"a/2/b/1", "a/2/b/2"}, keys("a/2/"))
+	require.Equal(t, []string{"a/1/b/1", "a/2/b/1"}, keys("a/"+w+"/b/1"))
+	require.Equal(t, []string{"a/1/b/1", "a/2/b/1", "a/2/b/2"}, keys(w+"/b/"))
+	require.Empty(t, keys("a/"+w+"/d/"))
+}
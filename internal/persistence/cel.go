@@ -1,118 +1,497 @@
 package persistence
 
 import (
+	"encoding/json"
 	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
 
+	"github.com/google/cel-go/cel"
 	"github.com/google/cel-go/common/operators"
-	"github.com/samber/lo"
+	"github.com/google/cel-go/common/overloads"
 	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
 )
 
-// GetTagsFromExpression takes in a pre-validated CEL expression and extracts the tags to filter upon.
-// The CEL logical operator is also returned (`operators.LogicalAnd` or `operators.LogicalOr`). As a
-// sanity check, an error will be returned when the expression does not conform to what is expected.
-func GetTagsFromExpression(expr *exprpb.Expr) (tags []*exprpb.Constant, exprFunction string, err error) {
-	switch exprKind := expr.GetExprKind().(type) {
+// FieldKind is the kind of value a field of a stored object is compared as.
+type FieldKind int
+
+const (
+	FieldKindString FieldKind = iota
+	FieldKindInt
+	FieldKindUint
+	FieldKindDouble
+	FieldKindBool
+)
+
+// FilterDialect translates references to the fields of stored objects into
+// the SQL of a particular database, so that filters can be pushed down into
+// the queries used by `CRUD.List()`.
+type FilterDialect interface {
+	// Field returns the SQL expression reading the given top-level field of
+	// the stored object as the given kind. Missing fields must evaluate to the
+	// zero value of the kind, and never to NULL.
+	Field(name string, kind FieldKind) string
+
+	// ListContains returns the SQL condition asserting that the list stored
+	// in the given top-level field contains the string value bound to the
+	// single placeholder of the condition.
+	ListContains(name string) string
+
+	// Match returns the SQL condition & its placeholder value asserting that
+	// the given field SQL expression matches the value, where the function is
+	// one of overloads.StartsWith, overloads.EndsWith or overloads.Contains.
+	Match(field string, function string, value string) (string, interface{})
+}
+
+// fieldNamePattern is the pattern a field name must match in order for it to
+// be safely used within SQL expressions.
+var fieldNamePattern = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
+var sqlComparisonOperators = map[string]string{
+	operators.Equals:        "=",
+	operators.NotEquals:     "<>",
+	operators.Less:          "<",
+	operators.LessEquals:    "<=",
+	operators.Greater:       ">",
+	operators.GreaterEquals: ">=",
+}
+
+// flippedComparisonOperators holds the operator to use when swapping the
+// operands of a comparison, e.g.: `443 < port` is the same as `port > 443`.
+var flippedComparisonOperators = map[string]string{
+	operators.Equals:        operators.Equals,
+	operators.NotEquals:     operators.NotEquals,
+	operators.Less:          operators.Greater,
+	operators.LessEquals:    operators.GreaterEquals,
+	operators.Greater:       operators.Less,
+	operators.GreaterEquals: operators.LessEquals,
+}
+
+// TranslateFilter translates the given type-checked CEL expression to a SQL
+// condition, along with the values of its placeholders.
+//
+// The top-level conjuncts of the expression are translated separately. When a
+// conjunct is not supported by the translation, it is left out of the returned
+// condition and `residual` is true, in which case the rows matching the
+// condition must still be evaluated using FilterKVList(). An empty condition
+// is returned when no conjunct can be translated.
+func TranslateFilter(d FilterDialect, filter *exprpb.CheckedExpr) (
+	where string, args []interface{}, residual bool,
+) {
+	if filter == nil {
+		return "", nil, false
+	}
+	t := &filterTranslator{dialect: d, types: filter.TypeMap}
+
+	var conditions []string
+	for _, conjunct := range conjuncts(filter.Expr) {
+		condition, conjunctArgs, ok := t.translate(conjunct, nil)
+		if !ok {
+			residual = true
+			continue
+		}
+		// The condition is combined with the other conditions of the query.
+		conditions = append(conditions, "("+condition+")")
+		args = append(args, conjunctArgs...)
+	}
+
+	return strings.Join(conditions, " AND "), args, residual
+}
+
+// conjuncts flattens the top-level logical and expressions.
+func conjuncts(expr *exprpb.Expr) []*exprpb.Expr {
+	if call := expr.GetCallExpr(); call != nil && call.Function == operators.LogicalAnd && call.Target == nil {
+		var res []*exprpb.Expr
+		for _, arg := range call.Args {
+			res = append(res, conjuncts(arg)...)
+		}
+		return res
+	}
+	return []*exprpb.Expr{expr}
+}
+
+type filterTranslator struct {
+	dialect FilterDialect
+	types   map[int64]*exprpb.Type
+}
+
+// translate returns the SQL condition of the given boolean expression. The
+// bindings hold the constant values of the variables of expanded macros.
+func (t *filterTranslator) translate(expr *exprpb.Expr, bindings map[string]*exprpb.Constant) (
+	string, []interface{}, bool,
+) {
+	switch kind := expr.GetExprKind().(type) {
+	case *exprpb.Expr_ConstExpr:
+		if v, ok := kind.ConstExpr.GetConstantKind().(*exprpb.Constant_BoolValue); ok {
+			return boolCondition(v.BoolValue), nil, true
+		}
+	case *exprpb.Expr_IdentExpr:
+		// Boolean fields can be used as conditions on their own, e.g.: `enabled`.
+		if name, kind, ok := t.field(expr, bindings); ok && kind == FieldKindBool {
+			return t.dialect.Field(name, kind) + " = ?", []interface{}{true}, true
+		}
 	case *exprpb.Expr_CallExpr:
-		// Support simple logic and/or queries, e.g.:
-		// - `"tag1" in tags && "tag2" in tags`
-		// - `"tag1" in tags || "tag2" in tags`
-		callExpr := exprKind.CallExpr
-
-		// Extract whether this is a logical `and` or `or` expression.
-		exprFunction = callExpr.Function
-
-		// Should not happen as long as we're validating expressions properly.
-		if !lo.Contains([]string{operators.LogicalAnd, operators.LogicalOr, operators.In}, callExpr.Function) {
-			return nil, "", fmt.Errorf("operator %q is not supported", callExpr.Function)
-		}
-
-		// Extract the tags the expression is asking to filter on. We're purposefully ignoring the
-		// field names to filter upon, as right now, the only supported identifier is `tags` anyway.
-		for _, arg := range callExpr.Args {
-			switch exprKind := arg.GetExprKind().(type) {
-			case *exprpb.Expr_IdentExpr:
-				// No-op as we don't need to do anything with identifiers right now.
-				continue
-			case *exprpb.Expr_CallExpr:
-				if exprKind.CallExpr.Function != operators.In {
-					return nil, "", fmt.Errorf("unexpected operator %q for call expression", exprKind.CallExpr.Function)
-				}
-
-				// Should never happen, just a sanity check.
-				if len(exprKind.CallExpr.Args) != 2 { //nolint:gomnd
-					return nil, "", fmt.Errorf(
-						"expecting both constant & identifier expression, but got %d call expression arguments",
-						len(exprKind.CallExpr.Args),
-					)
-				}
-
-				constExpr := exprKind.CallExpr.Args[0].GetConstExpr()
-				if constExpr == nil {
-					return nil, "", fmt.Errorf(
-						"expected constant expression, got %T",
-						exprKind.CallExpr.Args[0].GetExprKind(),
-					)
-				}
-				tags = append(tags, constExpr)
-			case *exprpb.Expr_ConstExpr:
-				// Has a single tag to filter on, e.g: `"tag1" in tags`.
-				tags = append(tags, exprKind.ConstExpr)
-			default:
-				return nil, "", fmt.Errorf("unexpected expression kind: %T", exprKind)
+		return t.translateCall(kind.CallExpr, bindings)
+	case *exprpb.Expr_ComprehensionExpr:
+		return t.translateComprehension(kind.ComprehensionExpr, bindings)
+	}
+	return "", nil, false
+}
+
+func (t *filterTranslator) translateCall(call *exprpb.Expr_Call, bindings map[string]*exprpb.Constant) (
+	string, []interface{}, bool,
+) {
+	switch call.Function {
+	case operators.LogicalAnd, operators.LogicalOr:
+		operator := " AND "
+		if call.Function == operators.LogicalOr {
+			operator = " OR "
+		}
+		var conditions []string
+		var args []interface{}
+		for _, arg := range call.Args {
+			condition, argArgs, ok := t.translate(arg, bindings)
+			if !ok {
+				return "", nil, false
 			}
+			conditions = append(conditions, "("+condition+")")
+			args = append(args, argArgs...)
 		}
-	case *exprpb.Expr_ComprehensionExpr:
-		// Support `exists()` & `all()` macros, e.g.:
-		// - `["tag1", "tag2"].all(x, x in tags)`
-		// - `["tag1", "tag2"].exists(x, x in tags)`
-		compExpr := exprKind.ComprehensionExpr
+		return strings.Join(conditions, operator), args, true
+	case operators.LogicalNot:
+		condition, args, ok := t.translate(call.Args[0], bindings)
+		if !ok {
+			return "", nil, false
+		}
+		// Conditions may evaluate to NULL, e.g.: when a list does not exist.
+		return "NOT COALESCE((" + condition + "), FALSE)", args, true
+	case operators.In:
+		return t.translateIn(call.Args[0], call.Args[1], bindings)
+	case overloads.StartsWith, overloads.EndsWith, overloads.Contains:
+		if call.Target == nil || len(call.Args) != 1 {
+			return "", nil, false
+		}
+		name, kind, ok := t.field(call.Target, bindings)
+		if !ok || kind != FieldKindString {
+			return "", nil, false
+		}
+		value, ok := constantValue(t.constant(call.Args[0], bindings))
+		if !ok {
+			return "", nil, false
+		}
+		condition, arg := t.dialect.Match(t.dialect.Field(name, kind), call.Function, value.(string))
+		return condition, []interface{}{arg}, true
+	}
 
-		// Extract whether this is a logical `and` or `or` expression.
-		loopCallExpr := compExpr.LoopStep.GetCallExpr()
-		if loopCallExpr == nil {
-			return nil, "", fmt.Errorf("unexpected loop step expression kind: %T", compExpr.LoopStep.GetExprKind())
+	operator, ok := sqlComparisonOperators[call.Function]
+	if !ok || len(call.Args) != 2 { //nolint:gomnd
+		return "", nil, false
+	}
+	lhs, rhs := call.Args[0], call.Args[1]
+	if _, _, isField := t.field(lhs, bindings); !isField {
+		lhs, rhs = rhs, lhs
+		operator = sqlComparisonOperators[flippedComparisonOperators[call.Function]]
+	}
+	return t.comparison(lhs, operator, rhs, bindings)
+}
+
+// comparison returns the SQL condition comparing the field of the lhs
+// expression to the constant value of the rhs expression.
+func (t *filterTranslator) comparison(lhs *exprpb.Expr, operator string, rhs *exprpb.Expr,
+	bindings map[string]*exprpb.Constant,
+) (string, []interface{}, bool) {
+	name, kind, ok := t.field(lhs, bindings)
+	if !ok {
+		return "", nil, false
+	}
+	value, ok := constantValue(t.constant(rhs, bindings))
+	if !ok || constantKind(value) != kind {
+		return "", nil, false
+	}
+	return t.dialect.Field(name, kind) + " " + operator + " ?", []interface{}{value}, true
+}
+
+// translateIn supports both `"value" in listField` & `field in ["a", "b"]`.
+func (t *filterTranslator) translateIn(element, list *exprpb.Expr, bindings map[string]*exprpb.Constant) (
+	string, []interface{}, bool,
+) {
+	if listExpr := list.GetListExpr(); listExpr != nil {
+		if len(listExpr.Elements) == 0 {
+			return boolCondition(false), nil, true
+		}
+		var conditions []string
+		var args []interface{}
+		for _, e := range listExpr.Elements {
+			condition, eArgs, ok := t.comparison(element, "=", e, bindings)
+			if !ok {
+				return "", nil, false
+			}
+			conditions = append(conditions, condition)
+			args = append(args, eArgs...)
 		}
-		exprFunction = loopCallExpr.Function
+		return strings.Join(conditions, " OR "), args, true
+	}
+
+	ident := list.GetIdentExpr()
+	if ident == nil || bindings[ident.Name] != nil || !fieldNamePattern.MatchString(ident.Name) {
+		return "", nil, false
+	}
+	listType := t.types[list.Id].GetListType()
+	if listType.GetElemType().GetPrimitive() != exprpb.Type_STRING {
+		return "", nil, false
+	}
+	value, ok := constantValue(t.constant(element, bindings))
+	if !ok || constantKind(value) != FieldKindString {
+		return "", nil, false
+	}
+	return t.dialect.ListContains(ident.Name), []interface{}{value}, true
+}
 
-		listExpr := compExpr.IterRange.GetListExpr()
-		if listExpr == nil {
-			return nil, "", fmt.Errorf("unexpected iterative range kind: %T", compExpr.IterRange.GetExprKind())
+// translateComprehension supports the `all()` & `exists()` macros, ranging
+// upon a list of constants, by expanding them for each of the list elements.
+func (t *filterTranslator) translateComprehension(comp *exprpb.Expr_Comprehension,
+	bindings map[string]*exprpb.Constant,
+) (string, []interface{}, bool) {
+	listExpr := comp.IterRange.GetListExpr()
+	step := comp.LoopStep.GetCallExpr()
+	if listExpr == nil || step == nil || len(step.Args) != 2 || //nolint:gomnd
+		step.Args[0].GetIdentExpr().GetName() != comp.AccuVar ||
+		comp.Result.GetIdentExpr().GetName() != comp.AccuVar {
+		return "", nil, false
+	}
+
+	operator := " AND "
+	switch step.Function {
+	case operators.LogicalAnd:
+		if len(listExpr.Elements) == 0 {
+			return boolCondition(true), nil, true
+		}
+	case operators.LogicalOr:
+		operator = " OR "
+		if len(listExpr.Elements) == 0 {
+			return boolCondition(false), nil, true
 		}
+	default:
+		return "", nil, false
+	}
 
-		for _, element := range listExpr.Elements {
-			constExpr := element.GetConstExpr()
-			if constExpr == nil {
-				return nil, "", fmt.Errorf("unexpected list expression kind: %T", element.GetExprKind())
-			}
-			tags = append(tags, constExpr)
+	var conditions []string
+	var args []interface{}
+	for _, e := range listExpr.Elements {
+		c := t.constant(e, bindings)
+		if c == nil {
+			return "", nil, false
 		}
+		elementBindings := make(map[string]*exprpb.Constant, len(bindings)+1)
+		for k, v := range bindings {
+			elementBindings[k] = v
+		}
+		elementBindings[comp.IterVar] = c
+		condition, eArgs, ok := t.translate(step.Args[1], elementBindings)
+		if !ok {
+			return "", nil, false
+		}
+		conditions = append(conditions, "("+condition+")")
+		args = append(args, eArgs...)
+	}
+	return strings.Join(conditions, operator), args, true
+}
+
+// field returns the name & kind of the field the expression refers to, when
+// it is a top-level field of a primitive type.
+func (t *filterTranslator) field(expr *exprpb.Expr, bindings map[string]*exprpb.Constant) (
+	string, FieldKind, bool,
+) {
+	ident := expr.GetIdentExpr()
+	if ident == nil || bindings[ident.Name] != nil || !fieldNamePattern.MatchString(ident.Name) {
+		return "", 0, false
+	}
+	var kind FieldKind
+	switch t.types[expr.Id].GetPrimitive() {
+	case exprpb.Type_STRING:
+		kind = FieldKindString
+	case exprpb.Type_INT64:
+		kind = FieldKindInt
+	case exprpb.Type_UINT64:
+		kind = FieldKindUint
+	case exprpb.Type_DOUBLE:
+		kind = FieldKindDouble
+	case exprpb.Type_BOOL:
+		kind = FieldKindBool
 	default:
-		return nil, "", fmt.Errorf("unsupported expression kind: %T", exprKind)
+		return "", 0, false
 	}
+	return ident.Name, kind, true
+}
 
-	return tags, exprFunction, nil
+// constant returns the constant value of the expression, or nil when the
+// expression is not a constant.
+func (t *filterTranslator) constant(expr *exprpb.Expr, bindings map[string]*exprpb.Constant) *exprpb.Constant {
+	if ident := expr.GetIdentExpr(); ident != nil {
+		return bindings[ident.Name]
+	}
+	return expr.GetConstExpr()
 }
 
-// GetQueryArgsFromExprConstants is a simple helper function to convert user-provided constant
-// values to their string interface counterparts, which is helpful for use as placeholder values
-// when writing DB queries. This will also filter out any duplicate tag values. An error will
-// only be returned when a constant is not of the `exprpb.Constant_StringValue` type.
-func GetQueryArgsFromExprConstants(constants []*exprpb.Constant) ([]interface{}, error) {
-	args, seenTags := make([]interface{}, 0, len(constants)), make(map[string]bool, len(constants))
-	for _, c := range constants {
-		strConst, ok := c.GetConstantKind().(*exprpb.Constant_StringValue)
-		if !ok {
-			return nil, fmt.Errorf("unexpected constant kind: %T", c.GetConstantKind())
+func constantValue(c *exprpb.Constant) (interface{}, bool) {
+	switch v := c.GetConstantKind().(type) {
+	case *exprpb.Constant_StringValue:
+		return v.StringValue, true
+	case *exprpb.Constant_Int64Value:
+		return v.Int64Value, true
+	case *exprpb.Constant_Uint64Value:
+		return v.Uint64Value, true
+	case *exprpb.Constant_DoubleValue:
+		return v.DoubleValue, true
+	case *exprpb.Constant_BoolValue:
+		return v.BoolValue, true
+	default:
+		return nil, false
+	}
+}
+
+func constantKind(v interface{}) FieldKind {
+	switch v.(type) {
+	case int64:
+		return FieldKindInt
+	case uint64:
+		return FieldKindUint
+	case float64:
+		return FieldKindDouble
+	case bool:
+		return FieldKindBool
+	default:
+		return FieldKindString
+	}
+}
+
+func boolCondition(v bool) string {
+	if v {
+		return "1 = 1"
+	}
+	return "1 = 0"
+}
+
+// FilterKVList evaluates the filter on each of the given stored objects, and
+// returns the page of matching objects described by the list options. Objects
+// on which the filter fails to evaluate are considered not to match.
+func FilterKVList(kvs []KVResult, opts *ListOpts) (ListResult, error) {
+	env, err := cel.NewEnv(cel.CrossTypeNumericComparisons(true))
+	if err != nil {
+		return ListResult{}, err
+	}
+	prg, err := env.Program(cel.CheckedExprToAst(opts.Filter))
+	if err != nil {
+		return ListResult{}, fmt.Errorf("unable to evaluate filter: %w", err)
+	}
+
+	// Variables are the references that do not resolve to a function.
+	variables := map[string]*exprpb.Type{}
+	for id, ref := range opts.Filter.ReferenceMap {
+		if ref.Name != "" && len(ref.OverloadId) == 0 {
+			variables[ref.Name] = opts.Filter.TypeMap[id]
 		}
+	}
 
-		// If for whatever reason the user provided duplicate tags, we'll want to drop them,
-		// as the length of the returned tags array can be used in `HAVING` clauses, in order
-		// to assert that all tags have been returned (when exprFunction = `operators.Equals`).
-		if !seenTags[strConst.StringValue] {
-			args, seenTags[strConst.StringValue] = append(args, strConst.StringValue), true
+	var matches []KVResult
+	for _, kv := range kvs {
+		var value struct {
+			Object map[string]interface{} `json:"object"`
+		}
+		if err := json.Unmarshal(kv.Value, &value); err != nil {
+			return ListResult{}, err
+		}
+		activation := make(map[string]interface{}, len(variables))
+		for name, typ := range variables {
+			activation[name] = filterValue(value.Object[name], typ)
+		}
+		out, _, err := prg.Eval(activation)
+		if err != nil {
+			continue
+		}
+		if match, ok := out.Value().(bool); ok && match {
+			matches = append(matches, kv)
 		}
 	}
 
-	return args, nil
+	res := ListResult{TotalCount: len(matches), KVList: []KVResult{}}
+	if opts.Offset < len(matches) {
+		matches = matches[opts.Offset:]
+		if opts.Limit > 0 && opts.Limit < len(matches) {
+			matches = matches[:opts.Limit]
+		}
+		res.KVList = matches
+	}
+	return res, nil
+}
+
+// filterValue converts a value decoded from the JSON of a stored object to
+// the given type. As zero values are not stored, missing values are converted
+// to the zero value of the type.
+func filterValue(v interface{}, typ *exprpb.Type) interface{} {
+	switch typ.GetPrimitive() {
+	case exprpb.Type_STRING:
+		return kindValue(v, FieldKindString)
+	case exprpb.Type_BOOL:
+		return kindValue(v, FieldKindBool)
+	case exprpb.Type_DOUBLE:
+		return kindValue(v, FieldKindDouble)
+	case exprpb.Type_INT64:
+		return kindValue(v, FieldKindInt)
+	case exprpb.Type_UINT64:
+		return kindValue(v, FieldKindUint)
+	}
+
+	switch {
+	case typ.GetListType() != nil:
+		list, _ := v.([]interface{})
+		res := make([]interface{}, len(list))
+		for i, e := range list {
+			res[i] = filterValue(e, typ.GetListType().ElemType)
+		}
+		return res
+	case typ.GetMapType() != nil:
+		if m, ok := v.(map[string]interface{}); ok {
+			return m
+		}
+		return map[string]interface{}{}
+	default:
+		return v
+	}
+}
+
+// kindValue converts a value decoded from the JSON of a stored object to the
+// given kind, where missing values are converted to the zero value of the kind.
+func kindValue(v interface{}, kind FieldKind) interface{} {
+	switch kind {
+	case FieldKindBool:
+		b, _ := v.(bool)
+		return b
+	case FieldKindDouble:
+		f, _ := v.(float64)
+		return f
+	case FieldKindInt:
+		switch n := v.(type) {
+		case float64:
+			return int64(n)
+		case string:
+			// 64-bit integers are encoded as JSON strings.
+			i, _ := strconv.ParseInt(n, 10, 64)
+			return i
+		}
+		return int64(0)
+	case FieldKindUint:
+		switch n := v.(type) {
+		case float64:
+			return uint64(n)
+		case string:
+			u, _ := strconv.ParseUint(n, 10, 64)
+			return u
+		}
+		return uint64(0)
+	default:
+		s, _ := v.(string)
+		return s
+	}
 }
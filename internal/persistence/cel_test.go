@@ -0,0 +1,142 @@
+package persistence
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/checker/decls"
+	"github.com/stretchr/testify/require"
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+type testFilterDialect struct{}
+
+func (testFilterDialect) Field(name string, kind FieldKind) string {
+	return fmt.Sprintf("field(%s, %d)", name, kind)
+}
+
+func (testFilterDialect) ListContains(name string) string {
+	return fmt.Sprintf("contains(%s, ?)", name)
+}
+
+func (testFilterDialect) Match(field string, function string, value string) (string, interface{}) {
+	return fmt.Sprintf("%s(%s, ?)", function, field), value
+}
+
+func compileTestFilter(t *testing.T, expression string) *exprpb.CheckedExpr {
+	env, err := cel.NewEnv(cel.Declarations(
+		decls.NewVar("name", decls.String),
+		decls.NewVar("port", decls.Int),
+		decls.NewVar("enabled", decls.Bool),
+		decls.NewVar("tags", decls.NewListType(decls.String)),
+		decls.NewVar("config", decls.NewMapType(decls.String, decls.Dyn)),
+	))
+	require.NoError(t, err)
+	ast, issues := env.Compile(expression)
+	require.NoError(t, issues.Err())
+	checked, err := cel.AstToCheckedExpr(ast)
+	require.NoError(t, err)
+	return checked
+}
+
+func TestTranslateFilter(t *testing.T) {
+	tests := []struct {
+		expression string
+		where      string
+		args       []interface{}
+		residual   bool
+	}{
+		{
+			expression: `"tag1" in tags`,
+			where:      "(contains(tags, ?))",
+			args:       []interface{}{"tag1"},
+		},
+		{
+			expression: `name == "foo" && port != 443`,
+			where:      "(field(name, 0) = ?) AND (field(port, 1) <> ?)",
+			args:       []interface{}{"foo", int64(443)},
+		},
+		{
+			expression: `443 < port || !enabled`,
+			where:      "((field(port, 1) > ?) OR (NOT COALESCE((field(enabled, 4) = ?), FALSE)))",
+			args:       []interface{}{int64(443), true},
+		},
+		{
+			expression: `name.startsWith("billing-")`,
+			where:      "(startsWith(field(name, 0), ?))",
+			args:       []interface{}{"billing-"},
+		},
+		{
+			expression: `name in ["foo", "bar"]`,
+			where:      "(field(name, 0) = ? OR field(name, 0) = ?)",
+			args:       []interface{}{"foo", "bar"},
+		},
+		{
+			expression: `["tag1", "tag2"].all(x, x in tags)`,
+			where:      "((contains(tags, ?)) AND (contains(tags, ?)))",
+			args:       []interface{}{"tag1", "tag2"},
+		},
+		{
+			expression: `[].exists(x, x in tags)`,
+			where:      "(1 = 0)",
+		},
+		{
+			expression: `"tag1" in tags && config.minute == 5`,
+			where:      "(contains(tags, ?))",
+			args:       []interface{}{"tag1"},
+			residual:   true,
+		},
+		{
+			expression: `name == "foo" || config.minute == 5`,
+			residual:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			where, args, residual := TranslateFilter(testFilterDialect{}, compileTestFilter(t, tt.expression))
+			require.Equal(t, tt.where, where)
+			require.Equal(t, tt.args, args)
+			require.Equal(t, tt.residual, residual)
+		})
+	}
+}
+
+func TestFilterKVList(t *testing.T) {
+	kvs := []KVResult{
+		{Key: []byte("a"), Value: []byte(`{"object":{"name":"a","port":443,"config":{"minute":5}}}`)},
+		{Key: []byte("b"), Value: []byte(`{"object":{"name":"b","config":{"minute":5}}}`)},
+		{Key: []byte("c"), Value: []byte(`{"object":{"name":"c","port":80,"tags":["tag1"]}}`)},
+		{Key: []byte("d"), Value: []byte(`{"object":{"name":"d","config":{"minute":5},"enabled":true}}`)},
+	}
+
+	t.Run("evaluates the filter", func(t *testing.T) {
+		res, err := FilterKVList(kvs, &ListOpts{
+			Limit:  10,
+			Filter: compileTestFilter(t, `config.minute == 5 && port < 443`),
+		})
+		require.NoError(t, err)
+		require.Equal(t, 2, res.TotalCount)
+		require.Equal(t, []KVResult{kvs[1], kvs[3]}, res.KVList)
+	})
+	t.Run("paginates the matches", func(t *testing.T) {
+		res, err := FilterKVList(kvs, &ListOpts{
+			Limit:  1,
+			Offset: 1,
+			Filter: compileTestFilter(t, `!enabled || "tag1" in tags`),
+		})
+		require.NoError(t, err)
+		require.Equal(t, 3, res.TotalCount)
+		require.Equal(t, []KVResult{kvs[1]}, res.KVList)
+	})
+	t.Run("offset past the matches", func(t *testing.T) {
+		res, err := FilterKVList(kvs, &ListOpts{
+			Limit:  1,
+			Offset: 5,
+			Filter: compileTestFilter(t, `name != ""`),
+		})
+		require.NoError(t, err)
+		require.Equal(t, 4, res.TotalCount)
+		require.Empty(t, res.KVList)
+	})
+}
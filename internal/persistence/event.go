@@ -0,0 +1,19 @@
+package persistence
+
+import "context"
+
+// EventSource is implemented by a Persister that can push notifications to
+// listeners, sparing them from polling the database for changes.
+type EventSource interface {
+	// Listen subscribes to notifications sent on channel. Payloads of the
+	// notifications are delivered on the returned channel, which is closed
+	// once ctx is done or the subscription fails.
+	Listen(ctx context.Context, channel string) (<-chan string, error)
+}
+
+// Notifier is implemented by a Tx that can send notifications to listeners
+// of an EventSource. Notifications are delivered only once the transaction
+// is committed.
+type Notifier interface {
+	Notify(ctx context.Context, channel string, payload string) error
+}
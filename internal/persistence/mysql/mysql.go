@@ -10,11 +10,9 @@ import (
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/go-sql-driver/mysql"
-	"github.com/google/cel-go/common/operators"
-	"github.com/kong/koko/internal/json"
+	"github.com/google/cel-go/common/overloads"
 	"github.com/kong/koko/internal/persistence"
 	"go.uber.org/zap"
-	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
 )
 
 // ErrMariaDBUnsupported is the error returned when db.DialectMariaDB is attempted to be used.
@@ -42,8 +40,9 @@ const duplicateEntryErrorCode uint16 = 1062
 // like MySQL does. While this isn't difficult to support, we're punting this support for the future.
 // Read more: https://mariadb.com/resources/blog/json-with-mariadb-10-2
 type MySQL struct {
-	db, readOnlyDB *sql.DB
-	queryTimeout   time.Duration
+	db           *sql.DB
+	replicas     *persistence.ReplicaSet[*sql.DB]
+	queryTimeout time.Duration
 }
 
 func (s *MySQL) Driver() persistence.Driver { return persistence.MySQL }
@@ -56,19 +55,43 @@ func (s *MySQL) SetDefaultSQLOptions(db *sql.DB) error {
 }
 
 func (s *MySQL) Get(ctx context.Context, key string) ([]byte, error) {
-	return (&mysqlQuery{s.readOnlyDB, s.queryTimeout}).Get(ctx, key)
+	var res []byte
+	err := s.replicas.Read(ctx, func(db *sql.DB) error {
+		var err error
+		res, err = (&mysqlQuery{db, s.queryTimeout}).Get(ctx, key)
+		return err
+	})
+	return res, err
 }
 
 func (s *MySQL) Insert(ctx context.Context, key string, value []byte) error {
-	return (&mysqlQuery{s.db, s.queryTimeout}).Insert(ctx, key, value)
+	return s.write(ctx, func(q *mysqlQuery) error { return q.Insert(ctx, key, value) })
 }
 
 func (s *MySQL) Put(ctx context.Context, key string, value []byte) error {
-	return (&mysqlQuery{s.db, s.queryTimeout}).Put(ctx, key, value)
+	return s.write(ctx, func(q *mysqlQuery) error { return q.Put(ctx, key, value) })
 }
 
 func (s *MySQL) Delete(ctx context.Context, key string) error {
-	return (&mysqlQuery{s.db, s.queryTimeout}).Delete(ctx, key)
+	return s.write(ctx, func(q *mysqlQuery) error { return q.Delete(ctx, key) })
+}
+
+// write runs the given write within a transaction, as the tags of the
+// written object are updated along with it.
+func (s *MySQL) write(ctx context.Context, fn func(q *mysqlQuery) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(&mysqlQuery{tx, s.queryTimeout}); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	s.replicas.Wrote(ctx)
+	return nil
 }
 
 func (s *MySQL) List(
@@ -76,7 +99,18 @@ func (s *MySQL) List(
 	prefix string,
 	opts *persistence.ListOpts,
 ) (persistence.ListResult, error) {
-	return (&mysqlQuery{s.readOnlyDB, s.queryTimeout}).List(ctx, prefix, opts)
+	var res persistence.ListResult
+	err := s.replicas.Read(ctx, func(db *sql.DB) error {
+		var err error
+		res, err = (&mysqlQuery{db, s.queryTimeout}).List(ctx, prefix, opts)
+		return err
+	})
+	return res, err
+}
+
+// WritePosition implements the persistence.ReplicaPositioner interface.
+func (s *MySQL) WritePosition(ctx context.Context) (string, error) {
+	return s.replicas.WritePosition(ctx)
 }
 
 func (s *MySQL) Tx(ctx context.Context) (persistence.Tx, error) {
@@ -87,6 +121,9 @@ func (s *MySQL) Tx(ctx context.Context) (persistence.Tx, error) {
 
 	return &mysqlTx{
 		tx: tx,
+		committed: func() {
+			s.replicas.Wrote(ctx)
+		},
 		query: mysqlQuery{
 			StdSqlCtx:    tx,
 			queryTimeout: s.queryTimeout,
@@ -95,6 +132,10 @@ func (s *MySQL) Tx(ctx context.Context) (persistence.Tx, error) {
 }
 
 func (s *MySQL) Close() error {
+	s.replicas.Close()
+	for _, db := range s.replicas.Replicas() {
+		_ = db.Close()
+	}
 	return s.db.Close()
 }
 
@@ -124,18 +165,41 @@ func New(opts Opts, queryTimeout time.Duration, logger *zap.Logger) (persistence
 	}
 
 	// By default, fallback to primary host for read operations.
-	readOnlyDB := db
-	if opts.ReadOnlyHostname != "" {
+	var replicas []persistence.Replica[*sql.DB]
+	for _, hostname := range opts.readOnlyHostnames() {
 		readOnlyOpts := opts
-		readOnlyOpts.Hostname = opts.ReadOnlyHostname
-		if readOnlyDB, err = NewSQLClient(readOnlyOpts, logger); err != nil {
-			return nil, fmt.Errorf("unable to set up read-only MySQL DB client: %w", err)
+		readOnlyOpts.Hostname = hostname
+		readOnlyDB, err := NewSQLClient(readOnlyOpts, logger)
+		if err != nil {
+			for _, replica := range replicas {
+				_ = replica.DB.Close()
+			}
+			_ = db.Close()
+			return nil, fmt.Errorf("unable to set up read-only MySQL DB client for '%s': %w", hostname, err)
+		}
+		replicas = append(replicas, persistence.Replica[*sql.DB]{Name: hostname, DB: readOnlyDB})
+	}
+
+	var dialect replicaDialect
+	if len(replicas) > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+		defer cancel()
+		if dialect, err = newReplicaDialect(ctx, db, logger); err != nil {
+			for _, replica := range replicas {
+				_ = replica.DB.Close()
+			}
+			_ = db.Close()
+			return nil, err
 		}
 	}
 
 	return &MySQL{
-		db:           db,
-		readOnlyDB:   readOnlyDB,
+		db: db,
+		replicas: persistence.NewReplicaSet[*sql.DB](db, replicas, dialect,
+			persistence.ReplicaSetOpts{
+				HealthCheckPeriod: opts.ReplicaHealthCheckPeriod,
+				Logger:            logger,
+			}),
 		queryTimeout: queryTimeout,
 	}, nil
 }
@@ -190,7 +254,7 @@ func (t *mysqlQuery) Insert(ctx context.Context, key string, value []byte) error
 		}
 		return err
 	}
-	return nil
+	return t.putTags(ctx, key, value)
 }
 
 func (t *mysqlQuery) Put(ctx context.Context, key string, value []byte) error {
@@ -222,7 +286,7 @@ func (t *mysqlQuery) Put(ctx context.Context, key string, value []byte) error {
 		return err
 	}
 
-	return nil
+	return t.putTags(ctx, key, value)
 }
 
 func (t *mysqlQuery) Delete(ctx context.Context, key string) error {
@@ -253,7 +317,42 @@ func (t *mysqlQuery) Delete(ctx context.Context, key string) error {
 		return persistence.ErrInvalidRowsAffected
 	}
 
-	return nil
+	return t.deleteTags(ctx, key)
+}
+
+// putTags replaces the indexed tags of the object stored at the given key.
+func (t *mysqlQuery) putTags(ctx context.Context, key string, value []byte) error {
+	if err := t.deleteTags(ctx, key); err != nil {
+		return err
+	}
+	tags := persistence.Tags(value)
+	if len(tags) == 0 {
+		return nil
+	}
+	query := sq.StatementBuilder.
+		Insert("store_tags").
+		Columns("`key`", "tag")
+	for _, tag := range tags {
+		query = query.Values(key, tag)
+	}
+	rawSQL, placeholders, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+	_, err = t.ExecContext(ctx, rawSQL, placeholders...)
+	return err
+}
+
+func (t *mysqlQuery) deleteTags(ctx context.Context, key string) error {
+	rawSQL, placeholders, err := sq.StatementBuilder.
+		Delete("store_tags").
+		Where("`key` = ?", key).
+		ToSql()
+	if err != nil {
+		return err
+	}
+	_, err = t.ExecContext(ctx, rawSQL, placeholders...)
+	return err
 }
 
 func (t *mysqlQuery) List(
@@ -264,21 +363,44 @@ func (t *mysqlQuery) List(
 	ctx, cancel := context.WithTimeout(ctx, t.queryTimeout)
 	defer cancel()
 
+	// When listing referenced objects, the keys are the ones of the index `i`,
+	// while the values are the ones of the objects `s` referenced by the index.
+	from, keyColumn := "store s", "s.`key`"
+	if opts.ReferencedPrefix != "" {
+		from, keyColumn = "store i", "i.`key`"
+	}
+	start, rest := persistence.ReferencedIDPosition(prefix)
+
 	// Replacing any wildcard operators with the proper wildcard operator for MySQL.
 	prefix = strings.ReplaceAll(prefix, persistence.WildcardOperator, "%")
 
 	query := sq.StatementBuilder.
-		Select("`key`", "value", "COUNT(*) OVER() AS full_count").
-		From("store").
-		Where("`key` LIKE ?", prefix+"%").
-		OrderBy("`key`").
+		Select(keyColumn, "s.value", "COUNT(*) OVER() AS full_count").
+		From(from).
+		Where(keyColumn+" LIKE ?", prefix+"%").
+		OrderBy(persistence.OrderBy(filterDialect{}, opts.Sort, keyColumn)...).
 		Limit(uint64(opts.Limit)).
 		Offset(uint64(opts.Offset))
 
-	// Parse out any provided, pre-validated CEL expression & add the proper clauses to the query.
-	var err error
-	if query, err = addFilterToQuery(query, opts.Filter); err != nil {
-		return persistence.ListResult{}, fmt.Errorf("unable to add filter CEL expression to query: %w", err)
+	if opts.ReferencedPrefix != "" {
+		query = query.Join(fmt.Sprintf(
+			"store s ON s.`key` = CONCAT(?, SUBSTRING(i.`key`, %d, CHAR_LENGTH(i.`key`) - %d))", start, rest,
+		), opts.ReferencedPrefix)
+	}
+	if opts.After != "" {
+		after, args := persistence.SortAfter(filterDialect{}, opts.Sort, opts.AfterValues, keyColumn, opts.After)
+		query = query.Where(after, args...)
+	}
+
+	// Push down the supported parts of any provided, pre-validated CEL expression.
+	// The remaining parts are evaluated once the rows are read, in which case the
+	// pagination of the query is done afterwards as well.
+	where, args, residual := persistence.TranslateFilter(filterDialect{}, opts.Filter)
+	if where != "" {
+		query = query.Where(where, args...)
+	}
+	if residual {
+		query = query.RemoveLimit().RemoveOffset()
 	}
 
 	rawSQL, placeholders, err := query.ToSql()
@@ -303,45 +425,53 @@ func (t *mysqlQuery) List(
 		res.KVList = append(res.KVList, kvr)
 	}
 
+	if residual {
+		return persistence.FilterKVList(res.KVList, opts)
+	}
 	return res, nil
 }
 
-func addFilterToQuery(query sq.SelectBuilder, expr *exprpb.Expr) (sq.SelectBuilder, error) {
-	// No-op when no expression is provided.
-	if expr == nil {
-		return query, nil
-	}
-
-	tags, exprFunction, err := persistence.GetTagsFromExpression(expr)
-	if err != nil {
-		return query, err
-	}
-
-	queryArgs, err := persistence.GetQueryArgsFromExprConstants(tags)
-	if err != nil {
-		return query, err
-	}
-
-	// No-op when there are no tags to filter against, to treat it as if there is no filter at all.
-	if len(queryArgs) == 0 {
-		return query, nil
+// filterDialect translates filters to MySQL SQL, using its JSON functions.
+//
+// NOTE: The `->` & `->>` operators shall never be used, as they are not supported in MariaDB.
+// Instead, `JSON_EXTRACT()` should be used in its place.
+type filterDialect struct{}
+
+func (filterDialect) Field(name string, kind persistence.FieldKind) string {
+	// Unquoted JSON values use the case-sensitive `utf8mb4_bin` collation.
+	field := fmt.Sprintf("JSON_UNQUOTE(JSON_EXTRACT(s.value, '$.object.%s'))", name)
+	switch kind {
+	case persistence.FieldKindInt:
+		return fmt.Sprintf("COALESCE(CAST(%s AS SIGNED), 0)", field)
+	case persistence.FieldKindUint:
+		return fmt.Sprintf("COALESCE(CAST(%s AS UNSIGNED), 0)", field)
+	case persistence.FieldKindDouble:
+		return fmt.Sprintf("COALESCE(CAST(%s AS DOUBLE), 0)", field)
+	case persistence.FieldKindBool:
+		return fmt.Sprintf("(COALESCE(%s, 'false') = 'true')", field)
+	default:
+		return fmt.Sprintf("COALESCE(%s, '')", field)
 	}
+}
 
-	tagsJSON, err := json.Marshal(queryArgs)
-	if err != nil {
-		return query, err
+func (filterDialect) ListContains(name string) string {
+	if name == persistence.TagsField {
+		return "s.`key` IN (SELECT t.`key` FROM store_tags t WHERE t.tag = ?)"
 	}
+	return fmt.Sprintf("JSON_CONTAINS(JSON_EXTRACT(s.value, '$.object.%s'), JSON_QUOTE(?))", name)
+}
 
-	// As the only supported field name to filter on are tags, we can simply hard-code the predicate.
-	//
-	// NOTE: The `->` & `->>` operators shall never be used, as they are not supported in MariaDB.
-	// Instead, `JSON_EXTRACT()` should be used in its place.
-	const tagsSelector = "JSON_EXTRACT(value, '$.object.tags')"
-	if exprFunction == operators.LogicalOr {
-		query = query.Where("JSON_OVERLAPS("+tagsSelector+", ?)", tagsJSON)
-	} else {
-		query = query.Where("JSON_CONTAINS("+tagsSelector+", ?, '$')", tagsJSON)
+func (filterDialect) Match(field string, function string, value string) (string, interface{}) {
+	value = likeEscaper.Replace(value)
+	switch function {
+	case overloads.StartsWith:
+		value += "%"
+	case overloads.EndsWith:
+		value = "%" + value
+	default:
+		value = "%" + value + "%"
 	}
-
-	return query, nil
+	return field + " LIKE ? ESCAPE '!'", value
 }
+
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
@@ -39,6 +39,13 @@ type Opts struct {
 	// Optional hostname for a read-only replica.
 	// Connection to this DB shares the same options as the primary (Opts.Hostname).
 	ReadOnlyHostname string
+	// Optional hostnames of additional read-only replicas, sharing the same
+	// options as ReadOnlyHostname. Replicas are only read from after a write
+	// when the primary runs with `gtid_mode=ON`.
+	ReadOnlyHostnames []string
+	// ReplicaHealthCheckPeriod is the duration between health checks of the
+	// read-only replicas. Defaults to persistence.DefaultReplicaHealthCheckPeriod.
+	ReplicaHealthCheckPeriod time.Duration
 
 	// TLS options.
 	EnableTLS                bool
@@ -54,6 +61,10 @@ type Opts struct {
 	// When not provided, defaults to persistence.DefaultSQLOpenFunc.
 	SQLOpen persistence.SQLOpenFunc
 
+	// MultiStatements allows multiple statements per query. It is only meant
+	// for running migrations, whose statements are not built from user input.
+	MultiStatements bool
+
 	// Parameters passed to the MySQL DB driver.
 	//
 	// This is here to allow the enablement of useful parameters, like `checkConnLiveness=true`.
@@ -76,6 +87,15 @@ func (o *Opts) Validate() error {
 	return nil
 }
 
+// readOnlyHostnames returns the hostnames of all read-only replicas.
+func (o *Opts) readOnlyHostnames() []string {
+	var res []string
+	if o.ReadOnlyHostname != "" {
+		res = append(res, o.ReadOnlyHostname)
+	}
+	return append(res, o.ReadOnlyHostnames...)
+}
+
 // DSN formats the given Opts into a DSN string which can be passed to the driver.
 func (o *Opts) DSN() (string, error) {
 	if o.Params == nil {
@@ -103,12 +123,13 @@ func (o *Opts) DSN() (string, error) {
 		Timeout:              persistence.DefaultDialTimeout,
 
 		// Dynamic settings.
-		Addr:   o.Hostname,
-		DBName: o.DBName,
-		Loc:    o.Location,
-		Params: o.Params,
-		User:   o.User,
-		Passwd: o.Password,
+		Addr:            o.Hostname,
+		DBName:          o.DBName,
+		Loc:             o.Location,
+		MultiStatements: o.MultiStatements,
+		Params:          o.Params,
+		User:            o.User,
+		Passwd:          o.Password,
 	}
 
 	if o.EnableTLS {
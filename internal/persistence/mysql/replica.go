@@ -0,0 +1,138 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// positionQuery returns the set of GTIDs executed by a database, which is
+// empty unless GTID based replication is enabled.
+const positionQuery = "SELECT @@GLOBAL.gtid_executed"
+
+// gtidModeQuery returns whether every transaction of a database is assigned
+// a GTID, which is only the case when it returns `ON`.
+const gtidModeQuery = "SELECT @@GLOBAL.gtid_mode"
+
+// unpositioned is the write position of primaries not assigning GTIDs to
+// every transaction. No position reaches it, so that the reads following a
+// write are served by the primary.
+const unpositioned = "unpositioned"
+
+// replicaDialect implements persistence.ReplicaDialect, where positions are
+// GTID sets, e.g.: `3E11FA47-71CA-11E1-9E33-C80AA9429562:1-5:11-18`.
+//
+// Read more: https://dev.mysql.com/doc/refman/8.0/en/replication-gtids-concepts.html
+type replicaDialect struct {
+	// unpositioned is true when the primary does not assign GTIDs to every
+	// transaction, in which case the replay position of replicas cannot be
+	// compared against the writes of the primary.
+	unpositioned bool
+}
+
+// newReplicaDialect returns the replica dialect of the primary database. When
+// GTIDs are not enabled on the primary, a warning is logged and the reads of
+// sessions that wrote are served by the primary.
+func newReplicaDialect(ctx context.Context, primary *sql.DB, logger *zap.Logger) (replicaDialect, error) {
+	var mode string
+	if err := primary.QueryRowContext(ctx, gtidModeQuery).Scan(&mode); err != nil {
+		return replicaDialect{}, fmt.Errorf("unable to read the GTID mode of the primary database: %w", err)
+	}
+	if !strings.EqualFold(mode, "ON") {
+		logger.Warn("GTIDs are not enabled on the primary MySQL database, reads "+
+			"following writes are served by the primary instead of the read replicas",
+			zap.String("gtid_mode", mode))
+		return replicaDialect{unpositioned: true}, nil
+	}
+	return replicaDialect{}, nil
+}
+
+func (d replicaDialect) WritePosition(ctx context.Context, primary *sql.DB) (string, error) {
+	if d.unpositioned {
+		return unpositioned, nil
+	}
+	return queryPosition(ctx, primary)
+}
+
+func (replicaDialect) ReplayPosition(ctx context.Context, replica *sql.DB) (string, error) {
+	return queryPosition(ctx, replica)
+}
+
+func queryPosition(ctx context.Context, db *sql.DB) (string, error) {
+	var position string
+	err := db.QueryRowContext(ctx, positionQuery).Scan(&position)
+	return position, err
+}
+
+// PositionReached returns whether the target GTID set is a subset of the
+// position GTID set. Targets that are not GTID sets are never reached.
+func (replicaDialect) PositionReached(position, target string) bool {
+	if target == "" {
+		return true
+	}
+	targetSet, ok := parseGTIDSet(target)
+	if !ok {
+		return false
+	}
+	set, ok := parseGTIDSet(position)
+	if !ok {
+		return false
+	}
+	for source, targetIntervals := range targetSet {
+		for _, target := range targetIntervals {
+			if !set.contains(source, target) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// gtidSet holds the intervals of transaction IDs of a GTID set by source,
+// where sources are server UUIDs, optionally followed by a tag.
+type gtidSet map[string][][2]uint64
+
+// contains returns whether the interval is within one of the intervals of the
+// source. MySQL merges contiguous intervals, so that an interval is never
+// spread across multiple ones.
+func (s gtidSet) contains(source string, interval [2]uint64) bool {
+	for _, i := range s[source] {
+		if i[0] <= interval[0] && interval[1] <= i[1] {
+			return true
+		}
+	}
+	return false
+}
+
+func parseGTIDSet(s string) (gtidSet, bool) {
+	res := gtidSet{}
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		fields := strings.Split(part, ":")
+		source := strings.ToLower(fields[0])
+		for _, field := range fields[1:] {
+			start, end, isRange := strings.Cut(field, "-")
+			first, err := strconv.ParseUint(start, 10, 64)
+			if err != nil {
+				// Tagged GTIDs name the tag before its intervals.
+				source = strings.ToLower(fields[0]) + ":" + field
+				continue
+			}
+			last := first
+			if isRange {
+				if last, err = strconv.ParseUint(end, 10, 64); err != nil {
+					return nil, false
+				}
+			}
+			res[source] = append(res[source], [2]uint64{first, last})
+		}
+	}
+	return res, len(res) > 0
+}
@@ -0,0 +1,45 @@
+package mysql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPositionReached(t *testing.T) {
+	const (
+		a = "3e11fa47-71ca-11e1-9e33-c80aa9429562"
+		b = "4f22fb58-82db-22f2-af44-d91bb0530673"
+	)
+	reached := replicaDialect{}.PositionReached
+	for _, tc := range []struct {
+		position, target string
+		reached          bool
+	}{
+		{position: a + ":1-5", target: a + ":1-5", reached: true},
+		{position: a + ":1-5:11-18", target: a + ":3-4:12", reached: true},
+		{position: a + ":1-5", target: a + ":1-6", reached: false},
+		{position: a + ":1-5", target: a + ":1-5,\n" + b + ":1", reached: false},
+		{position: a + ":1-5,\n" + b + ":1-2", target: b + ":2", reached: true},
+		{position: a + ":tag:1-3", target: a + ":tag:2", reached: true},
+		{position: a + ":1-3", target: a + ":tag:2", reached: false},
+		{position: "", target: a + ":1", reached: false},
+		// Targets without GTIDs are always reached.
+		{position: "", target: "", reached: true},
+		// Targets that are not GTID sets are never reached.
+		{position: a + ":1-5", target: unpositioned, reached: false},
+		{position: "", target: unpositioned, reached: false},
+	} {
+		require.Equal(t, tc.reached, reached(tc.position, tc.target),
+			"position %q, target %q", tc.position, tc.target)
+	}
+}
+
+func TestUnpositionedWritePosition(t *testing.T) {
+	dialect := replicaDialect{unpositioned: true}
+	position, err := dialect.WritePosition(context.Background(), nil)
+	require.NoError(t, err)
+	require.NotEmpty(t, position)
+	require.False(t, dialect.PositionReached("3e11fa47-71ca-11e1-9e33-c80aa9429562:1-5", position))
+}
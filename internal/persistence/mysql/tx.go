@@ -10,9 +10,17 @@ import (
 type mysqlTx struct {
 	tx    *sql.Tx
 	query mysqlQuery
+	// committed is called once the transaction is committed.
+	committed func()
 }
 
-func (t *mysqlTx) Commit() error { return t.tx.Commit() }
+func (t *mysqlTx) Commit() error {
+	if err := t.tx.Commit(); err != nil {
+		return err
+	}
+	t.committed()
+	return nil
+}
 
 func (t *mysqlTx) Rollback() error { return t.tx.Rollback() }
 
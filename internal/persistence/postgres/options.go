@@ -3,6 +3,7 @@ package postgres
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/kong/koko/internal/persistence"
 	"github.com/samber/lo"
@@ -34,6 +35,12 @@ type Opts struct {
 	// Optional hostname for a read-only replica.
 	// Connection to this DB shares the same options as the primary (Opts.Hostname).
 	ReadOnlyHostname string
+	// Optional hostnames of additional read-only replicas, sharing the same
+	// options as ReadOnlyHostname.
+	ReadOnlyHostnames []string
+	// ReplicaHealthCheckPeriod is the duration between health checks of the
+	// read-only replicas. Defaults to persistence.DefaultReplicaHealthCheckPeriod.
+	ReplicaHealthCheckPeriod time.Duration
 
 	// TLS options.
 	EnableTLS      bool
@@ -69,6 +76,15 @@ func (opts *Opts) Validate() error {
 	return nil
 }
 
+// readOnlyHostnames returns the hostnames of all read-only replicas.
+func (opts *Opts) readOnlyHostnames() []string {
+	var res []string
+	if opts.ReadOnlyHostname != "" {
+		res = append(res, opts.ReadOnlyHostname)
+	}
+	return append(res, opts.ReadOnlyHostnames...)
+}
+
 func (opts *Opts) DSN(logger *zap.Logger) (string, error) {
 	var dsn string
 	sslMode := defaultSSLMode
@@ -8,13 +8,12 @@ import (
 	"time"
 
 	sq "github.com/Masterminds/squirrel"
-	"github.com/google/cel-go/common/operators"
+	"github.com/google/cel-go/common/overloads"
 	"github.com/jackc/pgconn"
 	"github.com/jackc/pgx/v4"
 	_ "github.com/jackc/pgx/v4/stdlib"
 	"github.com/kong/koko/internal/persistence"
 	"go.uber.org/zap"
-	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
 )
 
 const (
@@ -22,6 +21,10 @@ const (
 	insertQuery = `insert into store(key,value) values($1,$2)`
 	putQuery    = `insert into store(key,value) values($1,$2) on conflict (key) do update set value=$2;`
 	deleteQuery = `delete from store where key=$1`
+	notifyQuery = `select pg_notify($1, $2)`
+
+	insertTagsQuery = `insert into store_tags(key,tag) select $1, unnest($2::text[])`
+	deleteTagsQuery = `delete from store_tags where key=$1`
 
 	DefaultPort = 5432
 	DefaultPool = "pgx"
@@ -30,9 +33,9 @@ const (
 )
 
 type Postgres struct {
-	dbPool         Pool
-	readOnlyDBPool Pool
-	queryTimeout   time.Duration
+	dbPool       Pool
+	replicas     *persistence.ReplicaSet[Pool]
+	queryTimeout time.Duration
 }
 
 // NewSQLClient creates a standard database/sql dbPool client, used for migrations.
@@ -64,20 +67,29 @@ func New(opts Opts, queryTimeout time.Duration, logger *zap.Logger) (persistence
 		return nil, fmt.Errorf("unable to set up DB client: %w", err)
 	}
 	// by default, fallback to primary host for read operations
-	readOnlyDBPool := dbPool
-	if opts.ReadOnlyHostname != "" {
+	var replicas []persistence.Replica[Pool]
+	for _, hostname := range opts.readOnlyHostnames() {
 		readOnlyOpts := opts
-		readOnlyOpts.Hostname = opts.ReadOnlyHostname
+		readOnlyOpts.Hostname = hostname
 		readOnlyOpts.Pool.ReadOnly = true
-		readOnlyDBPool, err = newPostgresPool(readOnlyOpts, logger)
+		readOnlyDBPool, err := newPostgresPool(readOnlyOpts, logger)
 		if err != nil {
-			return nil, fmt.Errorf("unable to set up read-only DB client: %w", err)
+			for _, replica := range replicas {
+				replica.DB.Close()
+			}
+			dbPool.Close()
+			return nil, fmt.Errorf("unable to set up read-only DB client for '%s': %w", hostname, err)
 		}
+		replicas = append(replicas, persistence.Replica[Pool]{Name: hostname, DB: readOnlyDBPool})
 	}
 	res := &Postgres{
-		dbPool:         dbPool,
-		readOnlyDBPool: readOnlyDBPool,
-		queryTimeout:   queryTimeout,
+		dbPool: dbPool,
+		replicas: persistence.NewReplicaSet[Pool](dbPool, replicas, replicaDialect{},
+			persistence.ReplicaSetOpts{
+				HealthCheckPeriod: opts.ReplicaHealthCheckPeriod,
+				Logger:            logger,
+			}),
+		queryTimeout: queryTimeout,
 	}
 	return res, nil
 }
@@ -95,30 +107,63 @@ func (s *Postgres) SetDefaultSQLOptions(db *sql.DB) error {
 }
 
 func (s *Postgres) Get(ctx context.Context, key string) ([]byte, error) {
-	q := postgresQuery{query: s.readOnlyDBPool, queryTimeout: s.queryTimeout}
-	return q.Get(ctx, key)
+	var res []byte
+	err := s.replicas.Read(ctx, func(pool Pool) error {
+		var err error
+		q := postgresQuery{query: pool, queryTimeout: s.queryTimeout}
+		res, err = q.Get(ctx, key)
+		return err
+	})
+	return res, err
 }
 
 func (s *Postgres) Insert(ctx context.Context, key string, value []byte) error {
-	q := postgresQuery{query: s.dbPool, queryTimeout: s.queryTimeout}
-	return q.Insert(ctx, key, value)
+	return s.write(ctx, func(q *postgresQuery) error {
+		return q.Insert(ctx, key, value)
+	})
 }
 
 func (s *Postgres) Put(ctx context.Context, key string, value []byte) error {
-	q := postgresQuery{query: s.dbPool, queryTimeout: s.queryTimeout}
-	return q.Put(ctx, key, value)
+	return s.write(ctx, func(q *postgresQuery) error {
+		return q.Put(ctx, key, value)
+	})
 }
 
 func (s *Postgres) Delete(ctx context.Context, key string) error {
-	q := postgresQuery{query: s.dbPool, queryTimeout: s.queryTimeout}
-	return q.Delete(ctx, key)
+	return s.write(ctx, func(q *postgresQuery) error {
+		return q.Delete(ctx, key)
+	})
+}
+
+// write runs the given write within a transaction, as the tags of the
+// written object are updated along with it.
+func (s *Postgres) write(ctx context.Context, fn func(q *postgresQuery) error) error {
+	err := s.dbPool.BeginFunc(ctx, func(tx pgx.Tx) error {
+		return fn(&postgresQuery{query: tx, queryTimeout: s.queryTimeout})
+	})
+	if err != nil {
+		return err
+	}
+	s.replicas.Wrote(ctx)
+	return nil
 }
 
 func (s *Postgres) List(ctx context.Context, prefix string, opts *persistence.ListOpts) (persistence.ListResult,
 	error,
 ) {
-	q := postgresQuery{query: s.readOnlyDBPool, queryTimeout: s.queryTimeout}
-	return q.List(ctx, prefix, opts)
+	var res persistence.ListResult
+	err := s.replicas.Read(ctx, func(pool Pool) error {
+		var err error
+		q := postgresQuery{query: pool, queryTimeout: s.queryTimeout}
+		res, err = q.List(ctx, prefix, opts)
+		return err
+	})
+	return res, err
+}
+
+// WritePosition implements the persistence.ReplicaPositioner interface.
+func (s *Postgres) WritePosition(ctx context.Context) (string, error) {
+	return s.replicas.WritePosition(ctx)
 }
 
 func (s *Postgres) Tx(ctx context.Context) (persistence.Tx, error) {
@@ -129,6 +174,9 @@ func (s *Postgres) Tx(ctx context.Context) (persistence.Tx, error) {
 	return &postgresTx{
 		ctx: ctx,
 		tx:  tx,
+		committed: func() {
+			s.replicas.Wrote(ctx)
+		},
 		query: postgresQuery{
 			query:        tx,
 			queryTimeout: s.queryTimeout,
@@ -136,7 +184,45 @@ func (s *Postgres) Tx(ctx context.Context) (persistence.Tx, error) {
 	}, nil
 }
 
+// Listen implements the persistence.EventSource interface.
+// A connection is taken out of the pool for the lifetime of the subscription.
+func (s *Postgres) Listen(ctx context.Context, channel string) (<-chan string, error) {
+	pooledConn, err := s.dbPool.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// the connection is not returned to the pool as it stays subscribed
+	conn := pooledConn.Hijack()
+	_, err = conn.Exec(ctx, "listen "+pgx.Identifier{channel}.Sanitize())
+	if err != nil {
+		_ = conn.Close(context.Background())
+		return nil, err
+	}
+
+	res := make(chan string)
+	go func() {
+		defer close(res)
+		defer conn.Close(context.Background())
+		for {
+			notification, err := conn.WaitForNotification(ctx)
+			if err != nil {
+				return
+			}
+			select {
+			case res <- notification.Payload:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return res, nil
+}
+
 func (s *Postgres) Close() error {
+	s.replicas.Close()
+	for _, pool := range s.replicas.Replicas() {
+		pool.Close()
+	}
 	s.dbPool.Close()
 	return nil
 }
@@ -186,7 +272,7 @@ func (t *postgresQuery) Insert(ctx context.Context, key string, value []byte) er
 	if rowCount != 1 {
 		return persistence.ErrInvalidRowsAffected
 	}
-	return nil
+	return t.putTags(ctx, key, value)
 }
 
 func (t *postgresQuery) Put(ctx context.Context, key string, value []byte) error {
@@ -200,7 +286,7 @@ func (t *postgresQuery) Put(ctx context.Context, key string, value []byte) error
 	if rowCount != 1 {
 		return persistence.ErrInvalidRowsAffected
 	}
-	return nil
+	return t.putTags(ctx, key, value)
 }
 
 func (t *postgresQuery) Delete(ctx context.Context, key string) error {
@@ -217,7 +303,21 @@ func (t *postgresQuery) Delete(ctx context.Context, key string) error {
 	if rowCount != 1 {
 		return persistence.ErrInvalidRowsAffected
 	}
-	return nil
+	_, err = t.query.Exec(ctx, deleteTagsQuery, key)
+	return err
+}
+
+// putTags replaces the indexed tags of the object stored at the given key.
+func (t *postgresQuery) putTags(ctx context.Context, key string, value []byte) error {
+	if _, err := t.query.Exec(ctx, deleteTagsQuery, key); err != nil {
+		return err
+	}
+	tags := persistence.Tags(value)
+	if len(tags) == 0 {
+		return nil
+	}
+	_, err := t.query.Exec(ctx, insertTagsQuery, key, tags)
+	return err
 }
 
 func (t *postgresQuery) List(
@@ -229,22 +329,45 @@ func (t *postgresQuery) List(
 	defer cancel()
 	kvlist := make([]persistence.KVResult, 0, opts.Limit)
 
+	// When listing referenced objects, the keys are the ones of the index `i`,
+	// while the values are the ones of the objects `s` referenced by the index.
+	from, keyColumn := "store s", "s.key"
+	if opts.ReferencedPrefix != "" {
+		from, keyColumn = "store i", "i.key"
+	}
+	start, rest := persistence.ReferencedIDPosition(prefix)
+
 	// Replacing any wildcard operators with the proper wildcard operator for Postgres.
 	prefix = strings.ReplaceAll(prefix, persistence.WildcardOperator, "%")
 
 	query := sq.StatementBuilder.
 		PlaceholderFormat(sq.Dollar).
-		Select("key", "value", "COUNT(*) OVER() AS full_count").
-		From("store").
-		Where("key LIKE ? || '%%'", prefix).
-		OrderBy("key").
+		Select(keyColumn, "s.value", "COUNT(*) OVER() AS full_count").
+		From(from).
+		Where(keyColumn+" LIKE ? || '%%'", prefix).
+		OrderBy(persistence.OrderBy(filterDialect{}, opts.Sort, keyColumn)...).
 		Limit(uint64(opts.Limit)).
 		Offset(uint64(opts.Offset))
 
-	// Parse out any provided, pre-validated CEL expression & add the proper clauses to the query.
-	var err error
-	if query, err = addFilterToQuery(query, opts.Filter); err != nil {
-		return persistence.ListResult{}, fmt.Errorf("unable to add filter CEL expression to query: %w", err)
+	if opts.ReferencedPrefix != "" {
+		query = query.Join(fmt.Sprintf(
+			"store s ON s.key = ? || substr(i.key, %d, length(i.key) - %d)", start, rest,
+		), opts.ReferencedPrefix)
+	}
+	if opts.After != "" {
+		after, args := persistence.SortAfter(filterDialect{}, opts.Sort, opts.AfterValues, keyColumn, opts.After)
+		query = query.Where(after, args...)
+	}
+
+	// Push down the supported parts of any provided, pre-validated CEL expression.
+	// The remaining parts are evaluated once the rows are read, in which case the
+	// pagination of the query is done afterwards as well.
+	where, args, residual := persistence.TranslateFilter(filterDialect{}, opts.Filter)
+	if where != "" {
+		query = query.Where(where, args...)
+	}
+	if residual {
+		query = query.RemoveLimit().RemoveOffset()
 	}
 
 	sql, placeholders, err := query.ToSql()
@@ -272,40 +395,49 @@ func (t *postgresQuery) List(
 		kvlist = append(kvlist, kvr)
 	}
 	res.KVList = kvlist
+	if residual {
+		return persistence.FilterKVList(kvlist, opts)
+	}
 	return res, nil
 }
 
-func addFilterToQuery(query sq.SelectBuilder, expr *exprpb.Expr) (sq.SelectBuilder, error) {
-	// No-op when no expression is provided.
-	if expr == nil {
-		return query, nil
-	}
-
-	tags, exprFunction, err := persistence.GetTagsFromExpression(expr)
-	if err != nil {
-		return query, err
-	}
-
-	queryArgs, err := persistence.GetQueryArgsFromExprConstants(tags)
-	if err != nil {
-		return query, err
+// filterDialect translates filters to Postgres SQL, using its JSON operators.
+// Read more: https://www.postgresql.org/docs/current/functions-json.html
+type filterDialect struct{}
+
+func (filterDialect) Field(name string, kind persistence.FieldKind) string {
+	field := fmt.Sprintf("(s.value #>> '{object,%s}')", name)
+	switch kind {
+	case persistence.FieldKindInt, persistence.FieldKindUint:
+		return fmt.Sprintf("COALESCE(%s::bigint, 0)", field)
+	case persistence.FieldKindDouble:
+		return fmt.Sprintf("COALESCE(%s::double precision, 0)", field)
+	case persistence.FieldKindBool:
+		return fmt.Sprintf("COALESCE(%s::boolean, false)", field)
+	default:
+		return fmt.Sprintf("COALESCE(%s, '')", field)
 	}
+}
 
-	// No-op when there are no tags to filter against, to treat it as if there is no filter at all.
-	if len(queryArgs) == 0 {
-		return query, nil
+func (filterDialect) ListContains(name string) string {
+	if name == persistence.TagsField {
+		return "s.key IN (SELECT t.key FROM store_tags t WHERE t.tag = ?)"
 	}
-
 	// The double question mark is how the SQL builder handles escaping a literal question mark.
-	operator := "??&"
-	if exprFunction == operators.LogicalOr {
-		operator = "??|"
-	}
-	placeholders := sq.Placeholders(len(queryArgs))
+	return fmt.Sprintf("s.value->'object'->'%s' ?? ?", name)
+}
 
-	// As the only supported field name to filter on are tags, we can simply hard-code the predicate.
-	return query.Where(
-		fmt.Sprintf("value->'object'->'tags' %s array[%s]", operator, placeholders),
-		queryArgs...,
-	), nil
+func (filterDialect) Match(field string, function string, value string) (string, interface{}) {
+	value = likeEscaper.Replace(value)
+	switch function {
+	case overloads.StartsWith:
+		value += "%"
+	case overloads.EndsWith:
+		value = "%" + value
+	default:
+		value = "%" + value + "%"
+	}
+	return field + " LIKE ? ESCAPE '!'", value
 }
+
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
@@ -147,3 +147,12 @@ func TestDSNParams(t *testing.T) {
 		require.Contains(t, dsn, "sslkey=/paths\\'certs/")
 	})
 }
+
+func TestPositionReached(t *testing.T) {
+	reached := replicaDialect{}.PositionReached
+	require.True(t, reached("16/B374D848", "16/B374D848"))
+	require.True(t, reached("17/0", "16/B374D848"))
+	require.False(t, reached("16/B374D847", "16/B374D848"))
+	require.False(t, reached("", "16/B374D848"))
+	require.True(t, reached("", ""))
+}
@@ -0,0 +1,56 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+)
+
+const (
+	writePositionQuery = `SELECT pg_current_wal_lsn()::text`
+	// The WAL location of a primary is returned when it is not in recovery,
+	// e.g.: once a replica is promoted.
+	replayPositionQuery = `SELECT CASE WHEN pg_is_in_recovery()
+		THEN pg_last_wal_replay_lsn() ELSE pg_current_wal_lsn() END::text`
+)
+
+// replicaDialect implements persistence.ReplicaDialect, where positions are
+// WAL locations, e.g.: `16/B374D848`.
+type replicaDialect struct{}
+
+func (replicaDialect) WritePosition(ctx context.Context, primary Pool) (string, error) {
+	var position string
+	err := primary.QueryRow(ctx, writePositionQuery).Scan(&position)
+	return position, err
+}
+
+func (replicaDialect) ReplayPosition(ctx context.Context, replica Pool) (string, error) {
+	var position *string
+	if err := replica.QueryRow(ctx, replayPositionQuery).Scan(&position); err != nil {
+		return "", err
+	}
+	if position == nil {
+		// The replica has not replayed any WAL yet, e.g.: when streaming
+		// replication is not set up.
+		return "", nil
+	}
+	return *position, nil
+}
+
+func (replicaDialect) PositionReached(position, target string) bool {
+	targetLSN, ok := parseLSN(target)
+	if !ok {
+		return true
+	}
+	lsn, ok := parseLSN(position)
+	return ok && lsn >= targetLSN
+}
+
+// parseLSN parses the text representation of a WAL location, which is made
+// of two hexadecimal numbers, of up to 32 bits each, separated by a slash.
+func parseLSN(s string) (uint64, bool) {
+	var high, low uint32
+	if _, err := fmt.Sscanf(s, "%X/%X", &high, &low); err != nil {
+		return 0, false
+	}
+	return uint64(high)<<32 | uint64(low), true
+}
@@ -11,10 +11,16 @@ type postgresTx struct {
 	ctx   context.Context
 	tx    pgx.Tx
 	query postgresQuery
+	// committed is called once the transaction is committed.
+	committed func()
 }
 
 func (t *postgresTx) Commit() error {
-	return t.tx.Commit(t.ctx)
+	if err := t.tx.Commit(t.ctx); err != nil {
+		return err
+	}
+	t.committed()
+	return nil
 }
 
 func (t *postgresTx) Rollback() error {
@@ -44,3 +50,11 @@ func (t *postgresTx) List(
 ) (persistence.ListResult, error) {
 	return t.query.List(ctx, prefix, opts)
 }
+
+// Notify implements the persistence.Notifier interface.
+func (t *postgresTx) Notify(ctx context.Context, channel string, payload string) error {
+	ctx, cancel := context.WithTimeout(ctx, t.query.queryTimeout)
+	defer cancel()
+	_, err := t.tx.Exec(ctx, notifyQuery, channel, payload)
+	return err
+}
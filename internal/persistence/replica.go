@@ -0,0 +1,328 @@
+package persistence
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// DefaultReplicaHealthCheckPeriod is the default duration between health
+// checks of read replicas.
+const DefaultReplicaHealthCheckPeriod = 5 * time.Second
+
+type primaryReadsKey struct{}
+
+// WithPrimaryReads returns a copy of ctx in which reads are served by the
+// primary database instead of its read replicas, e.g.: for reads that must
+// observe every committed write.
+func WithPrimaryReads(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryReadsKey{}, true)
+}
+
+// PrimaryReadsFromContext returns true if reads are served by the primary
+// database for ctx, see WithPrimaryReads.
+func PrimaryReadsFromContext(ctx context.Context) bool {
+	primary, _ := ctx.Value(primaryReadsKey{}).(bool)
+	return primary
+}
+
+// Session tracks the position of the last write of a client, so that its
+// reads are not served by read replicas that have not replayed the write yet.
+// A session spans multiple requests of the client by means of its token.
+type Session struct {
+	lock     sync.Mutex
+	position string
+}
+
+// NewSession returns a session resumed from a token returned by
+// Session.Token, or a new session when the token is empty.
+func NewSession(token string) (*Session, error) {
+	position, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, errors.New("invalid session token")
+	}
+	return &Session{position: string(position)}, nil
+}
+
+// Token returns the token resuming the session, or an empty string if no
+// write position has been recorded.
+func (s *Session) Token() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(s.Position()))
+}
+
+// Position returns the write position reads of the session must observe.
+func (s *Session) Position() string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.position
+}
+
+// advance records a write position unless an older one is given.
+func (s *Session) advance(position string, reached func(position, target string) bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if position != "" && (s.position == "" || !reached(s.position, position)) {
+		s.position = position
+	}
+}
+
+// NewSessionAt returns a session whose reads observe the writes up to
+// position, e.g.: as returned by ReplicaPositioner.WritePosition.
+func NewSessionAt(position string) *Session {
+	return &Session{position: position}
+}
+
+type sessionKey struct{}
+
+// WithSession returns a copy of ctx in which the reads and writes of
+// persisters are tracked by the given session.
+func WithSession(ctx context.Context, session *Session) context.Context {
+	return context.WithValue(ctx, sessionKey{}, session)
+}
+
+// SessionFromContext returns the session of ctx, if any.
+func SessionFromContext(ctx context.Context) *Session {
+	session, _ := ctx.Value(sessionKey{}).(*Session)
+	return session
+}
+
+// ReplicaPositioner is implemented by persisters whose reads may be served
+// by read replicas.
+type ReplicaPositioner interface {
+	// WritePosition returns the position of the last write of the primary
+	// database, or an empty string if reads are all served by the primary.
+	WritePosition(ctx context.Context) (string, error)
+}
+
+// ReplicaDialect implements the database specific parts of a ReplicaSet.
+// Positions are opaque to the ReplicaSet, e.g.: a Postgres WAL location.
+type ReplicaDialect[DB any] interface {
+	// WritePosition returns the position of the last write of the primary.
+	WritePosition(ctx context.Context, primary DB) (string, error)
+	// ReplayPosition returns the position up to which a replica replayed
+	// the writes of the primary. It is used to check the health of replicas.
+	ReplayPosition(ctx context.Context, replica DB) (string, error)
+	// PositionReached returns whether position is at or past target.
+	PositionReached(position, target string) bool
+}
+
+// Replica is a read-only database replicating the primary database.
+type Replica[DB any] struct {
+	// Name identifies the replica in logs, e.g.: its hostname.
+	Name string
+	DB   DB
+}
+
+// ReplicaSetOpts defines the options of a ReplicaSet.
+type ReplicaSetOpts struct {
+	// HealthCheckPeriod is the duration between health checks of the replicas.
+	// Defaults to DefaultReplicaHealthCheckPeriod.
+	HealthCheckPeriod time.Duration
+	Logger            *zap.Logger
+}
+
+// ReplicaSet routes the reads of a persister across read replicas, in a
+// round-robin fashion. Reads are served by the primary database when:
+// - no replica is healthy,
+// - the context was returned by WithPrimaryReads,
+// - no healthy replica has replayed the last write of the session of the
+// context, if any.
+//
+// Replicas failing their periodic health check are not read from until they
+// pass it again. Reads failing on a replica that is down are retried on the
+// primary.
+type ReplicaSet[DB any] struct {
+	primary  DB
+	replicas []*replica[DB]
+	dialect  ReplicaDialect[DB]
+	logger   *zap.Logger
+	next     uint32
+
+	stop func()
+	done chan struct{}
+}
+
+type replica[DB any] struct {
+	Replica[DB]
+	lock     sync.RWMutex
+	healthy  bool
+	position string
+}
+
+// NewReplicaSet returns a ReplicaSet, which checks the health of the replicas
+// until it is closed.
+func NewReplicaSet[DB any](primary DB, replicas []Replica[DB], dialect ReplicaDialect[DB],
+	opts ReplicaSetOpts,
+) *ReplicaSet[DB] {
+	if opts.HealthCheckPeriod == 0 {
+		opts.HealthCheckPeriod = DefaultReplicaHealthCheckPeriod
+	}
+	if opts.Logger == nil {
+		opts.Logger = zap.NewNop()
+	}
+	res := &ReplicaSet[DB]{
+		primary: primary,
+		dialect: dialect,
+		logger:  opts.Logger,
+		done:    make(chan struct{}),
+	}
+	for _, r := range replicas {
+		// Replicas are assumed to be healthy until checked.
+		res.replicas = append(res.replicas, &replica[DB]{Replica: r, healthy: true})
+	}
+	if len(res.replicas) == 0 {
+		close(res.done)
+		res.stop = func() {}
+		return res
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	res.stop = cancel
+	go func() {
+		defer close(res.done)
+		ticker := time.NewTicker(opts.HealthCheckPeriod)
+		defer ticker.Stop()
+		for {
+			res.checkHealth(ctx, opts.HealthCheckPeriod)
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return res
+}
+
+// Primary returns the primary database.
+func (s *ReplicaSet[DB]) Primary() DB {
+	return s.primary
+}
+
+// Replicas returns the read replicas.
+func (s *ReplicaSet[DB]) Replicas() []DB {
+	res := make([]DB, 0, len(s.replicas))
+	for _, r := range s.replicas {
+		res = append(res, r.DB)
+	}
+	return res
+}
+
+// Read runs fn against a healthy replica, or against the primary. See
+// ReplicaSet for how the database is selected.
+func (s *ReplicaSet[DB]) Read(ctx context.Context, fn func(db DB) error) error {
+	if len(s.replicas) == 0 || PrimaryReadsFromContext(ctx) {
+		return fn(s.primary)
+	}
+	var target string
+	if session := SessionFromContext(ctx); session != nil {
+		target = session.Position()
+	}
+	next := int(atomic.AddUint32(&s.next, 1))
+	for i := range s.replicas {
+		r := s.replicas[(next+i)%len(s.replicas)]
+		if !s.available(ctx, r, target) {
+			continue
+		}
+		err := fn(r.DB)
+		if err == nil || ctx.Err() != nil || errors.As(err, &ErrNotFound{}) {
+			return err
+		}
+		// Errors may be caused by the read itself, in which case it fails
+		// on the primary as well. The replica is only left out if it is down.
+		if _, checkErr := s.dialect.ReplayPosition(ctx, r.DB); checkErr == nil {
+			return err
+		}
+		s.setHealth(r, false, "", err)
+	}
+	return fn(s.primary)
+}
+
+// Wrote records the position of the last write of the primary in the
+// session of the context, if any. It must be called once writes are
+// committed.
+func (s *ReplicaSet[DB]) Wrote(ctx context.Context) {
+	session := SessionFromContext(ctx)
+	if session == nil || len(s.replicas) == 0 {
+		return
+	}
+	position, err := s.dialect.WritePosition(ctx, s.primary)
+	if err != nil {
+		// The write succeeded, only the reads of the session may be stale.
+		s.logger.Warn("failed to read the write position of the primary database",
+			zap.Error(err))
+		return
+	}
+	session.advance(position, s.dialect.PositionReached)
+}
+
+// WritePosition returns the position of the last write of the primary, or an
+// empty string if there are no replicas.
+func (s *ReplicaSet[DB]) WritePosition(ctx context.Context) (string, error) {
+	if len(s.replicas) == 0 {
+		return "", nil
+	}
+	return s.dialect.WritePosition(ctx, s.primary)
+}
+
+// Close stops the health checks of the replicas.
+func (s *ReplicaSet[DB]) Close() {
+	s.stop()
+	<-s.done
+}
+
+// available returns whether the replica is healthy and has replayed the
+// target position. The position of the replica is refreshed if it is behind.
+func (s *ReplicaSet[DB]) available(ctx context.Context, r *replica[DB], target string) bool {
+	r.lock.RLock()
+	healthy, position := r.healthy, r.position
+	r.lock.RUnlock()
+	if !healthy {
+		return false
+	}
+	if target == "" || s.dialect.PositionReached(position, target) {
+		return true
+	}
+	position, err := s.dialect.ReplayPosition(ctx, r.DB)
+	if err != nil {
+		if ctx.Err() == nil {
+			s.setHealth(r, false, "", err)
+		}
+		return false
+	}
+	s.setHealth(r, true, position, nil)
+	return s.dialect.PositionReached(position, target)
+}
+
+func (s *ReplicaSet[DB]) checkHealth(ctx context.Context, timeout time.Duration) {
+	for _, r := range s.replicas {
+		checkCtx, cancel := context.WithTimeout(ctx, timeout)
+		position, err := s.dialect.ReplayPosition(checkCtx, r.DB)
+		cancel()
+		if ctx.Err() != nil {
+			return
+		}
+		s.setHealth(r, err == nil, position, err)
+	}
+}
+
+func (s *ReplicaSet[DB]) setHealth(r *replica[DB], healthy bool, position string, err error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	switch {
+	case r.healthy && !healthy:
+		s.logger.Warn("read replica is down, reading from the primary database instead",
+			zap.String("replica", r.Name), zap.Error(err))
+	case !r.healthy && healthy:
+		s.logger.Info("read replica is up", zap.String("replica", r.Name))
+	}
+	r.healthy = healthy
+	if healthy {
+		r.position = position
+	}
+}
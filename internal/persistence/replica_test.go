@@ -0,0 +1,183 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testDB is a database whose position is a counter of writes.
+type testDB struct {
+	name     string
+	lock     sync.Mutex
+	position int
+	down     bool
+}
+
+func (db *testDB) setPosition(position int) {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+	db.position = position
+}
+
+func (db *testDB) setDown(down bool) {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+	db.down = down
+}
+
+func (db *testDB) read() (string, error) {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+	if db.down {
+		return "", errors.New(db.name + " is down")
+	}
+	return strconv.Itoa(db.position), nil
+}
+
+type testReplicaDialect struct{}
+
+func (testReplicaDialect) WritePosition(_ context.Context, primary *testDB) (string, error) {
+	return primary.read()
+}
+
+func (testReplicaDialect) ReplayPosition(_ context.Context, replica *testDB) (string, error) {
+	return replica.read()
+}
+
+func (testReplicaDialect) PositionReached(position, target string) bool {
+	p, _ := strconv.Atoi(position)
+	t, _ := strconv.Atoi(target)
+	return p >= t
+}
+
+func newTestReplicaSet(t *testing.T, primary *testDB, replicas ...*testDB) *ReplicaSet[*testDB] {
+	res := make([]Replica[*testDB], 0, len(replicas))
+	for _, r := range replicas {
+		res = append(res, Replica[*testDB]{Name: r.name, DB: r})
+	}
+	set := NewReplicaSet[*testDB](primary, res, testReplicaDialect{},
+		ReplicaSetOpts{HealthCheckPeriod: time.Hour})
+	t.Cleanup(set.Close)
+	return set
+}
+
+func readFrom(t *testing.T, ctx context.Context, set *ReplicaSet[*testDB]) string {
+	var name string
+	require.NoError(t, set.Read(ctx, func(db *testDB) error {
+		if _, err := db.read(); err != nil {
+			return err
+		}
+		name = db.name
+		return nil
+	}))
+	return name
+}
+
+func TestReplicaSet(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("reads from the primary without replicas", func(t *testing.T) {
+		set := newTestReplicaSet(t, &testDB{name: "primary"})
+		require.Equal(t, "primary", readFrom(t, ctx, set))
+	})
+	t.Run("reads are spread across replicas", func(t *testing.T) {
+		set := newTestReplicaSet(t, &testDB{name: "primary"},
+			&testDB{name: "r1"}, &testDB{name: "r2"})
+		names := map[string]int{}
+		for i := 0; i < 4; i++ {
+			names[readFrom(t, ctx, set)]++
+		}
+		require.Equal(t, map[string]int{"r1": 2, "r2": 2}, names)
+	})
+	t.Run("primary reads are served by the primary", func(t *testing.T) {
+		set := newTestReplicaSet(t, &testDB{name: "primary"}, &testDB{name: "r1"})
+		require.Equal(t, "primary", readFrom(t, WithPrimaryReads(ctx), set))
+	})
+	t.Run("unhealthy replicas are skipped", func(t *testing.T) {
+		r1 := &testDB{name: "r1", down: true}
+		set := newTestReplicaSet(t, &testDB{name: "primary"}, r1, &testDB{name: "r2"})
+		for i := 0; i < 4; i++ {
+			require.Equal(t, "r2", readFrom(t, ctx, set))
+		}
+		r1.setDown(false)
+		set.checkHealth(ctx, time.Second)
+		names := map[string]int{}
+		for i := 0; i < 4; i++ {
+			names[readFrom(t, ctx, set)]++
+		}
+		require.Equal(t, map[string]int{"r1": 2, "r2": 2}, names)
+	})
+	t.Run("reads fail over to the primary when replicas are down", func(t *testing.T) {
+		r1 := &testDB{name: "r1"}
+		set := newTestReplicaSet(t, &testDB{name: "primary"}, r1)
+		r1.setDown(true)
+		require.Equal(t, "primary", readFrom(t, ctx, set))
+	})
+	t.Run("read errors of healthy replicas are returned", func(t *testing.T) {
+		set := newTestReplicaSet(t, &testDB{name: "primary"}, &testDB{name: "r1"})
+		err := set.Read(ctx, func(db *testDB) error {
+			return errors.New("bad query")
+		})
+		require.EqualError(t, err, "bad query")
+	})
+	t.Run("sessions read their writes", func(t *testing.T) {
+		primary := &testDB{name: "primary"}
+		r1 := &testDB{name: "r1"}
+		set := newTestReplicaSet(t, primary, r1)
+		session, err := NewSession("")
+		require.NoError(t, err)
+		ctx := WithSession(ctx, session)
+
+		primary.setPosition(1)
+		set.Wrote(ctx)
+		require.Equal(t, "1", session.Position())
+		require.Equal(t, "primary", readFrom(t, ctx, set))
+		// Sessions without writes read from lagging replicas.
+		require.Equal(t, "r1", readFrom(t, context.Background(), set))
+
+		r1.setPosition(1)
+		require.Equal(t, "r1", readFrom(t, ctx, set))
+	})
+	t.Run("reads at the write position of the primary wait for replicas", func(t *testing.T) {
+		primary := &testDB{name: "primary"}
+		r1 := &testDB{name: "r1"}
+		set := newTestReplicaSet(t, primary, r1)
+		primary.setPosition(1)
+		position, err := set.WritePosition(ctx)
+		require.NoError(t, err)
+		require.Equal(t, "1", position)
+		ctx := WithSession(ctx, NewSessionAt(position))
+		require.Equal(t, "primary", readFrom(t, ctx, set))
+		r1.setPosition(1)
+		require.Equal(t, "r1", readFrom(t, ctx, set))
+
+		position, err = newTestReplicaSet(t, primary).WritePosition(ctx)
+		require.NoError(t, err)
+		require.Empty(t, position)
+	})
+}
+
+func TestSession(t *testing.T) {
+	session, err := NewSession("")
+	require.NoError(t, err)
+	require.Empty(t, session.Token())
+
+	reached := testReplicaDialect{}.PositionReached
+	session.advance("2", reached)
+	session.advance("", reached)
+	session.advance("1", reached)
+	require.Equal(t, "2", session.Position())
+
+	resumed, err := NewSession(session.Token())
+	require.NoError(t, err)
+	require.Equal(t, "2", resumed.Position())
+
+	_, err = NewSession("not a token!")
+	require.EqualError(t, err, "invalid session token")
+}
@@ -0,0 +1,96 @@
+package persistence
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// SortField is a top-level field of the stored objects to sort by.
+type SortField struct {
+	Name       string
+	Kind       FieldKind
+	Descending bool
+}
+
+// OrderBy returns the SQL `ORDER BY` expressions sorting by the given fields.
+// The key column is always sorted by last, so that the order is total.
+func OrderBy(d FilterDialect, sort []SortField, keyColumn string) []string {
+	res := make([]string, 0, len(sort)+1)
+	for _, field := range sort {
+		expr := d.Field(field.Name, field.Kind)
+		if field.Descending {
+			expr += " DESC"
+		}
+		res = append(res, expr)
+	}
+	return append(res, keyColumn)
+}
+
+// SortAfter returns the SQL condition, along with the values of its
+// placeholders, matching the keys sorting after the given key, when sorting by
+// the given fields. The values are the ones of the fields for the given key.
+func SortAfter(d FilterDialect, sort []SortField, values []interface{}, keyColumn string,
+	after string,
+) (string, []interface{}) {
+	// Expands the `(f1, f2, key) > (v1, v2, after)` row comparison, as fields
+	// can be sorted in different directions:
+	// `f1 > v1 OR (f1 = v1 AND (f2 > v2 OR (f2 = v2 AND key > after)))`.
+	condition, args := keyColumn+" > ?", []interface{}{after}
+	for i := len(sort) - 1; i >= 0; i-- {
+		field := d.Field(sort[i].Name, sort[i].Kind)
+		operator := " > ?"
+		if sort[i].Descending {
+			operator = " < ?"
+		}
+		condition = field + operator + " OR (" + field + " = ? AND (" + condition + "))"
+		args = append([]interface{}{values[i], values[i]}, args...)
+	}
+	return "(" + condition + ")", args
+}
+
+// SortValues returns the values of the given fields of a stored object, in the
+// same way as they are sorted by. Missing fields have the zero value of their kind.
+func SortValues(sort []SortField, value []byte) ([]interface{}, error) {
+	var v struct {
+		Object map[string]interface{} `json:"object"`
+	}
+	if err := json.Unmarshal(value, &v); err != nil {
+		return nil, err
+	}
+	res := make([]interface{}, len(sort))
+	for i, field := range sort {
+		res[i] = kindValue(v.Object[field.Name], field.Kind)
+	}
+	return res, nil
+}
+
+// SortValuesFromJSON converts sort values that have been encoded as JSON,
+// e.g.: within a page token, back to the kinds of the given fields. False is
+// returned when the values do not match the fields.
+func SortValuesFromJSON(sort []SortField, values []json.RawMessage) ([]interface{}, bool) {
+	if len(values) != len(sort) {
+		return nil, false
+	}
+	res := make([]interface{}, len(sort))
+	for i, field := range sort {
+		decoder := json.NewDecoder(strings.NewReader(string(values[i])))
+		decoder.UseNumber()
+		var v interface{}
+		if err := decoder.Decode(&v); err != nil {
+			return nil, false
+		}
+		if n, ok := v.(json.Number); ok {
+			// Numbers are converted like 64-bit integers encoded as JSON strings.
+			v = n.String()
+			if field.Kind == FieldKindDouble {
+				f, err := n.Float64()
+				if err != nil {
+					return nil, false
+				}
+				v = f
+			}
+		}
+		res[i] = kindValue(v, field.Kind)
+	}
+	return res, true
+}
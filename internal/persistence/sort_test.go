@@ -0,0 +1,50 @@
+package persistence
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSortQuery(t *testing.T) {
+	sort := []SortField{
+		{Name: "port", Kind: FieldKindInt, Descending: true},
+		{Name: "name", Kind: FieldKindString},
+	}
+
+	require.Equal(t, []string{"field(port, 1) DESC", "field(name, 0)", "key"},
+		OrderBy(testFilterDialect{}, sort, "key"))
+
+	where, args := SortAfter(testFilterDialect{}, sort, []interface{}{int64(443), "foo"}, "key", "k1")
+	require.Equal(t, "(field(port, 1) < ? OR (field(port, 1) = ? AND "+
+		"(field(name, 0) > ? OR (field(name, 0) = ? AND (key > ?)))))", where)
+	require.Equal(t, []interface{}{int64(443), int64(443), "foo", "foo", "k1"}, args)
+}
+
+func TestSortValues(t *testing.T) {
+	sort := []SortField{
+		{Name: "port", Kind: FieldKindInt},
+		{Name: "weight", Kind: FieldKindDouble},
+		{Name: "name", Kind: FieldKindString},
+		{Name: "enabled", Kind: FieldKindBool},
+	}
+
+	values, err := SortValues(sort, []byte(`{"object":{"port":"443","weight":1.5}}`))
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{int64(443), 1.5, "", false}, values)
+
+	// Values are encoded as JSON within page tokens.
+	var raw []json.RawMessage
+	for _, value := range values {
+		encoded, err := json.Marshal(value)
+		require.NoError(t, err)
+		raw = append(raw, encoded)
+	}
+	decoded, ok := SortValuesFromJSON(sort, raw)
+	require.True(t, ok)
+	require.Equal(t, values, decoded)
+
+	_, ok = SortValuesFromJSON(sort, raw[:1])
+	require.False(t, ok)
+}
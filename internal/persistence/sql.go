@@ -2,11 +2,23 @@ package persistence
 
 import (
 	"database/sql"
+	"strings"
 )
 
 // WildcardOperator is used to represent a SQL wildcard (`%`) within a key.
 const WildcardOperator = "\u0000"
 
+// ReferencedIDPosition returns the 1-based position of the ID contained in the
+// keys listed with the given prefix, along with the amount of characters of the
+// keys that are not part of the ID. See ListOpts.ReferencedPrefix.
+func ReferencedIDPosition(prefix string) (start int, rest int) {
+	before, after, found := strings.Cut(prefix, WildcardOperator)
+	if !found {
+		return len(prefix) + 1, len(prefix)
+	}
+	return len(before) + 1, len(before) + len(after)
+}
+
 // SQLPersister should be implemented for all persistence stores that implement an underlining sql.DB driver.
 type SQLPersister interface {
 	// Driver returns the relevant Golang SQL driver used to connect to the database.
@@ -8,11 +8,10 @@ import (
 	"time"
 
 	sq "github.com/Masterminds/squirrel"
-	"github.com/google/cel-go/common/operators"
+	"github.com/google/cel-go/common/overloads"
 	"github.com/kong/koko/internal/persistence"
 	"github.com/mattn/go-sqlite3"
 	"go.uber.org/zap"
-	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
 )
 
 const (
@@ -21,6 +20,9 @@ const (
 	insertQuery = `insert into store(key,value) values($1,$2);`
 	deleteQuery = `delete from store where key=$1`
 
+	insertTagQuery  = `insert into store_tags(key,tag) values($1,$2)`
+	deleteTagsQuery = `delete from store_tags where key=$1`
+
 	primaryKeyConstraintErrorCode = 1555
 )
 
@@ -92,18 +94,35 @@ func (s *SQLite) Get(ctx context.Context, key string) ([]byte, error) {
 }
 
 func (s *SQLite) Insert(ctx context.Context, key string, value []byte) error {
-	q := sqliteQuery{query: s.db, queryTimeout: s.queryTimeout}
-	return q.Insert(ctx, key, value)
+	return s.write(ctx, func(q *sqliteQuery) error {
+		return q.Insert(ctx, key, value)
+	})
 }
 
 func (s *SQLite) Put(ctx context.Context, key string, value []byte) error {
-	q := sqliteQuery{query: s.db, queryTimeout: s.queryTimeout}
-	return q.Put(ctx, key, value)
+	return s.write(ctx, func(q *sqliteQuery) error {
+		return q.Put(ctx, key, value)
+	})
 }
 
 func (s *SQLite) Delete(ctx context.Context, key string) error {
-	q := sqliteQuery{query: s.db, queryTimeout: s.queryTimeout}
-	return q.Delete(ctx, key)
+	return s.write(ctx, func(q *sqliteQuery) error {
+		return q.Delete(ctx, key)
+	})
+}
+
+// write runs the given write within a transaction, as the tags of the
+// written object are updated along with it.
+func (s *SQLite) write(ctx context.Context, fn func(q *sqliteQuery) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(&sqliteQuery{query: tx, queryTimeout: s.queryTimeout}); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
 }
 
 func (s *SQLite) List(ctx context.Context, prefix string, opts *persistence.ListOpts) (persistence.ListResult, error) {
@@ -206,7 +225,7 @@ func (t *sqliteQuery) Insert(ctx context.Context, key string, value []byte) erro
 	if rowCount != 1 {
 		return persistence.ErrInvalidRowsAffected
 	}
-	return nil
+	return t.putTags(ctx, key, value)
 }
 
 func (t *sqliteQuery) Put(ctx context.Context, key string, value []byte) error {
@@ -223,7 +242,7 @@ func (t *sqliteQuery) Put(ctx context.Context, key string, value []byte) error {
 	if rowCount != 1 {
 		return persistence.ErrInvalidRowsAffected
 	}
-	return nil
+	return t.putTags(ctx, key, value)
 }
 
 func (t *sqliteQuery) Delete(ctx context.Context, key string) error {
@@ -243,28 +262,65 @@ func (t *sqliteQuery) Delete(ctx context.Context, key string) error {
 	if rowCount != 1 {
 		return persistence.ErrInvalidRowsAffected
 	}
+	_, err = t.query.ExecContext(ctx, deleteTagsQuery, key)
+	return err
+}
+
+// putTags replaces the indexed tags of the object stored at the given key.
+func (t *sqliteQuery) putTags(ctx context.Context, key string, value []byte) error {
+	if _, err := t.query.ExecContext(ctx, deleteTagsQuery, key); err != nil {
+		return err
+	}
+	for _, tag := range persistence.Tags(value) {
+		if _, err := t.query.ExecContext(ctx, insertTagQuery, key, tag); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
 func (t *sqliteQuery) List(ctx context.Context, prefix string,
 	opts *persistence.ListOpts,
 ) (persistence.ListResult, error) {
+	// When listing referenced objects, the keys are the ones of the index `i`,
+	// while the values are the ones of the objects `s` referenced by the index.
+	from, keyColumn := "store s", "s.key"
+	if opts.ReferencedPrefix != "" {
+		from, keyColumn = "store i", "i.key"
+	}
+	start, rest := persistence.ReferencedIDPosition(prefix)
+
 	// Replacing any wildcard operators with the proper wildcard operator for SQLLite.
 	prefix = strings.ReplaceAll(prefix, persistence.WildcardOperator, "*")
 
 	query := sq.StatementBuilder.
 		PlaceholderFormat(sq.Dollar).
-		Select("s.key", "s.value", "COUNT(*) OVER() AS full_count").
-		From("store s").
-		Where("s.key GLOB ? || '*'", prefix).
-		OrderBy("s.key").
+		Select(keyColumn, "s.value", "COUNT(*) OVER() AS full_count").
+		From(from).
+		Where(keyColumn+" GLOB ? || '*'", prefix).
+		OrderBy(persistence.OrderBy(filterDialect{}, opts.Sort, keyColumn)...).
 		Limit(uint64(opts.Limit)).
 		Offset(uint64(opts.Offset))
 
-	// Parse out any provided, pre-validated CEL expression & add the proper clauses to the query.
-	var err error
-	if query, err = addFilterToQuery(query, opts.Filter); err != nil {
-		return persistence.ListResult{}, fmt.Errorf("unable to add filter CEL expression to query: %w", err)
+	if opts.ReferencedPrefix != "" {
+		query = query.Join(fmt.Sprintf(
+			"store s ON s.key = ? || substr(i.key, %d, length(i.key) - %d)", start, rest,
+		), opts.ReferencedPrefix)
+	}
+	if opts.After != "" {
+		after, args := persistence.SortAfter(filterDialect{}, opts.Sort, opts.AfterValues, keyColumn, opts.After)
+		query = query.Where(after, args...)
+	}
+
+	// Push down the supported parts of any provided, pre-validated CEL expression.
+	// The remaining parts are evaluated once the rows are read, in which case the
+	// pagination of the query is done afterwards as well.
+	where, args, residual := persistence.TranslateFilter(filterDialect{}, opts.Filter)
+	if where != "" {
+		query = query.Where(where, args...)
+	}
+	if residual {
+		query = query.RemoveLimit().RemoveOffset()
 	}
 
 	ctx, cancel := context.WithTimeout(ctx, t.queryTimeout)
@@ -295,45 +351,50 @@ func (t *sqliteQuery) List(ctx context.Context, prefix string,
 		kvlist = append(kvlist, kvr)
 	}
 	res.KVList = kvlist
+	if residual {
+		return persistence.FilterKVList(kvlist, opts)
+	}
 	return res, nil
 }
 
-func addFilterToQuery(query sq.SelectBuilder, expr *exprpb.Expr) (sq.SelectBuilder, error) {
-	// No-op when no expression is provided.
-	if expr == nil {
-		return query, nil
-	}
-
-	tags, exprFunction, err := persistence.GetTagsFromExpression(expr)
-	if err != nil {
-		return query, err
-	}
-
-	queryArgs, err := persistence.GetQueryArgsFromExprConstants(tags)
-	if err != nil {
-		return query, err
+// filterDialect translates filters to SQLite SQL, using its JSON functions.
+// Read more: https://www.sqlite.org/json1.html
+type filterDialect struct{}
+
+func (filterDialect) Field(name string, kind persistence.FieldKind) string {
+	field := fmt.Sprintf("json_extract(s.value, '$.object.%s')", name)
+	switch kind {
+	case persistence.FieldKindInt, persistence.FieldKindUint, persistence.FieldKindBool:
+		// Booleans are extracted as either 1 or 0.
+		return fmt.Sprintf("COALESCE(CAST(%s AS INTEGER), 0)", field)
+	case persistence.FieldKindDouble:
+		return fmt.Sprintf("COALESCE(CAST(%s AS REAL), 0)", field)
+	default:
+		return fmt.Sprintf("COALESCE(%s, '')", field)
 	}
+}
 
-	// No-op when there are no tags to filter against, to treat it as if there is no filter at all.
-	if len(queryArgs) == 0 {
-		return query, nil
+func (filterDialect) ListContains(name string) string {
+	if name == persistence.TagsField {
+		return "s.key IN (SELECT t.key FROM store_tags t WHERE t.tag = ?)"
 	}
+	// The `atom` column contains the result of the `json_each()` function.
+	// Read more: https://www.sqlite.org/json1.html#the_json_each_and_json_tree_table_valued_functions
+	return fmt.Sprintf("EXISTS (SELECT 1 FROM json_each(s.value, '$.object.%s') WHERE atom = ?)", name)
+}
 
-	// As the only supported field name to filter on are tags, we can simply hard-code the predicate.
-	query = query.
-		// Output a row for each tag value.
-		Join("json_each(s.value, '$.object.tags') t").
-		// The `atom` column contains the result of the `json_each()` function.
-		// Read more: https://www.sqlite.org/json1.html#the_json_each_and_json_tree_table_valued_functions
-		Where(sq.Eq{"t.atom": queryArgs}).
-		GroupBy("s.key")
-
-	// In the event we need to assert that the resource has all provided tags, we'll simply check
-	// the number of tags returned. This works as we're assuming duplicates in the DB are not
-	// allowed, and any duplicate tags in the expression have already been filtered out.
-	if exprFunction == operators.LogicalAnd {
-		query = query.Having("COUNT(DISTINCT t.atom) = ?", len(queryArgs))
+func (filterDialect) Match(field string, function string, value string) (string, interface{}) {
+	// `GLOB` is used as `LIKE` is case-insensitive.
+	value = globEscaper.Replace(value)
+	switch function {
+	case overloads.StartsWith:
+		value += "*"
+	case overloads.EndsWith:
+		value = "*" + value
+	default:
+		value = "*" + value + "*"
 	}
-
-	return query, nil
+	return field + " GLOB ?", value
 }
+
+var globEscaper = strings.NewReplacer("*", "[*]", "?", "[?]", "[", "[[]")
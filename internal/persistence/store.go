@@ -41,7 +41,9 @@ type KVResult struct {
 }
 
 type ListResult struct {
-	KVList     []KVResult
+	KVList []KVResult
+	// TotalCount is the number of keys with the prefix, across all pages,
+	// that sort after ListOpts.After.
 	TotalCount int
 }
 
@@ -64,14 +66,33 @@ type ListOpts struct {
 	// number and zero is used to indicate the first page.
 	Offset int
 
-	// CEL expression used for filtering.
+	// After is used for keyset pagination. When set, only keys that sort
+	// after it are returned, Offset must be zero, and the total count only
+	// includes the keys after it.
+	After string
+
+	// Sort is used to sort the results by the fields of the stored objects,
+	// before sorting them by key. When set along with After, AfterValues must
+	// be set to the values of the fields for the key set in After.
+	Sort        []SortField
+	AfterValues []interface{}
+
+	// ReferencedPrefix is used to list the objects referenced by index keys.
+	// When set, each listed key must contain the ID of an object, either in
+	// place of the wildcard operator of the prefix, or following the prefix
+	// when it contains none. The value of each listed key is then the value
+	// of the object keyed by ReferencedPrefix followed by the ID, and objects
+	// that do not exist are left out. The filter applies to these values.
+	ReferencedPrefix string
+
+	// Type-checked CEL expression used for filtering.
 	//
 	// When nil, no filtering of any kind will be done. When provided, the filter is
 	// expected to be pre-validated for correctness. More specific validations can
 	// occur later, e.g.: such validations that are specific to a particular resource.
 	//
 	// Read more: https://github.com/google/cel-spec
-	Filter *exprpb.Expr
+	Filter *exprpb.CheckedExpr
 }
 
 type Tx interface {
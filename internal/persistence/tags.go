@@ -0,0 +1,32 @@
+package persistence
+
+import "encoding/json"
+
+// TagsField is the field of the stored objects holding their tags.
+//
+// Tags are indexed by the SQL persisters within the `store_tags(key, tag)`
+// table, maintained along with the `store` table on every write, so that
+// filtering objects by tag is done using the same index on every database.
+const TagsField = "tags"
+
+// Tags returns the distinct tags of a stored object, in order. No tags are
+// returned for values that are not stored objects.
+func Tags(value []byte) []string {
+	var v struct {
+		Object struct {
+			Tags []interface{} `json:"tags"`
+		} `json:"object"`
+	}
+	if err := json.Unmarshal(value, &v); err != nil {
+		return nil
+	}
+	var res []string
+	seen := make(map[string]bool, len(v.Object.Tags))
+	for _, tag := range v.Object.Tags {
+		if tag, ok := tag.(string); ok && !seen[tag] {
+			seen[tag] = true
+			res = append(res, tag)
+		}
+	}
+	return res
+}
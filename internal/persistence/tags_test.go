@@ -0,0 +1,24 @@
+package persistence
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTags(t *testing.T) {
+	tests := []struct {
+		value string
+		tags  []string
+	}{
+		{value: `{"object":{"tags":["a","b","a"]}}`, tags: []string{"a", "b"}},
+		{value: `{"object":{"tags":["a",1,null]}}`, tags: []string{"a"}},
+		{value: `{"object":{"tags":"a"}}`},
+		{value: `{"object":{}}`},
+		{value: `{"object":"a"}`},
+		{value: `not json`},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.tags, Tags([]byte(tt.value)), tt.value)
+	}
+}
@@ -4,13 +4,18 @@ import (
 	"context"
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/checker/decls"
 	internalJSON "github.com/kong/koko/internal/json"
 	"github.com/kong/koko/internal/persistence"
 	"github.com/kong/koko/internal/test/util"
 	"github.com/stretchr/testify/require"
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
 )
 
 type jsonWrapper struct {
@@ -243,6 +248,148 @@ func TestPersister(t *testing.T) {
 			require.Nil(t, err)
 			require.Len(t, listResult.KVList, 0)
 		})
+		t.Run("list with keyset pagination", func(t *testing.T) {
+			for i := 0; i < 5; i++ {
+				key := fmt.Sprintf("keyset/key%06d", i)
+				require.Nil(t, p.Put(context.Background(), key, json(key)))
+			}
+			var keys []string
+			after := ""
+			for {
+				listResult, err := p.List(context.Background(), "keyset/", &persistence.ListOpts{
+					Limit: 2,
+					After: after,
+				})
+				require.Nil(t, err)
+				require.Equal(t, 5-len(keys), listResult.TotalCount)
+				for _, kv := range listResult.KVList {
+					keys = append(keys, string(kv.Key))
+				}
+				if listResult.TotalCount <= len(listResult.KVList) {
+					break
+				}
+				after = keys[len(keys)-1]
+			}
+			require.Equal(t, []string{
+				"keyset/key000000",
+				"keyset/key000001",
+				"keyset/key000002",
+				"keyset/key000003",
+				"keyset/key000004",
+			}, keys)
+		})
+		t.Run("list sorted by fields", func(t *testing.T) {
+			ctx := context.Background()
+			for key, value := range map[string]string{
+				"sort/a": `{"object":{"name":"b","port":80}}`,
+				"sort/b": `{"object":{"name":"a","port":443}}`,
+				"sort/c": `{"object":{"name":"c","port":443}}`,
+				"sort/d": `{"object":{"name":"d"}}`,
+			} {
+				require.Nil(t, p.Put(ctx, key, []byte(value)))
+			}
+			sort := []persistence.SortField{
+				{Name: "port", Kind: persistence.FieldKindInt, Descending: true},
+				{Name: "name", Kind: persistence.FieldKindString},
+			}
+
+			listResult, err := p.List(ctx, "sort/", &persistence.ListOpts{Limit: 10, Sort: sort})
+			require.Nil(t, err)
+			var keys []string
+			for _, kv := range listResult.KVList {
+				keys = append(keys, string(kv.Key))
+			}
+			require.Equal(t, []string{"sort/b", "sort/c", "sort/a", "sort/d"}, keys)
+
+			// Lists the keys after each key, by the values of its fields.
+			for i, kv := range listResult.KVList[:3] {
+				values, err := persistence.SortValues(sort, kv.Value)
+				require.Nil(t, err)
+				page, err := p.List(ctx, "sort/", &persistence.ListOpts{
+					Limit:       1,
+					Sort:        sort,
+					After:       string(kv.Key),
+					AfterValues: values,
+				})
+				require.Nil(t, err)
+				require.Equal(t, 3-i, page.TotalCount)
+				require.Equal(t, keys[i+1], string(page.KVList[0].Key))
+			}
+		})
+		t.Run("list referenced values", func(t *testing.T) {
+			ctx := context.Background()
+			for _, id := range []string{"a", "b", "c"} {
+				require.Nil(t, p.Put(ctx, "ref/o/"+id, json(id)))
+			}
+			// Forward index keys end with the ID, reverse index keys contain it.
+			for _, key := range []string{
+				"ref/ix/p1/a", "ref/ix/p1/c", "ref/ix/p1/missing", "ref/ix/p2/b",
+				"ref/rx/a/p1", "ref/rx/b/p2", "ref/rx/c/p1",
+			} {
+				require.Nil(t, p.Put(ctx, key, json("")))
+			}
+
+			for _, prefix := range []string{"ref/ix/p1/", "ref/rx/" + persistence.WildcardOperator + "/p1"} {
+				listResult, err := p.List(ctx, prefix, &persistence.ListOpts{
+					Limit:            1,
+					ReferencedPrefix: "ref/o/",
+				})
+				require.Nil(t, err)
+				require.Equal(t, 2, listResult.TotalCount)
+				require.Len(t, listResult.KVList, 1)
+				equalJSON(t, json("a"), listResult.KVList[0].Value)
+
+				listResult, err = p.List(ctx, prefix, &persistence.ListOpts{
+					Limit:            1,
+					After:            string(listResult.KVList[0].Key),
+					ReferencedPrefix: "ref/o/",
+				})
+				require.Nil(t, err)
+				require.Equal(t, 1, listResult.TotalCount)
+				equalJSON(t, json("c"), listResult.KVList[0].Value)
+			}
+		})
+		t.Run("list filtered by tags", func(t *testing.T) {
+			ctx := context.Background()
+			for key, value := range map[string]string{
+				"tags/a": `{"object":{"tags":["t1","t2"]}}`,
+				"tags/b": `{"object":{"tags":["t2"]}}`,
+				"tags/c": `{"object":{"tags":["T1"]}}`,
+				"tags/d": `{"object":{}}`,
+			} {
+				require.Nil(t, p.Insert(ctx, key, []byte(value)))
+			}
+			listTagged := func(tag string) []string {
+				listResult, err := p.List(ctx, "tags/", &persistence.ListOpts{
+					Limit:  10,
+					Filter: tagFilter(t, tag),
+				})
+				require.Nil(t, err)
+				keys := []string{}
+				for _, kv := range listResult.KVList {
+					keys = append(keys, string(kv.Key))
+				}
+				return keys
+			}
+			require.Equal(t, []string{"tags/a"}, listTagged("t1"))
+			require.Equal(t, []string{"tags/a", "tags/b"}, listTagged("t2"))
+
+			// Tags are updated along with the objects.
+			require.Nil(t, p.Put(ctx, "tags/a", []byte(`{"object":{"tags":["t1"]}}`)))
+			require.Nil(t, p.Put(ctx, "tags/d", []byte(`{"object":{"tags":["t2"]}}`)))
+			require.Nil(t, p.Delete(ctx, "tags/b"))
+			require.Equal(t, []string{"tags/a"}, listTagged("t1"))
+			require.Equal(t, []string{"tags/d"}, listTagged("t2"))
+
+			// Tags are rolled back along with the objects.
+			tx, err := p.Tx(ctx)
+			require.Nil(t, err)
+			require.Nil(t, tx.Put(ctx, "tags/c", []byte(`{"object":{"tags":["t1"]}}`)))
+			require.Nil(t, tx.Delete(ctx, "tags/a"))
+			require.Nil(t, tx.Rollback())
+			require.Equal(t, []string{"tags/a"}, listTagged("t1"))
+			require.Equal(t, []string{"tags/c"}, listTagged("T1"))
+		})
 	})
 	t.Run("Tx()", func(t *testing.T) {
 		t.Run("transaction rollbacks correctly", func(t *testing.T) {
@@ -263,3 +410,65 @@ func TestPersister(t *testing.T) {
 		})
 	})
 }
+
+func TestEventSource(t *testing.T) {
+	p, err := util.GetPersister(t)
+	require.Nil(t, err)
+	source, ok := p.(persistence.EventSource)
+	if !ok {
+		t.Skip("persister does not support notifications")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	notifications, err := source.Listen(ctx, "koko_test")
+	require.Nil(t, err)
+
+	t.Run("notification is delivered once committed", func(t *testing.T) {
+		tx, err := p.Tx(ctx)
+		require.Nil(t, err)
+		notifier, ok := tx.(persistence.Notifier)
+		require.True(t, ok)
+		require.Nil(t, notifier.Notify(ctx, "koko_test", "payload"))
+		require.Nil(t, tx.Commit())
+		select {
+		case payload := <-notifications:
+			require.Equal(t, "payload", payload)
+		case <-time.After(5 * time.Second):
+			require.Fail(t, "no notification received")
+		}
+	})
+	t.Run("notification is discarded on rollback", func(t *testing.T) {
+		tx, err := p.Tx(ctx)
+		require.Nil(t, err)
+		notifier, ok := tx.(persistence.Notifier)
+		require.True(t, ok)
+		require.Nil(t, notifier.Notify(ctx, "koko_test", "rolled-back"))
+		require.Nil(t, tx.Rollback())
+		select {
+		case payload := <-notifications:
+			require.Fail(t, "unexpected notification", payload)
+		case <-time.After(100 * time.Millisecond):
+		}
+	})
+	t.Run("channel is closed when context is done", func(t *testing.T) {
+		cancel()
+		require.Eventually(t, func() bool {
+			_, ok := <-notifications
+			return !ok
+		}, 5*time.Second, 10*time.Millisecond)
+	})
+}
+
+// tagFilter returns the type-checked CEL expression matching objects with the given tag.
+func tagFilter(t *testing.T, tag string) *exprpb.CheckedExpr {
+	env, err := cel.NewEnv(cel.Declarations(
+		decls.NewVar(persistence.TagsField, decls.NewListType(decls.String)),
+	))
+	require.NoError(t, err)
+	ast, issues := env.Compile(strconv.Quote(tag) + " in tags")
+	require.NoError(t, issues.Err())
+	res, err := cel.AstToCheckedExpr(ast)
+	require.NoError(t, err)
+	return res
+}
@@ -0,0 +1,43 @@
+package plugin
+
+import (
+	"fmt"
+
+	"github.com/kong/koko/internal/json"
+)
+
+// luaSchemaField is a field of the JSON representation of a Lua plugin schema.
+type luaSchemaField struct {
+	Type      string                       `json:"type"`
+	Encrypted bool                         `json:"encrypted"`
+	Fields    []map[string]*luaSchemaField `json:"fields"`
+}
+
+// EncryptedFields returns the paths, with dot-separated field names, of the
+// fields that the given raw Lua plugin schema marks as encrypted, e.g.:
+// "config.aws_secret". Fields nested within arrays are not included.
+func EncryptedFields(rawLuaSchema []byte) ([]string, error) {
+	var schema luaSchemaField
+	if err := json.Unmarshal(rawLuaSchema, &schema); err != nil {
+		return nil, fmt.Errorf("json unmarshal plugin schema: %w", err)
+	}
+	return encryptedFields("", schema.Fields), nil
+}
+
+func encryptedFields(prefix string, fields []map[string]*luaSchemaField) []string {
+	var res []string
+	for _, field := range fields {
+		for name, def := range field {
+			if def == nil {
+				continue
+			}
+			switch {
+			case def.Encrypted:
+				res = append(res, prefix+name)
+			case def.Type == "record":
+				res = append(res, encryptedFields(prefix+name+".", def.Fields)...)
+			}
+		}
+	}
+	return res
+}
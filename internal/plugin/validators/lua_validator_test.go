@@ -1566,6 +1566,31 @@ func TestPluginLuaSchema(t *testing.T) {
 	})
 }
 
+func TestEncryptedFields(t *testing.T) {
+	t.Run("returns the encrypted fields of a plugin", func(t *testing.T) {
+		rawLuaSchema, err := goodValidator.GetRawLuaSchema(context.Background(), "aws-lambda")
+		require.Nil(t, err)
+		fields, err := plugin.EncryptedFields(rawLuaSchema)
+		require.Nil(t, err)
+		require.ElementsMatch(t, []string{
+			"config.aws_key",
+			"config.aws_secret",
+			"config.aws_assume_role_arn",
+		}, fields)
+	})
+	t.Run("returns no fields for a plugin without secrets", func(t *testing.T) {
+		rawLuaSchema, err := goodValidator.GetRawLuaSchema(context.Background(), "cors")
+		require.Nil(t, err)
+		fields, err := plugin.EncryptedFields(rawLuaSchema)
+		require.Nil(t, err)
+		require.Empty(t, fields)
+	})
+	t.Run("fails on an invalid schema", func(t *testing.T) {
+		_, err := plugin.EncryptedFields([]byte("{"))
+		require.ErrorContains(t, err, "json unmarshal plugin schema")
+	})
+}
+
 func TestLuaValidator_GetAvailablePluginNames(t *testing.T) {
 	validator := &LuaValidator{luaSchemaNames: []string{"a", "b", "c"}}
 	assert.Equal(t, validator.luaSchemaNames, validator.GetAvailablePluginNames(context.Background()))
@@ -151,6 +151,14 @@ func (r Certificate) ProcessDefaults(ctx context.Context) error {
 	return nil
 }
 
+// Options implements the model.ObjectWithOptions interface.
+func (r Certificate) Options() model.ObjectOptions {
+	return model.ObjectOptions{
+		CascadeOnDelete: true,
+		SensitiveFields: []string{"key", "key_alt"},
+	}
+}
+
 func (r Certificate) Indexes() []model.Index {
 	return nil
 }
@@ -226,6 +234,7 @@ func init() {
 				XKokoConfig: &extension.Config{
 					DisableValidateEndpoint: true,
 					Referenceable:           true,
+					Sensitive:               true,
 				},
 			},
 			"cert_alt": {
@@ -248,6 +257,7 @@ func init() {
 				XKokoConfig: &extension.Config{
 					DisableValidateEndpoint: true,
 					Referenceable:           true,
+					Sensitive:               true,
 				},
 			},
 			"tags":       typedefs.Tags,
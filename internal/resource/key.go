@@ -57,6 +57,15 @@ func (k Key) SetResource(r model.Resource) error {
 	return model.SetResource(k, r)
 }
 
+// Options implements the model.ObjectWithOptions interface.
+func (k Key) Options() model.ObjectOptions {
+	return model.ObjectOptions{
+		CascadeOnDelete: true,
+		// The JWK is a JSON string which holds the private members of the key.
+		SensitiveFields: []string{"jwk", "pem.private_key"},
+	}
+}
+
 func (k Key) Indexes() []model.Index {
 	if k.Key == nil {
 		return nil
@@ -7,6 +7,8 @@ type List struct {
 	objects    []model.Object
 	totalCount int
 	nextPage   int
+
+	nextPageToken string
 }
 
 func NewList(typ model.Type) model.ObjectList {
@@ -33,6 +35,14 @@ func (l *List) GetNextPage() int {
 	return l.nextPage
 }
 
+func (l *List) SetNextPageToken(token string) {
+	l.nextPageToken = token
+}
+
+func (l *List) GetNextPageToken() string {
+	return l.nextPageToken
+}
+
 func (l *List) SetTotalCount(count int) {
 	l.totalCount = count
 }
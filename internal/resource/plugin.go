@@ -76,6 +76,38 @@ func (r Plugin) ProcessDefaults(ctx context.Context) error {
 	return err
 }
 
+// SensitiveFields returns the paths, with dot-separated field names, of the
+// fields of the plugin that its Lua schema marks as encrypted, e.g.:
+// "config.aws_secret".
+func (r Plugin) SensitiveFields(ctx context.Context) ([]string, error) {
+	rawLuaSchema, err := validator.GetRawLuaSchema(ctx, r.Plugin.Name)
+	if err != nil {
+		return nil, fmt.Errorf("get schema of plugin '%s': %w", r.Plugin.Name, err)
+	}
+	return plugin.EncryptedFields(rawLuaSchema)
+}
+
+// AllPluginSensitiveFields returns the paths, with dot-separated field names,
+// of the fields that the Lua schema of any available plugin marks as
+// encrypted, e.g.: "config.aws_secret".
+func AllPluginSensitiveFields(ctx context.Context) ([]string, error) {
+	var res []string
+	seen := map[string]bool{}
+	for _, name := range validator.GetAvailablePluginNames(ctx) {
+		fields, err := Plugin{Plugin: &v1.Plugin{Name: name}}.SensitiveFields(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, field := range fields {
+			if !seen[field] {
+				seen[field] = true
+				res = append(res, field)
+			}
+		}
+	}
+	return res, nil
+}
+
 func (r Plugin) Indexes() []model.Index {
 	serviceID, routeID, consumerID := "", "", ""
 	if r.Plugin.Service != nil {
@@ -111,6 +111,15 @@ func (r Vault) ProcessDefaults(ctx context.Context) error {
 	return nil
 }
 
+// Options implements the model.ObjectWithOptions interface.
+func (r Vault) Options() model.ObjectOptions {
+	return model.ObjectOptions{
+		CascadeOnDelete: true,
+		// The configuration may hold credentials, e.g.: the token of a HashiCorp Vault.
+		SensitiveFields: []string{"config"},
+	}
+}
+
 func (r Vault) Indexes() []model.Index {
 	return []model.Index{
 		{
@@ -180,6 +189,19 @@ func init() {
 						}
 						s[vs] = &generator.Schema{}
 					}
+					// Enterprise vaults are refused by the enum of names, but
+					// their secrets are marked so that they are redacted from
+					// the vaults stored.
+					s["hcv"] = &generator.Schema{
+						Properties: map[string]*generator.Schema{
+							"token": {
+								Type: "string",
+								XKokoConfig: &extension.Config{
+									Sensitive: true,
+								},
+							},
+						},
+					}
 					return s
 				}(),
 			},
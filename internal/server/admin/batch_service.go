@@ -0,0 +1,194 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	pbModel "github.com/kong/koko/internal/gen/grpc/kong/admin/model/v1"
+	v1 "github.com/kong/koko/internal/gen/grpc/kong/admin/service/v1"
+	"github.com/kong/koko/internal/json"
+	"github.com/kong/koko/internal/model"
+	"github.com/kong/koko/internal/model/json/validation"
+	"github.com/kong/koko/internal/resource"
+	"github.com/kong/koko/internal/server/util"
+	"github.com/kong/koko/internal/store"
+	"github.com/kong/koko/internal/store/event"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// batchTypes are the resource types that can be written using a batch: every
+// registered type but the internal ones, which Koko writes itself.
+var batchTypes = func() map[model.Type]bool {
+	internal := map[model.Type]bool{
+		resource.TypeNode:       true,
+		resource.TypeNodeStatus: true,
+		resource.TypeHash:       true,
+		event.Type:              true,
+	}
+	res := map[model.Type]bool{}
+	for _, typ := range model.AllTypes() {
+		if !internal[typ] {
+			res[typ] = true
+		}
+	}
+	return res
+}()
+
+type BatchService struct {
+	v1.UnimplementedBatchServiceServer
+	CommonOpts
+}
+
+func (s *BatchService) ApplyBatch(ctx context.Context,
+	req *v1.ApplyBatchRequest,
+) (*v1.ApplyBatchResponse, error) {
+	ops := make([]store.BatchOperation, len(req.Operations))
+	for i, op := range req.Operations {
+		var err error
+		ops[i], err = batchOperationFromReq(op)
+		if err != nil {
+			return nil, s.err(ctx, util.ErrClient{
+				Message: fmt.Sprintf("operations[%d]: %v", i, err),
+			})
+		}
+	}
+	db, err := s.CommonOpts.getDB(ctx, req.Cluster)
+	if err != nil {
+		return nil, err
+	}
+	ctx = context.WithValue(ctx, util.ContextKeyCluster, req.Cluster)
+	for i, op := range ops {
+		if op.Object == nil {
+			continue
+		}
+		if err := restoreRedacted(ctx, db, op.Object); err != nil {
+			return nil, s.err(ctx, batchErr(store.ErrBatchOperation{Index: i, Err: err}))
+		}
+	}
+	if err := db.ApplyBatch(ctx, ops); err != nil {
+		return nil, s.err(ctx, batchErr(err))
+	}
+
+	results := make([]*v1.BatchOperationResult, len(ops))
+	for i, op := range ops {
+		results[i] = &v1.BatchOperationResult{}
+		if op.Object == nil {
+			continue
+		}
+		results[i].Item, err = structFromObject(ctx, op.Object)
+		if err != nil {
+			return nil, s.err(ctx, err)
+		}
+	}
+	return &v1.ApplyBatchResponse{
+		Results: results,
+	}, nil
+}
+
+func batchOperationFromReq(op *v1.BatchOperation) (store.BatchOperation, error) {
+	typ := model.Type(op.Type)
+	if !batchTypes[typ] {
+		return store.BatchOperation{}, fmt.Errorf("invalid type: '%v'", op.Type)
+	}
+	switch op.Op {
+	case v1.BatchOperationType_BATCH_OPERATION_TYPE_CREATE,
+		v1.BatchOperationType_BATCH_OPERATION_TYPE_UPSERT:
+		if op.Item == nil {
+			return store.BatchOperation{}, fmt.Errorf("required item is missing")
+		}
+		object, err := model.NewObject(typ)
+		if err != nil {
+			return store.BatchOperation{}, err
+		}
+		itemJSON, err := json.ProtoJSONMarshal(op.Item)
+		if err != nil {
+			return store.BatchOperation{}, err
+		}
+		if err := json.ProtoJSONUnmarshal(itemJSON, object.Resource()); err != nil {
+			return store.BatchOperation{}, fmt.Errorf("invalid item: %v", err)
+		}
+		if op.Op == v1.BatchOperationType_BATCH_OPERATION_TYPE_CREATE {
+			return store.BatchOperation{
+				Type:   store.BatchOperationCreate,
+				Object: object,
+			}, nil
+		}
+		if err := validUUID(object.ID()); err != nil {
+			return store.BatchOperation{}, err
+		}
+		return store.BatchOperation{
+			Type:   store.BatchOperationUpsert,
+			Object: object,
+		}, nil
+	case v1.BatchOperationType_BATCH_OPERATION_TYPE_DELETE:
+		if err := validUUID(op.Id); err != nil {
+			return store.BatchOperation{}, err
+		}
+		return store.BatchOperation{
+			Type:       store.BatchOperationDelete,
+			ObjectType: typ,
+			ID:         op.Id,
+		}, nil
+	default:
+		return store.BatchOperation{}, fmt.Errorf("invalid operation: '%v'", op.Op)
+	}
+}
+
+// batchErr converts the error of a failed batch operation into an error that
+// points the client at the offending operation.
+func batchErr(err error) error {
+	var opErr store.ErrBatchOperation
+	if !errors.As(err, &opErr) {
+		return err
+	}
+	prefix := fmt.Sprintf("operations[%d]", opErr.Index)
+	field := func(name string) string {
+		if name == "" {
+			return prefix + ".item"
+		}
+		return prefix + ".item." + name
+	}
+
+	switch e := opErr.Err.(type) {
+	case validation.Error:
+		errs := make([]*pbModel.ErrorDetail, len(e.Errs))
+		for i, detail := range e.Errs {
+			errs[i] = &pbModel.ErrorDetail{
+				Type:     detail.Type,
+				Field:    field(detail.Field),
+				Messages: detail.Messages,
+			}
+		}
+		return validation.Error{Errs: errs}
+	case store.ErrConstraint:
+		return validation.Error{Errs: []*pbModel.ErrorDetail{
+			{
+				Type:     pbModel.ErrorType_ERROR_TYPE_REFERENCE,
+				Field:    field(e.Index.FieldName),
+				Messages: []string{e.Error()},
+			},
+		}}
+	case util.ErrClient:
+		return util.ErrClient{Message: prefix + ": " + e.Message}
+	}
+	if errors.Is(opErr.Err, store.ErrNotFound) {
+		return util.ErrClient{Message: prefix + ": object not found"}
+	}
+	return opErr.Err
+}
+
+func structFromObject(ctx context.Context, object model.Object) (*structpb.Struct, error) {
+	if err := redact(ctx, object); err != nil {
+		return nil, err
+	}
+	itemJSON, err := json.ProtoJSONMarshal(object.Resource())
+	if err != nil {
+		return nil, err
+	}
+	item := &structpb.Struct{}
+	if err := json.ProtoJSONUnmarshal(itemJSON, item); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
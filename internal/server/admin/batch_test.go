@@ -0,0 +1,150 @@
+package admin
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gavv/httpexpect/v2"
+	"github.com/google/uuid"
+	v1 "github.com/kong/koko/internal/gen/grpc/kong/admin/model/v1"
+)
+
+func TestApplyBatch(t *testing.T) {
+	s, cleanup := setup(t)
+	defer cleanup()
+	c := httpexpect.Default(t, s.URL)
+
+	serviceID := uuid.NewString()
+	t.Run("applies all operations", func(t *testing.T) {
+		res := c.POST("/v1/batch").WithJSON(map[string]interface{}{
+			"operations": []map[string]interface{}{
+				{
+					"op":   "BATCH_OPERATION_TYPE_UPSERT",
+					"type": "service",
+					"item": map[string]interface{}{
+						"id":   serviceID,
+						"name": "foo",
+						"host": "example.com",
+					},
+				},
+				{
+					"op":   "BATCH_OPERATION_TYPE_CREATE",
+					"type": "route",
+					"item": map[string]interface{}{
+						"name":    "bar",
+						"paths":   []string{"/"},
+						"service": map[string]interface{}{"id": serviceID},
+					},
+				},
+			},
+		}).Expect()
+		res.Status(http.StatusOK)
+		results := res.JSON().Path("$.results").Array()
+		results.Length().Equal(2)
+		results.Element(0).Path("$.item.id").String().Equal(serviceID)
+		results.Element(1).Path("$.item.service.id").String().Equal(serviceID)
+		routeID := results.Element(1).Path("$.item.id").String().Raw()
+
+		c.GET("/v1/services/" + serviceID).Expect().Status(http.StatusOK)
+		c.GET("/v1/routes/" + routeID).Expect().Status(http.StatusOK)
+	})
+	t.Run("invalid operation fails the whole batch", func(t *testing.T) {
+		res := c.POST("/v1/batch").WithJSON(map[string]interface{}{
+			"operations": []map[string]interface{}{
+				{
+					"op":   "BATCH_OPERATION_TYPE_CREATE",
+					"type": "service",
+					"item": map[string]interface{}{
+						"name": "baz",
+						"host": "example.com",
+					},
+				},
+				{
+					"op":   "BATCH_OPERATION_TYPE_CREATE",
+					"type": "route",
+					"item": map[string]interface{}{
+						"name": "qux",
+					},
+				},
+			},
+		}).Expect()
+		res.Status(http.StatusBadRequest)
+		body := res.JSON().Object()
+		body.ValueEqual("message", "validation error")
+		errRes := body.Value("details").Array().Element(0).Object()
+		errRes.ValueEqual("type", v1.ErrorType_ERROR_TYPE_ENTITY.String())
+		errRes.ValueEqual("field", "operations[1].item")
+
+		c.GET("/v1/services/baz").Expect().Status(http.StatusNotFound)
+	})
+	t.Run("invalid reference fails the whole batch", func(t *testing.T) {
+		res := c.POST("/v1/batch").WithJSON(map[string]interface{}{
+			"operations": []map[string]interface{}{
+				{
+					"op":   "BATCH_OPERATION_TYPE_DELETE",
+					"type": "service",
+					"id":   serviceID,
+				},
+				{
+					"op":   "BATCH_OPERATION_TYPE_CREATE",
+					"type": "route",
+					"item": map[string]interface{}{
+						"paths":   []string{"/foo"},
+						"service": map[string]interface{}{"id": uuid.NewString()},
+					},
+				},
+			},
+		}).Expect()
+		res.Status(http.StatusBadRequest)
+		body := res.JSON().Object()
+		errRes := body.Value("details").Array().Element(0).Object()
+		errRes.ValueEqual("type", v1.ErrorType_ERROR_TYPE_REFERENCE.String())
+		errRes.ValueEqual("field", "operations[1].item.service.id")
+
+		c.GET("/v1/services/" + serviceID).Expect().Status(http.StatusOK)
+	})
+	t.Run("deleting a non-existent object fails", func(t *testing.T) {
+		res := c.POST("/v1/batch").WithJSON(map[string]interface{}{
+			"operations": []map[string]interface{}{
+				{
+					"op":   "BATCH_OPERATION_TYPE_DELETE",
+					"type": "service",
+					"id":   uuid.NewString(),
+				},
+			},
+		}).Expect()
+		res.Status(http.StatusBadRequest)
+		res.JSON().Object().ValueEqual("message", "operations[0]: object not found")
+	})
+	t.Run("unsupported type fails", func(t *testing.T) {
+		for _, typ := range []string{"node", "node-status", "hash", "store_event"} {
+			res := c.POST("/v1/batch").WithJSON(map[string]interface{}{
+				"operations": []map[string]interface{}{
+					{
+						"op":   "BATCH_OPERATION_TYPE_DELETE",
+						"type": typ,
+						"id":   uuid.NewString(),
+					},
+				},
+			}).Expect()
+			res.Status(http.StatusBadRequest)
+			res.JSON().Object().ValueEqual("message",
+				fmt.Sprintf("operations[0]: invalid type: '%s'", typ))
+		}
+	})
+	t.Run("upsert requires a valid id", func(t *testing.T) {
+		res := c.POST("/v1/batch").WithJSON(map[string]interface{}{
+			"operations": []map[string]interface{}{
+				{
+					"op":   "BATCH_OPERATION_TYPE_UPSERT",
+					"type": "service",
+					"item": map[string]interface{}{
+						"host": "example.com",
+					},
+				},
+			},
+		}).Expect()
+		res.Status(http.StatusBadRequest)
+	})
+}
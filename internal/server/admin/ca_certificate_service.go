@@ -30,11 +30,14 @@ func (s *CACertificateService) GetCACertificate(ctx context.Context,
 		return nil, err
 	}
 	result := resource.NewCACertificate()
+	var revision uint64
 	s.logger(ctx).With(zap.String("id", req.Id)).Debug("reading CA certificate by id")
-	err = db.Read(ctx, result, store.GetByID(req.Id))
+	err = db.Read(ctx, result, store.GetByID(req.Id),
+		store.ReadRevision(&revision))
 	if err != nil {
 		return nil, s.err(ctx, err)
 	}
+	util.SetETag(ctx, revisionETag(revision))
 	return &v1.GetCACertificateResponse{
 		Item: result.CACertificate,
 	}, nil
@@ -49,10 +52,12 @@ func (s *CACertificateService) CreateCACertificate(ctx context.Context,
 	}
 	res := resource.NewCACertificate()
 	res.CACertificate = req.Item
-	if err := db.Create(ctx, res); err != nil {
+	var revision uint64
+	if err := db.Create(ctx, res, store.WriteRevision(&revision)); err != nil {
 		return nil, s.err(ctx, err)
 	}
 	util.SetHeader(ctx, http.StatusCreated)
+	util.SetETag(ctx, revisionETag(revision))
 	return &v1.CreateCACertificateResponse{
 		Item: res.CACertificate,
 	}, nil
@@ -64,15 +69,22 @@ func (s *CACertificateService) UpsertCACertificate(ctx context.Context,
 	if err := validUUID(req.Item.Id); err != nil {
 		return nil, s.err(ctx, err)
 	}
+	expected, err := expectedRevision(ctx, req.ExpectedRevision)
+	if err != nil {
+		return nil, s.err(ctx, err)
+	}
 	db, err := s.CommonOpts.getDB(ctx, req.Cluster)
 	if err != nil {
 		return nil, err
 	}
 	res := resource.NewCACertificate()
 	res.CACertificate = req.Item
-	if err := db.Upsert(ctx, res); err != nil {
+	var revision uint64
+	if err := db.Upsert(ctx, res, store.UpsertIfRevision(expected),
+		store.WriteRevision(&revision)); err != nil {
 		return nil, s.err(ctx, err)
 	}
+	util.SetETag(ctx, revisionETag(revision))
 	return &v1.UpsertCACertificateResponse{
 		Item: res.CACertificate,
 	}, nil
@@ -84,17 +96,27 @@ func (s *CACertificateService) DeleteCACertificate(ctx context.Context,
 	if err := validUUID(req.Id); err != nil {
 		return nil, s.err(ctx, err)
 	}
+	expected, err := expectedRevision(ctx, req.ExpectedRevision)
+	if err != nil {
+		return nil, s.err(ctx, err)
+	}
+	var deleted []store.Dependent
+	opts, err := deleteOpts(req.Mode, &deleted)
+	if err != nil {
+		return nil, s.err(ctx, err)
+	}
 	db, err := s.CommonOpts.getDB(ctx, req.Cluster)
 	if err != nil {
 		return nil, err
 	}
-	err = db.Delete(ctx, store.DeleteByID(req.Id),
-		store.DeleteByType(resource.TypeCACertificate))
+	err = db.Delete(ctx, append(opts, store.DeleteByID(req.Id),
+		store.DeleteByType(resource.TypeCACertificate), store.DeleteIfRevision(expected))...)
 	if err != nil {
 		return nil, s.err(ctx, err)
 	}
-	util.SetHeader(ctx, http.StatusNoContent)
-	return &v1.DeleteCACertificateResponse{}, nil
+	return &v1.DeleteCACertificateResponse{
+		Deleted: deletedDependents(ctx, req.Mode, deleted),
+	}, nil
 }
 
 func (s *CACertificateService) ListCACertificates(ctx context.Context,
@@ -105,16 +127,19 @@ func (s *CACertificateService) ListCACertificates(ctx context.Context,
 		return nil, err
 	}
 	list := resource.NewList(resource.TypeCACertificate)
-	listOptFns, err := ListOptsFromReq(req.Page)
+	listOptFns, err := ListOptsFromReq(ctx, resource.TypeCACertificate, req.Page)
 	if err != nil {
 		return nil, s.err(ctx, err)
 	}
+	revisions := map[string]uint64{}
+	listOptFns = append(listOptFns, store.ListRevisions(revisions))
 	if err := db.List(ctx, list, listOptFns...); err != nil {
 		return nil, s.err(ctx, err)
 	}
+	util.SetETag(ctx, listETag(revisions))
 	return &v1.ListCACertificatesResponse{
 		Items: caCertificatesFromObjects(list.GetAll()),
-		Page:  getPaginationResponse(list.GetTotalCount(), list.GetNextPage()),
+		Page:  listPaginationResponse(list),
 	}, nil
 }
 
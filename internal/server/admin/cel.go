@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"sync"
 
 	"github.com/google/cel-go/cel"
 	"github.com/google/cel-go/checker"
@@ -13,20 +14,20 @@ import (
 	"github.com/google/cel-go/common/overloads"
 	"github.com/google/cel-go/parser"
 	pbModel "github.com/kong/koko/internal/gen/grpc/kong/admin/model/v1"
+	"github.com/kong/koko/internal/model"
+	"github.com/kong/koko/internal/model/json/generator"
 	"github.com/kong/koko/internal/model/json/validation"
-	"github.com/samber/lo"
 	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
 )
 
 var (
-	// Exposes a shared CEL environment, used for filtering based upon tags.
-	//
-	// TODO(tjasko): Yes, this implementation is rather simple, and was done
-	//  purposefully to reduce complexity. In the event we were to introduce
-	//  more advanced filtering, we'd likely want to create a CEL environment
-	//  for each resource (as certain fields won't exist on all resources).
+	// Exposes a shared CEL environment, which declares the supported functions & operators.
+	// It's extended for each resource type in order to declare the fields of the resource.
 	celEnv *cel.Env
 
+	// celEnvs caches the CEL environment of each resource type, see celEnvFor().
+	celEnvs sync.Map
+
 	celUndeclaredReferencePattern = regexp.MustCompile("undeclared reference to '(.*)'")
 )
 
@@ -42,9 +43,62 @@ func init() {
 	}
 }
 
-// validateFilter attempts to extract a filter expression from the pagination request.
+// celEnvFor returns the CEL environment used to filter resources of the given type. A variable is
+// declared for each of the top-level properties of the JSON schema registered for the type. When
+// no schema is registered, a validation.Error is returned, as filtering is not supported.
+func celEnvFor(typ model.Type) (*cel.Env, error) {
+	if env, ok := celEnvs.Load(typ); ok {
+		return env.(*cel.Env), nil
+	}
+
+	schema, ok := generator.DefaultRegistry.Schema.Definitions[string(typ)]
+	if !ok {
+		return nil, validation.Error{Errs: []*pbModel.ErrorDetail{{
+			Type:     pbModel.ErrorType_ERROR_TYPE_FIELD,
+			Field:    "page.filter",
+			Messages: []string{"filtering is not supported"},
+		}}}
+	}
+	declarations := make([]*exprpb.Decl, 0, len(schema.Properties))
+	for name, property := range schema.Properties {
+		declarations = append(declarations, decls.NewVar(name, getCELType(property)))
+	}
+	env, err := celEnv.Extend(cel.Declarations(declarations...))
+	if err != nil {
+		return nil, fmt.Errorf("unable to establish CEL environment for type '%s': %w", typ, err)
+	}
+
+	celEnvs.Store(typ, env)
+	return env, nil
+}
+
+// getCELType returns the CEL type of values described by the given JSON schema. As fields of objects
+// are not known ahead of time, objects are maps of dynamic values, e.g.: `config.minute == 5`.
+func getCELType(schema *generator.Schema) *exprpb.Type {
+	switch schema.Type {
+	case "string":
+		return decls.String
+	case "integer":
+		return decls.Int
+	case "number":
+		return decls.Double
+	case "boolean":
+		return decls.Bool
+	case "array":
+		if schema.Items == nil {
+			return decls.NewListType(decls.Dyn)
+		}
+		return decls.NewListType(getCELType(schema.Items))
+	case "object":
+		return decls.NewMapType(decls.String, decls.Dyn)
+	default:
+		return decls.Dyn
+	}
+}
+
+// validateFilter attempts to extract a type-checked filter expression from the pagination request.
 // When an expression is invalid/unsupported, a validation.Error will be returned.
-func validateFilter(celEnv *cel.Env, filter string) (*exprpb.Expr, error) {
+func validateFilter(celEnv *cel.Env, filter string) (*exprpb.CheckedExpr, error) {
 	// We're limiting the max filter length to 2048 characters, in order to comply with the rest of Koko's
 	// API, as we enforce limits on nearly all user input. 2048 characters is a seemingly generous amount
 	// that attempts to not hinder the user, and allows us to impose some sort of upper limit.
@@ -88,8 +142,7 @@ func validateFilter(celEnv *cel.Env, filter string) (*exprpb.Expr, error) {
 
 	// Now that we know we have a syntactically correct CEL expression, walk the AST tree & validate
 	// the entirety of the expression, against what we're supporting in the CEL specification.
-	expr := ast.Expr()
-	if err := validateExpression(expr, nil, nil); err != nil {
+	if err := validateExpression(ast.Expr()); err != nil {
 		return nil, validation.Error{Errs: []*pbModel.ErrorDetail{{
 			Type:     pbModel.ErrorType_ERROR_TYPE_FIELD,
 			Field:    "page.filter",
@@ -97,99 +150,67 @@ func validateFilter(celEnv *cel.Env, filter string) (*exprpb.Expr, error) {
 		}}}
 	}
 
-	return expr, nil
+	// Values of map fields are dynamic, so the result of the expression may only be known when evaluated.
+	if resultType := ast.ResultType(); resultType.GetPrimitive() != exprpb.Type_BOOL && resultType.GetDyn() == nil {
+		return nil, validation.Error{Errs: []*pbModel.ErrorDetail{{
+			Type:     pbModel.ErrorType_ERROR_TYPE_FIELD,
+			Field:    "page.filter",
+			Messages: []string{"invalid filter expression: expression must evaluate to a boolean"},
+		}}}
+	}
+
+	return cel.AstToCheckedExpr(ast)
 }
 
-// validateExpression is a recursive function used to validate a user-inputted CEL expression. When called
-// outside this function, the `parentExpr` expression & `usedOperators` map should not be provided.
+// validateExpression is a recursive function used to validate a user-inputted CEL expression, which
+// contains specific business logic to our particular CEL implementation, as we currently limit what
+// expressions are supported. The supported functions & operators are limited by the declarations
+// of the CEL environment instead.
 //
 // In the event the expression is invalid, a friendly error will be returned, that then can
 // then be safely converted into a validation.Error if you wish.
-func validateExpression(expr *exprpb.Expr, parentExpr *exprpb.Expr, usedOperators map[string]bool) error {
-	if usedOperators == nil {
-		usedOperators = make(map[string]bool)
-	}
-
-	// Ensure the expression that was parsed is supported.
-	if err := validateExpressionKind(expr, parentExpr, usedOperators); err != nil {
-		return err
-	}
-
-	// Validate calls to predefined functions & operators.
-	if ce := expr.GetCallExpr(); ce != nil {
-		// Keep track of the operators that we do not support combining. This prevents queries like:
-		// `("tag1" in tags && "tag2" in tags) || ("tag3" in tags && "tag4" in tags)`.
-		if lo.Contains([]string{operators.LogicalAnd, operators.LogicalOr}, ce.Function) {
-			usedOperators[ce.Function] = true
-		}
-
-		// CEL prevents infinite recursion (checked during expression
-		// compilation), so we don't have to worry about that here.
-		for _, arg := range ce.Args {
-			if err := validateExpression(arg, expr, usedOperators); err != nil {
-				return err
-			}
-		}
-	}
-
-	// Not that is impossible to support having multiple logical operators, it just adds additional complexity.
-	if len(usedOperators) > 1 {
-		return errors.New("multiple logical operators are not supported in expressions")
-	}
-
-	return nil
-}
-
-// validateExpressionKind contains specific business logic to our particular CEL implementation,
-// as we currently limit what expressions are supported. This will also recursively validate other
-// expressions, that are generated when utilizing comprehension features (aka, macros).
-func validateExpressionKind(expr *exprpb.Expr, parentExpr *exprpb.Expr, usedOperators map[string]bool) error {
+func validateExpression(expr *exprpb.Expr) error {
 	var unsupportedExpression string
 	switch exprKind := expr.GetExprKind().(type) {
 	case *exprpb.Expr_ConstExpr, *exprpb.Expr_IdentExpr:
 		// No-op as both constant expressions (e.g.: `tag1`) & identifiers (`tags`) are supported.
 		break
 	case *exprpb.Expr_CallExpr:
-		// Only allow "logical not" on standard overloads (which are used internally by CEL during loop conditions).
-		if parentExpr != nil {
-			if parentExprKind, ok := parentExpr.GetExprKind().(*exprpb.Expr_CallExpr); ok {
-				if parentExprKind.CallExpr.Function == operators.NotStrictlyFalse {
-					break
-				}
+		// CEL prevents infinite recursion (checked during expression
+		// compilation), so we don't have to worry about that here.
+		if exprKind.CallExpr.Target != nil {
+			if err := validateExpression(exprKind.CallExpr.Target); err != nil {
+				return err
 			}
 		}
-		if exprKind.CallExpr.Function == operators.LogicalNot {
-			return fmt.Errorf("invalid filter expression: undeclared reference to '%s'", operators.LogicalNot)
-		}
-	case *exprpb.Expr_ComprehensionExpr:
-		if exprKind.ComprehensionExpr.IterRange != nil {
-			// Comprehension is only partially supported, as we force the user to provide a list when
-			// writing an expression that uses a macro, e.g.: `["tag1", "tag2"].all(x, x in tags)`.
-			if _, ok := exprKind.ComprehensionExpr.IterRange.GetExprKind().(*exprpb.Expr_ListExpr); !ok {
-				return errors.New("macros must range upon a provided list value, not a variable")
-			}
-
-			// Validate that the range to iterate on is of the proper type (a list).
-			if err := validateExpression(exprKind.ComprehensionExpr.IterRange, expr, usedOperators); err != nil {
+		for _, arg := range exprKind.CallExpr.Args {
+			if err := validateExpression(arg); err != nil {
 				return err
 			}
 		}
+	case *exprpb.Expr_ComprehensionExpr:
+		// Comprehension is only partially supported, as we force the user to provide a list when
+		// writing an expression that uses a macro, e.g.: `["tag1", "tag2"].all(x, x in tags)`.
+		if _, ok := exprKind.ComprehensionExpr.IterRange.GetExprKind().(*exprpb.Expr_ListExpr); !ok {
+			return errors.New("macros must range upon a provided list value, not a variable")
+		}
+		if err := validateExpression(exprKind.ComprehensionExpr.IterRange); err != nil {
+			return err
+		}
 
-		// Validate the conditional logic used (which is internally generated). This specifically
-		// allows us to support negations (which is used by the auto-generated `__result__` identifier),
-		// however return an error when they're used for anything else, e.g.: `!("tag1" in tags)`.
-		if exprKind.ComprehensionExpr.LoopCondition != nil {
-			if err := validateExpression(exprKind.ComprehensionExpr.LoopCondition, expr, usedOperators); err != nil {
-				return err
-			}
+		// The loop step (which is internally generated) contains the condition provided by the user.
+		if err := validateExpression(exprKind.ComprehensionExpr.LoopStep); err != nil {
+			return err
 		}
 	case *exprpb.Expr_SelectExpr:
-		// As there is no current use-case for field selection, e.g.: `field.key`, it's not supported.
-		unsupportedExpression = "field selection"
+		// Field selection is supported on map fields, e.g.: `config.minute`.
+		return validateExpression(exprKind.SelectExpr.Operand)
 	case *exprpb.Expr_ListExpr:
-		// List expressions are supported with comprehension (the `list()` macro), however otherwise, they are not.
-		if _, ok := parentExpr.GetExprKind().(*exprpb.Expr_ComprehensionExpr); !ok {
-			unsupportedExpression = "list"
+		// Lists are supported, e.g.: `protocol in ["http", "https"]`.
+		for _, element := range exprKind.ListExpr.Elements {
+			if err := validateExpression(element); err != nil {
+				return err
+			}
 		}
 	case *exprpb.Expr_StructExpr:
 		// Per the docs of `google.api.expr.v1alpha1.CreateStruct.message_name`,
@@ -210,6 +231,55 @@ func validateExpressionKind(expr *exprpb.Expr, parentExpr *exprpb.Expr, usedOper
 	return nil
 }
 
+// filterReferences returns the paths of the fields referred to by a filter
+// expression, e.g.: ["config", "minute"] for `config.minute == 5`.
+func filterReferences(expr *exprpb.Expr) [][]string {
+	if path := referencePath(expr); path != nil {
+		return [][]string{path}
+	}
+	var res [][]string
+	switch exprKind := expr.GetExprKind().(type) {
+	case *exprpb.Expr_CallExpr:
+		if exprKind.CallExpr.Target != nil {
+			res = append(res, filterReferences(exprKind.CallExpr.Target)...)
+		}
+		for _, arg := range exprKind.CallExpr.Args {
+			res = append(res, filterReferences(arg)...)
+		}
+	case *exprpb.Expr_ComprehensionExpr:
+		comprehension := exprKind.ComprehensionExpr
+		for _, expr := range []*exprpb.Expr{
+			comprehension.IterRange, comprehension.AccuInit, comprehension.LoopCondition,
+			comprehension.LoopStep, comprehension.Result,
+		} {
+			if expr != nil {
+				res = append(res, filterReferences(expr)...)
+			}
+		}
+	case *exprpb.Expr_SelectExpr:
+		res = append(res, filterReferences(exprKind.SelectExpr.Operand)...)
+	case *exprpb.Expr_ListExpr:
+		for _, element := range exprKind.ListExpr.Elements {
+			res = append(res, filterReferences(element)...)
+		}
+	}
+	return res
+}
+
+// referencePath returns the path of the field selected by expr, or nil if
+// expr does not select a field, e.g.: ["config", "minute"] for `config.minute`.
+func referencePath(expr *exprpb.Expr) []string {
+	switch exprKind := expr.GetExprKind().(type) {
+	case *exprpb.Expr_IdentExpr:
+		return []string{exprKind.IdentExpr.Name}
+	case *exprpb.Expr_SelectExpr:
+		if path := referencePath(exprKind.SelectExpr.Operand); path != nil {
+			return append(path, exprKind.SelectExpr.Field)
+		}
+	}
+	return nil
+}
+
 // Modified from cel-go's checker.init() function, which sets up CEL's default declarations.
 func getCELDeclarations() []*exprpb.Decl {
 	// Some shortcuts we use when building declarations.
@@ -217,10 +287,7 @@ func getCELDeclarations() []*exprpb.Decl {
 	typeParamAList := []string{"A"}
 	listOfA := decls.NewListType(paramA)
 
-	return []*exprpb.Decl{
-		// Our own variables that are supported.
-		decls.NewVar("tags", decls.NewListType(decls.String)),
-
+	declarations := []*exprpb.Decl{
 		// Only string constants are supported right now.
 		decls.NewVar(checker.FormatCheckedType(decls.String), decls.NewTypeType(decls.String)),
 
@@ -243,13 +310,26 @@ func getCELDeclarations() []*exprpb.Decl {
 			[]*exprpb.Type{decls.Bool},
 			decls.Bool,
 		)),
-		// "Logical not" is only supported for the `exists()` macro.
 		decls.NewFunction(operators.LogicalNot, decls.NewOverload(
 			overloads.LogicalNot,
 			[]*exprpb.Type{decls.Bool},
 			decls.Bool,
 		)),
 
+		// Equality.
+		decls.NewFunction(operators.Equals, decls.NewParameterizedOverload(
+			overloads.Equals,
+			[]*exprpb.Type{paramA, paramA},
+			decls.Bool,
+			typeParamAList,
+		)),
+		decls.NewFunction(operators.NotEquals, decls.NewParameterizedOverload(
+			overloads.NotEquals,
+			[]*exprpb.Type{paramA, paramA},
+			decls.Bool,
+			typeParamAList,
+		)),
+
 		// Collections.
 		decls.NewFunction(operators.In, decls.NewParameterizedOverload(
 			overloads.InList,
@@ -257,5 +337,52 @@ func getCELDeclarations() []*exprpb.Decl {
 			decls.Bool,
 			typeParamAList,
 		)),
+
+		// Strings.
+		decls.NewFunction(overloads.StartsWith, decls.NewInstanceOverload(
+			overloads.StartsWithString,
+			[]*exprpb.Type{decls.String, decls.String},
+			decls.Bool,
+		)),
+		decls.NewFunction(overloads.EndsWith, decls.NewInstanceOverload(
+			overloads.EndsWithString,
+			[]*exprpb.Type{decls.String, decls.String},
+			decls.Bool,
+		)),
+		decls.NewFunction(overloads.Contains, decls.NewInstanceOverload(
+			overloads.ContainsString,
+			[]*exprpb.Type{decls.String, decls.String},
+			decls.Bool,
+		)),
 	}
+
+	// Ordering, which is only supported between values of the same type.
+	for _, comparison := range []struct {
+		operator                                  string
+		intOverload, uintOverload, doubleOverload string
+		stringOverload                            string
+	}{
+		{operators.Less, overloads.LessInt64, overloads.LessUint64, overloads.LessDouble, overloads.LessString},
+		{
+			operators.LessEquals, overloads.LessEqualsInt64, overloads.LessEqualsUint64,
+			overloads.LessEqualsDouble, overloads.LessEqualsString,
+		},
+		{
+			operators.Greater, overloads.GreaterInt64, overloads.GreaterUint64,
+			overloads.GreaterDouble, overloads.GreaterString,
+		},
+		{
+			operators.GreaterEquals, overloads.GreaterEqualsInt64, overloads.GreaterEqualsUint64,
+			overloads.GreaterEqualsDouble, overloads.GreaterEqualsString,
+		},
+	} {
+		declarations = append(declarations, decls.NewFunction(comparison.operator,
+			decls.NewOverload(comparison.intOverload, []*exprpb.Type{decls.Int, decls.Int}, decls.Bool),
+			decls.NewOverload(comparison.uintOverload, []*exprpb.Type{decls.Uint, decls.Uint}, decls.Bool),
+			decls.NewOverload(comparison.doubleOverload, []*exprpb.Type{decls.Double, decls.Double}, decls.Bool),
+			decls.NewOverload(comparison.stringOverload, []*exprpb.Type{decls.String, decls.String}, decls.Bool),
+		))
+	}
+
+	return declarations
 }
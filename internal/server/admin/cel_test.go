@@ -19,6 +19,9 @@ func Test_validateFilter(t *testing.T) {
 	testEnv, err := celEnv.Extend(
 		cel.Types(&pbModel.Consumer{}),
 		cel.Declarations(
+			decls.NewVar("tags", decls.NewListType(decls.String)),
+			decls.NewVar("name", decls.String),
+			decls.NewVar("port", decls.Int),
 			decls.NewVar("mapData", decls.NewMapType(decls.String, decls.String)),
 			decls.NewVar("consumer", decls.NewObjectType("kong.admin.model.v1.Consumer")),
 		),
@@ -38,6 +41,25 @@ func Test_validateFilter(t *testing.T) {
 		// Functionally equivalent to the "logical or" test.
 		{name: "list.exists()", expression: `["tag1", "tag2"].exists(x, x in tags)`},
 		{name: "list.exists() containing spaces", expression: `["tag1 with spaces"].exists(x, x in tags)`},
+		{name: "mixed operators: #1", expression: `("tag1" in tags && "tag2" in tags) || "tag3" in tags`},
+		{
+			name:       "mixed operators: #2",
+			expression: `("tag1" in tags && "tag2" in tags) && ("tag3" in tags || "tag4" in tags)`,
+		},
+		{name: "operator: negate", expression: `!("tag1" in tags)`},
+		{name: "operator: equals", expression: `name == "foo"`},
+		{name: "operator: not equals", expression: `port != 443`},
+		{name: "operator: less than", expression: `port < 443`},
+		{name: "operator: less than or equal", expression: `port <= 443`},
+		{name: "operator: greater than", expression: `port > 443`},
+		{name: "operator: greater than or equals", expression: `port >= 443`},
+		{name: "operator: in list", expression: `name in ["foo", "bar"]`},
+		{name: "string: startsWith", expression: `name.startsWith("billing-")`},
+		{name: "string: endsWith", expression: `name.endsWith("-billing")`},
+		{name: "string: contains", expression: `name.contains("billing")`},
+		{name: "field selection", expression: `mapData.key1 == "value"`},
+		{name: "field selection on message", expression: `consumer.id == "value"`},
+		{name: "macro with condition on fields", expression: `["foo", "bar"].exists(x, name.startsWith(x))`},
 		{
 			name:       "exactly max length",
 			expression: fmt.Sprintf(`%q in tags`, strings.Repeat("x", 2038)),
@@ -67,19 +89,24 @@ func Test_validateFilter(t *testing.T) {
 
 		// Unsupported expressions.
 		{
-			name:          "macro ranging on identifier",
-			expression:    `tags.all(x, x in ["tag1", "tag2"])`,
-			expectedError: "macros must range upon a provided list value, not a variable",
+			name:          "comparison between types",
+			expression:    `port == "443"`,
+			expectedError: "invalid filter expression: found no matching overload for '_==_' applied to '(int, string)'",
 		},
 		{
-			name:          "mixed operators: #1",
-			expression:    `("tag1" in tags && "tag2" in tags) || "tag3" in tags`,
-			expectedError: "multiple logical operators are not supported in expressions",
+			name:          "non-boolean expression",
+			expression:    `name`,
+			expectedError: "invalid filter expression: expression must evaluate to a boolean",
 		},
 		{
-			name:          "mixed operators: #2",
-			expression:    `("tag1" in tags && "tag2" in tags) && ("tag3" in tags || "tag4" in tags)`,
-			expectedError: "multiple logical operators are not supported in expressions",
+			name:          "list creation",
+			expression:    `["value"]`,
+			expectedError: "invalid filter expression: expression must evaluate to a boolean",
+		},
+		{
+			name:          "macro ranging on identifier",
+			expression:    `tags.all(x, x in ["tag1", "tag2"])`,
+			expectedError: "macros must range upon a provided list value, not a variable",
 		},
 		{
 			name:          "list indexing",
@@ -101,16 +128,6 @@ func Test_validateFilter(t *testing.T) {
 			expression:    `google.protobuf.Int32Value{value: 1}`,
 			expectedError: `unsupported expression: message (google.protobuf.Int32Value)`,
 		},
-		{
-			name:          "field selection",
-			expression:    `consumer.id`,
-			expectedError: `unsupported expression: field selection`,
-		},
-		{
-			name:          "list creation",
-			expression:    `["value"]`,
-			expectedError: `unsupported expression: list`,
-		},
 
 		// Unsupported operators.
 		{
@@ -118,41 +135,6 @@ func Test_validateFilter(t *testing.T) {
 			expression:    `(("tag1" in tags) ? 1 : 2)`,
 			expectedError: "invalid filter expression: undeclared reference to '_?_:_'",
 		},
-		{
-			name:          "operator: negate",
-			expression:    `!("tag1" in tags)`,
-			expectedError: "invalid filter expression: undeclared reference to '!_'",
-		},
-		{
-			name:          "operator: equals",
-			expression:    `1 == 2`,
-			expectedError: "invalid filter expression: undeclared reference to '_==_'",
-		},
-		{
-			name:          "operator: not equals",
-			expression:    "1 != 2",
-			expectedError: "invalid filter expression: undeclared reference to '_!=_'",
-		},
-		{
-			name:          "operator: less than",
-			expression:    "1 < 2",
-			expectedError: "invalid filter expression: undeclared reference to '_<_'",
-		},
-		{
-			name:          "operator: less than or equal",
-			expression:    "1 <= 2",
-			expectedError: "invalid filter expression: undeclared reference to '_<=_'",
-		},
-		{
-			name:          "operator: greater than",
-			expression:    "1 > 2",
-			expectedError: "invalid filter expression: undeclared reference to '_>_'",
-		},
-		{
-			name:          "operator: greater than or equals",
-			expression:    "1 >= 2",
-			expectedError: "invalid filter expression: undeclared reference to '_>=_'",
-		},
 
 		// All arithmetic is unsupported.
 		{
@@ -30,8 +30,10 @@ func (s *CertificateService) GetCertificate(ctx context.Context,
 		return nil, err
 	}
 	result := resource.NewCertificate()
+	var revision uint64
 	s.logger(ctx).With(zap.String("id", req.Id)).Debug("reading certificate by id")
-	err = db.Read(ctx, result, store.GetByID(req.Id))
+	err = db.Read(ctx, result, store.GetByID(req.Id),
+		store.ReadRevision(&revision))
 	if err != nil {
 		return nil, s.err(ctx, err)
 	}
@@ -39,6 +41,10 @@ func (s *CertificateService) GetCertificate(ctx context.Context,
 	if err := addSNIMetadata(ctx, result.Certificate, db); err != nil {
 		return nil, err
 	}
+	if err := redact(ctx, result); err != nil {
+		return nil, s.err(ctx, err)
+	}
+	util.SetETag(ctx, revisionETag(revision))
 	return &v1.GetCertificateResponse{
 		Item: result.Certificate,
 	}, nil
@@ -53,10 +59,15 @@ func (s *CertificateService) CreateCertificate(ctx context.Context,
 	}
 	res := resource.NewCertificate()
 	res.Certificate = req.Item
-	if err := db.Create(ctx, res); err != nil {
+	var revision uint64
+	if err := db.Create(ctx, res, store.WriteRevision(&revision)); err != nil {
+		return nil, s.err(ctx, err)
+	}
+	if err := redact(ctx, res); err != nil {
 		return nil, s.err(ctx, err)
 	}
 	util.SetHeader(ctx, http.StatusCreated)
+	util.SetETag(ctx, revisionETag(revision))
 	return &v1.CreateCertificateResponse{
 		Item: res.Certificate,
 	}, nil
@@ -68,15 +79,28 @@ func (s *CertificateService) UpsertCertificate(ctx context.Context,
 	if err := validUUID(req.Item.Id); err != nil {
 		return nil, s.err(ctx, err)
 	}
+	expected, err := expectedRevision(ctx, req.ExpectedRevision)
+	if err != nil {
+		return nil, s.err(ctx, err)
+	}
 	db, err := s.CommonOpts.getDB(ctx, req.Cluster)
 	if err != nil {
 		return nil, err
 	}
 	res := resource.NewCertificate()
 	res.Certificate = req.Item
-	if err := db.Upsert(ctx, res); err != nil {
+	if err := restoreRedacted(ctx, db, res); err != nil {
+		return nil, s.err(ctx, err)
+	}
+	var revision uint64
+	if err := db.Upsert(ctx, res, store.UpsertIfRevision(expected),
+		store.WriteRevision(&revision)); err != nil {
+		return nil, s.err(ctx, err)
+	}
+	if err := redact(ctx, res); err != nil {
 		return nil, s.err(ctx, err)
 	}
+	util.SetETag(ctx, revisionETag(revision))
 	return &v1.UpsertCertificateResponse{
 		Item: res.Certificate,
 	}, nil
@@ -88,17 +112,27 @@ func (s *CertificateService) DeleteCertificate(ctx context.Context,
 	if err := validUUID(req.Id); err != nil {
 		return nil, s.err(ctx, err)
 	}
+	expected, err := expectedRevision(ctx, req.ExpectedRevision)
+	if err != nil {
+		return nil, s.err(ctx, err)
+	}
+	var deleted []store.Dependent
+	opts, err := deleteOpts(req.Mode, &deleted)
+	if err != nil {
+		return nil, s.err(ctx, err)
+	}
 	db, err := s.CommonOpts.getDB(ctx, req.Cluster)
 	if err != nil {
 		return nil, err
 	}
-	err = db.Delete(ctx, store.DeleteByID(req.Id),
-		store.DeleteByType(resource.TypeCertificate))
+	err = db.Delete(ctx, append(opts, store.DeleteByID(req.Id),
+		store.DeleteByType(resource.TypeCertificate), store.DeleteIfRevision(expected))...)
 	if err != nil {
 		return nil, s.err(ctx, err)
 	}
-	util.SetHeader(ctx, http.StatusNoContent)
-	return &v1.DeleteCertificateResponse{}, nil
+	return &v1.DeleteCertificateResponse{
+		Deleted: deletedDependents(ctx, req.Mode, deleted),
+	}, nil
 }
 
 func (s *CertificateService) ListCertificates(ctx context.Context,
@@ -109,10 +143,12 @@ func (s *CertificateService) ListCertificates(ctx context.Context,
 		return nil, err
 	}
 	list := resource.NewList(resource.TypeCertificate)
-	listOptFns, err := ListOptsFromReq(req.Page)
+	listOptFns, err := ListOptsFromReq(ctx, resource.TypeCertificate, req.Page)
 	if err != nil {
 		return nil, s.err(ctx, err)
 	}
+	revisions := map[string]uint64{}
+	listOptFns = append(listOptFns, store.ListRevisions(revisions))
 	if err := db.List(ctx, list, listOptFns...); err != nil {
 		return nil, s.err(ctx, err)
 	}
@@ -120,9 +156,13 @@ func (s *CertificateService) ListCertificates(ctx context.Context,
 	if err != nil {
 		return nil, err
 	}
+	if err := redact(ctx, list.GetAll()...); err != nil {
+		return nil, s.err(ctx, err)
+	}
+	util.SetETag(ctx, listETag(revisions))
 	return &v1.ListCertificatesResponse{
 		Items: allCerts,
-		Page:  getPaginationResponse(list.GetTotalCount(), list.GetNextPage()),
+		Page:  listPaginationResponse(list),
 	}, nil
 }
 
@@ -197,7 +237,7 @@ func getSNIsPage(
 ) ([]*pb.SNI, int, error) {
 	listFn := []store.ListOptsFunc{}
 	listFn = append(listFn, store.ListFor(resource.TypeCertificate, certID))
-	listOptFns, err := ListOptsFromReq(&pb.PaginationRequest{
+	listOptFns, err := ListOptsFromReq(ctx, resource.TypeSNI, &pb.PaginationRequest{
 		Number: page,
 		Size:   store.MaxPageSize,
 	})
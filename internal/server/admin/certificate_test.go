@@ -385,7 +385,7 @@ func TestCertificateCreate(t *testing.T) {
 		res.Header("grpc-metadata-koko-status-code").Empty()
 		body := res.JSON().Path("$.item").Object()
 		body.Value("cert").String().Equal(certificate.Cert)
-		body.Value("key").String().Equal(certificate.Key)
+		body.Value("key").String().Equal(redactedValue)
 		body.Path("$.metadata").Object().ContainsKey("subject")
 		body.Path("$.metadata").Object().ContainsKey("issuer")
 		body.Path("$.metadata").Object().ContainsKey("expiry")
@@ -400,7 +400,7 @@ func TestCertificateCreate(t *testing.T) {
 		res.Header("grpc-metadata-koko-status-code").Empty()
 		body := res.JSON().Path("$.item").Object()
 		body.Value("cert").String().Equal(certificate.Cert)
-		body.Value("key").String().Equal(certificate.Key)
+		body.Value("key").String().Equal(redactedValue)
 		body.Path("$.metadata").Object().Value("subject").Equal("CN=example.com")
 		body.Path("$.metadata").Object().Value("issuer").Equal("CN=example.com")
 		body.Path("$.metadata").Object().ContainsKey("expiry")
@@ -456,7 +456,7 @@ func TestCertificateUpsert(t *testing.T) {
 		body := res.JSON().Path("$.item").Object()
 		body.Value("id").String().Equal(id)
 		body.Value("cert").String().Equal(certificate.Cert)
-		body.Value("key").String().Equal(certificate.Key)
+		body.Value("key").String().Equal(redactedValue)
 		certificate.Id = body.Value("id").String().Raw()
 	})
 	t.Run("upsert an existing certificate succeeds", func(t *testing.T) {
@@ -481,9 +481,9 @@ func TestCertificateUpsert(t *testing.T) {
 		body = res.JSON().Path("$.item").Object()
 		body.Value("id").String().Equal(id)
 		body.Value("cert").String().Equal(certificate.Cert)
-		body.Value("key").String().Equal(certificate.Key)
+		body.Value("key").String().Equal(redactedValue)
 		body.Value("cert_alt").String().Equal(goodAltCert)
-		body.Value("key_alt").String().Equal(goodAltKey)
+		body.Value("key_alt").String().Equal(redactedValue)
 	})
 	t.Run("upsert certificate without id fails", func(t *testing.T) {
 		res := c.PUT("/v1/certificates/").
@@ -0,0 +1,147 @@
+package admin
+
+import (
+	"context"
+	"net/http"
+
+	model "github.com/kong/koko/internal/gen/grpc/kong/admin/model/v1"
+	v1 "github.com/kong/koko/internal/gen/grpc/kong/admin/service/v1"
+	"github.com/kong/koko/internal/server/util"
+	"github.com/kong/koko/internal/store"
+	"go.uber.org/zap"
+)
+
+// ClusterService manages clusters. Clusters are shared by the stores of
+// every cluster, and are therefore managed through the store of the default
+// cluster.
+type ClusterService struct {
+	v1.UnimplementedClusterServiceServer
+	CommonOpts
+	maxDPConfigSize int
+}
+
+func (s *ClusterService) CreateCluster(ctx context.Context,
+	req *v1.CreateClusterRequest,
+) (*v1.CreateClusterResponse, error) {
+	if req.Item == nil {
+		return nil, s.err(ctx, util.ErrClient{Message: "no cluster specified"})
+	}
+	if err := validClusterID(req.Item.Id); err != nil {
+		return nil, s.err(ctx, err)
+	}
+	db, err := s.CommonOpts.getDB(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	cluster := store.ClusterInfo{ID: req.Item.Id, Description: req.Item.Description}
+	if err := db.CreateCluster(ctx, &cluster); err != nil {
+		return nil, s.err(ctx, err)
+	}
+	s.logger(ctx).Info("created cluster", zap.String("cluster", cluster.ID))
+	util.SetHeader(ctx, http.StatusCreated)
+	return &v1.CreateClusterResponse{Item: clusterToProto(cluster)}, nil
+}
+
+func (s *ClusterService) GetCluster(ctx context.Context,
+	req *v1.GetClusterRequest,
+) (*v1.GetClusterResponse, error) {
+	if err := validClusterID(req.Id); err != nil {
+		return nil, s.err(ctx, err)
+	}
+	db, err := s.CommonOpts.getDB(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	cluster, err := db.ReadCluster(ctx, req.Id)
+	if err != nil {
+		return nil, s.err(ctx, err)
+	}
+	return &v1.GetClusterResponse{Item: clusterToProto(cluster)}, nil
+}
+
+func (s *ClusterService) ListClusters(ctx context.Context,
+	req *v1.ListClustersRequest,
+) (*v1.ListClustersResponse, error) {
+	db, err := s.CommonOpts.getDB(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	listOptFns, err := ListOptsFromReq(ctx, "", req.Page)
+	if err != nil {
+		return nil, s.err(ctx, err)
+	}
+	list, err := db.ListClusters(ctx, listOptFns...)
+	if err != nil {
+		return nil, s.err(ctx, err)
+	}
+	res := &v1.ListClustersResponse{
+		Page: getPaginationResponse(list.TotalCount, list.NextPage),
+	}
+	for _, cluster := range list.Clusters {
+		res.Items = append(res.Items, clusterToProto(cluster))
+	}
+	return res, nil
+}
+
+func (s *ClusterService) DeleteCluster(ctx context.Context,
+	req *v1.DeleteClusterRequest,
+) (*v1.DeleteClusterResponse, error) {
+	if err := validClusterID(req.Id); err != nil {
+		return nil, s.err(ctx, err)
+	}
+	if req.Id == store.DefaultCluster {
+		return nil, s.err(ctx, util.ErrClient{Message: "the default cluster cannot be deleted"})
+	}
+	db, err := s.CommonOpts.getDB(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	s.logger(ctx).Info("deleting cluster", zap.String("cluster", req.Id))
+	if err := db.DeleteCluster(ctx, req.Id); err != nil {
+		return nil, s.err(ctx, err)
+	}
+	util.SetHeader(ctx, http.StatusNoContent)
+	return &v1.DeleteClusterResponse{}, nil
+}
+
+func (s *ClusterService) GetClusterUsage(ctx context.Context,
+	req *v1.GetClusterUsageRequest,
+) (*v1.GetClusterUsageResponse, error) {
+	if err := validClusterID(req.Id); err != nil {
+		return nil, s.err(ctx, err)
+	}
+	db, err := s.CommonOpts.getDB(ctx, &model.RequestCluster{Id: req.Id})
+	if err != nil {
+		return nil, err
+	}
+	usage, err := db.Usage(ctx)
+	if err != nil {
+		return nil, s.err(ctx, err)
+	}
+	res := &v1.GetClusterUsageResponse{
+		MaxDpConfigSize: int32(s.maxDPConfigSize),
+	}
+	for _, u := range usage {
+		res.Entities = append(res.Entities, &v1.EntityUsage{
+			Type:  string(u.Type),
+			Count: int32(u.Count),
+			Limit: int32(u.Limit),
+		})
+	}
+	return res, nil
+}
+
+func validClusterID(id string) error {
+	if err := store.ValidateCluster(id); err != nil {
+		return util.ErrClient{Message: err.Error()}
+	}
+	return nil
+}
+
+func clusterToProto(cluster store.ClusterInfo) *v1.Cluster {
+	return &v1.Cluster{
+		Id:          cluster.ID,
+		Description: cluster.Description,
+		CreatedAt:   cluster.CreatedAt,
+	}
+}
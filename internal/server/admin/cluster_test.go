@@ -0,0 +1,136 @@
+package admin
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gavv/httpexpect/v2"
+	"github.com/kong/koko/internal/json"
+	"github.com/kong/koko/internal/log"
+	"github.com/kong/koko/internal/model"
+	"github.com/kong/koko/internal/resource"
+	serverUtil "github.com/kong/koko/internal/server/util"
+	"github.com/kong/koko/internal/store"
+	"github.com/kong/koko/internal/test/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCluster(t *testing.T) {
+	p, err := util.GetPersister(t)
+	require.Nil(t, err)
+	s, cleanup := setupWithStoreLoader(t, serverUtil.ClusterStoreLoader{
+		Store: store.New(p, log.Logger),
+	})
+	defer cleanup()
+	c := httpexpect.Default(t, s.URL)
+
+	t.Run("creates a cluster", func(t *testing.T) {
+		res := c.POST("/v1/clusters").WithJSON(map[string]string{
+			"id":          "east",
+			"description": "east coast",
+		}).Expect()
+		res.Status(http.StatusCreated)
+		item := res.JSON().Path("$.item").Object()
+		item.ValueEqual("id", "east")
+		item.ValueEqual("description", "east coast")
+		item.ContainsKey("created_at")
+	})
+	t.Run("creating a cluster with an existing ID fails", func(t *testing.T) {
+		c.POST("/v1/clusters").WithJSON(map[string]string{
+			"id": "east",
+		}).Expect().Status(http.StatusBadRequest)
+	})
+	t.Run("creating a cluster with an invalid ID fails", func(t *testing.T) {
+		res := c.POST("/v1/clusters").WithJSON(map[string]string{
+			"id": "East Coast",
+		}).Expect()
+		res.Status(http.StatusBadRequest)
+		res.JSON().Object().ValueEqual("message", "invalid cluster ID: 'East Coast'")
+	})
+	t.Run("gets a cluster", func(t *testing.T) {
+		res := c.GET("/v1/clusters/east").Expect()
+		res.Status(http.StatusOK)
+		res.JSON().Path("$.item.id").Equal("east")
+		c.GET("/v1/clusters/west").Expect().Status(http.StatusNotFound)
+	})
+	t.Run("entities are scoped to their cluster", func(t *testing.T) {
+		c.POST("/v1/services").WithQuery("cluster.id", "east").WithJSON(goodService()).
+			Expect().Status(http.StatusCreated)
+		res := c.GET("/v1/services").WithQuery("cluster.id", "east").Expect()
+		res.Status(http.StatusOK)
+		res.JSON().Path("$.items").Array().Length().Equal(1)
+		res = c.GET("/v1/services").Expect()
+		res.Status(http.StatusOK)
+		res.JSON().Object().NotContainsKey("items")
+	})
+	t.Run("requests of unknown clusters fail", func(t *testing.T) {
+		res := c.GET("/v1/services").WithQuery("cluster.id", "west").Expect()
+		res.Status(http.StatusNotFound)
+		res.JSON().Object().ValueEqual("message", "cluster 'west' not found")
+	})
+	t.Run("lists and deletes clusters", func(t *testing.T) {
+		c.POST("/v1/clusters").WithJSON(map[string]string{
+			"id": "west",
+		}).Expect().Status(http.StatusCreated)
+		res := c.GET("/v1/clusters").Expect()
+		res.Status(http.StatusOK)
+		res.JSON().Path("$.items").Array().Length().Equal(2)
+		res.JSON().Path("$.page.total_count").Equal(2)
+
+		c.DELETE("/v1/clusters/east").Expect().Status(http.StatusNoContent)
+		c.DELETE("/v1/clusters/east").Expect().Status(http.StatusNotFound)
+		c.GET("/v1/services").WithQuery("cluster.id", "east").Expect().
+			Status(http.StatusNotFound)
+		res = c.GET("/v1/clusters").Expect()
+		res.JSON().Path("$.items").Array().Length().Equal(1)
+	})
+	t.Run("the default cluster cannot be deleted", func(t *testing.T) {
+		res := c.DELETE("/v1/clusters/default").Expect()
+		res.Status(http.StatusBadRequest)
+		res.JSON().Object().ValueEqual("message", "the default cluster cannot be deleted")
+	})
+}
+
+func TestClusterUsage(t *testing.T) {
+	p, err := util.GetPersister(t)
+	require.Nil(t, err)
+	objectStore := store.New(p, log.Logger).WithQuotas(&store.Quotas{
+		Entities: map[model.Type]int{resource.TypeConsumer: 1},
+	})
+	s, cleanup := setupWithStoreLoader(t, serverUtil.ClusterStoreLoader{
+		Store: objectStore,
+	})
+	defer cleanup()
+	c := httpexpect.Default(t, s.URL)
+
+	t.Run("creating entities beyond quotas fails", func(t *testing.T) {
+		c.POST("/v1/consumers").WithJSON(goodConsumer()).Expect().
+			Status(http.StatusCreated)
+		res := c.POST("/v1/consumers").WithJSON(goodConsumer()).Expect()
+		res.Status(http.StatusBadRequest)
+		res.JSON().Path("$.details[0].type").Equal("ERROR_TYPE_ENTITY")
+		res.JSON().Path("$.details[0].messages").Array().Elements(
+			"quota exceeded: cluster 'default' holds the maximum of 1 'consumer' entities")
+	})
+	t.Run("reports usage against quotas", func(t *testing.T) {
+		res := c.GET("/v1/clusters/default/usage").Expect()
+		res.Status(http.StatusOK)
+		var usage struct {
+			Entities []struct {
+				Type  string `json:"type"`
+				Count int    `json:"count"`
+				Limit int    `json:"limit"`
+			} `json:"entities"`
+		}
+		require.Nil(t, json.Unmarshal([]byte(res.Body().Raw()), &usage))
+		counts := map[string][2]int{}
+		for _, u := range usage.Entities {
+			counts[u.Type] = [2]int{u.Count, u.Limit}
+		}
+		require.Equal(t, [2]int{1, 1}, counts["consumer"])
+		require.Equal(t, [2]int{0, 0}, counts["service"])
+	})
+	t.Run("reports usage of existing clusters only", func(t *testing.T) {
+		c.GET("/v1/clusters/east/usage").Expect().Status(http.StatusNotFound)
+	})
+}
@@ -46,7 +46,7 @@ func (s *ConsumerGroupService) ListConsumerGroupRateLimitingAdvancedConfig(
 		return nil, err
 	}
 	list := resource.NewList(resource.TypeConsumerGroupRateLimitingAdvancedConfig)
-	listOptFns, err := ListOptsFromReq(req.Page)
+	listOptFns, err := ListOptsFromReq(ctx, resource.TypeConsumerGroupRateLimitingAdvancedConfig, req.Page)
 	if err != nil {
 		return nil, s.err(ctx, err)
 	}
@@ -55,7 +55,7 @@ func (s *ConsumerGroupService) ListConsumerGroupRateLimitingAdvancedConfig(
 	}
 	return &v1.ListConsumerGroupRateLimitingAdvancedConfigResponse{
 		Items: consumerGroupRateLimitingConfigFromObjects(list.GetAll()),
-		Page:  getPaginationResponse(list.GetTotalCount(), list.GetNextPage()),
+		Page:  listPaginationResponse(list),
 	}, nil
 }
 
@@ -132,6 +132,11 @@ func (s *ConsumerGroupService) DeleteConsumerGroupRateLimitingAdvancedConfig(
 		return nil, s.err(ctx, err)
 	}
 
+	var deleted []store.Dependent
+	opts, err := deleteOpts(req.Mode, &deleted)
+	if err != nil {
+		return nil, s.err(ctx, err)
+	}
 	db, err := s.getDB(ctx, req.Cluster)
 	if err != nil {
 		return nil, err
@@ -147,15 +152,17 @@ func (s *ConsumerGroupService) DeleteConsumerGroupRateLimitingAdvancedConfig(
 
 	if err := db.Delete(
 		ctx,
-		store.DeleteByID(result.ID()),
-		store.DeleteByType(resource.TypeConsumerGroupRateLimitingAdvancedConfig),
+		append(opts,
+			store.DeleteByID(result.ID()),
+			store.DeleteByType(resource.TypeConsumerGroupRateLimitingAdvancedConfig),
+		)...,
 	); err != nil {
 		return nil, s.err(ctx, err)
 	}
 
-	util.SetHeader(ctx, http.StatusNoContent)
-
-	return &v1.DeleteConsumerGroupRateLimitingAdvancedConfigResponse{}, nil
+	return &v1.DeleteConsumerGroupRateLimitingAdvancedConfigResponse{
+		Deleted: deletedDependents(ctx, req.Mode, deleted),
+	}, nil
 }
 
 func consumerGroupRateLimitingConfigFromObjects(
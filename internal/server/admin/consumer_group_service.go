@@ -33,6 +33,7 @@ func (s *ConsumerGroupService) GetConsumerGroup(ctx context.Context,
 		return nil, err
 	}
 	result := resource.NewConsumerGroup()
+	var revision uint64
 	if err := getEntityByIDOrName(
 		ctx,
 		req.Id,
@@ -40,9 +41,11 @@ func (s *ConsumerGroupService) GetConsumerGroup(ctx context.Context,
 		store.GetByName(req.Id),
 		db,
 		s.logger(ctx),
+		store.ReadRevision(&revision),
 	); err != nil {
 		return nil, s.err(ctx, err)
 	}
+	util.SetETag(ctx, revisionETag(revision))
 	return &v1.GetConsumerGroupResponse{
 		Item: result.ConsumerGroup,
 	}, nil
@@ -57,10 +60,12 @@ func (s *ConsumerGroupService) CreateConsumerGroup(ctx context.Context,
 	}
 	res := resource.NewConsumerGroup()
 	res.ConsumerGroup = req.Item
-	if err := db.Create(ctx, res); err != nil {
+	var revision uint64
+	if err := db.Create(ctx, res, store.WriteRevision(&revision)); err != nil {
 		return nil, s.err(ctx, err)
 	}
 	util.SetHeader(ctx, http.StatusCreated)
+	util.SetETag(ctx, revisionETag(revision))
 	return &v1.CreateConsumerGroupResponse{
 		Item: res.ConsumerGroup,
 	}, nil
@@ -72,15 +77,22 @@ func (s *ConsumerGroupService) UpsertConsumerGroup(ctx context.Context,
 	if err := s.validateRequest(req); err != nil {
 		return nil, s.err(ctx, err)
 	}
+	expected, err := expectedRevision(ctx, req.ExpectedRevision)
+	if err != nil {
+		return nil, s.err(ctx, err)
+	}
 	db, err := s.getDB(ctx, req.Cluster)
 	if err != nil {
 		return nil, err
 	}
 	res := resource.NewConsumerGroup()
 	res.ConsumerGroup = req.Item
-	if err := db.Upsert(ctx, res); err != nil {
+	var revision uint64
+	if err := db.Upsert(ctx, res, store.UpsertIfRevision(expected),
+		store.WriteRevision(&revision)); err != nil {
 		return nil, s.err(ctx, err)
 	}
+	util.SetETag(ctx, revisionETag(revision))
 	return &v1.UpsertConsumerGroupResponse{
 		Item: res.ConsumerGroup,
 	}, nil
@@ -100,17 +112,27 @@ func (s *ConsumerGroupService) DeleteConsumerGroup(ctx context.Context,
 	if err := s.validateRequest(req); err != nil {
 		return nil, s.err(ctx, err)
 	}
+	expected, err := expectedRevision(ctx, req.ExpectedRevision)
+	if err != nil {
+		return nil, s.err(ctx, err)
+	}
+	var deleted []store.Dependent
+	opts, err := deleteOpts(req.Mode, &deleted)
+	if err != nil {
+		return nil, s.err(ctx, err)
+	}
 	db, err := s.getDB(ctx, req.Cluster)
 	if err != nil {
 		return nil, err
 	}
-	err = db.Delete(ctx, store.DeleteByID(req.Id),
-		store.DeleteByType(resource.TypeConsumerGroup))
+	err = db.Delete(ctx, append(opts, store.DeleteByID(req.Id),
+		store.DeleteByType(resource.TypeConsumerGroup), store.DeleteIfRevision(expected))...)
 	if err != nil {
 		return nil, s.err(ctx, err)
 	}
-	util.SetHeader(ctx, http.StatusNoContent)
-	return &v1.DeleteConsumerGroupResponse{}, nil
+	return &v1.DeleteConsumerGroupResponse{
+		Deleted: deletedDependents(ctx, req.Mode, deleted),
+	}, nil
 }
 
 func (s *ConsumerGroupService) ListConsumerGroups(ctx context.Context,
@@ -121,16 +143,19 @@ func (s *ConsumerGroupService) ListConsumerGroups(ctx context.Context,
 		return nil, err
 	}
 	list := resource.NewList(resource.TypeConsumerGroup)
-	listOptFns, err := ListOptsFromReq(req.Page)
+	listOptFns, err := ListOptsFromReq(ctx, resource.TypeConsumerGroup, req.Page)
 	if err != nil {
 		return nil, s.err(ctx, err)
 	}
+	revisions := map[string]uint64{}
+	listOptFns = append(listOptFns, store.ListRevisions(revisions))
 	if err := db.List(ctx, list, listOptFns...); err != nil {
 		return nil, s.err(ctx, err)
 	}
+	util.SetETag(ctx, listETag(revisions))
 	return &v1.ListConsumerGroupsResponse{
 		Items: consumerGroupsFromObjects(list.GetAll()),
-		Page:  getPaginationResponse(list.GetTotalCount(), list.GetNextPage()),
+		Page:  listPaginationResponse(list),
 	}, nil
 }
 
@@ -161,7 +186,7 @@ func (s *ConsumerGroupService) ListConsumerGroupMembers(
 
 	listFn := []store.ListOptsFunc{store.ListReverseFor(resource.TypeConsumerGroup, result.ID())}
 	list := resource.NewList(resource.TypeConsumer)
-	listOptFns, err := ListOptsFromReq(req.Page)
+	listOptFns, err := ListOptsFromReq(ctx, resource.TypeConsumer, req.Page)
 	if err != nil {
 		return nil, s.err(ctx, err)
 	}
@@ -173,7 +198,36 @@ func (s *ConsumerGroupService) ListConsumerGroupMembers(
 
 	return &v1.ListConsumerGroupMembersResponse{
 		Items: consumersFromObjects(list.GetAll()),
-		Page:  getPaginationResponse(list.GetTotalCount(), list.GetNextPage()),
+		Page:  listPaginationResponse(list),
+	}, nil
+}
+
+func (s *ConsumerGroupService) ListConsumerGroupMemberships(
+	ctx context.Context,
+	req *v1.ListConsumerGroupMembershipsRequest,
+) (*v1.ListConsumerGroupMembershipsResponse, error) {
+	db, err := s.getDB(ctx, req.Cluster)
+	if err != nil {
+		return nil, err
+	}
+	listOptFns, err := ListOptsFromReq(ctx, resource.TypeConsumerGroup, req.Page)
+	if err != nil {
+		return nil, s.err(ctx, err)
+	}
+	list, err := db.ListForeignKeys(ctx, resource.TypeConsumerGroup, resource.TypeConsumer, listOptFns...)
+	if err != nil {
+		return nil, s.err(ctx, err)
+	}
+	items := make([]*v1.ConsumerGroupMembership, 0, len(list.ForeignKeys))
+	for _, fk := range list.ForeignKeys {
+		items = append(items, &v1.ConsumerGroupMembership{
+			ConsumerGroupId: fk.ID,
+			ConsumerId:      fk.ForeignID,
+		})
+	}
+	return &v1.ListConsumerGroupMembershipsResponse{
+		Items: items,
+		Page:  getPaginationResponse(list.TotalCount, list.NextPage),
 	}, nil
 }
 
@@ -32,21 +32,26 @@ func (s *ConsumerService) GetConsumer(ctx context.Context,
 	}
 
 	result := resource.NewConsumer()
+	var revision uint64
 	if req.CustomId != "" {
 		s.logger(ctx).With(zap.String("custom_id", req.CustomId)).Debug("getting consumer by custom_id")
-		err = db.Read(ctx, result, store.GetByIndex("custom_id", req.CustomId))
+		err = db.Read(ctx, result, store.GetByIndex("custom_id", req.CustomId),
+			store.ReadRevision(&revision))
 		if err != nil {
 			return nil, s.err(ctx, err)
 		}
+		util.SetETag(ctx, revisionETag(revision))
 		return &v1.GetConsumerResponse{
 			Item: result.Consumer,
 		}, nil
 	}
 
-	err = getEntityByIDOrName(ctx, req.Id, result, store.GetByIndex("username", req.Id), db, s.logger(ctx))
+	err = getEntityByIDOrName(ctx, req.Id, result, store.GetByIndex("username", req.Id), db, s.logger(ctx),
+		store.ReadRevision(&revision))
 	if err != nil {
 		return nil, s.err(ctx, err)
 	}
+	util.SetETag(ctx, revisionETag(revision))
 	return &v1.GetConsumerResponse{
 		Item: result.Consumer,
 	}, nil
@@ -61,10 +66,12 @@ func (s *ConsumerService) CreateConsumer(ctx context.Context,
 	}
 	result := resource.NewConsumer()
 	result.Consumer = req.Item
-	if err := db.Create(ctx, result); err != nil {
+	var revision uint64
+	if err := db.Create(ctx, result, store.WriteRevision(&revision)); err != nil {
 		return nil, s.err(ctx, err)
 	}
 	util.SetHeader(ctx, http.StatusCreated)
+	util.SetETag(ctx, revisionETag(revision))
 	return &v1.CreateConsumerResponse{
 		Item: result.Consumer,
 	}, nil
@@ -76,15 +83,22 @@ func (s *ConsumerService) UpsertConsumer(ctx context.Context,
 	if err := validUUID(req.Item.Id); err != nil {
 		return nil, s.err(ctx, err)
 	}
+	expected, err := expectedRevision(ctx, req.ExpectedRevision)
+	if err != nil {
+		return nil, s.err(ctx, err)
+	}
 	db, err := s.CommonOpts.getDB(ctx, req.Cluster)
 	if err != nil {
 		return nil, err
 	}
 	result := resource.NewConsumer()
 	result.Consumer = req.Item
-	if err := db.Upsert(ctx, result); err != nil {
+	var revision uint64
+	if err := db.Upsert(ctx, result, store.UpsertIfRevision(expected),
+		store.WriteRevision(&revision)); err != nil {
 		return nil, s.err(ctx, err)
 	}
+	util.SetETag(ctx, revisionETag(revision))
 	return &v1.UpsertConsumerResponse{
 		Item: result.Consumer,
 	}, nil
@@ -96,17 +110,28 @@ func (s *ConsumerService) DeleteConsumer(ctx context.Context,
 	if req.Id == "" {
 		return nil, s.err(ctx, util.ErrClient{Message: "required ID is missing"})
 	}
+	expected, err := expectedRevision(ctx, req.ExpectedRevision)
+	if err != nil {
+		return nil, s.err(ctx, err)
+	}
+	var deleted []store.Dependent
+	opts, err := deleteOpts(req.Mode, &deleted)
+	if err != nil {
+		return nil, s.err(ctx, err)
+	}
 	db, err := s.CommonOpts.getDB(ctx, req.Cluster)
 	if err != nil {
 		return nil, err
 	}
 	s.logger(ctx).With(zap.String("id", req.Id)).Debug("deleting consumer by id")
-	err = db.Delete(ctx, store.DeleteByID(req.Id), store.DeleteByType(resource.TypeConsumer))
+	err = db.Delete(ctx, append(opts, store.DeleteByID(req.Id), store.DeleteByType(resource.TypeConsumer),
+		store.DeleteIfRevision(expected))...)
 	if err != nil {
 		return nil, s.err(ctx, err)
 	}
-	util.SetHeader(ctx, http.StatusNoContent)
-	return &v1.DeleteConsumerResponse{}, nil
+	return &v1.DeleteConsumerResponse{
+		Deleted: deletedDependents(ctx, req.Mode, deleted),
+	}, nil
 }
 
 func (s *ConsumerService) ListConsumers(ctx context.Context,
@@ -117,16 +142,19 @@ func (s *ConsumerService) ListConsumers(ctx context.Context,
 		return nil, err
 	}
 	list := resource.NewList(resource.TypeConsumer)
-	listOptFns, err := ListOptsFromReq(req.Page)
+	listOptFns, err := ListOptsFromReq(ctx, resource.TypeConsumer, req.Page)
 	if err != nil {
 		return nil, s.err(ctx, err)
 	}
+	revisions := map[string]uint64{}
+	listOptFns = append(listOptFns, store.ListRevisions(revisions))
 	if err := db.List(ctx, list, listOptFns...); err != nil {
 		return nil, s.err(ctx, err)
 	}
+	util.SetETag(ctx, listETag(revisions))
 	return &v1.ListConsumersResponse{
 		Items: consumersFromObjects(list.GetAll()),
-		Page:  getPaginationResponse(list.GetTotalCount(), list.GetNextPage()),
+		Page:  listPaginationResponse(list),
 	}, nil
 }
 
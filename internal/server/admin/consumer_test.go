@@ -441,6 +441,41 @@ func TestConsumerList(t *testing.T) {
 		require.ElementsMatch(t, userNameList, gotUserNames)
 		require.ElementsMatch(t, customIDList, gotCustomIds)
 	})
+	t.Run("list consumers with page tokens succeeds", func(t *testing.T) {
+		var gotIDs []string
+		token := ""
+		for i := 0; i < 3; i++ {
+			body := c.GET("/v1/consumers").
+				WithQuery("page.size", "1").
+				WithQuery("page.page_token", token).
+				Expect().Status(http.StatusOK).JSON().Object()
+			items := body.Value("items").Array()
+			items.Length().Equal(1)
+			gotIDs = append(gotIDs, items.Element(0).Object().Value("id").String().Raw())
+			page := body.Value("page").Object()
+			page.Value("total_count").Number().Equal(3)
+			if i == 2 {
+				page.NotContainsKey("next_page_token")
+				break
+			}
+			token = page.Value("next_page_token").String().NotEmpty().Raw()
+		}
+		require.ElementsMatch(t, idList, gotIDs)
+	})
+	t.Run("list consumers with an invalid page token fails", func(t *testing.T) {
+		res := c.GET("/v1/consumers").WithQuery("page.page_token", "!").Expect()
+		res.Status(http.StatusBadRequest)
+		res.JSON().Object().ValueEqual("message", "invalid page token")
+	})
+	t.Run("list consumers with a page number and page token fails", func(t *testing.T) {
+		res := c.GET("/v1/consumers").
+			WithQuery("page.number", "2").
+			WithQuery("page.page_token", "a2V5").
+			Expect()
+		res.Status(http.StatusBadRequest)
+		res.JSON().Object().ValueEqual("message",
+			"page number and page token cannot be used together")
+	})
 	t.Run("read request on resource with slash returns bad request", func(t *testing.T) {
 		res := c.GET("/v1/consumers/").Expect()
 		res.Status(http.StatusBadRequest)
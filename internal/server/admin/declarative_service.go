@@ -0,0 +1,131 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/kong/koko/internal/declarative"
+	pbModel "github.com/kong/koko/internal/gen/grpc/kong/admin/model/v1"
+	v1 "github.com/kong/koko/internal/gen/grpc/kong/admin/service/v1"
+	"github.com/kong/koko/internal/model"
+	"github.com/kong/koko/internal/model/json/validation"
+	"github.com/kong/koko/internal/server/util"
+	"github.com/kong/koko/internal/store"
+	"go.uber.org/zap"
+)
+
+type DeclarativeService struct {
+	v1.UnimplementedDeclarativeServiceServer
+	CommonOpts
+}
+
+func (s *DeclarativeService) SyncConfig(ctx context.Context,
+	req *v1.SyncConfigRequest,
+) (*v1.SyncConfigResponse, error) {
+	if req.Config == nil {
+		return nil, s.err(ctx, util.ErrClient{Message: "required config is missing"})
+	}
+	db, err := s.CommonOpts.getDB(ctx, req.Cluster)
+	if err != nil {
+		return nil, err
+	}
+	ctx = context.WithValue(ctx, util.ContextKeyCluster, req.Cluster)
+	changes, err := declarative.Diff(ctx, db, req.Config.AsMap(), declarative.Opts{
+		SelectTags: req.SelectTags,
+		RestoreRedacted: func(ctx context.Context, object model.Object) error {
+			return restoreRedacted(ctx, db, object)
+		},
+	})
+	if err != nil {
+		return nil, s.err(ctx, declarativeErr(err))
+	}
+	if !req.DryRun && !changes.Empty() {
+		s.logger(ctx).Info("applying declarative configuration",
+			zap.Int("creates", len(changes.Creates)),
+			zap.Int("updates", len(changes.Updates)),
+			zap.Int("deletes", len(changes.Deletes)))
+		if err := declarative.Apply(ctx, db, changes); err != nil {
+			return nil, s.err(ctx, declarativeErr(err))
+		}
+	}
+
+	res := &v1.SyncConfigResponse{}
+	for _, c := range []struct {
+		changes []declarative.Change
+		dst     *[]*v1.DeclarativeChange
+	}{
+		{changes.Creates, &res.Creates},
+		{changes.Updates, &res.Updates},
+		{changes.Deletes, &res.Deletes},
+	} {
+		for _, change := range c.changes {
+			pbChange, err := declarativeChangeToProto(ctx, change)
+			if err != nil {
+				return nil, s.err(ctx, err)
+			}
+			*c.dst = append(*c.dst, pbChange)
+		}
+	}
+	return res, nil
+}
+
+func declarativeChangeToProto(ctx context.Context, change declarative.Change) (*v1.DeclarativeChange, error) {
+	res := &v1.DeclarativeChange{
+		Type: string(change.Type),
+		Id:   change.ID,
+	}
+	var err error
+	if change.Old != nil {
+		if res.OldItem, err = structFromObject(ctx, change.Old); err != nil {
+			return nil, err
+		}
+	}
+	if change.New != nil {
+		if res.NewItem, err = structFromObject(ctx, change.New); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// declarativeErr converts an invalid declarative configuration error into
+// an error that points the client at the offending entity.
+func declarativeErr(err error) error {
+	var invalidErr declarative.ErrInvalid
+	if !errors.As(err, &invalidErr) {
+		return err
+	}
+	field := func(name string) string {
+		if name == "" {
+			return invalidErr.Path
+		}
+		return invalidErr.Path + "." + name
+	}
+
+	var validationErr validation.Error
+	var constraintErr store.ErrConstraint
+	switch {
+	case errors.As(invalidErr.Err, &validationErr):
+		errs := make([]*pbModel.ErrorDetail, len(validationErr.Errs))
+		for i, detail := range validationErr.Errs {
+			errs[i] = &pbModel.ErrorDetail{
+				Type:     detail.Type,
+				Field:    field(detail.Field),
+				Messages: detail.Messages,
+			}
+		}
+		return validation.Error{Errs: errs}
+	case errors.As(invalidErr.Err, &constraintErr):
+		return validation.Error{Errs: []*pbModel.ErrorDetail{
+			{
+				Type:     pbModel.ErrorType_ERROR_TYPE_REFERENCE,
+				Field:    field(constraintErr.Index.FieldName),
+				Messages: []string{constraintErr.Error()},
+			},
+		}}
+	case errors.Is(invalidErr.Err, store.ErrNotFound):
+		return util.ErrClient{Message: fmt.Sprintf("%s: not found", invalidErr.Path)}
+	}
+	return util.ErrClient{Message: invalidErr.Error()}
+}
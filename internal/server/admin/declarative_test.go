@@ -0,0 +1,128 @@
+package admin
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gavv/httpexpect/v2"
+	v1 "github.com/kong/koko/internal/gen/grpc/kong/admin/model/v1"
+)
+
+func TestSyncConfig(t *testing.T) {
+	s, cleanup := setup(t)
+	defer cleanup()
+	c := httpexpect.Default(t, s.URL)
+
+	config := map[string]interface{}{
+		"_format_version": "3.0",
+		"services": []map[string]interface{}{
+			{
+				"name": "foo",
+				"host": "example.com",
+				"routes": []map[string]interface{}{
+					{"name": "foo", "paths": []string{"/"}},
+				},
+			},
+		},
+	}
+
+	t.Run("dry run returns the changes without applying them", func(t *testing.T) {
+		res := c.POST("/v1/declarative/sync").WithJSON(map[string]interface{}{
+			"config":  config,
+			"dry_run": true,
+		}).Expect()
+		res.Status(http.StatusOK)
+		creates := res.JSON().Path("$.creates").Array()
+		creates.Length().Equal(2)
+		creates.Element(0).Object().ValueEqual("type", "service")
+		creates.Element(0).Path("$.new_item.name").Equal("foo")
+		creates.Element(1).Object().ValueEqual("type", "route")
+
+		c.GET("/v1/services/foo").Expect().Status(http.StatusNotFound)
+	})
+	t.Run("sync applies the changes", func(t *testing.T) {
+		res := c.POST("/v1/declarative/sync").WithJSON(map[string]interface{}{
+			"config": config,
+		}).Expect()
+		res.Status(http.StatusOK)
+		res.JSON().Path("$.creates").Array().Length().Equal(2)
+
+		c.GET("/v1/services/foo").Expect().Status(http.StatusOK)
+		c.GET("/v1/routes/foo").Expect().Status(http.StatusOK)
+	})
+	t.Run("sync reports updates and deletes", func(t *testing.T) {
+		res := c.POST("/v1/declarative/sync").WithJSON(map[string]interface{}{
+			"config": map[string]interface{}{
+				"_format_version": "3.0",
+				"services": []map[string]interface{}{
+					{"name": "foo", "host": "foo.example.com"},
+				},
+			},
+		}).Expect()
+		res.Status(http.StatusOK)
+		body := res.JSON().Object()
+		body.NotContainsKey("creates")
+		updates := body.Value("updates").Array()
+		updates.Length().Equal(1)
+		updates.Element(0).Path("$.old_item.host").Equal("example.com")
+		updates.Element(0).Path("$.new_item.host").Equal("foo.example.com")
+		deletes := body.Value("deletes").Array()
+		deletes.Length().Equal(1)
+		deletes.Element(0).Object().ValueEqual("type", "route")
+
+		c.GET("/v1/routes/foo").Expect().Status(http.StatusNotFound)
+	})
+	t.Run("invalid entity fails", func(t *testing.T) {
+		res := c.POST("/v1/declarative/sync").WithJSON(map[string]interface{}{
+			"config": map[string]interface{}{
+				"_format_version": "3.0",
+				"services": []map[string]interface{}{
+					{"name": "foo"},
+				},
+			},
+		}).Expect()
+		res.Status(http.StatusBadRequest)
+		body := res.JSON().Object()
+		body.ValueEqual("message", "validation error")
+		errRes := body.Value("details").Array().Element(0).Object()
+		errRes.ValueEqual("type", v1.ErrorType_ERROR_TYPE_ENTITY.String())
+		errRes.ValueEqual("field", "services[0]")
+	})
+	t.Run("deleting entities referenced outside of the selected tags fails", func(t *testing.T) {
+		c.POST("/v1/declarative/sync").WithJSON(map[string]interface{}{
+			"config": map[string]interface{}{
+				"_format_version": "3.0",
+				"services": []map[string]interface{}{
+					{"name": "foo", "host": "foo.example.com", "tags": []string{"team"}},
+				},
+			},
+		}).Expect().Status(http.StatusOK)
+		serviceID := c.GET("/v1/services/foo").Expect().JSON().Path("$.item.id").String().Raw()
+		c.POST("/v1/routes").WithJSON(map[string]interface{}{
+			"name":    "untagged",
+			"paths":   []string{"/untagged"},
+			"service": map[string]interface{}{"id": serviceID},
+		}).Expect().Status(http.StatusCreated)
+
+		res := c.POST("/v1/declarative/sync").WithJSON(map[string]interface{}{
+			"config": map[string]interface{}{
+				"_format_version": "3.0",
+				"_info":           map[string]interface{}{"select_tags": []string{"team"}},
+			},
+		}).Expect()
+		res.Status(http.StatusConflict)
+		errRes := res.JSON().Path("$.details").Array().Element(0).Object()
+		errRes.ValueEqual("type", v1.ErrorType_ERROR_TYPE_REFERENCE.String())
+		errRes.Value("messages").Array().Element(0).String().Contains("route")
+		c.GET("/v1/services/foo").Expect().Status(http.StatusOK)
+	})
+	t.Run("invalid configuration fails", func(t *testing.T) {
+		res := c.POST("/v1/declarative/sync").WithJSON(map[string]interface{}{
+			"config": map[string]interface{}{
+				"services": []map[string]interface{}{},
+			},
+		}).Expect()
+		res.Status(http.StatusBadRequest)
+		res.JSON().Object().ValueEqual("message", "'_format_version' is required")
+	})
+}
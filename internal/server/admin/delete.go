@@ -0,0 +1,50 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	pbModel "github.com/kong/koko/internal/gen/grpc/kong/admin/model/v1"
+	"github.com/kong/koko/internal/server/util"
+	"github.com/kong/koko/internal/store"
+)
+
+// deleteOpts returns the store options to delete a resource in the given mode.
+// The dependents deleted along with the resource are appended to deleted.
+func deleteOpts(mode pbModel.DeleteMode, deleted *[]store.Dependent) ([]store.DeleteOptsFunc, error) {
+	var storeMode store.DeleteMode
+	switch mode {
+	case pbModel.DeleteMode_DELETE_MODE_UNSPECIFIED, pbModel.DeleteMode_DELETE_MODE_CASCADE:
+		storeMode = store.DeleteModeCascade
+	case pbModel.DeleteMode_DELETE_MODE_RESTRICT:
+		storeMode = store.DeleteModeRestrict
+	default:
+		return nil, util.ErrClient{Message: fmt.Sprintf("invalid delete mode '%d'", mode)}
+	}
+	return []store.DeleteOptsFunc{
+		store.DeleteWithMode(storeMode),
+		store.DeletedDependents(deleted),
+	}, nil
+}
+
+// deletedDependents returns the dependents deleted along with a resource, and
+// sets the HTTP status code of the delete response. The response has no content
+// unless the dependents are explicitly deleted in cascade.
+func deletedDependents(ctx context.Context, mode pbModel.DeleteMode,
+	deleted []store.Dependent,
+) []*pbModel.EntityReference {
+	if mode == pbModel.DeleteMode_DELETE_MODE_CASCADE {
+		util.SetHeader(ctx, http.StatusOK)
+	} else {
+		util.SetHeader(ctx, http.StatusNoContent)
+	}
+	res := make([]*pbModel.EntityReference, 0, len(deleted))
+	for _, dependent := range deleted {
+		res = append(res, &pbModel.EntityReference{
+			Type: string(dependent.Type),
+			Id:   dependent.ID,
+		})
+	}
+	return res
+}
@@ -0,0 +1,72 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	v1 "github.com/kong/koko/internal/gen/grpc/kong/admin/service/v1"
+	"github.com/kong/koko/internal/model"
+	"github.com/kong/koko/internal/server/util"
+	"github.com/kong/koko/internal/store"
+)
+
+// maxDependentsDepth is the maximum number of levels of dependents listed.
+const maxDependentsDepth = 10
+
+type DependentService struct {
+	v1.UnimplementedDependentServiceServer
+	CommonOpts
+}
+
+func (s *DependentService) GetDependents(ctx context.Context,
+	req *v1.GetDependentsRequest,
+) (*v1.GetDependentsResponse, error) {
+	if !model.ValidType(model.Type(req.Type)) {
+		return nil, s.err(ctx, util.ErrClient{Message: fmt.Sprintf("invalid type: '%s'", req.Type)})
+	}
+	if err := validUUID(req.Id); err != nil {
+		return nil, s.err(ctx, err)
+	}
+	depth := int(req.Depth)
+	if depth == 0 {
+		depth = 1
+	}
+	if depth < 1 || depth > maxDependentsDepth {
+		return nil, s.err(ctx, util.ErrClient{
+			Message: fmt.Sprintf("depth must be between 1 and %d", maxDependentsDepth),
+		})
+	}
+	db, err := s.CommonOpts.getDB(ctx, req.Cluster)
+	if err != nil {
+		return nil, err
+	}
+	referrers, err := db.ListReferrers(ctx, model.Type(req.Type), req.Id, depth)
+	if err != nil {
+		return nil, s.err(ctx, err)
+	}
+	return &v1.GetDependentsResponse{Dependents: dependentGroups(referrers)}, nil
+}
+
+// dependentGroups groups referrers by type, sorted by type and ID.
+func dependentGroups(referrers []store.Referrer) []*v1.DependentGroup {
+	sort.Slice(referrers, func(i, j int) bool {
+		if referrers[i].Type != referrers[j].Type {
+			return referrers[i].Type < referrers[j].Type
+		}
+		return referrers[i].ID < referrers[j].ID
+	})
+	var res []*v1.DependentGroup
+	for _, referrer := range referrers {
+		if len(res) == 0 || res[len(res)-1].Type != string(referrer.Type) {
+			res = append(res, &v1.DependentGroup{Type: string(referrer.Type)})
+		}
+		group := res[len(res)-1]
+		group.Items = append(group.Items, &v1.Dependent{
+			Id:         referrer.ID,
+			Field:      referrer.Field,
+			Dependents: dependentGroups(referrer.Referrers),
+		})
+	}
+	return res
+}
@@ -0,0 +1,113 @@
+package admin
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gavv/httpexpect/v2"
+	"github.com/google/uuid"
+	v1 "github.com/kong/koko/internal/gen/grpc/kong/admin/model/v1"
+)
+
+func TestGetDependents(t *testing.T) {
+	s, cleanup := setup(t)
+	defer cleanup()
+	c := httpexpect.Default(t, s.URL)
+
+	res := c.POST("/v1/ca-certificates").WithJSON(&v1.CACertificate{
+		Cert: goodCACertOne,
+	}).Expect()
+	res.Status(http.StatusCreated)
+	caCertID := res.JSON().Path("$.item.id").String().Raw()
+
+	res = c.POST("/v1/certificates").WithJSON(&v1.Certificate{
+		Cert: goodCertOne,
+		Key:  goodKeyOne,
+	}).Expect()
+	res.Status(http.StatusCreated)
+	certID := res.JSON().Path("$.item.id").String().Raw()
+
+	res = c.POST("/v1/snis").WithJSON(&v1.SNI{
+		Name:        "example.com",
+		Certificate: &v1.Certificate{Id: certID},
+	}).Expect()
+	res.Status(http.StatusCreated)
+	sniID := res.JSON().Path("$.item.id").String().Raw()
+
+	service := goodService()
+	service.Protocol = "https"
+	service.CaCertificates = []string{caCertID}
+	service.ClientCertificate = &v1.Certificate{Id: certID}
+	res = c.POST("/v1/services").WithJSON(service).Expect()
+	res.Status(http.StatusCreated)
+	serviceID := res.JSON().Path("$.item.id").String().Raw()
+
+	route := goodRoute()
+	route.Service = &v1.Service{Id: serviceID}
+	res = c.POST("/v1/routes").WithJSON(route).Expect()
+	res.Status(http.StatusCreated)
+	routeID := res.JSON().Path("$.item.id").String().Raw()
+
+	t.Run("gets the dependents of a resource", func(t *testing.T) {
+		res := c.GET("/v1/dependents/ca_certificate/" + caCertID).Expect()
+		res.Status(http.StatusOK)
+		res.JSON().Object().Value("dependents").Equal([]interface{}{
+			map[string]interface{}{
+				"type": "service",
+				"items": []interface{}{
+					map[string]interface{}{"id": serviceID, "field": "ca_certificates"},
+				},
+			},
+		})
+	})
+	t.Run("gets the dependents of a resource recursively", func(t *testing.T) {
+		res := c.GET("/v1/dependents/certificate/"+certID).
+			WithQuery("depth", 2).Expect()
+		res.Status(http.StatusOK)
+		res.JSON().Object().Value("dependents").Equal([]interface{}{
+			map[string]interface{}{
+				"type": "service",
+				"items": []interface{}{
+					map[string]interface{}{
+						"id":    serviceID,
+						"field": "client_certificate.id",
+						"dependents": []interface{}{
+							map[string]interface{}{
+								"type": "route",
+								"items": []interface{}{
+									map[string]interface{}{"id": routeID, "field": "service.id"},
+								},
+							},
+						},
+					},
+				},
+			},
+			map[string]interface{}{
+				"type": "sni",
+				"items": []interface{}{
+					map[string]interface{}{"id": sniID, "field": "certificate.id"},
+				},
+			},
+		})
+	})
+	t.Run("gets no dependents of a resource without dependents", func(t *testing.T) {
+		res := c.GET("/v1/dependents/route/" + routeID).Expect()
+		res.Status(http.StatusOK)
+		res.JSON().Object().NotContainsKey("dependents")
+	})
+	t.Run("getting the dependents of a non-existent resource returns 404", func(t *testing.T) {
+		c.GET("/v1/dependents/service/" + uuid.NewString()).
+			Expect().Status(http.StatusNotFound)
+	})
+	t.Run("getting the dependents of an invalid type returns 400", func(t *testing.T) {
+		res := c.GET("/v1/dependents/foo/" + serviceID).Expect()
+		res.Status(http.StatusBadRequest)
+		res.JSON().Object().ValueEqual("message", "invalid type: 'foo'")
+	})
+	t.Run("getting the dependents with an invalid depth returns 400", func(t *testing.T) {
+		res := c.GET("/v1/dependents/service/"+serviceID).
+			WithQuery("depth", 11).Expect()
+		res.Status(http.StatusBadRequest)
+		res.JSON().Object().ValueEqual("message", "depth must be between 1 and 10")
+	})
+}
@@ -34,6 +34,10 @@ type HandlerOpts struct {
 	StoreLoader util.StoreLoader
 
 	Validator plugin.Validator
+
+	// MaxDPConfigSize is the maximum size in bytes of the uncompressed
+	// configuration of data planes, as reported with the usage of clusters.
+	MaxDPConfigSize int
 }
 
 type CommonOpts struct {
@@ -78,6 +82,13 @@ type services struct {
 	sni           v1.SNIServiceServer
 	vault         v1.VaultServiceServer
 	consumerGroup v1.ConsumerGroupServiceServer
+	batch         v1.BatchServiceServer
+	declarative   v1.DeclarativeServiceServer
+	history       v1.HistoryServiceServer
+	dependent     v1.DependentServiceServer
+	integrity     v1.IntegrityServiceServer
+	snapshot      v1.SnapshotServiceServer
+	cluster       v1.ClusterServiceServer
 
 	status v1.StatusServiceServer
 	node   v1.NodeServiceServer
@@ -224,6 +235,63 @@ func buildServices(opts HandlerOpts) services {
 				},
 			},
 		},
+		batch: &BatchService{
+			CommonOpts: CommonOpts{
+				storeLoader: opts.StoreLoader,
+				loggerFields: []zapcore.Field{
+					zap.String("admin-service", "batch"),
+				},
+			},
+		},
+		declarative: &DeclarativeService{
+			CommonOpts: CommonOpts{
+				storeLoader: opts.StoreLoader,
+				loggerFields: []zapcore.Field{
+					zap.String("admin-service", "declarative"),
+				},
+			},
+		},
+		history: &HistoryService{
+			CommonOpts: CommonOpts{
+				storeLoader: opts.StoreLoader,
+				loggerFields: []zapcore.Field{
+					zap.String("admin-service", "history"),
+				},
+			},
+		},
+		dependent: &DependentService{
+			CommonOpts: CommonOpts{
+				storeLoader: opts.StoreLoader,
+				loggerFields: []zapcore.Field{
+					zap.String("admin-service", "dependent"),
+				},
+			},
+		},
+		integrity: &IntegrityService{
+			CommonOpts: CommonOpts{
+				storeLoader: opts.StoreLoader,
+				loggerFields: []zapcore.Field{
+					zap.String("admin-service", "integrity"),
+				},
+			},
+		},
+		snapshot: &SnapshotService{
+			CommonOpts: CommonOpts{
+				storeLoader: opts.StoreLoader,
+				loggerFields: []zapcore.Field{
+					zap.String("admin-service", "snapshot"),
+				},
+			},
+		},
+		cluster: &ClusterService{
+			CommonOpts: CommonOpts{
+				storeLoader: opts.StoreLoader,
+				loggerFields: []zapcore.Field{
+					zap.String("admin-service", "cluster"),
+				},
+			},
+			maxDPConfigSize: opts.MaxDPConfigSize,
+		},
 	}
 }
 
@@ -236,6 +304,7 @@ func NewHandler(opts HandlerOpts) (http.Handler, error) {
 	mux := runtime.NewServeMux(
 		runtime.WithMarshalerOption(runtime.MIMEWildcard, json.Marshaller),
 		runtime.WithErrorHandler(util.ErrorHandler),
+		runtime.WithForwardResponseOption(util.SetHTTPETag),
 		runtime.WithForwardResponseOption(util.SetHTTPStatus),
 		runtime.WithForwardResponseOption(util.FinishTrace),
 	)
@@ -349,6 +418,48 @@ func NewHandler(opts HandlerOpts) (http.Handler, error) {
 		return nil, err
 	}
 
+	err = v1.RegisterBatchServiceHandlerServer(context.Background(),
+		mux, services.batch)
+	if err != nil {
+		return nil, err
+	}
+
+	err = v1.RegisterDeclarativeServiceHandlerServer(context.Background(),
+		mux, services.declarative)
+	if err != nil {
+		return nil, err
+	}
+
+	err = v1.RegisterHistoryServiceHandlerServer(context.Background(),
+		mux, services.history)
+	if err != nil {
+		return nil, err
+	}
+
+	err = v1.RegisterDependentServiceHandlerServer(context.Background(),
+		mux, services.dependent)
+	if err != nil {
+		return nil, err
+	}
+
+	err = v1.RegisterIntegrityServiceHandlerServer(context.Background(),
+		mux, services.integrity)
+	if err != nil {
+		return nil, err
+	}
+
+	err = v1.RegisterSnapshotServiceHandlerServer(context.Background(),
+		mux, services.snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	err = v1.RegisterClusterServiceHandlerServer(context.Background(),
+		mux, services.cluster)
+	if err != nil {
+		return nil, err
+	}
+
 	return mux, nil
 }
 
@@ -385,4 +496,11 @@ func RegisterAdminService(server *grpc.Server, opts HandlerOpts) {
 	v1.RegisterConsumerGroupServiceServer(server, services.consumerGroup)
 	v1.RegisterKeyServiceServer(server, services.key)
 	v1.RegisterKeySetServiceServer(server, services.keyset)
+	v1.RegisterBatchServiceServer(server, services.batch)
+	v1.RegisterDeclarativeServiceServer(server, services.declarative)
+	v1.RegisterHistoryServiceServer(server, services.history)
+	v1.RegisterDependentServiceServer(server, services.dependent)
+	v1.RegisterIntegrityServiceServer(server, services.integrity)
+	v1.RegisterSnapshotServiceServer(server, services.snapshot)
+	v1.RegisterClusterServiceServer(server, services.cluster)
 }
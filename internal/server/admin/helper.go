@@ -23,7 +23,14 @@ var (
 // ListOptsFromReq validates & transforms a Protobuf pagination request message to
 // a list of persistence store options. When a nil Protobuf pagination request is
 // passed in, an empty slice of persistence store options and no error is returned.
-func ListOptsFromReq(listOpts *pbModel.PaginationRequest) ([]store.ListOptsFunc, error) {
+//
+// Filters are type-checked against the fields of the given resource type. When the
+// type is empty, or no JSON schema is registered for it, filters are rejected. Filters
+// and sort orders referring to sensitive fields are rejected unless secrets are
+// revealed for ctx, as the results would disclose the values of the fields.
+func ListOptsFromReq(ctx context.Context, typ model.Type,
+	listOpts *pbModel.PaginationRequest,
+) ([]store.ListOptsFunc, error) {
 	// No pagination request message, so we'll no-op.
 	if listOpts == nil {
 		return nil, nil
@@ -37,16 +44,43 @@ func ListOptsFromReq(listOpts *pbModel.PaginationRequest) ([]store.ListOptsFunc,
 		store.ListWithPageNum(int(listOpts.Number)),
 		store.ListWithPageSize(int(listOpts.Size)),
 	}
+	if listOpts.PageToken != "" {
+		opts = append(opts, store.ListWithPageToken(listOpts.PageToken))
+	}
 
 	// Parse the pagination CEL expression filter when provided.
 	if listOpts.Filter != "" {
-		expr, err := validateFilter(celEnv, listOpts.Filter)
+		env, err := celEnvFor(typ)
+		if err != nil {
+			return nil, err
+		}
+		expr, err := validateFilter(env, listOpts.Filter)
+		if err != nil {
+			return nil, err
+		}
+		err = checkSensitiveReferences(ctx, typ, "page.filter", "filtered",
+			filterReferences(expr.Expr))
 		if err != nil {
 			return nil, err
 		}
 		opts = append(opts, store.ListWithFilter(expr))
 	}
 
+	if listOpts.OrderBy != "" {
+		fields, err := parseOrderBy(typ, listOpts.OrderBy)
+		if err != nil {
+			return nil, err
+		}
+		paths := make([][]string, len(fields))
+		for i, field := range fields {
+			paths[i] = []string{field.Name}
+		}
+		if err := checkSensitiveReferences(ctx, typ, "page.order_by", "sorted", paths); err != nil {
+			return nil, err
+		}
+		opts = append(opts, store.ListWithSort(fields...))
+	}
+
 	return opts, nil
 }
 
@@ -54,6 +88,9 @@ func validateListOptions(listOpts *pbModel.PaginationRequest) error {
 	if listOpts.Number < 0 {
 		return util.ErrClient{Message: fmt.Sprintf("invalid page number '%d', page must be > 0", listOpts.Number)}
 	}
+	if listOpts.Number > 0 && listOpts.PageToken != "" {
+		return util.ErrClient{Message: "page number and page token cannot be used together"}
+	}
 	if listOpts.Size < 0 || listOpts.Size > store.MaxPageSize {
 		return util.ErrClient{Message: fmt.Sprintf(
 			"invalid page_size '%d', must be within range [1 - %d]",
@@ -71,6 +108,15 @@ func validUUID(id string) error {
 	return nil
 }
 
+// listPaginationResponse returns the pagination response of a list.
+func listPaginationResponse(list model.ObjectList) *pbModel.PaginationResponse {
+	res := getPaginationResponse(list.GetTotalCount(), list.GetNextPage())
+	if res != nil {
+		res.NextPageToken = list.GetNextPageToken()
+	}
+	return res
+}
+
 func getPaginationResponse(totalCount int, nextPage int) *pbModel.PaginationResponse {
 	if totalCount == 0 {
 		return nil
@@ -87,14 +133,14 @@ func matchesPattern(idOrName string, entity model.Object) bool {
 }
 
 func getEntityByIDOrName(ctx context.Context, idOrName string, entity model.Object, nameOpt store.ReadOptsFunc,
-	s store.Store, logger *zap.Logger,
+	s store.Store, logger *zap.Logger, opts ...store.ReadOptsFunc,
 ) error {
 	if idOrName == "" {
 		return util.ErrClient{Message: "required ID is missing"}
 	}
 	if err := validUUID(idOrName); err == nil {
 		logger.With(zap.String("id", idOrName)).Debug(fmt.Sprintf("reading %v by id", entity.Type()))
-		err = s.Read(ctx, entity, store.GetByID(idOrName))
+		err = s.Read(ctx, entity, append(opts, store.GetByID(idOrName))...)
 		if err != nil {
 			return err
 		}
@@ -103,7 +149,7 @@ func getEntityByIDOrName(ctx context.Context, idOrName string, entity model.Obje
 	if matchesPattern(idOrName, entity) {
 		logger.With(zap.String("name", idOrName)).Debug(fmt.Sprintf("attempting reading %v by name",
 			entity.Type()))
-		err := s.Read(ctx, entity, nameOpt)
+		err := s.Read(ctx, entity, append(opts, nameOpt)...)
 		if err != nil {
 			return err
 		}
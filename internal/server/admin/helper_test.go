@@ -1,10 +1,15 @@
 package admin
 
 import (
+	"context"
 	"testing"
 
 	pbModel "github.com/kong/koko/internal/gen/grpc/kong/admin/model/v1"
+	"github.com/kong/koko/internal/model"
 	"github.com/kong/koko/internal/model/json/validation"
+	"github.com/kong/koko/internal/persistence"
+	"github.com/kong/koko/internal/resource"
+	"github.com/kong/koko/internal/server/util"
 	"github.com/kong/koko/internal/store"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -13,7 +18,7 @@ import (
 func Test_ListOptsFromRequest(t *testing.T) {
 	t.Run("Page 1, Size 1000 is successful", func(t *testing.T) {
 		p := &pbModel.PaginationRequest{Number: 1, Size: 1000}
-		listOptFns, err := ListOptsFromReq(p)
+		listOptFns, err := ListOptsFromReq(context.Background(), resource.TypeService, p)
 		require.NoError(t, err)
 		require.Len(t, listOptFns, 2)
 		listOpts := &store.ListOpts{}
@@ -25,7 +30,7 @@ func Test_ListOptsFromRequest(t *testing.T) {
 	})
 	t.Run("Page 0, Size 10 succeeds with default Page", func(t *testing.T) {
 		p := &pbModel.PaginationRequest{Number: 0, Size: 10}
-		listOptFns, err := ListOptsFromReq(p)
+		listOptFns, err := ListOptsFromReq(context.Background(), resource.TypeService, p)
 		require.NoError(t, err)
 		listOpts := &store.ListOpts{}
 		for _, fn := range listOptFns {
@@ -36,7 +41,7 @@ func Test_ListOptsFromRequest(t *testing.T) {
 	})
 	t.Run("Page 1, Size 0 succeeds with default Page Size", func(t *testing.T) {
 		p := &pbModel.PaginationRequest{Number: 1, Size: 0}
-		listOptFns, err := ListOptsFromReq(p)
+		listOptFns, err := ListOptsFromReq(context.Background(), resource.TypeService, p)
 		require.NoError(t, err)
 		listOpts := &store.ListOpts{}
 		for _, fn := range listOptFns {
@@ -47,11 +52,11 @@ func Test_ListOptsFromRequest(t *testing.T) {
 	})
 	t.Run("Page 1, Size 1001 fails with error", func(t *testing.T) {
 		p := &pbModel.PaginationRequest{Number: 1, Size: 1001}
-		_, err := ListOptsFromReq(p)
+		_, err := ListOptsFromReq(context.Background(), resource.TypeService, p)
 		require.EqualError(t, err, "invalid page_size '1001', must be within range [1 - 1000]")
 	})
 	t.Run("setting filter expression", func(t *testing.T) {
-		listOptFns, err := ListOptsFromReq(&pbModel.PaginationRequest{Filter: `"tag1" in tags`})
+		listOptFns, err := ListOptsFromReq(context.Background(), resource.TypeService, &pbModel.PaginationRequest{Filter: `"tag1" in tags`})
 		require.NoError(t, err)
 		listOpts := &store.ListOpts{}
 		for _, fn := range listOptFns {
@@ -60,7 +65,7 @@ func Test_ListOptsFromRequest(t *testing.T) {
 		require.NotNil(t, listOpts.Filter)
 	})
 	t.Run("setting invalid expression", func(t *testing.T) {
-		_, err := ListOptsFromReq(&pbModel.PaginationRequest{Filter: `"tag1" in undefined`})
+		_, err := ListOptsFromReq(context.Background(), resource.TypeService, &pbModel.PaginationRequest{Filter: `"tag1" in undefined`})
 		assert.Equal(t, validation.Error{
 			Errs: []*pbModel.ErrorDetail{{
 				Type:     pbModel.ErrorType_ERROR_TYPE_FIELD,
@@ -69,4 +74,147 @@ func Test_ListOptsFromRequest(t *testing.T) {
 			}},
 		}, err)
 	})
+	t.Run("setting filter expression on resource fields", func(t *testing.T) {
+		listOptFns, err := ListOptsFromReq(context.Background(), resource.TypeService, &pbModel.PaginationRequest{
+			Filter: `protocol == "https" && port != 443 && name.startsWith("billing-")`,
+		})
+		require.NoError(t, err)
+		listOpts := &store.ListOpts{}
+		for _, fn := range listOptFns {
+			fn(listOpts)
+		}
+		require.NotNil(t, listOpts.Filter)
+	})
+	t.Run("setting expression on a field of another resource", func(t *testing.T) {
+		_, err := ListOptsFromReq(context.Background(), resource.TypeConsumer, &pbModel.PaginationRequest{Filter: `port == 443`})
+		assert.Equal(t, validation.Error{
+			Errs: []*pbModel.ErrorDetail{{
+				Type:     pbModel.ErrorType_ERROR_TYPE_FIELD,
+				Field:    "page.filter",
+				Messages: []string{"invalid filter expression: undeclared reference to 'port'"},
+			}},
+		}, err)
+	})
+	t.Run("setting expression without a resource type", func(t *testing.T) {
+		_, err := ListOptsFromReq(context.Background(), "", &pbModel.PaginationRequest{Filter: `"tag1" in tags`})
+		assert.Equal(t, validation.Error{
+			Errs: []*pbModel.ErrorDetail{{
+				Type:     pbModel.ErrorType_ERROR_TYPE_FIELD,
+				Field:    "page.filter",
+				Messages: []string{"filtering is not supported"},
+			}},
+		}, err)
+	})
+	t.Run("setting sort order", func(t *testing.T) {
+		listOptFns, err := ListOptsFromReq(context.Background(), resource.TypeService, &pbModel.PaginationRequest{
+			OrderBy: "port desc, name ASC,enabled",
+		})
+		require.NoError(t, err)
+		listOpts := &store.ListOpts{}
+		for _, fn := range listOptFns {
+			fn(listOpts)
+		}
+		require.Equal(t, []persistence.SortField{
+			{Name: "port", Kind: persistence.FieldKindInt, Descending: true},
+			{Name: "name", Kind: persistence.FieldKindString},
+			{Name: "enabled", Kind: persistence.FieldKindBool},
+		}, listOpts.Sort)
+	})
+	t.Run("setting invalid sort order", func(t *testing.T) {
+		tests := []struct {
+			orderBy string
+			typ     model.Type
+			message string
+		}{
+			{orderBy: "port up", typ: resource.TypeService,
+				message: "invalid sort direction 'up', must be one of 'asc' or 'desc'"},
+			{orderBy: "name,", typ: resource.TypeService, message: "invalid sort order ''"},
+			{orderBy: "name asc desc", typ: resource.TypeService, message: "invalid sort order 'name asc desc'"},
+			{orderBy: "undefined", typ: resource.TypeService, message: "unknown field 'undefined'"},
+			{orderBy: "tags", typ: resource.TypeService, message: "field 'tags' cannot be sorted by"},
+			{orderBy: "name, name desc", typ: resource.TypeService,
+				message: "field 'name' is sorted by more than once"},
+			{orderBy: "name", message: "sorting is not supported"},
+		}
+		for _, tt := range tests {
+			_, err := ListOptsFromReq(context.Background(), tt.typ, &pbModel.PaginationRequest{OrderBy: tt.orderBy})
+			assert.Equal(t, validation.Error{
+				Errs: []*pbModel.ErrorDetail{{
+					Type:     pbModel.ErrorType_ERROR_TYPE_FIELD,
+					Field:    "page.order_by",
+					Messages: []string{tt.message},
+				}},
+			}, err, tt.orderBy)
+		}
+	})
+	t.Run("referring to sensitive fields", func(t *testing.T) {
+		tests := []struct {
+			typ     model.Type
+			page    *pbModel.PaginationRequest
+			field   string
+			message string
+		}{
+			{
+				typ:     resource.TypeCertificate,
+				page:    &pbModel.PaginationRequest{Filter: `key.startsWith("-----BEGIN")`},
+				field:   "page.filter",
+				message: "field 'key' holds secrets and cannot be filtered by",
+			},
+			{
+				typ:     resource.TypeCertificate,
+				page:    &pbModel.PaginationRequest{Filter: `["a"].exists(x, key_alt == x)`},
+				field:   "page.filter",
+				message: "field 'key_alt' holds secrets and cannot be filtered by",
+			},
+			{
+				typ:     resource.TypeKey,
+				page:    &pbModel.PaginationRequest{Filter: `pem.private_key == "foo"`},
+				field:   "page.filter",
+				message: "field 'pem.private_key' holds secrets and cannot be filtered by",
+			},
+			{
+				typ:     resource.TypeVault,
+				page:    &pbModel.PaginationRequest{Filter: `config.env.prefix == "foo"`},
+				field:   "page.filter",
+				message: "field 'config.env.prefix' holds secrets and cannot be filtered by",
+			},
+			{
+				typ:     resource.TypePlugin,
+				page:    &pbModel.PaginationRequest{Filter: `config.aws_secret.startsWith("a")`},
+				field:   "page.filter",
+				message: "field 'config.aws_secret' holds secrets and cannot be filtered by",
+			},
+			{
+				typ:     resource.TypeCertificate,
+				page:    &pbModel.PaginationRequest{OrderBy: "cert, key"},
+				field:   "page.order_by",
+				message: "field 'key' holds secrets and cannot be sorted by",
+			},
+			{
+				typ:     resource.TypeKey,
+				page:    &pbModel.PaginationRequest{OrderBy: "jwk desc"},
+				field:   "page.order_by",
+				message: "field 'jwk' holds secrets and cannot be sorted by",
+			},
+		}
+		for _, tt := range tests {
+			_, err := ListOptsFromReq(context.Background(), tt.typ, tt.page)
+			assert.Equal(t, validation.Error{
+				Errs: []*pbModel.ErrorDetail{{
+					Type:     pbModel.ErrorType_ERROR_TYPE_FIELD,
+					Field:    tt.field,
+					Messages: []string{tt.message},
+				}},
+			}, err, tt.page.String())
+
+			_, err = ListOptsFromReq(util.WithRevealSecrets(context.Background()), tt.typ, tt.page)
+			assert.NoError(t, err, tt.page.String())
+		}
+
+		_, err := ListOptsFromReq(context.Background(), resource.TypePlugin, &pbModel.PaginationRequest{
+			Filter:  `config.minute == 5 && name == "rate-limiting"`,
+			OrderBy: "name",
+		})
+		assert.NoError(t, err)
+	})
 }
@@ -41,9 +41,12 @@ func setup(t *testing.T) (*httptest.Server, func()) {
 }
 
 func setupWithDB(t *testing.T, store store.Store) (*httptest.Server, func()) {
-	storeLoader := serverUtil.DefaultStoreLoader{
+	return setupWithStoreLoader(t, serverUtil.DefaultStoreLoader{
 		Store: store,
-	}
+	})
+}
+
+func setupWithStoreLoader(t *testing.T, storeLoader serverUtil.StoreLoader) (*httptest.Server, func()) {
 	handler, err := NewHandler(HandlerOpts{
 		Logger:      log.Logger,
 		StoreLoader: storeLoader,
@@ -61,7 +64,8 @@ func setupWithDB(t *testing.T, store store.Store) (*httptest.Server, func()) {
 		resource.SetValidator(luaValidator)
 	}
 
-	h := serverUtil.HandlerWithRecovery(serverUtil.HandlerWithLogger(handler, log.Logger), log.Logger)
+	h := serverUtil.HandlerWithRecovery(serverUtil.HandlerWithLogger(serverUtil.HandlerWithActor(
+		serverUtil.HandlerWithRevealSecrets(handler, true), serverUtil.FakeJWTService), log.Logger), log.Logger)
 	s := httptest.NewServer(h)
 	return s, func() {
 		s.Close()
@@ -0,0 +1,168 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	v1 "github.com/kong/koko/internal/gen/grpc/kong/admin/service/v1"
+	"github.com/kong/koko/internal/model"
+	"github.com/kong/koko/internal/server/util"
+	"github.com/kong/koko/internal/store"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+type HistoryService struct {
+	v1.UnimplementedHistoryServiceServer
+	CommonOpts
+}
+
+func (s *HistoryService) ListRevisions(ctx context.Context,
+	req *v1.ListRevisionsRequest,
+) (*v1.ListRevisionsResponse, error) {
+	if err := validateHistoryRequest(req.Type, req.Id); err != nil {
+		return nil, s.err(ctx, err)
+	}
+	db, err := s.CommonOpts.getDB(ctx, req.Cluster)
+	if err != nil {
+		return nil, err
+	}
+	ctx = context.WithValue(ctx, util.ContextKeyCluster, req.Cluster)
+	listOptFns, err := ListOptsFromReq(ctx, "", req.Page)
+	if err != nil {
+		return nil, s.err(ctx, err)
+	}
+	list, err := db.ListHistory(ctx, model.Type(req.Type), req.Id, listOptFns...)
+	if err != nil {
+		return nil, s.err(ctx, err)
+	}
+	res := &v1.ListRevisionsResponse{
+		Page: getPaginationResponse(list.TotalCount, list.NextPage),
+	}
+	for _, entry := range list.Entries {
+		revision, err := revisionFromEntry(ctx, entry)
+		if err != nil {
+			return nil, s.err(ctx, err)
+		}
+		res.Items = append(res.Items, revision)
+	}
+	return res, nil
+}
+
+func (s *HistoryService) GetRevision(ctx context.Context,
+	req *v1.GetRevisionRequest,
+) (*v1.GetRevisionResponse, error) {
+	if err := validateHistoryRequest(req.Type, req.Id); err != nil {
+		return nil, s.err(ctx, err)
+	}
+	db, err := s.CommonOpts.getDB(ctx, req.Cluster)
+	if err != nil {
+		return nil, err
+	}
+	ctx = context.WithValue(ctx, util.ContextKeyCluster, req.Cluster)
+	entry, err := db.ReadHistory(ctx, model.Type(req.Type), req.Id, req.Revision)
+	if err != nil {
+		return nil, s.err(ctx, err)
+	}
+	revision, err := revisionFromEntry(ctx, entry)
+	if err != nil {
+		return nil, s.err(ctx, err)
+	}
+	return &v1.GetRevisionResponse{Item: revision}, nil
+}
+
+func (s *HistoryService) DiffRevisions(ctx context.Context,
+	req *v1.DiffRevisionsRequest,
+) (*v1.DiffRevisionsResponse, error) {
+	if err := validateHistoryRequest(req.Type, req.Id); err != nil {
+		return nil, s.err(ctx, err)
+	}
+	db, err := s.CommonOpts.getDB(ctx, req.Cluster)
+	if err != nil {
+		return nil, err
+	}
+	ctx = context.WithValue(ctx, util.ContextKeyCluster, req.Cluster)
+	var items [2]*structpb.Struct
+	for i, revision := range []uint64{req.From, req.To} {
+		entry, err := db.ReadHistory(ctx, model.Type(req.Type), req.Id, revision)
+		if err != nil {
+			return nil, s.err(ctx, err)
+		}
+		// Deleted entities have no fields.
+		items[i] = &structpb.Struct{}
+		if entry.New != nil {
+			if items[i], err = structFromObject(ctx, entry.New); err != nil {
+				return nil, s.err(ctx, err)
+			}
+		}
+	}
+	return &v1.DiffRevisionsResponse{
+		Changes: diffFields("", items[0].Fields, items[1].Fields),
+	}, nil
+}
+
+func validateHistoryRequest(typ, id string) error {
+	if !model.ValidType(model.Type(typ)) {
+		return util.ErrClient{Message: fmt.Sprintf("invalid type: '%s'", typ)}
+	}
+	return validUUID(id)
+}
+
+func revisionFromEntry(ctx context.Context, entry store.HistoryEntry) (*v1.Revision, error) {
+	res := &v1.Revision{
+		Type:      string(entry.Type),
+		Id:        entry.ID,
+		Revision:  entry.Revision,
+		Action:    string(entry.Action),
+		Actor:     entry.Actor,
+		CreatedAt: entry.CreatedAt,
+	}
+	var err error
+	if entry.Old != nil {
+		if res.OldItem, err = structFromObject(ctx, entry.Old); err != nil {
+			return nil, err
+		}
+	}
+	if entry.New != nil {
+		if res.NewItem, err = structFromObject(ctx, entry.New); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// diffFields returns the changes between the old and new fields, sorted by
+// field. Nested objects are compared field by field while other values,
+// including lists, are compared as a whole.
+func diffFields(prefix string, oldFields, newFields map[string]*structpb.Value) []*v1.FieldChange {
+	names := make([]string, 0, len(oldFields)+len(newFields))
+	for name := range oldFields {
+		names = append(names, name)
+	}
+	for name := range newFields {
+		if _, ok := oldFields[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var res []*v1.FieldChange
+	for _, name := range names {
+		oldValue, newValue := oldFields[name], newFields[name]
+		field := prefix + name
+		oldStruct, newStruct := oldValue.GetStructValue(), newValue.GetStructValue()
+		if oldStruct != nil && newStruct != nil {
+			res = append(res, diffFields(field+".", oldStruct.Fields, newStruct.Fields)...)
+			continue
+		}
+		if !proto.Equal(oldValue, newValue) {
+			res = append(res, &v1.FieldChange{
+				Field:    field,
+				OldValue: oldValue,
+				NewValue: newValue,
+			})
+		}
+	}
+	return res
+}
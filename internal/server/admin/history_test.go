@@ -0,0 +1,99 @@
+package admin
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gavv/httpexpect/v2"
+	"github.com/google/uuid"
+	serverUtil "github.com/kong/koko/internal/server/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistory(t *testing.T) {
+	s, cleanup := setup(t)
+	defer cleanup()
+	c := httpexpect.Default(t, s.URL)
+
+	user := &serverUtil.FakeUser{
+		ID:  uuid.NewString(),
+		Org: &serverUtil.FakeOrg{ID: uuid.NewString()},
+	}
+	authorization, err := serverUtil.GetAuthBearerTokenHeader(user, nil)
+	require.NoError(t, err)
+
+	id := uuid.NewString()
+	service := goodService()
+	service.Id = id
+	c.POST("/v1/services").WithJSON(service).
+		WithHeader("Authorization", authorization).
+		Expect().Status(http.StatusCreated)
+	service.Host = "foo.example.com"
+	service.Tags = []string{"foo"}
+	// Tokens failing verification are not trusted.
+	unverified := authorization[:strings.LastIndex(authorization, ".")] + ".invalid"
+	c.PUT("/v1/services/"+id).WithJSON(service).
+		WithHeader("Authorization", unverified).
+		Expect().Status(http.StatusOK)
+	c.DELETE("/v1/services/" + id).Expect().Status(http.StatusNoContent)
+
+	t.Run("lists the revisions of an entity", func(t *testing.T) {
+		res := c.GET("/v1/history/service/" + id).Expect()
+		res.Status(http.StatusOK)
+		items := res.JSON().Path("$.items").Array()
+		items.Length().Equal(3)
+		first := items.Element(0).Object()
+		first.ValueEqual("type", "service")
+		first.ValueEqual("id", id)
+		first.ValueEqual("revision", "1")
+		first.ValueEqual("action", "create")
+		first.ValueEqual("actor", user.ID)
+		first.ContainsKey("created_at")
+		first.NotContainsKey("old_item")
+		first.Path("$.new_item.host").Equal("example.com")
+		items.Element(1).Object().ValueEqual("action", "update")
+		items.Element(1).Object().NotContainsKey("actor")
+		last := items.Element(2).Object()
+		last.ValueEqual("action", "delete")
+		last.NotContainsKey("new_item")
+		res.JSON().Path("$.page.total_count").Equal(3)
+	})
+	t.Run("gets a revision of an entity", func(t *testing.T) {
+		res := c.GET("/v1/history/service/" + id + "/2").Expect()
+		res.Status(http.StatusOK)
+		item := res.JSON().Path("$.item").Object()
+		item.ValueEqual("revision", "2")
+		item.Path("$.old_item.host").Equal("example.com")
+		item.Path("$.new_item.host").Equal("foo.example.com")
+	})
+	t.Run("getting an unknown revision fails", func(t *testing.T) {
+		c.GET("/v1/history/service/" + id + "/4").Expect().
+			Status(http.StatusNotFound)
+	})
+	t.Run("diffs two revisions of an entity", func(t *testing.T) {
+		res := c.GET("/v1/history/service/" + id + "/diff/1/2").Expect()
+		res.Status(http.StatusOK)
+		changes := res.JSON().Path("$.changes").Array()
+		// updated_at only changes if the revisions are a second apart.
+		changes.Length().InRange(2, 3)
+		changes.Element(0).Object().ValueEqual("field", "host").
+			ValueEqual("old_value", "example.com").
+			ValueEqual("new_value", "foo.example.com")
+		changes.Element(1).Object().ValueEqual("field", "tags").
+			NotContainsKey("old_value").
+			ValueEqual("new_value", []string{"foo"})
+	})
+	t.Run("diffs with a deleted revision", func(t *testing.T) {
+		res := c.GET("/v1/history/service/" + id + "/diff/2/3").Expect()
+		res.Status(http.StatusOK)
+		for _, change := range res.JSON().Path("$.changes").Array().Iter() {
+			change.Object().NotContainsKey("new_value")
+		}
+	})
+	t.Run("invalid type fails", func(t *testing.T) {
+		res := c.GET("/v1/history/foo/" + id).Expect()
+		res.Status(http.StatusBadRequest)
+		res.JSON().Object().ValueEqual("message", "invalid type: 'foo'")
+	})
+}
@@ -0,0 +1,38 @@
+package admin
+
+import (
+	"context"
+
+	v1 "github.com/kong/koko/internal/gen/grpc/kong/admin/service/v1"
+)
+
+type IntegrityService struct {
+	v1.UnimplementedIntegrityServiceServer
+	CommonOpts
+}
+
+func (s *IntegrityService) CheckIntegrity(ctx context.Context,
+	req *v1.CheckIntegrityRequest,
+) (*v1.CheckIntegrityResponse, error) {
+	db, err := s.CommonOpts.getDB(ctx, req.Cluster)
+	if err != nil {
+		return nil, err
+	}
+	report, err := db.CheckClusterIntegrity(ctx, req.Repair)
+	if err != nil {
+		return nil, s.err(ctx, err)
+	}
+	res := &v1.CheckIntegrityResponse{ObjectCount: int32(report.Objects)}
+	for _, issue := range report.Issues {
+		res.Issues = append(res.Issues, &v1.IntegrityIssue{
+			Kind:     string(issue.Kind),
+			Cluster:  issue.Cluster,
+			Key:      issue.Key,
+			Type:     string(issue.Type),
+			Id:       issue.ID,
+			Message:  issue.Message,
+			Repaired: issue.Repaired,
+		})
+	}
+	return res, nil
+}
@@ -0,0 +1,88 @@
+package admin
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/gavv/httpexpect/v2"
+	"github.com/kong/koko/internal/log"
+	"github.com/kong/koko/internal/resource"
+	serverUtil "github.com/kong/koko/internal/server/util"
+	"github.com/kong/koko/internal/store"
+	"github.com/kong/koko/internal/test/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckIntegrity(t *testing.T) {
+	p, err := util.GetPersister(t)
+	require.Nil(t, err)
+	objectStore := store.New(p, log.Logger)
+	s, cleanup := setupWithStoreLoader(t, serverUtil.ClusterStoreLoader{Store: objectStore})
+	defer cleanup()
+	c := httpexpect.Default(t, s.URL)
+
+	service := goodService()
+	res := c.POST("/v1/services").WithJSON(service).Expect()
+	res.Status(http.StatusCreated)
+	id := res.JSON().Path("$.item.id").String().Raw()
+
+	t.Run("finds no issues in a consistent database", func(t *testing.T) {
+		res := c.POST("/v1/integrity-check").WithJSON(map[string]interface{}{}).Expect()
+		res.Status(http.StatusOK)
+		body := res.JSON().Object()
+		body.ValueEqual("object_count", 1)
+		body.NotContainsKey("issues")
+	})
+	t.Run("reports and repairs missing indexes", func(t *testing.T) {
+		key := "c/default/ix/u/service/name/" + service.Name
+		require.Nil(t, p.Delete(context.Background(), key))
+
+		res := c.POST("/v1/integrity-check").WithJSON(map[string]interface{}{}).Expect()
+		res.Status(http.StatusOK)
+		issues := res.JSON().Path("$.issues").Array()
+		issues.Length().Equal(1)
+		issue := issues.Element(0).Object()
+		issue.ValueEqual("kind", "missing-index")
+		issue.ValueEqual("cluster", "default")
+		issue.ValueEqual("key", key)
+		issue.ValueEqual("type", "service")
+		issue.ValueEqual("id", id)
+		issue.NotContainsKey("repaired")
+
+		res = c.POST("/v1/integrity-check").WithJSON(map[string]bool{"repair": true}).Expect()
+		res.Status(http.StatusOK)
+		res.JSON().Path("$.issues").Array().Element(0).Object().ValueEqual("repaired", true)
+
+		c.GET("/v1/services/" + service.Name).Expect().Status(http.StatusOK)
+		res = c.POST("/v1/integrity-check").WithJSON(map[string]interface{}{}).Expect()
+		res.Status(http.StatusOK)
+		res.JSON().Object().NotContainsKey("issues")
+	})
+	t.Run("checks the requested cluster only", func(t *testing.T) {
+		ctx := context.Background()
+		require.Nil(t, objectStore.CreateCluster(ctx, &store.ClusterInfo{ID: "east"}))
+		east := resource.NewService()
+		east.Service = goodService()
+		require.Nil(t, objectStore.ForCluster("east").Create(ctx, east))
+		key := "c/east/ix/u/service/name/" + east.Service.Name
+		require.Nil(t, p.Delete(ctx, key))
+
+		res := c.POST("/v1/integrity-check").WithJSON(map[string]interface{}{}).Expect()
+		res.Status(http.StatusOK)
+		body := res.JSON().Object()
+		body.ValueEqual("object_count", 1)
+		body.NotContainsKey("issues")
+
+		res = c.POST("/v1/integrity-check").WithJSON(map[string]interface{}{
+			"cluster": map[string]string{"id": "east"},
+		}).Expect()
+		res.Status(http.StatusOK)
+		body = res.JSON().Object()
+		body.ValueEqual("object_count", 1)
+		issues := body.Value("issues").Array()
+		issues.Length().Equal(1)
+		issues.Element(0).Object().ValueEqual("cluster", "east")
+		issues.Element(0).Object().ValueEqual("key", key)
+	})
+}
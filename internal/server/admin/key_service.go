@@ -32,11 +32,17 @@ func (s *KeyService) GetKey(
 		return nil, err
 	}
 	result := resource.NewKey()
-	err = getEntityByIDOrName(ctx, req.Id, result, store.GetByName(req.Id), db, logger)
+	var revision uint64
+	err = getEntityByIDOrName(ctx, req.Id, result, store.GetByName(req.Id), db, logger,
+		store.ReadRevision(&revision))
 	if err != nil {
 		return nil, s.err(ctx, err)
 	}
 
+	if err := redact(ctx, result); err != nil {
+		return nil, s.err(ctx, err)
+	}
+	util.SetETag(ctx, revisionETag(revision))
 	return &v1.GetKeyResponse{
 		Item: result.Key,
 	}, nil
@@ -53,12 +59,17 @@ func (s *KeyService) CreateKey(
 
 	res := resource.NewKey()
 	res.Key = req.Item
-	if err := db.Create(ctx, res); err != nil {
+	var revision uint64
+	if err := db.Create(ctx, res, store.WriteRevision(&revision)); err != nil {
 		s.logger(ctx).Error("unable to create new key entity", zap.Error(err))
 		return nil, s.err(ctx, err)
 	}
 
+	if err := redact(ctx, res); err != nil {
+		return nil, s.err(ctx, err)
+	}
 	util.SetHeader(ctx, http.StatusCreated)
+	util.SetETag(ctx, revisionETag(revision))
 	return &v1.CreateKeyResponse{
 		Item: res.Key,
 	}, nil
@@ -71,15 +82,28 @@ func (s *KeyService) UpsertKey(
 	if err := validUUID(req.Item.Id); err != nil {
 		return nil, s.err(ctx, err)
 	}
+	expected, err := expectedRevision(ctx, req.ExpectedRevision)
+	if err != nil {
+		return nil, s.err(ctx, err)
+	}
 	db, err := s.getDB(ctx, req.Cluster)
 	if err != nil {
 		return nil, err
 	}
 	res := resource.NewKey()
 	res.Key = req.Item
-	if err := db.Upsert(ctx, res); err != nil {
+	if err := restoreRedacted(ctx, db, res); err != nil {
+		return nil, s.err(ctx, err)
+	}
+	var revision uint64
+	if err := db.Upsert(ctx, res, store.UpsertIfRevision(expected),
+		store.WriteRevision(&revision)); err != nil {
+		return nil, s.err(ctx, err)
+	}
+	if err := redact(ctx, res); err != nil {
 		return nil, s.err(ctx, err)
 	}
+	util.SetETag(ctx, revisionETag(revision))
 	return &v1.UpsertKeyResponse{
 		Item: res.Key,
 	}, nil
@@ -92,16 +116,27 @@ func (s *KeyService) DeleteKey(
 	if err := validUUID(req.Id); err != nil {
 		return nil, s.err(ctx, err)
 	}
+	expected, err := expectedRevision(ctx, req.ExpectedRevision)
+	if err != nil {
+		return nil, s.err(ctx, err)
+	}
+	var deleted []store.Dependent
+	opts, err := deleteOpts(req.Mode, &deleted)
+	if err != nil {
+		return nil, s.err(ctx, err)
+	}
 	db, err := s.getDB(ctx, req.Cluster)
 	if err != nil {
 		return nil, err
 	}
-	err = db.Delete(ctx, store.DeleteByID(req.Id), store.DeleteByType(resource.TypeKey))
+	err = db.Delete(ctx, append(opts, store.DeleteByID(req.Id), store.DeleteByType(resource.TypeKey),
+		store.DeleteIfRevision(expected))...)
 	if err != nil {
 		return nil, s.err(ctx, err)
 	}
-	util.SetHeader(ctx, http.StatusNoContent)
-	return &v1.DeleteKeyResponse{}, nil
+	return &v1.DeleteKeyResponse{
+		Deleted: deletedDependents(ctx, req.Mode, deleted),
+	}, nil
 }
 
 func (s *KeyService) ListKeys(
@@ -116,20 +151,26 @@ func (s *KeyService) ListKeys(
 	listFn := []store.ListOptsFunc{}
 
 	list := resource.NewList(resource.TypeKey)
-	listOptFns, err := ListOptsFromReq(req.Page)
+	listOptFns, err := ListOptsFromReq(ctx, resource.TypeKey, req.Page)
 	if err != nil {
 		return nil, s.err(ctx, err)
 	}
 
 	listFn = append(listFn, listOptFns...)
 
+	revisions := map[string]uint64{}
+	listFn = append(listFn, store.ListRevisions(revisions))
 	if err := db.List(ctx, list, listFn...); err != nil {
 		return nil, s.err(ctx, err)
 	}
 
+	if err := redact(ctx, list.GetAll()...); err != nil {
+		return nil, s.err(ctx, err)
+	}
+	util.SetETag(ctx, listETag(revisions))
 	return &v1.ListKeysResponse{
 		Items: keysFromObjects(list.GetAll()),
-		Page:  getPaginationResponse(list.GetTotalCount(), list.GetNextPage()),
+		Page:  listPaginationResponse(list),
 	}, nil
 }
 
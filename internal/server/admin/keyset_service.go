@@ -32,11 +32,14 @@ func (s *KeySetService) GetKeySet(
 		return nil, err
 	}
 	result := resource.NewKeySet()
-	err = getEntityByIDOrName(ctx, req.Id, result, store.GetByName(req.Id), db, logger)
+	var revision uint64
+	err = getEntityByIDOrName(ctx, req.Id, result, store.GetByName(req.Id), db, logger,
+		store.ReadRevision(&revision))
 	if err != nil {
 		return nil, util.HandleErr(ctx, logger, err)
 	}
 
+	util.SetETag(ctx, revisionETag(revision))
 	return &v1.GetKeySetResponse{
 		Item: result.KeySet,
 	}, nil
@@ -53,12 +56,14 @@ func (s *KeySetService) CreateKeySet(
 
 	res := resource.NewKeySet()
 	res.KeySet = req.Item
-	if err := db.Create(ctx, res); err != nil {
+	var revision uint64
+	if err := db.Create(ctx, res, store.WriteRevision(&revision)); err != nil {
 		s.logger(ctx).Error("unable to create keyset entity", zap.Error(err))
 		return nil, s.err(ctx, err)
 	}
 
 	util.SetHeader(ctx, http.StatusCreated)
+	util.SetETag(ctx, revisionETag(revision))
 	return &v1.CreateKeySetResponse{
 		Item: res.KeySet,
 	}, nil
@@ -71,15 +76,22 @@ func (s *KeySetService) UpsertKeySet(
 	if err := validUUID(req.Item.Id); err != nil {
 		return nil, s.err(ctx, err)
 	}
+	expected, err := expectedRevision(ctx, req.ExpectedRevision)
+	if err != nil {
+		return nil, s.err(ctx, err)
+	}
 	db, err := s.getDB(ctx, req.Cluster)
 	if err != nil {
 		return nil, err
 	}
 	res := resource.NewKeySet()
 	res.KeySet = req.Item
-	if err := db.Upsert(ctx, res); err != nil {
+	var revision uint64
+	if err := db.Upsert(ctx, res, store.UpsertIfRevision(expected),
+		store.WriteRevision(&revision)); err != nil {
 		return nil, s.err(ctx, err)
 	}
+	util.SetETag(ctx, revisionETag(revision))
 	return &v1.UpsertKeySetResponse{
 		Item: res.KeySet,
 	}, nil
@@ -92,16 +104,27 @@ func (s *KeySetService) DeleteKeySet(
 	if err := validUUID(req.Id); err != nil {
 		return nil, s.err(ctx, err)
 	}
+	expected, err := expectedRevision(ctx, req.ExpectedRevision)
+	if err != nil {
+		return nil, s.err(ctx, err)
+	}
+	var deleted []store.Dependent
+	opts, err := deleteOpts(req.Mode, &deleted)
+	if err != nil {
+		return nil, s.err(ctx, err)
+	}
 	db, err := s.getDB(ctx, req.Cluster)
 	if err != nil {
 		return nil, err
 	}
-	err = db.Delete(ctx, store.DeleteByID(req.Id), store.DeleteByType(resource.TypeKeySet))
+	err = db.Delete(ctx, append(opts, store.DeleteByID(req.Id), store.DeleteByType(resource.TypeKeySet),
+		store.DeleteIfRevision(expected))...)
 	if err != nil {
 		return nil, s.err(ctx, err)
 	}
-	util.SetHeader(ctx, http.StatusNoContent)
-	return &v1.DeleteKeySetResponse{}, nil
+	return &v1.DeleteKeySetResponse{
+		Deleted: deletedDependents(ctx, req.Mode, deleted),
+	}, nil
 }
 
 func (s *KeySetService) ListKeySets(
@@ -116,20 +139,23 @@ func (s *KeySetService) ListKeySets(
 	listFn := []store.ListOptsFunc{}
 
 	list := resource.NewList(resource.TypeKeySet)
-	listOptFns, err := ListOptsFromReq(req.Page)
+	listOptFns, err := ListOptsFromReq(ctx, resource.TypeKeySet, req.Page)
 	if err != nil {
 		return nil, s.err(ctx, err)
 	}
 
 	listFn = append(listFn, listOptFns...)
 
+	revisions := map[string]uint64{}
+	listFn = append(listFn, store.ListRevisions(revisions))
 	if err := db.List(ctx, list, listFn...); err != nil {
 		return nil, s.err(ctx, err)
 	}
 
+	util.SetETag(ctx, listETag(revisions))
 	return &v1.ListKeySetsResponse{
 		Items: keySetsFromObjects(list.GetAll()),
-		Page:  getPaginationResponse(list.GetTotalCount(), list.GetNextPage()),
+		Page:  listPaginationResponse(list),
 	}, nil
 }
 
@@ -10,14 +10,11 @@ import (
 	"github.com/gavv/httpexpect/v2"
 	"github.com/google/uuid"
 	v1 "github.com/kong/koko/internal/gen/grpc/kong/admin/model/v1"
-	"github.com/kong/koko/internal/model"
 	"github.com/kong/koko/internal/model/json/validation"
-	"github.com/kong/koko/internal/resource"
 	"github.com/kong/koko/internal/test/seed"
 	"github.com/samber/lo"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
-	"google.golang.org/grpc/codes"
 )
 
 type listFilterTestData struct {
@@ -134,6 +131,27 @@ func TestListFiltering(t *testing.T) {
 				pageRequest:      &v1.PaginationRequest{Size: 1, Filter: `"tag-1" in tags`},
 				expectedPagedIDs: lo.Chunk(resourceIDsByTag["tag-1"], 1),
 			},
+			{
+				name:             "mixed operators",
+				pageRequest:      &v1.PaginationRequest{Filter: `"tag-1" in tags || ("tag-2" in tags && "tag-1" in tags)`},
+				expectedPagedIDs: [][]string{resourceIDsByTag["tag-1"]},
+			},
+			{
+				name:             "negation",
+				pageRequest:      &v1.PaginationRequest{Filter: `"tag-1" in tags && !("tag-2" in tags)`},
+				expectedPagedIDs: [][]string{lo.Without(resourceIDsByTag["tag-1"], resourceIDsByTag["tag-1, tag-2"]...)},
+			},
+			{
+				name:             "field comparison",
+				pageRequest:      &v1.PaginationRequest{Filter: `"tag-1" in tags && created_at > 0`},
+				expectedPagedIDs: [][]string{resourceIDsByTag["tag-1"]},
+			},
+			{
+				// Comparing two fields cannot be translated to SQL, and is evaluated after querying.
+				name:             "pagination with evaluated expression",
+				pageRequest:      &v1.PaginationRequest{Size: 1, Filter: `"tag-1" in tags && created_at <= updated_at`},
+				expectedPagedIDs: lo.Chunk(resourceIDsByTag["tag-1"], 1),
+			},
 		}
 
 		t.Run(string(resourceInfo.Name), func(t *testing.T) {
@@ -208,44 +226,184 @@ func TestListFilteringWithSpaces(t *testing.T) {
 	}
 }
 
-func TestListFilteringWithReferenceListing(t *testing.T) {
+func TestListFilteringOnFields(t *testing.T) {
 	s, cleanup := setup(t)
 	defer cleanup()
+	c := httpexpect.Default(t, s.URL)
 
-	refID := uuid.NewString()
+	services := map[string]*v1.Service{
+		"billing-http":  {Name: "billing-http", Host: "example.com", Protocol: "http", Port: 80},
+		"billing-https": {Name: "billing-https", Host: "example.com", Protocol: "https", Port: 8443},
+		"orders-https":  {Name: "orders-https", Host: "example.com", Protocol: "https", Port: 443},
+		"orders_https":  {Name: "orders_https", Host: "example.com", Protocol: "https", Port: 9443},
+	}
+	for _, service := range services {
+		c.POST("/v1/services").WithJSON(service).Expect().Status(http.StatusCreated)
+	}
 
 	tests := []struct {
-		apiPath   string
-		refFields map[string]model.Type
+		filter        string
+		expectedNames []string
 	}{
-		{apiPath: "plugins", refFields: map[string]model.Type{
-			"consumer_id": resource.TypeConsumer,
-			"route_id":    resource.TypeRoute,
-			"service_id":  resource.TypeService,
+		{filter: `protocol == "https" && port != 443`, expectedNames: []string{"billing-https", "orders_https"}},
+		{filter: `name.startsWith("billing-")`, expectedNames: []string{"billing-http", "billing-https"}},
+		{filter: `name.endsWith("s-https")`, expectedNames: []string{"orders-https"}},
+		{filter: `name.contains("_")`, expectedNames: []string{"orders_https"}},
+		{filter: `port > 8000 && port <= 8443`, expectedNames: []string{"billing-https"}},
+		{filter: `!(protocol in ["http", "https"])`},
+		{filter: `created_at > 0 && name in ["billing-http", "orders-https"]`, expectedNames: []string{
+			"billing-http", "orders-https",
 		}},
-		{apiPath: "routes", refFields: map[string]model.Type{"service_id": resource.TypeService}},
-		{apiPath: "snis", refFields: map[string]model.Type{"certificate_id": resource.TypeCertificate}},
-		{apiPath: "targets", refFields: map[string]model.Type{"upstream_id": resource.TypeUpstream}},
+		{filter: `write_timeout == read_timeout && port == 80`, expectedNames: []string{"billing-http"}},
 	}
 	for _, tt := range tests {
-		t.Run(tt.apiPath, func(t *testing.T) {
-			for queryArg, refField := range tt.refFields {
-				t.Run("by "+string(refField), func(t *testing.T) {
-					res := httpexpect.Default(t, s.URL).
-						GET("/v1/"+tt.apiPath).
-						WithQuery("page.filter", `"tag-1" in tags`).
-						WithQuery(queryArg, refID).
-						Expect()
-
-					res.Status(http.StatusBadRequest)
-					body := res.JSON().Object()
-					body.Value("code").Number().Equal(codes.FailedPrecondition)
-					body.Value("message").String().Equal(
-						"listing resources scoped to a resource while " +
-							"applying a filter are not yet supported",
-					)
-				})
+		t.Run(tt.filter, func(t *testing.T) {
+			res := c.GET("/v1/services").WithQuery("page.filter", tt.filter).Expect()
+			res.Status(http.StatusOK)
+			body := res.JSON().Object()
+			if len(tt.expectedNames) == 0 {
+				body.Empty()
+				return
+			}
+			body.Path("$.page.total_count").Equal(len(tt.expectedNames))
+			items := body.Value("items").Array()
+			items.Length().Equal(len(tt.expectedNames))
+			var names []string
+			for _, item := range items.Iter() {
+				names = append(names, item.Object().Value("name").String().Raw())
+			}
+			assert.ElementsMatch(t, tt.expectedNames, names)
+		})
+	}
+}
+
+func TestListFilteringWithReferenceListing(t *testing.T) {
+	s, cleanup := setup(t)
+	defer cleanup()
+	c := httpexpect.Default(t, s.URL)
+
+	service := &v1.Service{Id: uuid.NewString(), Name: "svc", Host: "example.com"}
+	c.POST("/v1/services").WithJSON(service).Expect().Status(http.StatusCreated)
+	upstream := &v1.Upstream{Id: uuid.NewString(), Name: "upstream"}
+	c.POST("/v1/upstreams").WithJSON(upstream).Expect().Status(http.StatusCreated)
+	serviceRef := map[string]string{"id": service.Id}
+	upstreamRef := map[string]string{"id": upstream.Id}
+
+	// Creates entities tagged with the given tags, returning their IDs.
+	create := func(path string, entities ...map[string]interface{}) []string {
+		var ids []string
+		for _, entity := range entities {
+			id := uuid.NewString()
+			entity["id"] = id
+			c.POST(path).WithJSON(entity).Expect().Status(http.StatusCreated)
+			ids = append(ids, id)
+		}
+		return ids
+	}
+	routeIDs := create("/v1/routes",
+		map[string]interface{}{"name": "r1", "paths": []string{"/1"}, "tags": []string{"team-a"}, "service": serviceRef},
+		map[string]interface{}{"name": "r2", "paths": []string{"/2"}, "tags": []string{"team-b"}, "service": serviceRef},
+		map[string]interface{}{"name": "r3", "paths": []string{"/3"}, "tags": []string{"team-a"}, "service": serviceRef},
+		map[string]interface{}{"name": "r4", "paths": []string{"/4"}, "tags": []string{"team-a"}},
+	)
+	routeRef := map[string]string{"id": routeIDs[0]}
+	pluginIDs := create("/v1/plugins",
+		map[string]interface{}{"name": "key-auth", "tags": []string{"prod"}, "route": routeRef},
+		map[string]interface{}{"name": "basic-auth", "route": routeRef},
+		map[string]interface{}{"name": "key-auth", "tags": []string{"prod"}, "service": serviceRef},
+		map[string]interface{}{"name": "basic-auth", "tags": []string{"dev"}, "service": serviceRef},
+	)
+	targetIDs := create("/v1/targets",
+		map[string]interface{}{"target": "10.0.0.1", "tags": []string{"prod"}, "upstream": upstreamRef},
+		map[string]interface{}{"target": "10.0.0.2", "upstream": upstreamRef},
+	)
+
+	tests := []struct {
+		name        string
+		apiPath     string
+		queryArg    string
+		refID       string
+		filter      string
+		expectedIDs []string
+	}{
+		{
+			name:        "routes by service",
+			apiPath:     "routes",
+			queryArg:    "service_id",
+			refID:       service.Id,
+			filter:      `"team-a" in tags`,
+			expectedIDs: []string{routeIDs[0], routeIDs[2]},
+		},
+		{
+			name:        "routes by service with a field filter",
+			apiPath:     "routes",
+			queryArg:    "service_id",
+			refID:       service.Id,
+			filter:      `name != "r1" && "team-a" in tags`,
+			expectedIDs: []string{routeIDs[2]},
+		},
+		{
+			name:        "plugins by route",
+			apiPath:     "plugins",
+			queryArg:    "route_id",
+			refID:       routeIDs[0],
+			filter:      `"prod" in tags`,
+			expectedIDs: []string{pluginIDs[0]},
+		},
+		{
+			name:        "plugins by service",
+			apiPath:     "plugins",
+			queryArg:    "service_id",
+			refID:       service.Id,
+			filter:      `name.startsWith("basic")`,
+			expectedIDs: []string{pluginIDs[3]},
+		},
+		{
+			name:        "targets by upstream",
+			apiPath:     "targets",
+			queryArg:    "upstream_id",
+			refID:       upstream.Id,
+			filter:      `"prod" in tags`,
+			expectedIDs: []string{targetIDs[0]},
+		},
+		{
+			name:     "no matches",
+			apiPath:  "targets",
+			queryArg: "upstream_id",
+			refID:    upstream.Id,
+			filter:   `"dev" in tags`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Lists one item per page in order to also assert the pagination.
+			var ids []string
+			var pageToken string
+			for {
+				req := c.GET("/v1/"+tt.apiPath).
+					WithQuery(tt.queryArg, tt.refID).
+					WithQuery("page.filter", tt.filter).
+					WithQuery("page.size", 1)
+				if pageToken != "" {
+					req = req.WithQuery("page.page_token", pageToken)
+				}
+				res := req.Expect()
+				res.Status(http.StatusOK)
+				body := res.JSON().Object()
+				if len(tt.expectedIDs) == 0 {
+					body.Empty()
+					return
+				}
+				for _, item := range body.Value("items").Array().Iter() {
+					ids = append(ids, item.Object().Value("id").String().Raw())
+				}
+				token, ok := body.Value("page").Object().Raw()["next_page_token"]
+				if !ok {
+					break
+				}
+				pageToken = token.(string)
 			}
+			assert.ElementsMatch(t, tt.expectedIDs, ids)
 		})
 	}
 }
@@ -0,0 +1,109 @@
+package admin
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gavv/httpexpect/v2"
+	"github.com/google/uuid"
+	v1 "github.com/kong/koko/internal/gen/grpc/kong/admin/model/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListSorting(t *testing.T) {
+	s, cleanup := setup(t)
+	defer cleanup()
+	c := httpexpect.Default(t, s.URL)
+
+	services := []*v1.Service{
+		{Name: "a", Host: "example.com", Port: 8080},
+		{Name: "b", Host: "example.com", Port: 443, Protocol: "https"},
+		{Name: "c", Host: "example.com", Port: 8080},
+		{Name: "d", Host: "example.com", Port: 80},
+		{Name: "e", Host: "example.com", Port: 9000, Protocol: "https"},
+	}
+	ids := map[string]string{}
+	for _, service := range services {
+		service.Id = uuid.NewString()
+		ids[service.Name] = service.Id
+		c.POST("/v1/services").WithJSON(service).Expect().Status(http.StatusCreated)
+	}
+
+	// Lists the names of the services page by page, following page tokens.
+	listNames := func(query map[string]string, pageSize int, onPage func(page int)) []string {
+		var names []string
+		token := ""
+		for page := 0; ; page++ {
+			req := c.GET("/v1/services").
+				WithQuery("page.size", pageSize).
+				WithQuery("page.page_token", token)
+			for k, v := range query {
+				req.WithQuery(k, v)
+			}
+			body := req.Expect().Status(http.StatusOK).JSON().Object()
+			for _, item := range body.Value("items").Array().Iter() {
+				names = append(names, item.Object().Value("name").String().Raw())
+			}
+			next, ok := body.Value("page").Object().Raw()["next_page_token"]
+			if !ok {
+				return names
+			}
+			token = next.(string)
+			if onPage != nil {
+				onPage(page)
+			}
+		}
+	}
+
+	t.Run("sorts by multiple fields", func(t *testing.T) {
+		names := listNames(map[string]string{"page.order_by": "port desc, name"}, 2, nil)
+		require.Equal(t, []string{"e", "a", "c", "b", "d"}, names)
+	})
+	t.Run("sorts ties by ID", func(t *testing.T) {
+		names := listNames(map[string]string{"page.order_by": "host"}, 2, nil)
+		require.Len(t, names, 5)
+		byID := make([]string, 0, len(names))
+		for _, name := range names {
+			byID = append(byID, ids[name])
+		}
+		require.IsIncreasing(t, byID)
+	})
+	t.Run("sorts filtered services", func(t *testing.T) {
+		names := listNames(map[string]string{
+			"page.order_by": "name desc",
+			"page.filter":   `protocol == "https" || port == 80`,
+		}, 1, nil)
+		require.Equal(t, []string{"e", "d", "b"}, names)
+	})
+	t.Run("sorts by page number", func(t *testing.T) {
+		body := c.GET("/v1/services").
+			WithQuery("page.order_by", "port,name").
+			WithQuery("page.size", 2).
+			WithQuery("page.number", 2).
+			Expect().Status(http.StatusOK).JSON().Object()
+		items := body.Value("items").Array()
+		items.Length().Equal(2)
+		items.Element(0).Object().Value("name").Equal("a")
+		items.Element(1).Object().Value("name").Equal("c")
+	})
+	t.Run("page tokens are consistent under deletes", func(t *testing.T) {
+		names := listNames(map[string]string{"page.order_by": "port, name"}, 2, func(page int) {
+			if page == 0 {
+				// Deletes the last listed service, which page tokens sort after.
+				c.DELETE("/v1/services/" + ids["b"]).Expect().Status(http.StatusNoContent)
+			}
+		})
+		require.Equal(t, []string{"d", "b", "a", "c", "e"}, names)
+	})
+	t.Run("invalid sort order fails", func(t *testing.T) {
+		res := c.GET("/v1/services").WithQuery("page.order_by", "tags").Expect()
+		res.Status(http.StatusBadRequest)
+		body := res.JSON().Object()
+		body.Value("message").String().Equal("validation error")
+		details := body.Value("details").Array()
+		details.Length().Equal(1)
+		details.Element(0).Object().Value("field").String().Equal("page.order_by")
+		details.Element(0).Object().Value("messages").Array().
+			Equal([]string{"field 'tags' cannot be sorted by"})
+	})
+}
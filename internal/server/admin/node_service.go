@@ -69,9 +69,11 @@ var emptyConfigHash = strings.Repeat("0", 32) //nolint:gomnd
 func (s *NodeService) nodeStatusToCompatibilityStatus(ctx context.Context,
 	configHash string, nodeStatus *nonPublic.NodeStatus,
 ) *pbModel.CompatibilityStatus {
-	// no config hash means the configuration state cannot be reliably tracked
+	// no config hash means the configuration state cannot be reliably tracked,
+	// unless the node was rejected and never received any configuration
 	// no nodeStatus implies the compat status is not tracked
-	if emptyConfigHash == configHash || configHash == "" || nodeStatus == nil {
+	noConfigHash := emptyConfigHash == configHash || configHash == ""
+	if nodeStatus == nil || (noConfigHash && !isRejectedNodeStatus(nodeStatus)) {
 		return &pbModel.CompatibilityStatus{
 			State: pbModel.CompatibilityState_COMPATIBILITY_STATE_UNKNOWN,
 		}
@@ -108,6 +110,17 @@ func (s *NodeService) nodeStatusToCompatibilityStatus(ctx context.Context,
 	}
 }
 
+// isRejectedNodeStatus returns true if nodeStatus records that the node was
+// rejected for not satisfying the data-plane prerequisites.
+func isRejectedNodeStatus(nodeStatus *nonPublic.NodeStatus) bool {
+	for _, issue := range nodeStatus.Issues {
+		if config.ChangeID(issue.GetCode()) == config.ChangeIDPrerequisitesNotMet {
+			return true
+		}
+	}
+	return false
+}
+
 func (s *NodeService) CreateNode(ctx context.Context,
 	req *v1.CreateNodeRequest,
 ) (*v1.CreateNodeResponse, error) {
@@ -149,12 +162,17 @@ func (s *NodeService) DeleteNode(ctx context.Context,
 	if req.Id == "" {
 		return nil, s.err(ctx, util.ErrClient{Message: "required ID is missing"})
 	}
+	var deleted []store.Dependent
+	opts, err := deleteOpts(req.Mode, &deleted)
+	if err != nil {
+		return nil, s.err(ctx, err)
+	}
 	db, err := s.CommonOpts.getDB(ctx, req.Cluster)
 	if err != nil {
 		return nil, err
 	}
-	err = db.Delete(ctx, store.DeleteByID(req.Id),
-		store.DeleteByType(resource.TypeNode))
+	err = db.Delete(ctx, append(opts, store.DeleteByID(req.Id),
+		store.DeleteByType(resource.TypeNode))...)
 	if err != nil {
 		return nil, s.err(ctx, err)
 	}
@@ -166,8 +184,9 @@ func (s *NodeService) DeleteNode(ctx context.Context,
 		return nil, s.err(ctx, err)
 	}
 
-	util.SetHeader(ctx, http.StatusNoContent)
-	return &v1.DeleteNodeResponse{}, nil
+	return &v1.DeleteNodeResponse{
+		Deleted: deletedDependents(ctx, req.Mode, deleted),
+	}, nil
 }
 
 func (s *NodeService) ListNodes(ctx context.Context,
@@ -178,7 +197,7 @@ func (s *NodeService) ListNodes(ctx context.Context,
 		return nil, err
 	}
 	list := resource.NewList(resource.TypeNode)
-	listOptFns, err := ListOptsFromReq(req.Page)
+	listOptFns, err := ListOptsFromReq(ctx, resource.TypeNode, req.Page)
 	if err != nil {
 		return nil, s.err(ctx, err)
 	}
@@ -193,7 +212,7 @@ func (s *NodeService) ListNodes(ctx context.Context,
 	s.addStatusToNodes(ctx, nodes, nodeStatuses)
 	return &v1.ListNodesResponse{
 		Items: nodes,
-		Page:  getPaginationResponse(list.GetTotalCount(), list.GetNextPage()),
+		Page:  listPaginationResponse(list),
 	}, nil
 }
 
@@ -15,6 +15,7 @@ import (
 	nonPublic "github.com/kong/koko/internal/gen/grpc/kong/nonpublic/v1"
 	"github.com/kong/koko/internal/log"
 	"github.com/kong/koko/internal/resource"
+	"github.com/kong/koko/internal/server/kong/ws/config"
 	_ "github.com/kong/koko/internal/server/kong/ws/config/compat"
 	serverUtil "github.com/kong/koko/internal/server/util"
 	"github.com/kong/koko/internal/store"
@@ -403,6 +404,37 @@ func TestNodeRead(t *testing.T) {
 		compatibilityStatus.ValueEqual("state",
 			"COMPATIBILITY_STATE_UNKNOWN")
 	})
+	t.Run("read a rejected node with empty config hash", func(t *testing.T) {
+		n := resource.NewNode()
+		n.Node = goodNode()
+		n.Node.ConfigHash = ""
+		nodeID := n.ID()
+		err = db.Create(ctx, n)
+		require.NoError(t, err)
+
+		nodeStatus := resource.NewNodeStatus()
+		nodeStatus.NodeStatus = &nonPublic.NodeStatus{
+			Id: nodeID,
+			Issues: []*model.CompatibilityIssue{
+				{
+					Code: string(config.ChangeIDPrerequisitesNotMet),
+				},
+			},
+		}
+		err = db.Create(ctx, nodeStatus)
+		require.NoError(t, err)
+
+		res := c.GET("/v1/nodes/" + nodeID).Expect().Status(http.StatusOK)
+		body := res.JSON().Path("$.item").Object()
+		body.ValueEqual("id", nodeID)
+		compatibilityStatus := body.Path("$.compatibility_status").Object()
+		compatibilityStatus.ValueEqual("state",
+			"COMPATIBILITY_STATE_INCOMPATIBLE")
+		issues := compatibilityStatus.Value("issues").Array()
+		issues.Length().Equal(1)
+		issues.Element(0).Object().ValueEqual("code", "D101")
+		issues.Element(0).Object().ValueEqual("severity", "error")
+	})
 }
 
 func TestNodeList(t *testing.T) {
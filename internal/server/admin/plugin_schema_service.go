@@ -77,7 +77,7 @@ func (s *PluginSchemaService) ListLuaPluginSchemas(ctx context.Context,
 
 	listFn := []store.ListOptsFunc{}
 	list := resource.NewList(resource.TypePluginSchema)
-	listOptFns, err := ListOptsFromReq(req.Page)
+	listOptFns, err := ListOptsFromReq(ctx, resource.TypePluginSchema, req.Page)
 	if err != nil {
 		return nil, s.err(ctx, err)
 	}
@@ -90,7 +90,7 @@ func (s *PluginSchemaService) ListLuaPluginSchemas(ctx context.Context,
 
 	return &v1.ListLuaPluginSchemasResponse{
 		Items: pluginSchemasFromObjects(list.GetAll()),
-		Page:  getPaginationResponse(list.GetTotalCount(), list.GetNextPage()),
+		Page:  listPaginationResponse(list),
 	}, nil
 }
 
@@ -100,6 +100,11 @@ func (s *PluginSchemaService) DeleteLuaPluginSchema(ctx context.Context,
 	if err := checkSchemaName(req.Name); err != nil {
 		return nil, s.err(ctx, err)
 	}
+	var deleted []store.Dependent
+	opts, err := deleteOpts(req.Mode, &deleted)
+	if err != nil {
+		return nil, s.err(ctx, err)
+	}
 	db, err := s.CommonOpts.getDB(ctx, req.Cluster)
 	if err != nil {
 		return nil, err
@@ -115,13 +120,14 @@ func (s *PluginSchemaService) DeleteLuaPluginSchema(ctx context.Context,
 		return nil, s.err(ctx, util.ErrClient{Message: errMsg})
 	}
 
-	err = db.Delete(ctx, store.DeleteByID(req.Name),
-		store.DeleteByType(resource.TypePluginSchema))
+	err = db.Delete(ctx, append(opts, store.DeleteByID(req.Name),
+		store.DeleteByType(resource.TypePluginSchema))...)
 	if err != nil {
 		return nil, s.err(ctx, err)
 	}
-	util.SetHeader(ctx, http.StatusNoContent)
-	return &v1.DeleteLuaPluginSchemaResponse{}, nil
+	return &v1.DeleteLuaPluginSchemaResponse{
+		Deleted: deletedDependents(ctx, req.Mode, deleted),
+	}, nil
 }
 
 func (s *PluginSchemaService) isPluginSchemaInUse(
@@ -149,7 +155,7 @@ func (s *PluginSchemaService) getPluginsPage(
 	ctx context.Context, page int32, db store.Store,
 ) ([]*pb.Plugin, int, error) {
 	list := resource.NewList(resource.TypePlugin)
-	listOptFns, err := ListOptsFromReq(&pb.PaginationRequest{
+	listOptFns, err := ListOptsFromReq(ctx, resource.TypePlugin, &pb.PaginationRequest{
 		Number: page,
 		Size:   store.MaxPageSize,
 	})
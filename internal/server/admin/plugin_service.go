@@ -35,11 +35,18 @@ func (s *PluginService) GetPlugin(ctx context.Context,
 		return nil, err
 	}
 	result := resource.NewPlugin()
+	var revision uint64
 	s.logger(ctx).With(zap.String("id", req.Id)).Debug("reading plugin by id")
-	err = db.Read(ctx, result, store.GetByID(req.Id))
+	err = db.Read(ctx, result, store.GetByID(req.Id),
+		store.ReadRevision(&revision))
 	if err != nil {
 		return nil, s.err(ctx, err)
 	}
+	ctx = context.WithValue(ctx, util.ContextKeyCluster, req.Cluster)
+	if err := redact(ctx, result); err != nil {
+		return nil, s.err(ctx, err)
+	}
+	util.SetETag(ctx, revisionETag(revision))
 	return &v1.GetPluginResponse{
 		Item: result.Plugin,
 	}, nil
@@ -55,10 +62,15 @@ func (s *PluginService) CreatePlugin(ctx context.Context,
 	ctx = context.WithValue(ctx, util.ContextKeyCluster, req.Cluster)
 	res := resource.NewPlugin()
 	res.Plugin = req.Item
-	if err := db.Create(ctx, res); err != nil {
+	var revision uint64
+	if err := db.Create(ctx, res, store.WriteRevision(&revision)); err != nil {
+		return nil, s.err(ctx, err)
+	}
+	if err := redact(ctx, res); err != nil {
 		return nil, s.err(ctx, err)
 	}
 	util.SetHeader(ctx, http.StatusCreated)
+	util.SetETag(ctx, revisionETag(revision))
 	return &v1.CreatePluginResponse{
 		Item: res.Plugin,
 	}, nil
@@ -70,6 +82,10 @@ func (s *PluginService) UpsertPlugin(ctx context.Context,
 	if err := validUUID(req.Item.Id); err != nil {
 		return nil, s.err(ctx, err)
 	}
+	expected, err := expectedRevision(ctx, req.ExpectedRevision)
+	if err != nil {
+		return nil, s.err(ctx, err)
+	}
 	db, err := s.CommonOpts.getDB(ctx, req.Cluster)
 	if err != nil {
 		return nil, err
@@ -77,9 +93,18 @@ func (s *PluginService) UpsertPlugin(ctx context.Context,
 	ctx = context.WithValue(ctx, util.ContextKeyCluster, req.Cluster)
 	res := resource.NewPlugin()
 	res.Plugin = req.Item
-	if err := db.Upsert(ctx, res); err != nil {
+	if err := restoreRedacted(ctx, db, res); err != nil {
+		return nil, s.err(ctx, err)
+	}
+	var revision uint64
+	if err := db.Upsert(ctx, res, store.UpsertIfRevision(expected),
+		store.WriteRevision(&revision)); err != nil {
+		return nil, s.err(ctx, err)
+	}
+	if err := redact(ctx, res); err != nil {
 		return nil, s.err(ctx, err)
 	}
+	util.SetETag(ctx, revisionETag(revision))
 	return &v1.UpsertPluginResponse{
 		Item: res.Plugin,
 	}, nil
@@ -91,17 +116,27 @@ func (s *PluginService) DeletePlugin(ctx context.Context,
 	if err := validUUID(req.Id); err != nil {
 		return nil, s.err(ctx, err)
 	}
+	expected, err := expectedRevision(ctx, req.ExpectedRevision)
+	if err != nil {
+		return nil, s.err(ctx, err)
+	}
+	var deleted []store.Dependent
+	opts, err := deleteOpts(req.Mode, &deleted)
+	if err != nil {
+		return nil, s.err(ctx, err)
+	}
 	db, err := s.CommonOpts.getDB(ctx, req.Cluster)
 	if err != nil {
 		return nil, err
 	}
-	err = db.Delete(ctx, store.DeleteByID(req.Id),
-		store.DeleteByType(resource.TypePlugin))
+	err = db.Delete(ctx, append(opts, store.DeleteByID(req.Id),
+		store.DeleteByType(resource.TypePlugin), store.DeleteIfRevision(expected))...)
 	if err != nil {
 		return nil, s.err(ctx, err)
 	}
-	util.SetHeader(ctx, http.StatusNoContent)
-	return &v1.DeletePluginResponse{}, nil
+	return &v1.DeletePluginResponse{
+		Deleted: deletedDependents(ctx, req.Mode, deleted),
+	}, nil
 }
 
 func (s *PluginService) ListPlugins(ctx context.Context,
@@ -152,19 +187,26 @@ func (s *PluginService) ListPlugins(ctx context.Context,
 	}
 
 	list := resource.NewList(resource.TypePlugin)
-	listOptFns, err := ListOptsFromReq(req.Page)
+	listOptFns, err := ListOptsFromReq(ctx, resource.TypePlugin, req.Page)
 	if err != nil {
 		return nil, s.err(ctx, err)
 	}
 	listFn = append(listFn, listOptFns...) // combine all the list options
 
+	revisions := map[string]uint64{}
+	listFn = append(listFn, store.ListRevisions(revisions))
 	if err := db.List(ctx, list, listFn...); err != nil {
 		return nil, s.err(ctx, err)
 	}
 
+	ctx = context.WithValue(ctx, util.ContextKeyCluster, req.Cluster)
+	if err := redact(ctx, list.GetAll()...); err != nil {
+		return nil, s.err(ctx, err)
+	}
+	util.SetETag(ctx, listETag(revisions))
 	return &v1.ListPluginsResponse{
 		Items: pluginsFromObjects(list.GetAll()),
-		Page:  getPaginationResponse(list.GetTotalCount(), list.GetNextPage()),
+		Page:  listPaginationResponse(list),
 	}, nil
 }
 
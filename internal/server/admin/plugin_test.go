@@ -510,6 +510,24 @@ func TestPluginRead(t *testing.T) {
 	})
 }
 
+func TestPluginReadHoldingEncryptionMarker(t *testing.T) {
+	s, cleanup := setup(t)
+	defer cleanup()
+	c := httpexpect.Default(t, s.URL)
+	plugin := `{
+		"name": "request-transformer",
+		"config": {"add": {"headers": ["x-marker:$koko-enc$v1$"]}}
+	}`
+	res := c.POST("/v1/plugins").WithBytes([]byte(plugin)).Expect()
+	res.Status(http.StatusCreated)
+	id := res.JSON().Path("$.item.id").String().Raw()
+
+	c.GET("/v1/plugins/{id}", id).Expect().Status(http.StatusOK).
+		JSON().Path("$.item.config.add.headers[0]").Equal("x-marker:$koko-enc$v1$")
+	c.GET("/v1/plugins").Expect().Status(http.StatusOK).
+		JSON().Path("$.items").Array().Length().Equal(1)
+}
+
 func TestConfiguredPluginsList(t *testing.T) {
 	s, cleanup := setup(t)
 	defer cleanup()
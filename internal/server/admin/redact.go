@@ -0,0 +1,364 @@
+package admin
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	pbModel "github.com/kong/koko/internal/gen/grpc/kong/admin/model/v1"
+	"github.com/kong/koko/internal/json"
+	"github.com/kong/koko/internal/model"
+	"github.com/kong/koko/internal/model/json/generator"
+	"github.com/kong/koko/internal/model/json/validation"
+	"github.com/kong/koko/internal/model/json/validation/typedefs"
+	"github.com/kong/koko/internal/resource"
+	"github.com/kong/koko/internal/server/util"
+	"github.com/kong/koko/internal/store"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// redactedValue replaces the values of sensitive fields in the responses of
+// the admin API. Upserts sending it back in place of a sensitive value keep
+// the stored value.
+const redactedValue = "<redacted>"
+
+var referenceRegex = regexp.MustCompile(typedefs.ReferencePattern)
+
+// sensitiveField is a field of an entity holding secrets.
+type sensitiveField struct {
+	path []string
+	// members are the sensitive members of a string field holding a JSON
+	// object. The field is redacted as a whole when there are none.
+	members []string
+}
+
+func (f sensitiveField) String() string {
+	return strings.Join(f.path, ".")
+}
+
+// objectWithSensitiveFields is implemented by entities whose sensitive fields
+// depend on their contents, e.g.: plugins, whose configuration fields are
+// defined by the schema of the plugin.
+type objectWithSensitiveFields interface {
+	SensitiveFields(ctx context.Context) ([]string, error)
+}
+
+// sensitiveFields returns the sensitive fields of object, as marked on the
+// JSON schema registered for its type.
+func sensitiveFields(ctx context.Context, object model.Object) ([]sensitiveField, error) {
+	var res []sensitiveField
+	if schema, ok := generator.DefaultRegistry.Schema.Definitions[string(object.Type())]; ok {
+		res = schemaSensitiveFields(nil, schema)
+	}
+	if object, ok := object.(objectWithSensitiveFields); ok {
+		paths, err := object.SensitiveFields(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range paths {
+			res = append(res, sensitiveField{path: strings.Split(path, ".")})
+		}
+	}
+	return res, nil
+}
+
+func schemaSensitiveFields(prefix []string, schema *generator.Schema) []sensitiveField {
+	names := make([]string, 0, len(schema.Properties))
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var res []sensitiveField
+	for _, name := range names {
+		property := schema.Properties[name]
+		path := append(append([]string{}, prefix...), name)
+		if config := property.XKokoConfig; config != nil {
+			if config.Sensitive {
+				res = append(res, sensitiveField{path: path})
+				continue
+			}
+			if len(config.SensitiveMembers) > 0 {
+				res = append(res, sensitiveField{path: path, members: config.SensitiveMembers})
+				continue
+			}
+		}
+		res = append(res, schemaSensitiveFields(path, property)...)
+	}
+	return res
+}
+
+// typeSensitiveFields returns the paths of the fields of objects of typ that
+// may hold secrets: the sensitive fields marked on the JSON schema of the type,
+// the fields encrypted at rest and, for plugins, the encrypted fields of every
+// plugin.
+func typeSensitiveFields(ctx context.Context, typ model.Type) ([][]string, error) {
+	var res [][]string
+	if schema, ok := generator.DefaultRegistry.Schema.Definitions[string(typ)]; ok {
+		for _, field := range schemaSensitiveFields(nil, schema) {
+			res = append(res, field.path)
+		}
+	}
+	paths := model.OptionsForType(typ).SensitiveFields
+	if typ == resource.TypePlugin {
+		pluginPaths, err := resource.AllPluginSensitiveFields(ctx)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(append([]string{}, paths...), pluginPaths...)
+	}
+	for _, path := range paths {
+		res = append(res, strings.Split(path, "."))
+	}
+	return res, nil
+}
+
+// checkSensitiveReferences returns a validation.Error for field, e.g.:
+// page.filter, if any of the referenced paths is, holds or is held by a
+// sensitive field of objects of typ, unless secrets are revealed for ctx.
+// Filtering or sorting by sensitive fields would otherwise disclose their
+// values, e.g.: prefix by prefix.
+func checkSensitiveReferences(ctx context.Context, typ model.Type,
+	field, operation string, references [][]string,
+) error {
+	if util.RevealSecrets(ctx) || len(references) == 0 {
+		return nil
+	}
+	sensitive, err := typeSensitiveFields(ctx, typ)
+	if err != nil {
+		return err
+	}
+	for _, reference := range references {
+		for _, path := range sensitive {
+			if !isPathPrefix(reference, path) && !isPathPrefix(path, reference) {
+				continue
+			}
+			return validation.Error{Errs: []*pbModel.ErrorDetail{{
+				Type:  pbModel.ErrorType_ERROR_TYPE_FIELD,
+				Field: field,
+				Messages: []string{fmt.Sprintf("field '%s' holds secrets and cannot be %s by",
+					strings.Join(reference, "."), operation)},
+			}}}
+		}
+	}
+	return nil
+}
+
+func isPathPrefix(prefix, path []string) bool {
+	if len(prefix) > len(path) {
+		return false
+	}
+	for i := range prefix {
+		if prefix[i] != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// redact replaces the values of the sensitive fields of objects with
+// redactedValue, unless the client asked for secrets to be revealed.
+// References to secrets stored in vaults are left as is.
+func redact(ctx context.Context, objects ...model.Object) error {
+	if util.RevealSecrets(ctx) {
+		return nil
+	}
+	for _, object := range objects {
+		fields, err := sensitiveFields(ctx, object)
+		if err != nil {
+			return err
+		}
+		for _, field := range fields {
+			field := field
+			err := updateStringField(object.Resource().ProtoReflect(), field.path,
+				func(value string) (string, error) {
+					return redactValue(value, field.members), nil
+				})
+			if err != nil {
+				return fmt.Errorf("redact field '%s': %w", field, err)
+			}
+		}
+	}
+	return nil
+}
+
+func redactValue(value string, members []string) string {
+	if referenceRegex.MatchString(value) {
+		return value
+	}
+	if len(members) == 0 {
+		return redactedValue
+	}
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(value), &object); err != nil {
+		// Not a JSON object, the value is redacted as a whole.
+		return redactedValue
+	}
+	for _, member := range members {
+		if _, ok := object[member]; ok {
+			object[member] = json.RawMessage(`"` + redactedValue + `"`)
+		}
+	}
+	res, err := marshalMembers(object)
+	if err != nil {
+		return redactedValue
+	}
+	return res
+}
+
+// restoreRedacted sets the sensitive fields of object that hold redactedValue,
+// as sent back by clients that read the object, to the values stored for the
+// object.
+func restoreRedacted(ctx context.Context, db store.Store, object model.Object) error {
+	fields, err := sensitiveFields(ctx, object)
+	if err != nil {
+		return err
+	}
+	var redacted []sensitiveField
+	for _, field := range fields {
+		value, ok := stringField(object.Resource().ProtoReflect(), field.path)
+		if ok && strings.Contains(value, redactedValue) {
+			redacted = append(redacted, field)
+		}
+	}
+	if len(redacted) == 0 {
+		return nil
+	}
+
+	stored, err := model.NewObject(object.Type())
+	if err != nil {
+		return err
+	}
+	err = db.Read(ctx, stored, store.GetByID(object.ID()))
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return util.ErrClient{Message: fmt.Sprintf(
+				"field '%s' holds a redacted value, which can only be sent back for an existing %s",
+				redacted[0], object.Type())}
+		}
+		return err
+	}
+	for _, field := range redacted {
+		storedValue, _ := stringField(stored.Resource().ProtoReflect(), field.path)
+		field := field
+		err := updateStringField(object.Resource().ProtoReflect(), field.path,
+			func(value string) (string, error) {
+				return restoreValue(value, storedValue, field.members)
+			})
+		if err != nil {
+			return fmt.Errorf("restore field '%s': %w", field, err)
+		}
+	}
+	return nil
+}
+
+func restoreValue(value, storedValue string, members []string) (string, error) {
+	if len(members) == 0 {
+		if value == redactedValue {
+			return storedValue, nil
+		}
+		return value, nil
+	}
+	var object, storedObject map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(value), &object); err != nil {
+		// Not a JSON object, validation rejects it.
+		return value, nil //nolint:nilerr
+	}
+	// The stored value may be of another format, e.g.: a PEM key.
+	_ = json.Unmarshal([]byte(storedValue), &storedObject)
+	for _, member := range members {
+		var memberValue string
+		if json.Unmarshal(object[member], &memberValue) != nil || memberValue != redactedValue {
+			continue
+		}
+		if storedMember, ok := storedObject[member]; ok {
+			object[member] = storedMember
+		}
+	}
+	return marshalMembers(object)
+}
+
+// marshalMembers returns the JSON encoding of object, without escaping HTML
+// characters so that redactedValue reads as is.
+func marshalMembers(object map[string]json.RawMessage) (string, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(object); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// stringField returns the value of the string field at path of msg, and
+// whether the field is set.
+func stringField(msg protoreflect.Message, path []string) (string, bool) {
+	var res string
+	var found bool
+	_ = updateStringField(msg, path, func(value string) (string, error) {
+		res, found = value, true
+		return value, nil
+	})
+	return res, found
+}
+
+// updateStringField calls fn with the value of the string field at path of
+// msg, if set, and sets the field to the returned value. Fields of
+// google.protobuf.Struct messages, e.g.: the configuration of plugins, are
+// walked like the fields of any other message.
+func updateStringField(msg protoreflect.Message, path []string,
+	fn func(string) (string, error),
+) error {
+	if s, ok := msg.Interface().(*structpb.Struct); ok {
+		return updateStructField(s, path, fn)
+	}
+	fd := msg.Descriptor().Fields().ByName(protoreflect.Name(path[0]))
+	if fd == nil || fd.IsList() || fd.IsMap() || !msg.Has(fd) {
+		return nil
+	}
+	if len(path) > 1 {
+		if fd.Message() == nil {
+			return nil
+		}
+		return updateStringField(msg.Mutable(fd).Message(), path[1:], fn)
+	}
+	if fd.Kind() != protoreflect.StringKind {
+		return nil
+	}
+	value, err := fn(msg.Get(fd).String())
+	if err != nil {
+		return err
+	}
+	msg.Set(fd, protoreflect.ValueOfString(value))
+	return nil
+}
+
+func updateStructField(s *structpb.Struct, path []string,
+	fn func(string) (string, error),
+) error {
+	value, ok := s.Fields[path[0]]
+	if !ok {
+		return nil
+	}
+	if len(path) > 1 {
+		if nested := value.GetStructValue(); nested != nil {
+			return updateStructField(nested, path[1:], fn)
+		}
+		return nil
+	}
+	stringValue, ok := value.GetKind().(*structpb.Value_StringValue)
+	if !ok {
+		return nil
+	}
+	res, err := fn(stringValue.StringValue)
+	if err != nil {
+		return err
+	}
+	s.Fields[path[0]] = structpb.NewStringValue(res)
+	return nil
+}
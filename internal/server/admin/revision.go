@@ -0,0 +1,68 @@
+package admin
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kong/koko/internal/server/util"
+	"google.golang.org/grpc/metadata"
+)
+
+// ifMatchKeys are the metadata keys carrying the If-Match header of gRPC
+// and HTTP requests respectively.
+var ifMatchKeys = []string{"if-match", "grpcgateway-if-match"}
+
+// expectedRevision returns the revision a write request is conditioned on.
+// The revision of the request takes precedence over the If-Match header.
+// Zero is returned for unconditional writes.
+func expectedRevision(ctx context.Context, reqRevision uint64) (uint64, error) {
+	if reqRevision != 0 {
+		return reqRevision, nil
+	}
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return 0, nil
+	}
+	for _, key := range ifMatchKeys {
+		values := md.Get(key)
+		if len(values) == 0 {
+			continue
+		}
+		value := strings.TrimSpace(values[0])
+		if value == "*" {
+			return 0, nil
+		}
+		revision, err := strconv.ParseUint(
+			strings.Trim(strings.TrimPrefix(value, "W/"), `"`), 10, 64)
+		if err != nil || revision == 0 {
+			return 0, util.ErrClient{Message: fmt.Sprintf("invalid If-Match header: '%s'", value)}
+		}
+		return revision, nil
+	}
+	return 0, nil
+}
+
+// revisionETag returns the strong ETag of a resource at revision.
+func revisionETag(revision uint64) string {
+	return fmt.Sprintf(`"%d"`, revision)
+}
+
+// listETag returns a weak ETag identifying a page of resources by their IDs
+// and revisions.
+func listETag(revisions map[string]uint64) string {
+	ids := make([]string, 0, len(revisions))
+	for id := range revisions {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	h := sha256.New()
+	for _, id := range ids {
+		fmt.Fprintf(h, "%s:%d\n", id, revisions[id])
+	}
+	return fmt.Sprintf(`W/"%s"`, hex.EncodeToString(h.Sum(nil))[:16])
+}
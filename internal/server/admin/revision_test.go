@@ -0,0 +1,116 @@
+package admin
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/gavv/httpexpect/v2"
+	"github.com/google/uuid"
+	v1 "github.com/kong/koko/internal/gen/grpc/kong/admin/model/v1"
+	"github.com/kong/koko/internal/log"
+	"github.com/kong/koko/internal/store"
+	"github.com/kong/koko/internal/test/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRevisionPreconditions(t *testing.T) {
+	s, cleanup := setup(t)
+	defer cleanup()
+	c := httpexpect.Default(t, s.URL)
+
+	id := uuid.NewString()
+	service := goodService()
+	service.Id = id
+
+	t.Run("create returns the first revision", func(t *testing.T) {
+		res := c.POST("/v1/services").WithJSON(service).Expect()
+		res.Status(http.StatusCreated)
+		res.Header("ETag").Equal(`"1"`)
+		res.Header("grpc-metadata-etag").Empty()
+	})
+	t.Run("get returns the current revision", func(t *testing.T) {
+		res := c.GET("/v1/services/" + id).Expect()
+		res.Status(http.StatusOK)
+		res.Header("ETag").Equal(`"1"`)
+		c.GET("/v1/services/foo").Expect().Header("ETag").Equal(`"1"`)
+	})
+	t.Run("list returns a weak etag", func(t *testing.T) {
+		res := c.GET("/v1/services").Expect()
+		res.Status(http.StatusOK)
+		etag := res.Header("ETag").Raw()
+		require.Regexp(t, `^W/"[0-9a-f]{16}"$`, etag)
+		c.GET("/v1/services").Expect().Header("ETag").Equal(etag)
+	})
+	t.Run("upsert with a stale If-Match header fails", func(t *testing.T) {
+		service.Host = "foo.example.com"
+		res := c.PUT("/v1/services/"+id).WithJSON(service).
+			WithHeader("If-Match", `"2"`).Expect()
+		res.Status(http.StatusPreconditionFailed)
+		res.Header("grpc-metadata-koko-status-code").Empty()
+		res.JSON().Object().ValueEqual("message",
+			"revision mismatch: expected '2', got '1'")
+	})
+	t.Run("upsert with the current If-Match header succeeds", func(t *testing.T) {
+		res := c.PUT("/v1/services/"+id).WithJSON(service).
+			WithHeader("If-Match", `"1"`).Expect()
+		res.Status(http.StatusOK)
+		res.Header("ETag").Equal(`"2"`)
+	})
+	t.Run("upsert without a precondition succeeds", func(t *testing.T) {
+		res := c.PUT("/v1/services/" + id).WithJSON(service).Expect()
+		res.Status(http.StatusOK)
+		res.Header("ETag").Equal(`"3"`)
+	})
+	t.Run("upsert with an invalid If-Match header fails", func(t *testing.T) {
+		res := c.PUT("/v1/services/"+id).WithJSON(service).
+			WithHeader("If-Match", "foo").Expect()
+		res.Status(http.StatusBadRequest)
+	})
+	t.Run("expected_revision takes precedence over If-Match", func(t *testing.T) {
+		res := c.PUT("/v1/services/"+id).WithJSON(service).
+			WithQuery("expected_revision", 2).
+			WithHeader("If-Match", `"3"`).Expect()
+		res.Status(http.StatusPreconditionFailed)
+	})
+	t.Run("upsert of a missing service with a revision fails", func(t *testing.T) {
+		missing := &v1.Service{Id: uuid.NewString(), Host: "example.com"}
+		res := c.PUT("/v1/services/"+missing.Id).WithJSON(missing).
+			WithHeader("If-Match", `"1"`).Expect()
+		res.Status(http.StatusPreconditionFailed)
+	})
+	t.Run("delete with a stale revision fails", func(t *testing.T) {
+		res := c.DELETE("/v1/services/"+id).
+			WithQuery("expected_revision", 2).Expect()
+		res.Status(http.StatusPreconditionFailed)
+		c.GET("/v1/services/" + id).Expect().Status(http.StatusOK)
+	})
+	t.Run("delete with the current revision succeeds", func(t *testing.T) {
+		c.DELETE("/v1/services/"+id).WithHeader("If-Match", `W/"3"`).
+			Expect().Status(http.StatusNoContent)
+		c.GET("/v1/services/" + id).Expect().Status(http.StatusNotFound)
+	})
+}
+
+func TestRevisionPreconditionsOfLegacyObjects(t *testing.T) {
+	p, err := util.GetPersister(t)
+	require.Nil(t, err)
+	s, cleanup := setupWithDB(t, store.New(p, log.Logger).ForCluster(store.DefaultCluster))
+	defer cleanup()
+	c := httpexpect.Default(t, s.URL)
+
+	// Objects written before revisions were tracked have no revision.
+	id := uuid.NewString()
+	require.Nil(t, p.Put(context.Background(), "c/default/o/service/"+id,
+		[]byte(`{"type":3,"object":{"id":"`+id+`","host":"example.com"}}`)))
+
+	res := c.GET("/v1/services/" + id).Expect()
+	res.Status(http.StatusOK)
+	res.Header("ETag").Equal(`"1"`)
+
+	service := &v1.Service{Id: id, Host: "foo.example.com"}
+	res = c.PUT("/v1/services/"+id).WithJSON(service).
+		WithHeader("If-Match", res.Header("ETag").Raw()).Expect()
+	res.Status(http.StatusOK)
+	res.Header("ETag").Equal(`"2"`)
+}
@@ -29,10 +29,13 @@ func (s *RouteService) GetRoute(ctx context.Context,
 		return nil, err
 	}
 	result := resource.NewRoute()
-	err = getEntityByIDOrName(ctx, req.Id, result, store.GetByName(req.Id), db, s.logger(ctx))
+	var revision uint64
+	err = getEntityByIDOrName(ctx, req.Id, result, store.GetByName(req.Id), db, s.logger(ctx),
+		store.ReadRevision(&revision))
 	if err != nil {
 		return nil, s.err(ctx, err)
 	}
+	util.SetETag(ctx, revisionETag(revision))
 	return &v1.GetRouteResponse{
 		Item: result.Route,
 	}, nil
@@ -47,10 +50,12 @@ func (s *RouteService) CreateRoute(ctx context.Context,
 	}
 	res := resource.NewRoute()
 	res.Route = req.Item
-	if err := db.Create(ctx, res); err != nil {
+	var revision uint64
+	if err := db.Create(ctx, res, store.WriteRevision(&revision)); err != nil {
 		return nil, s.err(ctx, err)
 	}
 	util.SetHeader(ctx, http.StatusCreated)
+	util.SetETag(ctx, revisionETag(revision))
 	return &v1.CreateRouteResponse{
 		Item: res.Route,
 	}, nil
@@ -62,15 +67,22 @@ func (s *RouteService) UpsertRoute(ctx context.Context,
 	if err := validUUID(req.Item.Id); err != nil {
 		return nil, s.err(ctx, err)
 	}
+	expected, err := expectedRevision(ctx, req.ExpectedRevision)
+	if err != nil {
+		return nil, s.err(ctx, err)
+	}
 	db, err := s.CommonOpts.getDB(ctx, req.Cluster)
 	if err != nil {
 		return nil, err
 	}
 	res := resource.NewRoute()
 	res.Route = req.Item
-	if err := db.Upsert(ctx, res); err != nil {
+	var revision uint64
+	if err := db.Upsert(ctx, res, store.UpsertIfRevision(expected),
+		store.WriteRevision(&revision)); err != nil {
 		return nil, s.err(ctx, err)
 	}
+	util.SetETag(ctx, revisionETag(revision))
 	return &v1.UpsertRouteResponse{
 		Item: res.Route,
 	}, nil
@@ -82,17 +94,27 @@ func (s *RouteService) DeleteRoute(ctx context.Context,
 	if err := validUUID(req.Id); err != nil {
 		return nil, s.err(ctx, err)
 	}
+	expected, err := expectedRevision(ctx, req.ExpectedRevision)
+	if err != nil {
+		return nil, s.err(ctx, err)
+	}
+	var deleted []store.Dependent
+	opts, err := deleteOpts(req.Mode, &deleted)
+	if err != nil {
+		return nil, s.err(ctx, err)
+	}
 	db, err := s.CommonOpts.getDB(ctx, req.Cluster)
 	if err != nil {
 		return nil, err
 	}
-	err = db.Delete(ctx, store.DeleteByID(req.Id),
-		store.DeleteByType(resource.TypeRoute))
+	err = db.Delete(ctx, append(opts, store.DeleteByID(req.Id),
+		store.DeleteByType(resource.TypeRoute), store.DeleteIfRevision(expected))...)
 	if err != nil {
 		return nil, s.err(ctx, err)
 	}
-	util.SetHeader(ctx, http.StatusNoContent)
-	return &v1.DeleteRouteResponse{}, nil
+	return &v1.DeleteRouteResponse{
+		Deleted: deletedDependents(ctx, req.Mode, deleted),
+	}, nil
 }
 
 func (s *RouteService) ListRoutes(ctx context.Context,
@@ -115,20 +137,23 @@ func (s *RouteService) ListRoutes(ctx context.Context,
 	}
 
 	list := resource.NewList(resource.TypeRoute)
-	listOptFns, err := ListOptsFromReq(req.Page)
+	listOptFns, err := ListOptsFromReq(ctx, resource.TypeRoute, req.Page)
 	if err != nil {
 		return nil, s.err(ctx, err)
 	}
 
 	listFn = append(listFn, listOptFns...)
 
+	revisions := map[string]uint64{}
+	listFn = append(listFn, store.ListRevisions(revisions))
 	if err := db.List(ctx, list, listFn...); err != nil {
 		return nil, s.err(ctx, err)
 	}
 
+	util.SetETag(ctx, listETag(revisions))
 	return &v1.ListRoutesResponse{
 		Items: routesFromObjects(list.GetAll()),
-		Page:  getPaginationResponse(list.GetTotalCount(), list.GetNextPage()),
+		Page:  listPaginationResponse(list),
 	}, nil
 }
 
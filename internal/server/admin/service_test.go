@@ -307,7 +307,7 @@ func TestServiceCreate(t *testing.T) {
 		res.Header("grpc-metadata-koko-status-code").Empty()
 		body := res.JSON().Path("$.item").Object()
 		body.Value("cert").String().Equal(goodCertOne)
-		body.Value("key").String().Equal(goodKeyOne)
+		body.Value("key").String().Equal(redactedValue)
 		certID := body.Value("id").String().Raw()
 
 		// create service
@@ -377,7 +377,7 @@ func TestServiceCreate(t *testing.T) {
 			res.Header("grpc-metadata-koko-status-code").Empty()
 			body := res.JSON().Path("$.item").Object()
 			body.Value("cert").String().Equal(goodCertTwo)
-			body.Value("key").String().Equal(goodKeyTwo)
+			body.Value("key").String().Equal(redactedValue)
 			certID := body.Value("id").String().Raw()
 
 			// create service
@@ -412,7 +412,7 @@ func TestServiceCreate(t *testing.T) {
 			res.Header("grpc-metadata-koko-status-code").Empty()
 			body := res.JSON().Path("$.item").Object()
 			body.Value("cert").String().Equal(goodCertThree)
-			body.Value("key").String().Equal(goodKeyThree)
+			body.Value("key").String().Equal(redactedValue)
 			certID := body.Value("id").String().Raw()
 
 			// create service
@@ -712,6 +712,58 @@ func TestServiceDelete(t *testing.T) {
 		c.GET("/v1/plugins/" + servicePluginID).Expect().Status(http.StatusNotFound)
 		c.GET("/v1/plugins/" + routePluginID).Expect().Status(http.StatusNotFound)
 	})
+	t.Run("deleting a service by mode", func(t *testing.T) {
+		svc := goodService()
+		svc.Name = "baz"
+		res := c.POST("/v1/services").WithJSON(svc).Expect()
+		res.Status(http.StatusCreated)
+		sid := res.JSON().Path("$.item.id").String().Raw()
+
+		route := goodRoute()
+		route.Service = &v1.Service{Id: sid}
+		res = c.POST("/v1/routes").WithJSON(route).Expect()
+		res.Status(http.StatusCreated)
+		rid := res.JSON().Path("$.item.id").String().Raw()
+
+		plugin := goodKeyAuthPlugin()
+		plugin.Route = &v1.Route{Id: rid}
+		res = c.POST("/v1/plugins").WithJSON(plugin).Expect()
+		res.Status(http.StatusCreated)
+		pid := res.JSON().Path("$.item.id").String().Raw()
+
+		// restrict refuses to delete a service that has routes
+		res = c.DELETE("/v1/services/"+sid).
+			WithQuery("mode", "DELETE_MODE_RESTRICT").Expect()
+		res.Status(http.StatusConflict)
+		body := res.JSON().Object()
+		body.Value("message").String().Equal(fmt.Sprintf(
+			"service '%s' is referenced by route '%s'", sid, rid))
+		details := body.Value("details").Array()
+		details.Length().Equal(1)
+		details.Element(0).Object().Value("type").Equal(
+			v1.ErrorType_ERROR_TYPE_REFERENCE.String())
+		details.Element(0).Object().Value("messages").Array().
+			Equal([]string{fmt.Sprintf("route '%s'", rid)})
+		c.GET("/v1/services/" + sid).Expect().Status(http.StatusOK)
+		c.GET("/v1/routes/" + rid).Expect().Status(http.StatusOK)
+
+		// invalid modes are rejected
+		c.DELETE("/v1/services/"+sid).
+			WithQuery("mode", 42).Expect().Status(http.StatusBadRequest)
+
+		// cascade lists the dependents deleted recursively
+		res = c.DELETE("/v1/services/"+sid).
+			WithQuery("mode", "DELETE_MODE_CASCADE").Expect()
+		res.Status(http.StatusOK)
+		deleted := res.JSON().Path("$.deleted").Array()
+		deleted.Length().Equal(2)
+		deleted.Contains(
+			map[string]string{"type": "route", "id": rid},
+			map[string]string{"type": "plugin", "id": pid},
+		)
+		c.GET("/v1/routes/" + rid).Expect().Status(http.StatusNotFound)
+		c.GET("/v1/plugins/" + pid).Expect().Status(http.StatusNotFound)
+	})
 }
 
 func TestServiceRead(t *testing.T) {
@@ -0,0 +1,145 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/kong/koko/internal/declarative"
+	v1 "github.com/kong/koko/internal/gen/grpc/kong/admin/service/v1"
+	"github.com/kong/koko/internal/model"
+	"github.com/kong/koko/internal/resource"
+	"github.com/kong/koko/internal/server/util"
+	"github.com/kong/koko/internal/store"
+	"go.uber.org/zap"
+)
+
+// snapshotTypes are the types of the entities saved in a snapshot, ordered
+// such that referenced types come before the types referencing them.
+// Plugin schemas come first as plugins are validated against them.
+var snapshotTypes = append(append([]model.Type{resource.TypePluginSchema},
+	declarative.Types()...), resource.TypeConsumerGroupRateLimitingAdvancedConfig)
+
+type SnapshotService struct {
+	v1.UnimplementedSnapshotServiceServer
+	CommonOpts
+}
+
+func (s *SnapshotService) CreateSnapshot(ctx context.Context,
+	req *v1.CreateSnapshotRequest,
+) (*v1.CreateSnapshotResponse, error) {
+	if err := validSnapshotName(req.Name); err != nil {
+		return nil, s.err(ctx, err)
+	}
+	db, err := s.CommonOpts.getDB(ctx, req.Cluster)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := store.Snapshot{Name: req.Name}
+	hash := resource.NewHash()
+	err = db.Read(ctx, hash, store.GetByID(hash.ID()))
+	switch err {
+	case nil:
+		snapshot.ExpectedHash = hash.Hash.ExpectedHash
+	case store.ErrNotFound:
+		// no configuration has been computed yet
+	default:
+		return nil, s.err(ctx, err)
+	}
+	if err := db.CreateSnapshot(ctx, &snapshot, snapshotTypes); err != nil {
+		return nil, s.err(ctx, err)
+	}
+	util.SetHeader(ctx, http.StatusCreated)
+	return &v1.CreateSnapshotResponse{Item: snapshotToProto(snapshot)}, nil
+}
+
+func (s *SnapshotService) GetSnapshot(ctx context.Context,
+	req *v1.GetSnapshotRequest,
+) (*v1.GetSnapshotResponse, error) {
+	if err := validSnapshotName(req.Name); err != nil {
+		return nil, s.err(ctx, err)
+	}
+	db, err := s.CommonOpts.getDB(ctx, req.Cluster)
+	if err != nil {
+		return nil, err
+	}
+	snapshot, err := db.ReadSnapshot(ctx, req.Name)
+	if err != nil {
+		return nil, s.err(ctx, err)
+	}
+	return &v1.GetSnapshotResponse{Item: snapshotToProto(snapshot)}, nil
+}
+
+func (s *SnapshotService) ListSnapshots(ctx context.Context,
+	req *v1.ListSnapshotsRequest,
+) (*v1.ListSnapshotsResponse, error) {
+	db, err := s.CommonOpts.getDB(ctx, req.Cluster)
+	if err != nil {
+		return nil, err
+	}
+	listOptFns, err := ListOptsFromReq(ctx, "", req.Page)
+	if err != nil {
+		return nil, s.err(ctx, err)
+	}
+	list, err := db.ListSnapshots(ctx, listOptFns...)
+	if err != nil {
+		return nil, s.err(ctx, err)
+	}
+	res := &v1.ListSnapshotsResponse{
+		Page: getPaginationResponse(list.TotalCount, list.NextPage),
+	}
+	for _, snapshot := range list.Snapshots {
+		res.Items = append(res.Items, snapshotToProto(snapshot))
+	}
+	return res, nil
+}
+
+func (s *SnapshotService) DeleteSnapshot(ctx context.Context,
+	req *v1.DeleteSnapshotRequest,
+) (*v1.DeleteSnapshotResponse, error) {
+	if err := validSnapshotName(req.Name); err != nil {
+		return nil, s.err(ctx, err)
+	}
+	db, err := s.CommonOpts.getDB(ctx, req.Cluster)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.DeleteSnapshot(ctx, req.Name); err != nil {
+		return nil, s.err(ctx, err)
+	}
+	util.SetHeader(ctx, http.StatusNoContent)
+	return &v1.DeleteSnapshotResponse{}, nil
+}
+
+func (s *SnapshotService) RestoreSnapshot(ctx context.Context,
+	req *v1.RestoreSnapshotRequest,
+) (*v1.RestoreSnapshotResponse, error) {
+	if err := validSnapshotName(req.Name); err != nil {
+		return nil, s.err(ctx, err)
+	}
+	db, err := s.CommonOpts.getDB(ctx, req.Cluster)
+	if err != nil {
+		return nil, err
+	}
+	s.logger(ctx).Info("restoring snapshot", zap.String("name", req.Name))
+	if err := db.RestoreSnapshot(ctx, req.Name, snapshotTypes); err != nil {
+		return nil, s.err(ctx, err)
+	}
+	return &v1.RestoreSnapshotResponse{}, nil
+}
+
+func validSnapshotName(name string) error {
+	if !nameRegex.MatchString(name) {
+		return util.ErrClient{Message: fmt.Sprintf("invalid snapshot name: '%s'", name)}
+	}
+	return nil
+}
+
+func snapshotToProto(snapshot store.Snapshot) *v1.Snapshot {
+	return &v1.Snapshot{
+		Name:         snapshot.Name,
+		CreatedAt:    snapshot.CreatedAt,
+		ExpectedHash: snapshot.ExpectedHash,
+		EntityCount:  int32(snapshot.ObjectCount),
+	}
+}
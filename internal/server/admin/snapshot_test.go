@@ -0,0 +1,78 @@
+package admin
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gavv/httpexpect/v2"
+	"github.com/google/uuid"
+)
+
+func TestSnapshot(t *testing.T) {
+	s, cleanup := setup(t)
+	defer cleanup()
+	c := httpexpect.Default(t, s.URL)
+
+	id := uuid.NewString()
+	service := goodService()
+	service.Id = id
+	c.POST("/v1/services").WithJSON(service).Expect().Status(http.StatusCreated)
+
+	t.Run("creates a snapshot", func(t *testing.T) {
+		res := c.POST("/v1/snapshots").WithJSON(map[string]string{
+			"name": "before",
+		}).Expect()
+		res.Status(http.StatusCreated)
+		item := res.JSON().Path("$.item").Object()
+		item.ValueEqual("name", "before")
+		item.ValueEqual("entity_count", 1)
+		item.ContainsKey("created_at")
+	})
+	t.Run("creating a snapshot with an existing name fails", func(t *testing.T) {
+		c.POST("/v1/snapshots").WithJSON(map[string]string{
+			"name": "before",
+		}).Expect().Status(http.StatusBadRequest)
+	})
+	t.Run("creating a snapshot with an invalid name fails", func(t *testing.T) {
+		res := c.POST("/v1/snapshots").WithJSON(map[string]string{
+			"name": "foo bar",
+		}).Expect()
+		res.Status(http.StatusBadRequest)
+		res.JSON().Object().ValueEqual("message", "invalid snapshot name: 'foo bar'")
+	})
+	t.Run("gets a snapshot", func(t *testing.T) {
+		res := c.GET("/v1/snapshots/before").Expect()
+		res.Status(http.StatusOK)
+		res.JSON().Path("$.item.name").Equal("before")
+		c.GET("/v1/snapshots/unknown").Expect().Status(http.StatusNotFound)
+	})
+	t.Run("restores a snapshot", func(t *testing.T) {
+		service.Host = "foo.example.com"
+		c.PUT("/v1/services/" + id).WithJSON(service).Expect().Status(http.StatusOK)
+		other := goodService()
+		other.Name = "other"
+		c.POST("/v1/services").WithJSON(other).Expect().Status(http.StatusCreated)
+
+		c.POST("/v1/snapshots/before/restore").WithJSON(map[string]string{}).
+			Expect().Status(http.StatusOK)
+		res := c.GET("/v1/services").Expect()
+		res.Status(http.StatusOK)
+		items := res.JSON().Path("$.items").Array()
+		items.Length().Equal(1)
+		items.Element(0).Object().ValueEqual("host", "example.com")
+	})
+	t.Run("lists and deletes snapshots", func(t *testing.T) {
+		c.POST("/v1/snapshots").WithJSON(map[string]string{
+			"name": "after",
+		}).Expect().Status(http.StatusCreated)
+		res := c.GET("/v1/snapshots").Expect()
+		res.Status(http.StatusOK)
+		res.JSON().Path("$.items").Array().Length().Equal(2)
+		res.JSON().Path("$.page.total_count").Equal(2)
+
+		c.DELETE("/v1/snapshots/after").Expect().Status(http.StatusNoContent)
+		c.DELETE("/v1/snapshots/after").Expect().Status(http.StatusNotFound)
+		res = c.GET("/v1/snapshots").Expect()
+		res.JSON().Path("$.items").Array().Length().Equal(1)
+	})
+}
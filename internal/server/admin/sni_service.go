@@ -27,10 +27,13 @@ func (s *SNIService) GetSNI(ctx context.Context, req *v1.GetSNIRequest) (*v1.Get
 		return nil, err
 	}
 	result := resource.NewSNI()
-	err = getEntityByIDOrName(ctx, req.Id, result, store.GetByName(req.Id), db, s.logger(ctx))
+	var revision uint64
+	err = getEntityByIDOrName(ctx, req.Id, result, store.GetByName(req.Id), db, s.logger(ctx),
+		store.ReadRevision(&revision))
 	if err != nil {
 		return nil, s.err(ctx, err)
 	}
+	util.SetETag(ctx, revisionETag(revision))
 	return &v1.GetSNIResponse{
 		Item: result.SNI,
 	}, nil
@@ -43,10 +46,12 @@ func (s *SNIService) CreateSNI(ctx context.Context, req *v1.CreateSNIRequest) (*
 	}
 	res := resource.NewSNI()
 	res.SNI = req.Item
-	if err := db.Create(ctx, res); err != nil {
+	var revision uint64
+	if err := db.Create(ctx, res, store.WriteRevision(&revision)); err != nil {
 		return nil, s.err(ctx, err)
 	}
 	util.SetHeader(ctx, http.StatusCreated)
+	util.SetETag(ctx, revisionETag(revision))
 	return &v1.CreateSNIResponse{
 		Item: res.SNI,
 	}, nil
@@ -56,15 +61,22 @@ func (s *SNIService) UpsertSNI(ctx context.Context, req *v1.UpsertSNIRequest) (*
 	if err := validUUID(req.Item.Id); err != nil {
 		return nil, s.err(ctx, err)
 	}
+	expected, err := expectedRevision(ctx, req.ExpectedRevision)
+	if err != nil {
+		return nil, s.err(ctx, err)
+	}
 	db, err := s.CommonOpts.getDB(ctx, req.Cluster)
 	if err != nil {
 		return nil, err
 	}
 	res := resource.NewSNI()
 	res.SNI = req.Item
-	if err := db.Upsert(ctx, res); err != nil {
+	var revision uint64
+	if err := db.Upsert(ctx, res, store.UpsertIfRevision(expected),
+		store.WriteRevision(&revision)); err != nil {
 		return nil, s.err(ctx, err)
 	}
+	util.SetETag(ctx, revisionETag(revision))
 	return &v1.UpsertSNIResponse{
 		Item: res.SNI,
 	}, nil
@@ -74,16 +86,26 @@ func (s *SNIService) DeleteSNI(ctx context.Context, req *v1.DeleteSNIRequest) (*
 	if err := validUUID(req.Id); err != nil {
 		return nil, s.err(ctx, err)
 	}
+	expected, err := expectedRevision(ctx, req.ExpectedRevision)
+	if err != nil {
+		return nil, s.err(ctx, err)
+	}
+	var deleted []store.Dependent
+	opts, err := deleteOpts(req.Mode, &deleted)
+	if err != nil {
+		return nil, s.err(ctx, err)
+	}
 	db, err := s.CommonOpts.getDB(ctx, req.Cluster)
 	if err != nil {
 		return nil, err
 	}
-	if err = db.Delete(ctx, store.DeleteByID(req.Id),
-		store.DeleteByType(resource.TypeSNI)); err != nil {
+	if err = db.Delete(ctx, append(opts, store.DeleteByID(req.Id),
+		store.DeleteByType(resource.TypeSNI), store.DeleteIfRevision(expected))...); err != nil {
 		return nil, s.err(ctx, err)
 	}
-	util.SetHeader(ctx, http.StatusNoContent)
-	return &v1.DeleteSNIResponse{}, nil
+	return &v1.DeleteSNIResponse{
+		Deleted: deletedDependents(ctx, req.Mode, deleted),
+	}, nil
 }
 
 func (s *SNIService) ListSNIs(ctx context.Context, req *v1.ListSNIsRequest) (*v1.ListSNIsResponse, error) {
@@ -104,20 +126,23 @@ func (s *SNIService) ListSNIs(ctx context.Context, req *v1.ListSNIsRequest) (*v1
 	}
 
 	list := resource.NewList(resource.TypeSNI)
-	listOptFns, err := ListOptsFromReq(req.Page)
+	listOptFns, err := ListOptsFromReq(ctx, resource.TypeSNI, req.Page)
 	if err != nil {
 		return nil, s.err(ctx, err)
 	}
 
 	listFn = append(listFn, listOptFns...)
 
+	revisions := map[string]uint64{}
+	listFn = append(listFn, store.ListRevisions(revisions))
 	if err := db.List(ctx, list, listFn...); err != nil {
 		return nil, s.err(ctx, err)
 	}
 
+	util.SetETag(ctx, listETag(revisions))
 	return &v1.ListSNIsResponse{
 		Items: snisFromObjects(list.GetAll()),
-		Page:  getPaginationResponse(list.GetTotalCount(), list.GetNextPage()),
+		Page:  listPaginationResponse(list),
 	}, nil
 }
 
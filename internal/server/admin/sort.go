@@ -0,0 +1,74 @@
+package admin
+
+import (
+	"fmt"
+	"strings"
+
+	pbModel "github.com/kong/koko/internal/gen/grpc/kong/admin/model/v1"
+	"github.com/kong/koko/internal/model"
+	"github.com/kong/koko/internal/model/json/generator"
+	"github.com/kong/koko/internal/model/json/validation"
+	"github.com/kong/koko/internal/persistence"
+)
+
+// sortFieldKinds maps the JSON schema types of the fields that can be sorted by to their kind.
+var sortFieldKinds = map[string]persistence.FieldKind{
+	"string":  persistence.FieldKindString,
+	"integer": persistence.FieldKindInt,
+	"number":  persistence.FieldKindDouble,
+	"boolean": persistence.FieldKindBool,
+}
+
+// parseOrderBy parses the sort order of the pagination request, e.g.: `port desc, name`, validating
+// the fields against the JSON schema registered for the given type. A validation.Error is returned
+// when the sort order is invalid.
+func parseOrderBy(typ model.Type, orderBy string) ([]persistence.SortField, error) {
+	schema, ok := generator.DefaultRegistry.Schema.Definitions[string(typ)]
+	if !ok {
+		return nil, orderByError("sorting is not supported")
+	}
+
+	var res []persistence.SortField
+	seen := map[string]bool{}
+	for _, spec := range strings.Split(orderBy, ",") {
+		parts := strings.Fields(spec)
+		if len(parts) == 0 || len(parts) > 2 {
+			return nil, orderByError(fmt.Sprintf("invalid sort order '%s'", strings.TrimSpace(spec)))
+		}
+		field := persistence.SortField{Name: parts[0]}
+		if len(parts) == 2 {
+			switch strings.ToLower(parts[1]) {
+			case "asc":
+			case "desc":
+				field.Descending = true
+			default:
+				return nil, orderByError(fmt.Sprintf(
+					"invalid sort direction '%s', must be one of 'asc' or 'desc'", parts[1]))
+			}
+		}
+
+		property, ok := schema.Properties[field.Name]
+		if !ok {
+			return nil, orderByError(fmt.Sprintf("unknown field '%s'", field.Name))
+		}
+		kind, ok := sortFieldKinds[property.Type]
+		if !ok {
+			return nil, orderByError(fmt.Sprintf("field '%s' cannot be sorted by", field.Name))
+		}
+		if seen[field.Name] {
+			return nil, orderByError(fmt.Sprintf("field '%s' is sorted by more than once", field.Name))
+		}
+		seen[field.Name] = true
+		field.Kind = kind
+		res = append(res, field)
+	}
+	return res, nil
+}
+
+func orderByError(message string) error {
+	return validation.Error{Errs: []*pbModel.ErrorDetail{{
+		Type:     pbModel.ErrorType_ERROR_TYPE_FIELD,
+		Field:    "page.order_by",
+		Messages: []string{message},
+	}}}
+}
@@ -32,11 +32,14 @@ func (s *TargetService) GetTarget(ctx context.Context,
 		return nil, err
 	}
 	result := resource.NewTarget()
+	var revision uint64
 	s.logger(ctx).With(zap.String("id", req.Id)).Debug("reading target by id")
-	err = db.Read(ctx, result, store.GetByID(req.Id))
+	err = db.Read(ctx, result, store.GetByID(req.Id),
+		store.ReadRevision(&revision))
 	if err != nil {
 		return nil, s.err(ctx, err)
 	}
+	util.SetETag(ctx, revisionETag(revision))
 	return &v1.GetTargetResponse{
 		Item: result.Target,
 	}, nil
@@ -51,10 +54,12 @@ func (s *TargetService) CreateTarget(ctx context.Context,
 	}
 	res := resource.NewTarget()
 	res.Target = req.Item
-	if err := db.Create(ctx, res); err != nil {
+	var revision uint64
+	if err := db.Create(ctx, res, store.WriteRevision(&revision)); err != nil {
 		return nil, s.err(ctx, err)
 	}
 	util.SetHeader(ctx, http.StatusCreated)
+	util.SetETag(ctx, revisionETag(revision))
 	return &v1.CreateTargetResponse{
 		Item: res.Target,
 	}, nil
@@ -66,15 +71,22 @@ func (s *TargetService) UpsertTarget(ctx context.Context,
 	if err := validUUID(req.Item.Id); err != nil {
 		return nil, s.err(ctx, err)
 	}
+	expected, err := expectedRevision(ctx, req.ExpectedRevision)
+	if err != nil {
+		return nil, s.err(ctx, err)
+	}
 	db, err := s.CommonOpts.getDB(ctx, req.Cluster)
 	if err != nil {
 		return nil, err
 	}
 	res := resource.NewTarget()
 	res.Target = req.Item
-	if err := db.Upsert(ctx, res); err != nil {
+	var revision uint64
+	if err := db.Upsert(ctx, res, store.UpsertIfRevision(expected),
+		store.WriteRevision(&revision)); err != nil {
 		return nil, s.err(ctx, err)
 	}
+	util.SetETag(ctx, revisionETag(revision))
 	return &v1.UpsertTargetResponse{
 		Item: res.Target,
 	}, nil
@@ -86,17 +98,27 @@ func (s *TargetService) DeleteTarget(ctx context.Context,
 	if err := validUUID(req.Id); err != nil {
 		return nil, s.err(ctx, err)
 	}
+	expected, err := expectedRevision(ctx, req.ExpectedRevision)
+	if err != nil {
+		return nil, s.err(ctx, err)
+	}
+	var deleted []store.Dependent
+	opts, err := deleteOpts(req.Mode, &deleted)
+	if err != nil {
+		return nil, s.err(ctx, err)
+	}
 	db, err := s.CommonOpts.getDB(ctx, req.Cluster)
 	if err != nil {
 		return nil, err
 	}
-	err = db.Delete(ctx, store.DeleteByID(req.Id),
-		store.DeleteByType(resource.TypeTarget))
+	err = db.Delete(ctx, append(opts, store.DeleteByID(req.Id),
+		store.DeleteByType(resource.TypeTarget), store.DeleteIfRevision(expected))...)
 	if err != nil {
 		return nil, s.err(ctx, err)
 	}
-	util.SetHeader(ctx, http.StatusNoContent)
-	return &v1.DeleteTargetResponse{}, nil
+	return &v1.DeleteTargetResponse{
+		Deleted: deletedDependents(ctx, req.Mode, deleted),
+	}, nil
 }
 
 func (s *TargetService) ListTargets(ctx context.Context,
@@ -121,19 +143,22 @@ func (s *TargetService) ListTargets(ctx context.Context,
 
 	list := resource.NewList(resource.TypeTarget)
 
-	listOptFns, err := ListOptsFromReq(req.Page)
+	listOptFns, err := ListOptsFromReq(ctx, resource.TypeTarget, req.Page)
 	if err != nil {
 		return nil, s.err(ctx, err)
 	}
 
 	listFn = append(listFn, listOptFns...)
 
+	revisions := map[string]uint64{}
+	listFn = append(listFn, store.ListRevisions(revisions))
 	if err := db.List(ctx, list, listFn...); err != nil {
 		return nil, s.err(ctx, err)
 	}
+	util.SetETag(ctx, listETag(revisions))
 	return &v1.ListTargetsResponse{
 		Items: targetsFromObjects(list.GetAll()),
-		Page:  getPaginationResponse(list.GetTotalCount(), list.GetNextPage()),
+		Page:  listPaginationResponse(list),
 	}, nil
 }
 
@@ -30,11 +30,17 @@ func (s *VaultService) GetVault(ctx context.Context,
 		return nil, err
 	}
 	result := resource.NewVault()
+	var revision uint64
 	s.logger(ctx).With(zap.String("id", req.Id)).Debug("reading vault by id")
-	err = getEntityByIDOrName(ctx, req.Id, result, store.GetByIndex("prefix", req.Id), db, s.logger(ctx))
+	err = getEntityByIDOrName(ctx, req.Id, result, store.GetByIndex("prefix", req.Id), db, s.logger(ctx),
+		store.ReadRevision(&revision))
 	if err != nil {
 		return nil, s.err(ctx, err)
 	}
+	if err := redact(ctx, result); err != nil {
+		return nil, s.err(ctx, err)
+	}
+	util.SetETag(ctx, revisionETag(revision))
 	return &v1.GetVaultResponse{
 		Item: result.Vault,
 	}, nil
@@ -49,10 +55,15 @@ func (s *VaultService) CreateVault(ctx context.Context,
 	}
 	res := resource.NewVault()
 	res.Vault = req.Item
-	if err := db.Create(ctx, res); err != nil {
+	var revision uint64
+	if err := db.Create(ctx, res, store.WriteRevision(&revision)); err != nil {
+		return nil, s.err(ctx, err)
+	}
+	if err := redact(ctx, res); err != nil {
 		return nil, s.err(ctx, err)
 	}
 	util.SetHeader(ctx, http.StatusCreated)
+	util.SetETag(ctx, revisionETag(revision))
 	return &v1.CreateVaultResponse{
 		Item: res.Vault,
 	}, nil
@@ -64,15 +75,28 @@ func (s *VaultService) UpsertVault(ctx context.Context,
 	if err := validUUID(req.Item.Id); err != nil {
 		return nil, s.err(ctx, err)
 	}
+	expected, err := expectedRevision(ctx, req.ExpectedRevision)
+	if err != nil {
+		return nil, s.err(ctx, err)
+	}
 	db, err := s.CommonOpts.getDB(ctx, req.Cluster)
 	if err != nil {
 		return nil, err
 	}
 	res := resource.NewVault()
 	res.Vault = req.Item
-	if err := db.Upsert(ctx, res); err != nil {
+	if err := restoreRedacted(ctx, db, res); err != nil {
+		return nil, s.err(ctx, err)
+	}
+	var revision uint64
+	if err := db.Upsert(ctx, res, store.UpsertIfRevision(expected),
+		store.WriteRevision(&revision)); err != nil {
+		return nil, s.err(ctx, err)
+	}
+	if err := redact(ctx, res); err != nil {
 		return nil, s.err(ctx, err)
 	}
+	util.SetETag(ctx, revisionETag(revision))
 	return &v1.UpsertVaultResponse{
 		Item: res.Vault,
 	}, nil
@@ -84,17 +108,27 @@ func (s *VaultService) DeleteVault(ctx context.Context,
 	if err := validUUID(req.Id); err != nil {
 		return nil, s.err(ctx, err)
 	}
+	expected, err := expectedRevision(ctx, req.ExpectedRevision)
+	if err != nil {
+		return nil, s.err(ctx, err)
+	}
+	var deleted []store.Dependent
+	opts, err := deleteOpts(req.Mode, &deleted)
+	if err != nil {
+		return nil, s.err(ctx, err)
+	}
 	db, err := s.CommonOpts.getDB(ctx, req.Cluster)
 	if err != nil {
 		return nil, err
 	}
-	err = db.Delete(ctx, store.DeleteByID(req.Id),
-		store.DeleteByType(resource.TypeVault))
+	err = db.Delete(ctx, append(opts, store.DeleteByID(req.Id),
+		store.DeleteByType(resource.TypeVault), store.DeleteIfRevision(expected))...)
 	if err != nil {
 		return nil, s.err(ctx, err)
 	}
-	util.SetHeader(ctx, http.StatusNoContent)
-	return &v1.DeleteVaultResponse{}, nil
+	return &v1.DeleteVaultResponse{
+		Deleted: deletedDependents(ctx, req.Mode, deleted),
+	}, nil
 }
 
 func (s *VaultService) ListVaults(ctx context.Context,
@@ -105,16 +139,22 @@ func (s *VaultService) ListVaults(ctx context.Context,
 		return nil, err
 	}
 	list := resource.NewList(resource.TypeVault)
-	listOptFns, err := ListOptsFromReq(req.Page)
+	listOptFns, err := ListOptsFromReq(ctx, resource.TypeVault, req.Page)
 	if err != nil {
 		return nil, s.err(ctx, err)
 	}
+	revisions := map[string]uint64{}
+	listOptFns = append(listOptFns, store.ListRevisions(revisions))
 	if err := db.List(ctx, list, listOptFns...); err != nil {
 		return nil, s.err(ctx, err)
 	}
+	if err := redact(ctx, list.GetAll()...); err != nil {
+		return nil, s.err(ctx, err)
+	}
+	util.SetETag(ctx, listETag(revisions))
 	return &v1.ListVaultsResponse{
 		Items: vaultsFromObjects(list.GetAll()),
-		Page:  getPaginationResponse(list.GetTotalCount(), list.GetNextPage()),
+		Page:  listPaginationResponse(list),
 	}, nil
 }
 
@@ -23,13 +23,16 @@ type GrpcInterceptorInjector interface {
 }
 
 type GRPC struct {
-	server  *grpc.Server
-	logger  *zap.Logger
-	address string
+	server   *grpc.Server
+	logger   *zap.Logger
+	address  string
+	listener net.Listener
 }
 
 type GRPCOpts struct {
-	Address    string
+	Address string
+	// Listener, when set, is served instead of listening on Address.
+	Listener   net.Listener
 	Logger     *zap.Logger
 	GRPCServer *grpc.Server
 }
@@ -39,9 +42,10 @@ func NewGRPC(opts GRPCOpts) (*GRPC, error) {
 		return nil, fmt.Errorf("GRPCServer is required")
 	}
 	return &GRPC{
-		address: opts.Address,
-		server:  opts.GRPCServer,
-		logger:  opts.Logger.With(zap.String("address", opts.Address)),
+		address:  opts.Address,
+		listener: opts.Listener,
+		server:   opts.GRPCServer,
+		logger:   opts.Logger.With(zap.String("address", opts.Address)),
 	}, nil
 }
 
@@ -50,13 +54,17 @@ func (g *GRPC) Run(ctx context.Context) error {
 	s := g.server
 	go func() {
 		g.logger.Info("starting server")
-		listener, err := net.Listen("tcp", g.address)
-		if err != nil {
-			errCh <- err
-			return
+		listener := g.listener
+		if listener == nil {
+			var err error
+			listener, err = net.Listen("tcp", g.address)
+			if err != nil {
+				errCh <- err
+				return
+			}
 		}
 		// TODO(hbagdi): figure out TLS details
-		err = s.Serve(listener)
+		err := s.Serve(listener)
 		if err != nil {
 			if err != http.ErrServerClosed {
 				errCh <- err
@@ -0,0 +1,255 @@
+package ws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	model "github.com/kong/koko/internal/gen/grpc/kong/admin/model/v1"
+	admin "github.com/kong/koko/internal/gen/grpc/kong/admin/service/v1"
+	"github.com/kong/koko/internal/store"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// clusterHeaderKey is the header selecting the cluster of a data plane when
+// set by a trusted proxy terminating the TLS connections of data planes, see
+// ClusterFromTrustedProxy.
+const clusterHeaderKey = "koko-cluster"
+
+// clusterSyncInterval is the interval at which the managers of deleted
+// clusters are stopped.
+var clusterSyncInterval = 30 * time.Second
+
+// NamedCluster is the Cluster with the given ID.
+type NamedCluster string
+
+func (c NamedCluster) Get() string {
+	return string(c)
+}
+
+// ClusterFromRequest returns the cluster of a data plane, read from the first
+// organizational unit of the subject of its verified client certificate. It
+// defaults to store.DefaultCluster. Data planes setting the Koko-Cluster
+// header to another cluster are refused, as the header is not authenticated.
+func ClusterFromRequest(r *http.Request) (string, error) {
+	cluster, err := clusterFromCertificate(r)
+	if err != nil {
+		return "", err
+	}
+	if header := r.Header.Get(clusterHeaderKey); header != "" && header != cluster {
+		return "", ErrAuth{
+			HTTPStatus: http.StatusForbidden,
+			Message: fmt.Sprintf("cluster '%s' does not match the client certificate",
+				header),
+		}
+	}
+	return cluster, nil
+}
+
+// ClusterFromTrustedProxy returns the cluster of a data plane, read from the
+// Koko-Cluster header of its request, or else like ClusterFromRequest.
+// It must only be used when every request goes through a proxy that
+// authenticates data planes and sets the header, overriding any value sent by
+// data planes.
+func ClusterFromTrustedProxy(r *http.Request) (string, error) {
+	cluster := r.Header.Get(clusterHeaderKey)
+	if cluster == "" {
+		return clusterFromCertificate(r)
+	}
+	if err := validateCluster(cluster); err != nil {
+		return "", err
+	}
+	return cluster, nil
+}
+
+func clusterFromCertificate(r *http.Request) (string, error) {
+	// Authentication already failed without a certificate.
+	cert, err := readTLSCertificate(r)
+	if err != nil || len(cert.Subject.OrganizationalUnit) == 0 {
+		return store.DefaultCluster, nil
+	}
+	cluster := cert.Subject.OrganizationalUnit[0]
+	if err := validateCluster(cluster); err != nil {
+		return "", err
+	}
+	return cluster, nil
+}
+
+func validateCluster(cluster string) error {
+	if err := store.ValidateCluster(cluster); err != nil {
+		return ErrAuth{
+			HTTPStatus: http.StatusBadRequest,
+			Message:    err.Error(),
+		}
+	}
+	return nil
+}
+
+// ClusterAuthenticator authenticates data planes like DefaultAuthenticator,
+// and hands them over to the Manager of their cluster.
+type ClusterAuthenticator struct {
+	Managers *ClusterManagers
+	AuthFn   AuthFn
+	// ClusterFn returns the cluster of a data plane.
+	// Defaults to ClusterFromRequest.
+	ClusterFn func(r *http.Request) (string, error)
+}
+
+func (c *ClusterAuthenticator) Authenticate(r *http.Request) (*Manager, error) {
+	if err := c.AuthFn(r); err != nil {
+		return nil, err
+	}
+	clusterFn := c.ClusterFn
+	if clusterFn == nil {
+		clusterFn = ClusterFromRequest
+	}
+	cluster, err := clusterFn(r)
+	if err != nil {
+		return nil, err
+	}
+	return c.Managers.Get(r.Context(), cluster)
+}
+
+type ClusterManagersOpts struct {
+	// Ctx bounds the lifetime of the managers.
+	Ctx    context.Context
+	Client admin.ClusterServiceClient
+	Logger *zap.Logger
+	// NewManager creates the Manager of a cluster, which must stop once ctx
+	// is done.
+	NewManager func(ctx context.Context, cluster Cluster) (*Manager, error)
+}
+
+// ClusterManagers holds a Manager per cluster, created once the first data
+// plane of the cluster connects. The managers of deleted clusters are stopped
+// and their data planes disconnected.
+type ClusterManagers struct {
+	opts ClusterManagersOpts
+
+	lock     sync.Mutex
+	managers map[string]*clusterManager
+}
+
+type clusterManager struct {
+	manager *Manager
+	cancel  context.CancelFunc
+}
+
+func NewClusterManagers(opts ClusterManagersOpts) (*ClusterManagers, error) {
+	if opts.Ctx == nil {
+		return nil, errors.New("opts.Ctx is required")
+	}
+	if opts.Client == nil {
+		return nil, errors.New("opts.Client is required")
+	}
+	if opts.Logger == nil {
+		return nil, errors.New("opts.Logger is required")
+	}
+	if opts.NewManager == nil {
+		return nil, errors.New("opts.NewManager is required")
+	}
+	return &ClusterManagers{
+		opts:     opts,
+		managers: map[string]*clusterManager{},
+	}, nil
+}
+
+// Get returns the Manager of a cluster. ErrAuth is returned if the cluster
+// does not exist. The default cluster always exists.
+func (c *ClusterManagers) Get(ctx context.Context, cluster string) (*Manager, error) {
+	if m := c.get(cluster); m != nil {
+		return m, nil
+	}
+	// The cluster is looked up without holding the lock, so that connecting
+	// data planes of other clusters aren't held up by the round trip.
+	if cluster != store.DefaultCluster {
+		_, err := c.opts.Client.GetCluster(ctx, &admin.GetClusterRequest{Id: cluster})
+		switch status.Code(err) {
+		case codes.OK:
+		case codes.NotFound, codes.InvalidArgument:
+			return nil, ErrAuth{
+				HTTPStatus: http.StatusNotFound,
+				Message:    fmt.Sprintf("cluster '%s' not found", cluster),
+			}
+		default:
+			return nil, fmt.Errorf("get cluster '%s': %w", cluster, err)
+		}
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	// Another data plane of the cluster may have connected in the meantime.
+	if m, ok := c.managers[cluster]; ok {
+		return m.manager, nil
+	}
+	managerCtx, cancel := context.WithCancel(c.opts.Ctx)
+	manager, err := c.opts.NewManager(managerCtx, NamedCluster(cluster))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	c.opts.Logger.Info("created manager", zap.String("cluster", cluster))
+	c.managers[cluster] = &clusterManager{manager: manager, cancel: cancel}
+	return manager, nil
+}
+
+// get returns the Manager of a cluster, or nil if it is yet to be created.
+func (c *ClusterManagers) get(cluster string) *Manager {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if m, ok := c.managers[cluster]; ok {
+		return m.manager
+	}
+	return nil
+}
+
+// Run stops the managers of deleted clusters until ctx is done.
+func (c *ClusterManagers) Run(ctx context.Context) {
+	ticker := time.NewTicker(clusterSyncInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := c.sync(ctx); err != nil {
+				c.opts.Logger.Error("failed to list clusters", zap.Error(err))
+			}
+		}
+	}
+}
+
+// sync stops the managers of the clusters that no longer exist.
+func (c *ClusterManagers) sync(ctx context.Context) error {
+	clusters := map[string]bool{store.DefaultCluster: true}
+	page := &model.PaginationRequest{Size: store.MaxPageSize, Number: 1}
+	for page.Number != 0 {
+		res, err := c.opts.Client.ListClusters(ctx, &admin.ListClustersRequest{Page: page})
+		if err != nil {
+			return err
+		}
+		for _, cluster := range res.Items {
+			clusters[cluster.Id] = true
+		}
+		page.Number = res.Page.GetNextPageNum()
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for cluster, m := range c.managers {
+		if clusters[cluster] {
+			continue
+		}
+		c.opts.Logger.Info("cluster deleted, disconnecting its nodes",
+			zap.String("cluster", cluster))
+		m.cancel()
+		m.manager.closeNodes()
+		delete(c.managers, cluster)
+	}
+	return nil
+}
@@ -0,0 +1,218 @@
+package ws
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+	"testing"
+
+	model "github.com/kong/koko/internal/gen/grpc/kong/admin/model/v1"
+	admin "github.com/kong/koko/internal/gen/grpc/kong/admin/service/v1"
+	"github.com/kong/koko/internal/log"
+	"github.com/kong/koko/internal/store"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func clusterRequest(header string, ou ...string) *http.Request {
+	r := &http.Request{Header: http.Header{}}
+	if header != "" {
+		r.Header.Set("Koko-Cluster", header)
+	}
+	r.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{
+		{Subject: pkix.Name{OrganizationalUnit: ou}},
+	}}
+	return r
+}
+
+func TestClusterFromRequest(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		request *http.Request
+		cluster string
+	}{
+		{name: "defaults to the default cluster", request: clusterRequest(""), cluster: store.DefaultCluster},
+		{name: "reads the certificate", request: clusterRequest("", "west"), cluster: "west"},
+		{name: "accepts a matching header", request: clusterRequest("west", "west"), cluster: "west"},
+		{name: "defaults without TLS", request: &http.Request{}, cluster: store.DefaultCluster},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cluster, err := ClusterFromRequest(tc.request)
+			require.NoError(t, err)
+			require.Equal(t, tc.cluster, cluster)
+		})
+	}
+	t.Run("headers selecting another cluster are refused", func(t *testing.T) {
+		_, err := ClusterFromRequest(clusterRequest("east", "west"))
+		require.Equal(t, ErrAuth{
+			HTTPStatus: http.StatusForbidden,
+			Message:    "cluster 'east' does not match the client certificate",
+		}, err)
+		_, err = ClusterFromRequest(clusterRequest("east"))
+		require.IsType(t, ErrAuth{}, err)
+	})
+	t.Run("invalid clusters fail", func(t *testing.T) {
+		_, err := ClusterFromRequest(clusterRequest("", "East Coast"))
+		require.Equal(t, ErrAuth{
+			HTTPStatus: http.StatusBadRequest,
+			Message:    "invalid cluster ID: 'East Coast'",
+		}, err)
+	})
+}
+
+func TestClusterFromTrustedProxy(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		request *http.Request
+		cluster string
+	}{
+		{name: "defaults to the default cluster", request: clusterRequest(""), cluster: store.DefaultCluster},
+		{name: "reads the header", request: clusterRequest("east", "west"), cluster: "east"},
+		{name: "reads the certificate", request: clusterRequest("", "west"), cluster: "west"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cluster, err := ClusterFromTrustedProxy(tc.request)
+			require.NoError(t, err)
+			require.Equal(t, tc.cluster, cluster)
+		})
+	}
+	t.Run("invalid clusters fail", func(t *testing.T) {
+		_, err := ClusterFromTrustedProxy(clusterRequest("East Coast"))
+		require.Equal(t, ErrAuth{
+			HTTPStatus: http.StatusBadRequest,
+			Message:    "invalid cluster ID: 'East Coast'",
+		}, err)
+	})
+}
+
+func TestClusterAuthenticator(t *testing.T) {
+	managers, err := NewClusterManagers(ClusterManagersOpts{
+		Ctx:    context.Background(),
+		Client: &clusterClient{clusters: []string{"a", "b"}},
+		Logger: log.Logger,
+		NewManager: func(_ context.Context, cluster Cluster) (*Manager, error) {
+			return &Manager{Cluster: cluster}, nil
+		},
+	})
+	require.NoError(t, err)
+	authenticator := &ClusterAuthenticator{
+		Managers: managers,
+		AuthFn:   func(*http.Request) error { return nil },
+	}
+
+	t.Run("hands data planes to the manager of their certificate", func(t *testing.T) {
+		m, err := authenticator.Authenticate(clusterRequest("", "a"))
+		require.NoError(t, err)
+		require.Equal(t, "a", m.Cluster.Get())
+	})
+	t.Run("data planes cannot select another cluster", func(t *testing.T) {
+		_, err := authenticator.Authenticate(clusterRequest("b", "a"))
+		var authErr ErrAuth
+		require.ErrorAs(t, err, &authErr)
+		require.Equal(t, http.StatusForbidden, authErr.HTTPStatus)
+	})
+}
+
+// clusterClient serves a fixed set of clusters.
+type clusterClient struct {
+	admin.ClusterServiceClient
+	clusters []string
+	// blocked, when set, holds up GetCluster until closed, once it has
+	// signaled entered.
+	blocked, entered chan struct{}
+}
+
+func (c *clusterClient) GetCluster(_ context.Context, req *admin.GetClusterRequest,
+	_ ...grpc.CallOption,
+) (*admin.GetClusterResponse, error) {
+	if c.blocked != nil {
+		c.entered <- struct{}{}
+		<-c.blocked
+	}
+	for _, cluster := range c.clusters {
+		if cluster == req.Id {
+			return &admin.GetClusterResponse{Item: &admin.Cluster{Id: cluster}}, nil
+		}
+	}
+	return nil, status.Error(codes.NotFound, "not found")
+}
+
+func (c *clusterClient) ListClusters(_ context.Context, _ *admin.ListClustersRequest,
+	_ ...grpc.CallOption,
+) (*admin.ListClustersResponse, error) {
+	res := &admin.ListClustersResponse{Page: &model.PaginationResponse{}}
+	for _, cluster := range c.clusters {
+		res.Items = append(res.Items, &admin.Cluster{Id: cluster})
+	}
+	return res, nil
+}
+
+func TestClusterManagers(t *testing.T) {
+	ctx := context.Background()
+	client := &clusterClient{clusters: []string{"east"}}
+	contexts := map[string]context.Context{}
+	managers, err := NewClusterManagers(ClusterManagersOpts{
+		Ctx:    ctx,
+		Client: client,
+		Logger: log.Logger,
+		NewManager: func(ctx context.Context, cluster Cluster) (*Manager, error) {
+			contexts[cluster.Get()] = ctx
+			return &Manager{
+				Cluster:      cluster,
+				nodes:        &NodeList{},
+				pendingNodes: &NodeList{},
+			}, nil
+		},
+	})
+	require.NoError(t, err)
+
+	t.Run("creates a manager per cluster", func(t *testing.T) {
+		def, err := managers.Get(ctx, store.DefaultCluster)
+		require.NoError(t, err)
+		require.Equal(t, store.DefaultCluster, def.Cluster.Get())
+		east, err := managers.Get(ctx, "east")
+		require.NoError(t, err)
+		require.Equal(t, "east", east.Cluster.Get())
+
+		again, err := managers.Get(ctx, "east")
+		require.NoError(t, err)
+		require.Same(t, east, again)
+	})
+	t.Run("looking up a cluster does not hold up other clusters", func(t *testing.T) {
+		client.clusters = []string{"east", "north"}
+		client.blocked, client.entered = make(chan struct{}), make(chan struct{})
+		defer func() { client.blocked = nil }()
+		north := make(chan error)
+		go func() {
+			_, err := managers.Get(ctx, "north")
+			north <- err
+		}()
+		<-client.entered
+
+		east, err := managers.Get(ctx, "east")
+		require.NoError(t, err)
+		require.Equal(t, "east", east.Cluster.Get())
+		close(client.blocked)
+		require.NoError(t, <-north)
+	})
+	t.Run("unknown clusters fail", func(t *testing.T) {
+		_, err := managers.Get(ctx, "west")
+		require.Equal(t, ErrAuth{
+			HTTPStatus: http.StatusNotFound,
+			Message:    "cluster 'west' not found",
+		}, err)
+	})
+	t.Run("managers of deleted clusters are stopped", func(t *testing.T) {
+		client.clusters = nil
+		require.NoError(t, managers.sync(ctx))
+		require.Error(t, contexts["east"].Err())
+		require.NoError(t, contexts[store.DefaultCluster].Err())
+
+		_, err := managers.Get(ctx, "east")
+		require.IsType(t, ErrAuth{}, err)
+	})
+}
@@ -21,24 +21,24 @@ func (l *KongCertificateLoader) Mutate(ctx context.Context,
 	ctx, cancel := context.WithTimeout(ctx, defaultRequestTimeout)
 	defer cancel()
 
-	var pageNum int32 = 1
+	var pageToken string
 	var allCertificates []*v1.Certificate
 	for {
 		resp, err := l.Client.ListCertificates(ctx, &admin.ListCertificatesRequest{
 			Cluster: &v1.RequestCluster{Id: opts.ClusterID},
 			Page: &v1.PaginationRequest{
-				Size:   pageSize,
-				Number: pageNum,
+				Size:      pageSize,
+				PageToken: pageToken,
 			},
 		})
 		if err != nil {
 			return err
 		}
 		allCertificates = append(allCertificates, resp.Items...)
-		if resp.Page == nil || resp.Page.NextPageNum == 0 {
+		if resp.Page == nil || resp.Page.NextPageToken == "" {
 			break
 		}
-		pageNum = resp.Page.NextPageNum
+		pageToken = resp.Page.NextPageToken
 	}
 	res := make([]Map, 0, len(allCertificates))
 	for _, r := range allCertificates {
@@ -69,21 +69,28 @@ type Change struct {
 	Update ConfigTableUpdates
 }
 
-func (c *Change) valid() error {
-	switch c.Metadata.Severity {
+func (m *ChangeMetadata) valid() error {
+	switch m.Severity {
 	case ChangeSeverityWarning:
 	case ChangeSeverityError:
 	default:
-		return fmt.Errorf("invalid change severity: '%v'", c.Metadata.Severity)
+		return fmt.Errorf("invalid change severity: '%v'", m.Severity)
 	}
 
-	if err := c.Metadata.ID.Valid(); err != nil {
+	if err := m.ID.Valid(); err != nil {
 		return err
 	}
 
-	if c.Metadata.Description == "" || c.Metadata.Resolution == "" {
+	if m.Description == "" || m.Resolution == "" {
 		return fmt.Errorf("change has no description or resolution")
 	}
+	return nil
+}
+
+func (c *Change) valid() error {
+	if err := c.Metadata.valid(); err != nil {
+		return err
+	}
 
 	if c.SemverRange == "" {
 		return fmt.Errorf("invalid version range '%v'", c.SemverRange)
@@ -105,6 +112,12 @@ type CompatChangeRegistry interface {
 	// If a change is already registers or if a change is invalid,
 	// it returns an error.
 	Register(c Change) error
+	// RegisterMetadata registers metadata for a change that is not associated
+	// with any configuration update, e.g. a data-plane node being rejected
+	// altogether.
+	// If a change is already registered or if the metadata is invalid,
+	// it returns an error.
+	RegisterMetadata(m ChangeMetadata) error
 	// GetMetadata returns ChangeMetadata for an id.
 	// It returns ErrRegistryEntryNotFound if a change with id has not been previously
 	// registered.
@@ -118,11 +131,14 @@ type CompatChangeRegistry interface {
 // It is not thread-safe.
 type compatChangeRegistryImpl struct {
 	changes map[ChangeID]Change
+	// metadata holds changes that carry no configuration update.
+	metadata map[ChangeID]ChangeMetadata
 }
 
 func newCompatChangeRegistry() CompatChangeRegistry {
 	return &compatChangeRegistryImpl{
-		changes: map[ChangeID]Change{},
+		changes:  map[ChangeID]Change{},
+		metadata: map[ChangeID]ChangeMetadata{},
 	}
 }
 
@@ -131,19 +147,38 @@ func (c *compatChangeRegistryImpl) Register(change Change) error {
 		return fmt.Errorf("invalid change: %w", err)
 	}
 	id := change.Metadata.ID
-	if _, ok := c.changes[id]; ok {
+	if c.registered(id) {
 		return fmt.Errorf("change '%s' already registered", id)
 	}
 	c.changes[id] = change
 	return nil
 }
 
+func (c *compatChangeRegistryImpl) RegisterMetadata(metadata ChangeMetadata) error {
+	if err := metadata.valid(); err != nil {
+		return fmt.Errorf("invalid change: %w", err)
+	}
+	if c.registered(metadata.ID) {
+		return fmt.Errorf("change '%s' already registered", metadata.ID)
+	}
+	c.metadata[metadata.ID] = metadata
+	return nil
+}
+
+func (c *compatChangeRegistryImpl) registered(id ChangeID) bool {
+	_, isChange := c.changes[id]
+	_, isMetadata := c.metadata[id]
+	return isChange || isMetadata
+}
+
 func (c *compatChangeRegistryImpl) GetMetadata(id ChangeID) (ChangeMetadata, error) {
-	res, ok := c.changes[id]
-	if !ok {
-		return ChangeMetadata{}, ErrRegistryEntryNotFound
+	if res, ok := c.changes[id]; ok {
+		return res.Metadata, nil
+	}
+	if res, ok := c.metadata[id]; ok {
+		return res, nil
 	}
-	return res.Metadata, nil
+	return ChangeMetadata{}, ErrRegistryEntryNotFound
 }
 
 func (c *compatChangeRegistryImpl) GetUpdates() VersionedConfigUpdates {
@@ -148,6 +148,60 @@ func TestCompatChangeRegistryImpl_Register(t *testing.T) {
 	})
 }
 
+func TestCompatChangeRegistryImpl_RegisterMetadata(t *testing.T) {
+	registry := newCompatChangeRegistry()
+	require.NoError(t, registry.Register(Change{
+		Metadata: ChangeMetadata{
+			ID:          "T042",
+			Severity:    ChangeSeverityWarning,
+			Description: "42 is not the answer",
+			Resolution:  "Make it so",
+		},
+		SemverRange: "< 2.8.0",
+	}))
+
+	t.Run("invalid metadata errors", func(t *testing.T) {
+		err := registry.RegisterMetadata(ChangeMetadata{ID: "T043"})
+		require.ErrorContains(t, err, "invalid change severity")
+	})
+	t.Run("valid metadata doesn't error", func(t *testing.T) {
+		err := registry.RegisterMetadata(ChangeMetadata{
+			ID:          "T043",
+			Severity:    ChangeSeverityError,
+			Description: "node rejected",
+			Resolution:  "fix the node",
+		})
+		require.NoError(t, err)
+		metadata, err := registry.GetMetadata("T043")
+		require.NoError(t, err)
+		require.Equal(t, "node rejected", metadata.Description)
+	})
+	t.Run("metadata-only changes carry no updates", func(t *testing.T) {
+		updates := registry.GetUpdates()
+		require.Len(t, updates["< 2.8.0"], 1)
+		require.Equal(t, ChangeID("T042"), updates["< 2.8.0"][0].ChangeID)
+	})
+	t.Run("registering with an ID of an existing change errors", func(t *testing.T) {
+		err := registry.RegisterMetadata(ChangeMetadata{
+			ID:          "T042",
+			Severity:    ChangeSeverityError,
+			Description: "node rejected",
+			Resolution:  "fix the node",
+		})
+		require.ErrorContains(t, err, "already registered")
+		err = registry.Register(Change{
+			Metadata: ChangeMetadata{
+				ID:          "T043",
+				Severity:    ChangeSeverityError,
+				Description: "node rejected",
+				Resolution:  "fix the node",
+			},
+			SemverRange: "< 2.8.0",
+		})
+		require.ErrorContains(t, err, "already registered")
+	})
+}
+
 func TestCompatChangeRegistryImpl_GetMetadata(t *testing.T) {
 	registry := newCompatChangeRegistry()
 	require.NoError(t, registry.Register(Change{
@@ -1043,6 +1043,56 @@ var (
 				Remove: true,
 			},
 		},
+		{
+			Metadata: config.ChangeMetadata{
+				ID:          config.ChangeID("P143"),
+				Severity:    config.ChangeSeverityError,
+				Description: standardCoreEntityMessage("consumer_group", "2.7"),
+				Resolution:  standardUpgradeMessage("2.7"),
+			},
+			SemverRange: versionsPre270,
+			Update: config.ConfigTableUpdates{
+				Name:   config.ConsumerGroup.String(),
+				Type:   config.ConsumerGroup,
+				Remove: true,
+			},
+		},
+		{
+			Metadata: config.ChangeMetadata{
+				ID:          config.ChangeID("P144"),
+				Severity:    config.ChangeSeverityError,
+				Description: standardCoreEntityMessage("consumer_group_consumer", "2.7"),
+				Resolution:  standardUpgradeMessage("2.7"),
+			},
+			SemverRange: versionsPre270,
+			Update: config.ConfigTableUpdates{
+				Name:   config.ConsumerGroupConsumer.String(),
+				Type:   config.ConsumerGroupConsumer,
+				Remove: true,
+				// Removal of consumer groups is already reported by P143.
+				DisableChangeTracking: func(rawJSON string) bool {
+					return true
+				},
+			},
+		},
+		{
+			Metadata: config.ChangeMetadata{
+				ID:          config.ChangeID("P145"),
+				Severity:    config.ChangeSeverityError,
+				Description: standardCoreEntityMessage("consumer_group_plugin", "2.7"),
+				Resolution:  standardUpgradeMessage("2.7"),
+			},
+			SemverRange: versionsPre270,
+			Update: config.ConfigTableUpdates{
+				Name:   config.ConsumerGroupPlugin.String(),
+				Type:   config.ConsumerGroupPlugin,
+				Remove: true,
+				// Removal of consumer groups is already reported by P143.
+				DisableChangeTracking: func(rawJSON string) bool {
+					return true
+				},
+			},
+		},
 	}
 )
 
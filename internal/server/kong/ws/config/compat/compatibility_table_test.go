@@ -2553,6 +2553,124 @@ func TestDisableChangeTracking(t *testing.T) {
 		]
 	}
 }
+`,
+			expectedChanges: config.TrackedChanges{},
+		},
+		{
+			name: "[consumer_group] consumer groups and related entities are removed for DP < 2.7",
+			uncompressedPayload: `
+{
+	"config_table": {
+		"consumer_groups": [
+			{
+				"id": "2bbf81d2-a42f-45c1-b41e-c406445ceda4",
+				"name": "gold"
+			}
+		],
+		"consumer_group_consumers": [
+			{
+				"consumer_group": "2bbf81d2-a42f-45c1-b41e-c406445ceda4",
+				"consumer": "d3da058d-3b0f-4b4c-9b7b-3c8f2e7c6a41"
+			}
+		],
+		"consumer_group_plugins": [
+			{
+				"id": "f2f5d6a2-35d5-4c4b-8a85-1b4d0f4e5d0c",
+				"name": "rate-limiting-advanced",
+				"consumer_group": "2bbf81d2-a42f-45c1-b41e-c406445ceda4",
+				"config": {
+					"window_size": [60],
+					"limit": [10],
+					"window_type": "sliding"
+				}
+			}
+		]
+	}
+}
+`,
+			dataPlaneVersion: "2.6.0",
+			expectedPayload: `
+{
+	"config_table": {
+	}
+}
+`,
+			expectedChanges: config.TrackedChanges{
+				ChangeDetails: []config.ChangeDetail{
+					{
+						ID: "P143",
+						Resources: []config.ResourceInfo{
+							{
+								Type: "consumer_group",
+								ID:   "2bbf81d2-a42f-45c1-b41e-c406445ceda4",
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "[consumer_group] consumer groups and related entities are not removed for DP >= 2.7",
+			uncompressedPayload: `
+{
+	"config_table": {
+		"consumer_groups": [
+			{
+				"id": "2bbf81d2-a42f-45c1-b41e-c406445ceda4",
+				"name": "gold"
+			}
+		],
+		"consumer_group_consumers": [
+			{
+				"consumer_group": "2bbf81d2-a42f-45c1-b41e-c406445ceda4",
+				"consumer": "d3da058d-3b0f-4b4c-9b7b-3c8f2e7c6a41"
+			}
+		],
+		"consumer_group_plugins": [
+			{
+				"id": "f2f5d6a2-35d5-4c4b-8a85-1b4d0f4e5d0c",
+				"name": "rate-limiting-advanced",
+				"consumer_group": "2bbf81d2-a42f-45c1-b41e-c406445ceda4",
+				"config": {
+					"window_size": [60],
+					"limit": [10],
+					"window_type": "sliding"
+				}
+			}
+		]
+	}
+}
+`,
+			dataPlaneVersion: "2.7.0",
+			expectedPayload: `
+{
+	"config_table": {
+		"consumer_groups": [
+			{
+				"id": "2bbf81d2-a42f-45c1-b41e-c406445ceda4",
+				"name": "gold"
+			}
+		],
+		"consumer_group_consumers": [
+			{
+				"consumer_group": "2bbf81d2-a42f-45c1-b41e-c406445ceda4",
+				"consumer": "d3da058d-3b0f-4b4c-9b7b-3c8f2e7c6a41"
+			}
+		],
+		"consumer_group_plugins": [
+			{
+				"id": "f2f5d6a2-35d5-4c4b-8a85-1b4d0f4e5d0c",
+				"name": "rate-limiting-advanced",
+				"consumer_group": "2bbf81d2-a42f-45c1-b41e-c406445ceda4",
+				"config": {
+					"window_size": [60],
+					"limit": [10],
+					"window_type": "sliding"
+				}
+			}
+		]
+	}
+}
 `,
 			expectedChanges: config.TrackedChanges{},
 		},
@@ -19,6 +19,8 @@ type Content struct {
 	CompressedPayload []byte
 	Hash              string
 	GranularHashes    map[string]string
+	// Size is the size of the uncompressed payload in bytes.
+	Size int
 }
 
 type MutatorOpts struct {
@@ -37,9 +39,26 @@ type Loader interface {
 type DataPlaneConfig Map
 
 type KongConfigurationLoader struct {
+	// MaxPayloadSize is the maximum size in bytes of the uncompressed
+	// payload, or zero if there is no limit.
+	MaxPayloadSize int
+
 	mutators []Mutator
 }
 
+// ErrPayloadTooLarge is returned by KongConfigurationLoader.Load when the
+// payload exceeds KongConfigurationLoader.MaxPayloadSize. Data planes keep
+// their current configuration in that case.
+type ErrPayloadTooLarge struct {
+	Size  int
+	Limit int
+}
+
+func (e ErrPayloadTooLarge) Error() string {
+	return fmt.Sprintf("configuration payload of %d bytes exceeds the "+
+		"maximum payload size of %d bytes", e.Size, e.Limit)
+}
+
 func (l *KongConfigurationLoader) Register(mutator Mutator) error {
 	for _, m := range l.mutators {
 		if m.Name() == mutator.Name() {
@@ -67,7 +86,14 @@ func (l *KongConfigurationLoader) Load(ctx context.Context, clusterID string) (C
 		}
 	}
 
-	return ReconfigurePayload(configTable)
+	content, err := ReconfigurePayload(configTable)
+	if err != nil {
+		return Content{}, err
+	}
+	if l.MaxPayloadSize > 0 && content.Size > l.MaxPayloadSize {
+		return Content{}, ErrPayloadTooLarge{Size: content.Size, Limit: l.MaxPayloadSize}
+	}
+	return content, nil
 }
 
 func ReconfigurePayload(c DataPlaneConfig) (Content, error) {
@@ -83,7 +109,8 @@ func ReconfigurePayload(c DataPlaneConfig) (Content, error) {
 	writer := gzip.NewWriter(&buf)
 	defer writer.Close()
 
-	err := json.Marshaller.NewEncoder(writer).Encode(payload)
+	counter := &countingWriter{writer: writer}
+	err := json.Marshaller.NewEncoder(counter).Encode(payload)
 	if err != nil {
 		return Content{}, fmt.Errorf("json marshal: %v", err)
 	}
@@ -95,9 +122,22 @@ func ReconfigurePayload(c DataPlaneConfig) (Content, error) {
 		CompressedPayload: buf.Bytes(),
 		Hash:              configHash,
 		GranularHashes:    hashes,
+		Size:              counter.count,
 	}, nil
 }
 
+// countingWriter counts the bytes written to writer.
+type countingWriter struct {
+	writer io.Writer
+	count  int
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.writer.Write(p)
+	w.count += n
+	return n, err
+}
+
 func CompressPayload(payload []byte) ([]byte, error) {
 	var buf bytes.Buffer
 	w := gzip.NewWriter(&buf)
@@ -1,6 +1,9 @@
 package config
 
 import (
+	"context"
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -15,6 +18,7 @@ func TestReconfigurePayload(t *testing.T) {
 
 	payload, err := UncompressPayload(res.CompressedPayload)
 	require.Nil(t, err)
+	require.Equal(t, len(payload), res.Size)
 	require.JSONEq(t,
 		`{
 			"type": "reconfigure",
@@ -30,3 +34,41 @@ func TestReconfigurePayload(t *testing.T) {
 		}`,
 		string(payload))
 }
+
+type testMutator struct {
+	services int
+}
+
+func (m testMutator) Name() string {
+	return "test"
+}
+
+func (m testMutator) Mutate(_ context.Context, _ MutatorOpts, config DataPlaneConfig) error {
+	services := make([]Map, 0, m.services)
+	for i := 0; i < m.services; i++ {
+		services = append(services, Map{"name": strings.Repeat("s", 100)})
+	}
+	config["services"] = services
+	return nil
+}
+
+func TestKongConfigurationLoader_MaxPayloadSize(t *testing.T) {
+	ctx := context.Background()
+	loader := &KongConfigurationLoader{}
+	require.Nil(t, loader.Register(testMutator{services: 10}))
+	content, err := loader.Load(ctx, "default")
+	require.Nil(t, err)
+
+	t.Run("payloads within the limit are loaded", func(t *testing.T) {
+		loader.MaxPayloadSize = content.Size
+		_, err := loader.Load(ctx, "default")
+		require.Nil(t, err)
+	})
+	t.Run("payloads beyond the limit fail to load", func(t *testing.T) {
+		loader.MaxPayloadSize = content.Size - 1
+		_, err := loader.Load(ctx, "default")
+		require.Equal(t, ErrPayloadTooLarge{Size: content.Size, Limit: content.Size - 1}, err)
+		require.EqualError(t, err, fmt.Sprintf("configuration payload of %d bytes "+
+			"exceeds the maximum payload size of %d bytes", content.Size, content.Size-1))
+	})
+}
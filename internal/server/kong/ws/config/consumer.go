@@ -23,24 +23,24 @@ func (l *KongConsumerLoader) Mutate(ctx context.Context,
 	ctx, cancel := context.WithTimeout(ctx, defaultRequestTimeout)
 	defer cancel()
 
-	var pageNum int32 = 1
+	var pageToken string
 	var allConsumers []*v1.Consumer
 	for {
 		resp, err := l.Client.ListConsumers(ctx, &admin.ListConsumersRequest{
 			Cluster: &v1.RequestCluster{Id: opts.ClusterID},
 			Page: &v1.PaginationRequest{
-				Size:   pageSize,
-				Number: pageNum,
+				Size:      pageSize,
+				PageToken: pageToken,
 			},
 		})
 		if err != nil {
 			return err
 		}
 		allConsumers = append(allConsumers, resp.Items...)
-		if resp.Page == nil || resp.Page.NextPageNum == 0 {
+		if resp.Page == nil || resp.Page.NextPageToken == "" {
 			break
 		}
-		pageNum = resp.Page.NextPageNum
+		pageToken = resp.Page.NextPageToken
 	}
 	res := make([]Map, 0, len(allConsumers))
 	for _, r := range allConsumers {
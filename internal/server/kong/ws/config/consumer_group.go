@@ -0,0 +1,178 @@
+package config
+
+import (
+	"context"
+
+	v1 "github.com/kong/koko/internal/gen/grpc/kong/admin/model/v1"
+	admin "github.com/kong/koko/internal/gen/grpc/kong/admin/service/v1"
+)
+
+// consumerGroupPluginName is the name of the only plugin that supports
+// consumer group specific overrides.
+const consumerGroupPluginName = "rate-limiting-advanced"
+
+type KongConsumerGroupLoader struct {
+	Client admin.ConsumerGroupServiceClient
+}
+
+func (l KongConsumerGroupLoader) Name() string {
+	return "consumer_group"
+}
+
+// Mutate reads consumer groups, their members and their plugin overrides from
+// CP persistence store and populates the read data into config under the
+// 'consumer_groups', 'consumer_group_consumers' and 'consumer_group_plugins'
+// keys.
+func (l *KongConsumerGroupLoader) Mutate(ctx context.Context,
+	opts MutatorOpts, config DataPlaneConfig,
+) error {
+	ctx, cancel := context.WithTimeout(ctx, defaultRequestTimeout)
+	defer cancel()
+
+	allConsumerGroups, err := l.listConsumerGroups(ctx, opts)
+	if err != nil {
+		return err
+	}
+	// The members of every group are listed at once, rather than listing
+	// the members of each group.
+	memberships, err := l.listConsumerGroupMemberships(ctx, opts)
+	if err != nil {
+		return err
+	}
+	members := map[string][]string{}
+	for _, membership := range memberships {
+		members[membership.ConsumerGroupId] = append(members[membership.ConsumerGroupId],
+			membership.ConsumerId)
+	}
+	consumerGroups := make([]Map, 0, len(allConsumerGroups))
+	consumerGroupConsumers := make([]Map, 0, len(memberships))
+	for _, r := range allConsumerGroups {
+		m, err := convert(r)
+		if err != nil {
+			return err
+		}
+		delete(m, "updated_at")
+		consumerGroups = append(consumerGroups, m)
+
+		for _, consumerID := range members[r.Id] {
+			consumerGroupConsumers = append(consumerGroupConsumers, Map{
+				"consumer_group": r.Id,
+				"consumer":       consumerID,
+			})
+		}
+	}
+
+	allConfigs, err := l.listRateLimitingAdvancedConfigs(ctx, opts)
+	if err != nil {
+		return err
+	}
+	consumerGroupPlugins := make([]Map, 0, len(allConfigs))
+	for _, r := range allConfigs {
+		m, err := consumerGroupPluginFromRLAConfig(r)
+		if err != nil {
+			return err
+		}
+		consumerGroupPlugins = append(consumerGroupPlugins, m)
+	}
+
+	config["consumer_groups"] = consumerGroups
+	config["consumer_group_consumers"] = consumerGroupConsumers
+	config["consumer_group_plugins"] = consumerGroupPlugins
+	return nil
+}
+
+func (l *KongConsumerGroupLoader) listConsumerGroups(ctx context.Context,
+	opts MutatorOpts,
+) ([]*v1.ConsumerGroup, error) {
+	var pageToken string
+	var res []*v1.ConsumerGroup
+	for {
+		resp, err := l.Client.ListConsumerGroups(ctx, &admin.ListConsumerGroupsRequest{
+			Cluster: &v1.RequestCluster{Id: opts.ClusterID},
+			Page: &v1.PaginationRequest{
+				Size:      pageSize,
+				PageToken: pageToken,
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, resp.Items...)
+		if resp.Page == nil || resp.Page.NextPageToken == "" {
+			break
+		}
+		pageToken = resp.Page.NextPageToken
+	}
+	return res, nil
+}
+
+func (l *KongConsumerGroupLoader) listConsumerGroupMemberships(ctx context.Context,
+	opts MutatorOpts,
+) ([]*admin.ConsumerGroupMembership, error) {
+	var res []*admin.ConsumerGroupMembership
+	for page := int32(1); page != 0; {
+		resp, err := l.Client.ListConsumerGroupMemberships(ctx, &admin.ListConsumerGroupMembershipsRequest{
+			Cluster: &v1.RequestCluster{Id: opts.ClusterID},
+			Page: &v1.PaginationRequest{
+				Size:   pageSize,
+				Number: page,
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, resp.Items...)
+		page = resp.GetPage().GetNextPageNum()
+	}
+	return res, nil
+}
+
+func (l *KongConsumerGroupLoader) listRateLimitingAdvancedConfigs(ctx context.Context,
+	opts MutatorOpts,
+) ([]*v1.ConsumerGroupRateLimitingAdvancedConfig, error) {
+	var pageToken string
+	var res []*v1.ConsumerGroupRateLimitingAdvancedConfig
+	for {
+		resp, err := l.Client.ListConsumerGroupRateLimitingAdvancedConfig(ctx,
+			&admin.ListConsumerGroupRateLimitingAdvancedConfigRequest{
+				Cluster: &v1.RequestCluster{Id: opts.ClusterID},
+				Page: &v1.PaginationRequest{
+					Size:      pageSize,
+					PageToken: pageToken,
+				},
+			})
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, resp.Items...)
+		if resp.Page == nil || resp.Page.NextPageToken == "" {
+			break
+		}
+		pageToken = resp.Page.NextPageToken
+	}
+	return res, nil
+}
+
+// consumerGroupPluginFromRLAConfig converts the rate-limiting-advanced
+// overrides of a consumer group into the 'consumer_group_plugins' shape
+// expected by Kong gateway.
+func consumerGroupPluginFromRLAConfig(r *v1.ConsumerGroupRateLimitingAdvancedConfig) (Map, error) {
+	m, err := convert(r)
+	if err != nil {
+		return nil, err
+	}
+	res := Map{
+		"name":           consumerGroupPluginName,
+		"consumer_group": r.ConsumerGroupId,
+	}
+	for _, field := range []string{"id", "created_at"} {
+		if value, ok := m[field]; ok {
+			res[field] = value
+			delete(m, field)
+		}
+	}
+	delete(m, "updated_at")
+	delete(m, "consumer_group_id")
+	res["config"] = map[string]interface{}(m)
+	return res, nil
+}
@@ -0,0 +1,132 @@
+package config
+
+import (
+	"context"
+	"testing"
+
+	model "github.com/kong/koko/internal/gen/grpc/kong/admin/model/v1"
+	v1 "github.com/kong/koko/internal/gen/grpc/kong/admin/service/v1"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+type mockConsumerGroupClient struct {
+	v1.ConsumerGroupServiceClient
+}
+
+func (m mockConsumerGroupClient) ListConsumerGroups(
+	ctx context.Context,
+	req *v1.ListConsumerGroupsRequest,
+	opts ...grpc.CallOption,
+) (*v1.ListConsumerGroupsResponse, error) {
+	return &v1.ListConsumerGroupsResponse{
+		Items: []*model.ConsumerGroup{
+			{
+				Id:        "2bbf81d2-a42f-45c1-b41e-c406445ceda4",
+				Name:      "gold",
+				CreatedAt: 42,
+				UpdatedAt: 42,
+			},
+			{
+				Id:   "8da62582-ce84-47ec-932f-7babda59c4e3",
+				Name: "silver",
+			},
+		},
+	}, nil
+}
+
+func (m mockConsumerGroupClient) ListConsumerGroupMemberships(
+	ctx context.Context,
+	req *v1.ListConsumerGroupMembershipsRequest,
+	opts ...grpc.CallOption,
+) (*v1.ListConsumerGroupMembershipsResponse, error) {
+	if req.Page.Number == 2 {
+		return &v1.ListConsumerGroupMembershipsResponse{
+			Items: []*v1.ConsumerGroupMembership{
+				{
+					ConsumerGroupId: "2bbf81d2-a42f-45c1-b41e-c406445ceda4",
+					ConsumerId:      "f1c3e7a2-5b8d-4e0a-9c6f-2d7b8a9e0c13",
+				},
+			},
+			Page: &model.PaginationResponse{TotalCount: 3},
+		}, nil
+	}
+	return &v1.ListConsumerGroupMembershipsResponse{
+		Items: []*v1.ConsumerGroupMembership{
+			{
+				ConsumerGroupId: "8da62582-ce84-47ec-932f-7babda59c4e3",
+				ConsumerId:      "a0b8f3d1-6c2e-4f7a-8d9b-1e3c5a7f9b24",
+			},
+			{
+				ConsumerGroupId: "2bbf81d2-a42f-45c1-b41e-c406445ceda4",
+				ConsumerId:      "d3da058d-3b0f-4b4c-9b7b-3c8f2e7c6a41",
+			},
+		},
+		Page: &model.PaginationResponse{TotalCount: 3, NextPageNum: 2},
+	}, nil
+}
+
+func (m mockConsumerGroupClient) ListConsumerGroupRateLimitingAdvancedConfig(
+	ctx context.Context,
+	req *v1.ListConsumerGroupRateLimitingAdvancedConfigRequest,
+	opts ...grpc.CallOption,
+) (*v1.ListConsumerGroupRateLimitingAdvancedConfigResponse, error) {
+	return &v1.ListConsumerGroupRateLimitingAdvancedConfigResponse{
+		Items: []*model.ConsumerGroupRateLimitingAdvancedConfig{
+			{
+				Id:              "f2f5d6a2-35d5-4c4b-8a85-1b4d0f4e5d0c",
+				ConsumerGroupId: "2bbf81d2-a42f-45c1-b41e-c406445ceda4",
+				CreatedAt:       42,
+				UpdatedAt:       42,
+				WindowSize:      []int32{60},
+				Limit:           []int32{10},
+				WindowType:      "sliding",
+			},
+		},
+	}, nil
+}
+
+func TestKongConsumerGroupLoader_Mutate(t *testing.T) {
+	config := DataPlaneConfig{}
+	l := KongConsumerGroupLoader{Client: &mockConsumerGroupClient{}}
+
+	opts := MutatorOpts{ClusterID: "xx"}
+
+	err := l.Mutate(context.Background(), opts, config)
+	require.NoError(t, err)
+	require.Equal(t, []Map{
+		{
+			"id":         "2bbf81d2-a42f-45c1-b41e-c406445ceda4",
+			"name":       "gold",
+			"created_at": float64(42),
+		},
+		{"id": "8da62582-ce84-47ec-932f-7babda59c4e3", "name": "silver"},
+	}, config["consumer_groups"])
+	require.Equal(t, []Map{
+		{
+			"consumer_group": "2bbf81d2-a42f-45c1-b41e-c406445ceda4",
+			"consumer":       "d3da058d-3b0f-4b4c-9b7b-3c8f2e7c6a41",
+		},
+		{
+			"consumer_group": "2bbf81d2-a42f-45c1-b41e-c406445ceda4",
+			"consumer":       "f1c3e7a2-5b8d-4e0a-9c6f-2d7b8a9e0c13",
+		},
+		{
+			"consumer_group": "8da62582-ce84-47ec-932f-7babda59c4e3",
+			"consumer":       "a0b8f3d1-6c2e-4f7a-8d9b-1e3c5a7f9b24",
+		},
+	}, config["consumer_group_consumers"])
+	require.Equal(t, []Map{
+		{
+			"id":             "f2f5d6a2-35d5-4c4b-8a85-1b4d0f4e5d0c",
+			"name":           "rate-limiting-advanced",
+			"consumer_group": "2bbf81d2-a42f-45c1-b41e-c406445ceda4",
+			"created_at":     float64(42),
+			"config": map[string]interface{}{
+				"window_size": []interface{}{float64(60)},
+				"limit":       []interface{}{float64(10)},
+				"window_type": "sliding",
+			},
+		},
+	}, config["consumer_group_plugins"])
+}
@@ -23,24 +23,24 @@ func (l *KongKeyLoader) Mutate(
 	ctx, cancel := context.WithTimeout(ctx, defaultRequestTimeout)
 	defer cancel()
 
-	var pageNum int32 = 1
+	var pageToken string
 	var allKeys []*v1.Key
 	for {
 		keys, err := l.Client.ListKeys(ctx, &admin.ListKeysRequest{
 			Cluster: &v1.RequestCluster{Id: opts.ClusterID},
 			Page: &v1.PaginationRequest{
-				Size:   pageSize,
-				Number: pageNum,
+				Size:      pageSize,
+				PageToken: pageToken,
 			},
 		})
 		if err != nil {
 			return err
 		}
 		allKeys = append(allKeys, keys.Items...)
-		if keys.Page == nil || keys.Page.NextPageNum == 0 {
+		if keys.Page == nil || keys.Page.NextPageToken == "" {
 			break
 		}
-		pageNum = keys.Page.NextPageNum
+		pageToken = keys.Page.NextPageToken
 	}
 
 	res := make([]Map, len(allKeys))
@@ -23,24 +23,24 @@ func (l *KongKeySetLoader) Mutate(
 	ctx, cancel := context.WithTimeout(ctx, defaultRequestTimeout)
 	defer cancel()
 
-	var pageNum int32 = 1
+	var pageToken string
 	var allKeySets []*v1.KeySet
 	for {
 		keysets, err := l.Client.ListKeySets(ctx, &admin.ListKeySetsRequest{
 			Cluster: &v1.RequestCluster{Id: opts.ClusterID},
 			Page: &v1.PaginationRequest{
-				Size:   pageSize,
-				Number: pageNum,
+				Size:      pageSize,
+				PageToken: pageToken,
 			},
 		})
 		if err != nil {
 			return err
 		}
 		allKeySets = append(allKeySets, keysets.Items...)
-		if keysets.Page == nil || keysets.Page.NextPageNum == 0 {
+		if keysets.Page == nil || keysets.Page.NextPageToken == "" {
 			break
 		}
-		pageNum = keysets.Page.NextPageNum
+		pageToken = keysets.Page.NextPageToken
 	}
 
 	res := make([]Map, len(allKeySets))
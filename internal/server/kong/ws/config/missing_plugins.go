@@ -0,0 +1,84 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+	"go.uber.org/zap"
+	"golang.org/x/exp/slices"
+)
+
+// ChangeIDMissingPlugin is tracked for plugins that are removed from the
+// configuration of a data-plane node because the node does not have them
+// installed.
+const ChangeIDMissingPlugin = ChangeID("D102")
+
+func init() {
+	err := ChangeRegistry.RegisterMetadata(ChangeMetadata{
+		ID:       ChangeIDMissingPlugin,
+		Severity: ChangeSeverityError,
+		Description: "One or more configured plugins are not installed on " +
+			"the data-plane node. These plugins have been removed from the " +
+			"configuration sent to the node.",
+		Resolution: "Please install the affected plugins on the data-plane " +
+			"node or remove them from the configuration.",
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+// ProcessMissingPlugins removes all plugins that are not part of
+// installedPlugins from compressedPayload. Each removed plugin is tracked
+// under ChangeIDMissingPlugin.
+func (vc *WSVersionCompatibility) ProcessMissingPlugins(dataPlaneVersionStr string,
+	compressedPayload []byte, installedPlugins []string,
+) ([]byte, TrackedChanges, error) {
+	tracker := NewChangeTracker()
+
+	uncompressedPayloadBytes, err := UncompressPayload(compressedPayload)
+	if err != nil {
+		return nil, TrackedChanges{},
+			fmt.Errorf("unable to uncompress payload: %w", err)
+	}
+	processedPayload := string(uncompressedPayloadBytes)
+
+	removeCount := 0
+	plugins := gjson.Get(processedPayload, "config_table.plugins")
+	for i, plugin := range plugins.Array() {
+		pluginName := plugin.Get("name").String()
+		if slices.Contains(installedPlugins, pluginName) {
+			continue
+		}
+		pluginID := plugin.Get("id").String()
+		err := tracker.TrackForResource(ChangeIDMissingPlugin, ResourceInfo{
+			Type: "plugin",
+			ID:   pluginID,
+		})
+		if err != nil {
+			vc.logger.Error("failed to track version compatibility change",
+				zap.String("change-id", string(ChangeIDMissingPlugin)),
+				zap.String("resource-type", "plugin"))
+		}
+		pluginDelete := fmt.Sprintf("config_table.plugins.%d", i-removeCount)
+		processedPayload, err = sjson.Delete(processedPayload, pluginDelete)
+		if err != nil {
+			return nil, TrackedChanges{}, fmt.Errorf(
+				"unable to remove plugin '%s': %w", pluginName, err)
+		}
+		vc.logger.With(zap.String("plugin", pluginName)).
+			With(zap.String("data-plane", dataPlaneVersionStr)).
+			Warn("removing plugin which is not installed on data plane")
+		removeCount++
+	}
+
+	if removeCount == 0 {
+		return compressedPayload, tracker.Get(), nil
+	}
+	compatibleCompressedPayload, err := CompressPayload([]byte(processedPayload))
+	if err != nil {
+		return nil, TrackedChanges{}, err
+	}
+	return compatibleCompressedPayload, tracker.Get(), nil
+}
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/kong/koko/internal/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVersionCompatibility_ProcessMissingPlugins(t *testing.T) {
+	wsvc, err := NewVersionCompatibilityProcessor(VersionCompatibilityOpts{
+		Logger:        log.Logger,
+		KongCPVersion: "3.0.0",
+	})
+	require.NoError(t, err)
+
+	payload := `{
+		"config_table": {
+			"plugins": [
+				{
+					"id": "08d4dbf0-0962-4e07-8843-e885d1b558e3",
+					"name": "key-auth"
+				},
+				{
+					"id": "4063da9d-0652-4124-9290-7d9a8428e5c6",
+					"name": "my-custom-plugin"
+				},
+				{
+					"id": "c4bd4dd7-9a4b-4a43-a5f3-8f4a4b8f6a3e",
+					"name": "my-custom-plugin"
+				},
+				{
+					"id": "e5d3f5a7-f7c8-4e53-8a09-0c4e4a5e4b1c",
+					"name": "cors"
+				}
+			]
+		}
+	}`
+	compressedPayload, err := CompressPayload([]byte(payload))
+	require.NoError(t, err)
+
+	t.Run("all plugins installed", func(t *testing.T) {
+		processed, changes, err := wsvc.ProcessMissingPlugins("3.0.0",
+			compressedPayload, []string{"cors", "key-auth", "my-custom-plugin"})
+		require.NoError(t, err)
+		require.Equal(t, compressedPayload, processed)
+		require.Empty(t, changes.ChangeDetails)
+	})
+
+	t.Run("missing plugins are removed and tracked", func(t *testing.T) {
+		processed, changes, err := wsvc.ProcessMissingPlugins("3.0.0",
+			compressedPayload, []string{"cors", "key-auth"})
+		require.NoError(t, err)
+		uncompressed, err := UncompressPayload(processed)
+		require.NoError(t, err)
+		require.JSONEq(t, `{
+			"config_table": {
+				"plugins": [
+					{
+						"id": "08d4dbf0-0962-4e07-8843-e885d1b558e3",
+						"name": "key-auth"
+					},
+					{
+						"id": "e5d3f5a7-f7c8-4e53-8a09-0c4e4a5e4b1c",
+						"name": "cors"
+					}
+				]
+			}
+		}`, string(uncompressed))
+		require.Equal(t, TrackedChanges{
+			ChangeDetails: []ChangeDetail{
+				{
+					ID: ChangeIDMissingPlugin,
+					Resources: []ResourceInfo{
+						{
+							Type: "plugin",
+							ID:   "4063da9d-0652-4124-9290-7d9a8428e5c6",
+						},
+						{
+							Type: "plugin",
+							ID:   "c4bd4dd7-9a4b-4a43-a5f3-8f4a4b8f6a3e",
+						},
+					},
+				},
+			},
+		}, changes)
+	})
+
+	t.Run("no plugins configured", func(t *testing.T) {
+		compressed, err := CompressPayload([]byte(`{"config_table": {}}`))
+		require.NoError(t, err)
+		processed, changes, err := wsvc.ProcessMissingPlugins("3.0.0",
+			compressed, []string{})
+		require.NoError(t, err)
+		require.Equal(t, compressed, processed)
+		require.Empty(t, changes.ChangeDetails)
+	})
+}
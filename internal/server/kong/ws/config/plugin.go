@@ -20,24 +20,24 @@ func (l *KongPluginLoader) Mutate(ctx context.Context,
 ) error {
 	ctx, cancel := context.WithTimeout(ctx, defaultRequestTimeout)
 	defer cancel()
-	var pageNum int32 = 1
+	var pageToken string
 	var allPlugins []*v1.Plugin
 	for {
 		resp, err := l.Client.ListPlugins(ctx, &admin.ListPluginsRequest{
 			Cluster: &v1.RequestCluster{Id: opts.ClusterID},
 			Page: &v1.PaginationRequest{
-				Size:   pageSize,
-				Number: pageNum,
+				Size:      pageSize,
+				PageToken: pageToken,
 			},
 		})
 		if err != nil {
 			return err
 		}
 		allPlugins = append(allPlugins, resp.Items...)
-		if resp.Page == nil || resp.Page.NextPageNum == 0 {
+		if resp.Page == nil || resp.Page.NextPageToken == "" {
 			break
 		}
-		pageNum = resp.Page.NextPageNum
+		pageToken = resp.Page.NextPageToken
 	}
 	res := make([]Map, 0, len(allPlugins))
 	for _, r := range allPlugins {
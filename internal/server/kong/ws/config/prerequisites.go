@@ -0,0 +1,22 @@
+package config
+
+// ChangeIDPrerequisitesNotMet is recorded in the status of a data-plane node
+// that was rejected because it does not satisfy the data-plane prerequisites
+// of the control-plane.
+const ChangeIDPrerequisitesNotMet = ChangeID("D101")
+
+func init() {
+	err := ChangeRegistry.RegisterMetadata(ChangeMetadata{
+		ID:       ChangeIDPrerequisitesNotMet,
+		Severity: ChangeSeverityError,
+		Description: "The data-plane node does not satisfy one or more " +
+			"prerequisites of the control-plane and is not sent any " +
+			"configuration.",
+		Resolution: "Ensure that all plugins required by the control-plane " +
+			"are installed and enabled on the data-plane node, then " +
+			"reconnect the node.",
+	})
+	if err != nil {
+		panic(err)
+	}
+}
@@ -19,6 +19,8 @@ type VersionedConfigUpdates map[string][]ConfigTableUpdates
 type VersionCompatibility interface {
 	AddConfigTableUpdates(configTableUpdates VersionedConfigUpdates) error
 	ProcessConfigTableUpdates(dataPlaneVersionStr string, compressedPayload []byte) ([]byte, TrackedChanges, error)
+	ProcessMissingPlugins(dataPlaneVersionStr string, compressedPayload []byte,
+		installedPlugins []string) ([]byte, TrackedChanges, error)
 }
 
 type VersionCompatibilityOpts struct {
@@ -300,14 +302,18 @@ func (vc *WSVersionCompatibility) removeCoreEntity(
 	entityType string,
 	dataPlaneVersionStr string,
 	configTableKey string,
-	changeID ChangeID,
+	configTableUpdate ConfigTableUpdates,
 	tracker *ChangeTracker,
 ) string {
-	entityIDs := gjson.Get(processedPayload, fmt.Sprintf("config_table.%s.#.id", configTableKey))
-	for _, id := range entityIDs.Array() {
+	changeID := configTableUpdate.ChangeID
+	entities := gjson.Get(processedPayload, fmt.Sprintf("config_table.%s", configTableKey))
+	for _, entity := range entities.Array() {
+		if !shouldTrackChange(configTableUpdate, entity.Raw) {
+			continue
+		}
 		err := tracker.TrackForResource(changeID, ResourceInfo{
 			Type: entityType,
-			ID:   id.String(),
+			ID:   entity.Get("id").String(),
 		})
 		if err != nil {
 			vc.logger.Error("failed to track version compatibility change",
@@ -765,7 +771,7 @@ func (vc *WSVersionCompatibility) processCoreEntityUpdates(payload string,
 	}
 	if configTableUpdate.Remove && results.Exists() {
 		processedPayload = vc.removeCoreEntity(processedPayload, entityType,
-			dataPlaneVersionStr, configTableKey, configTableUpdate.ChangeID, tracker)
+			dataPlaneVersionStr, configTableKey, configTableUpdate, tracker)
 	}
 	return processedPayload
 }
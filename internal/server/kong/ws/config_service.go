@@ -2,6 +2,7 @@ package ws
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/kong/go-wrpc/wrpc"
@@ -81,6 +82,8 @@ func (c *configService) PingCP(
 // from the CP (currently the list of plugins it has available).
 // Then the manager can validate and promote the
 // node from "pending" to fully working.
+// A node that does not satisfy the data-plane prerequisites receives an
+// error response and remains pending.
 func (c *configService) ReportMetadata(
 	ctx context.Context,
 	peer *wrpc.Peer,
@@ -102,8 +105,15 @@ func (c *configService) ReportMetadata(
 	}
 	node.Logger.Debug("plugin list reported by the DP", zap.Strings("plugins", plugins))
 
-	err := c.manager.addWRPCNode(node)
+	err := c.manager.addWRPCNode(node, plugins)
 	if err != nil {
+		var prerequisitesErr ErrPrerequisitesNotMet
+		if errors.As(err, &prerequisitesErr) {
+			node.Logger.With(zap.Error(err)).Error("node does not satisfy data-plane prerequisites")
+			return &config_service.ReportMetadataResponse{
+				Response: &config_service.ReportMetadataResponse_Error{Error: err.Error()},
+			}, nil
+		}
 		node.Logger.With(zap.Error(err)).Error("error when adding validated node")
 		return nil, err
 	}
@@ -186,7 +186,8 @@ func (m *Manager) writeNode(node *Node) {
 			zap.String("node-id", node.ID))
 	}
 
-	trackedChanges, err := m.payload.ChangesFor(node.hash.String(), node.Version)
+	trackedChanges, err := m.payload.ChangesFor(node.hash.String(), node.Version,
+		node.plugins)
 	if err != nil {
 		m.logger.Error("no tracked changes for key",
 			zap.String("hash", node.hash.String()),
@@ -195,30 +196,47 @@ func (m *Manager) writeNode(node *Node) {
 		return
 	}
 
-	newHash, tracked := m.nodeStatusTracked(node.ID, trackedChanges)
-	if !tracked {
-		issues := trackedChangesToCompatIssues(trackedChanges)
-		_, err = m.configClient.Status.UpdateNodeStatus(ctx, &relay.UpdateNodeStatusRequest{
-			Item: &nonPublic.NodeStatus{
-				Id:     node.ID,
-				Issues: issues,
-			},
-			Cluster: m.reqCluster(),
-		})
-		if err != nil {
-			m.logger.Error("unable to update DP node status resource",
-				zap.Error(err),
-				zap.String("node-id", node.ID))
-		} else {
-			err := m.nodeStatusCache.Set(node.ID, newHash)
-			if err != nil {
-				m.logger.Error("unable to cache node's status hash",
-					zap.Error(err),
-					zap.String("hash", newHash),
-					zap.String("node-id", node.ID),
-				)
-			}
-		}
+	m.writeNodeStatus(ctx, node.ID, trackedChanges)
+}
+
+// writeNodeRejection records in the status of node that it was not admitted
+// because it does not satisfy DataPlaneRequisites.
+func (m *Manager) writeNodeRejection(node *Node) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultRequestTimeout)
+	defer cancel()
+	m.writeNodeStatus(ctx, node.ID, config.TrackedChanges{
+		ChangeDetails: []config.ChangeDetail{{ID: config.ChangeIDPrerequisitesNotMet}},
+	})
+}
+
+func (m *Manager) writeNodeStatus(ctx context.Context, nodeID string,
+	trackedChanges config.TrackedChanges,
+) {
+	newHash, tracked := m.nodeStatusTracked(nodeID, trackedChanges)
+	if tracked {
+		return
+	}
+	issues := trackedChangesToCompatIssues(trackedChanges)
+	_, err := m.configClient.Status.UpdateNodeStatus(ctx, &relay.UpdateNodeStatusRequest{
+		Item: &nonPublic.NodeStatus{
+			Id:     nodeID,
+			Issues: issues,
+		},
+		Cluster: m.reqCluster(),
+	})
+	if err != nil {
+		m.logger.Error("unable to update DP node status resource",
+			zap.Error(err),
+			zap.String("node-id", nodeID))
+		return
+	}
+	err = m.nodeStatusCache.Set(nodeID, newHash)
+	if err != nil {
+		m.logger.Error("unable to cache node's status hash",
+			zap.Error(err),
+			zap.String("hash", newHash),
+			zap.String("node-id", nodeID),
+		)
 	}
 }
 
@@ -343,6 +361,17 @@ func (m *Manager) startThreads() {
 }
 
 func (m *Manager) AddWebsocketNode(node *Node) {
+	// the first message sent by the node lists its installed plugins
+	plugins, err := node.GetPluginList()
+	if err != nil {
+		node.Logger.With(zap.Error(err)).Error("failed to read plugin list")
+		if err := node.Close(); err != nil {
+			node.Logger.Info("error closing node", zap.Error(err))
+		}
+		return
+	}
+	node.setPlugins(plugins)
+
 	m.init.Do(m.startThreads)
 	// track each authenticated node
 	m.writeNode(node)
@@ -407,6 +436,16 @@ func (m *Manager) removeNode(node *Node) {
 	}
 }
 
+// closeNodes disconnects the nodes of the manager once it is stopped, e.g.:
+// when its cluster is deleted.
+func (m *Manager) closeNodes() {
+	for _, node := range append(m.nodes.All(), m.pendingNodes.All()...) {
+		if err := node.Close(); err != nil {
+			node.Logger.Info("error closing node", zap.Error(err))
+		}
+	}
+}
+
 // FindNode returns a pointer to the node given a remote address
 // or nil if not found.
 func (m *Manager) FindNode(remoteAddress string) *Node {
@@ -431,9 +470,18 @@ func (m *Manager) AddPendingNode(node *Node) error {
 // addWRPCNode is called on behalf of a node when it is ready
 // to receive config updates. The ReportMetadata RPC method handler
 // does this after receiving the list of plugins.
+// The node is validated against the configured DataPlaneRequisites, a node
+// that fails validation is left in `m.pendingNodes` and the failure is
+// recorded in its node status.
 // Here, the node is moved from the `m.pendingNodes` to the
 // final `m.nodes` list.
-func (m *Manager) addWRPCNode(node *Node) error {
+func (m *Manager) addWRPCNode(node *Node, plugins []string) error {
+	if err := validatePrerequisites(m.ReadConfig().DataPlaneRequisites, plugins); err != nil {
+		m.writeNodeRejection(node)
+		return err
+	}
+
+	node.setPlugins(plugins)
 	m.init.Do(m.startThreads)
 
 	err := m.pendingNodes.Remove(node)
@@ -649,7 +697,12 @@ func (m *Manager) updateEventHandler(ctx context.Context) {
 	m.logger.Info("reconciling payload")
 	backoffer := newBackOff(ctx, 1*time.Minute) // retry for a minute
 	err := backoff.RetryNotify(func() error {
-		return m.reconcileKongPayload(ctx)
+		err := m.reconcileKongPayload(ctx)
+		if errors.As(err, &config.ErrPayloadTooLarge{}) {
+			// the payload only shrinks once entities are deleted
+			return backoff.Permanent(err)
+		}
+		return err
 	}, backoffer, func(err error, duration time.Duration) {
 		m.logger.With(
 			zap.Error(err),
@@ -44,6 +44,14 @@ func (vc MockVersionCompatibility) ProcessConfigTableUpdates(
 	return nil, config.TrackedChanges{}, nil
 }
 
+func (vc MockVersionCompatibility) ProcessMissingPlugins(
+	v string,
+	py []byte,
+	plugins []string,
+) ([]byte, config.TrackedChanges, error) {
+	return nil, config.TrackedChanges{}, nil
+}
+
 func TestChooseServiceVersionUnknown(t *testing.T) {
 	r := require.New(t)
 	testPeer := &wrpc.Peer{}
@@ -9,6 +9,7 @@ import (
 	"net"
 	"regexp"
 	"sync"
+	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/kong/go-wrpc/wrpc"
@@ -62,6 +63,9 @@ type Node struct {
 	peer     *wrpc.Peer
 	Logger   *zap.Logger
 	hash     sum
+	// plugins are the plugins installed on the node.
+	// nil when the node has not reported them yet.
+	plugins []string
 }
 
 type nodeOpts struct {
@@ -137,17 +141,29 @@ func (n *Node) RemoteAddr() net.Addr {
 	return &net.IPAddr{}
 }
 
+// pluginListTimeout is the time a DP is given to send the list of its
+// plugins after connecting on the old WebSocket protocol.
+var pluginListTimeout = 30 * time.Second
+
 // GetPluginList receives the list of plugins the DP sends
 // right after connection on the old WebSocket protocol.
+// It fails if the list is not received within pluginListTimeout.
 func (n *Node) GetPluginList() ([]string, error) {
 	if n.nodetype != nodeTypeWebSocket {
 		return nil, fmt.Errorf("not implemented")
 	}
 
+	if err := n.conn.SetReadDeadline(time.Now().Add(pluginListTimeout)); err != nil {
+		return nil, fmt.Errorf("set read deadline: %w", err)
+	}
 	messageType, message, err := n.conn.ReadMessage()
 	if err != nil {
 		return nil, fmt.Errorf("read websocket message: %w", err)
 	}
+	// The following messages are read by readThread, without a deadline.
+	if err := n.conn.SetReadDeadline(time.Time{}); err != nil {
+		return nil, fmt.Errorf("clear read deadline: %w", err)
+	}
 	if messageType != websocket.BinaryMessage {
 		return nil, fmt.Errorf("kong data-plane sent a message of type %v, "+
 			"expected %v", messageType, websocket.BinaryMessage)
@@ -217,15 +233,25 @@ func (n *Node) sendConfig(ctx context.Context, payload *Payload) error {
 	return nil
 }
 
+func (n *Node) setPlugins(plugins []string) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	n.plugins = plugins
+}
+
 func (n *Node) getPayload(ctx context.Context, payload *Payload) (config.Content, error) {
+	n.lock.RLock()
+	plugins := n.plugins
+	n.lock.RUnlock()
+
 	if n.nodetype == nodeTypeWebSocket {
-		return payload.Payload(ctx, n.Version)
+		return payload.Payload(ctx, n.Version, plugins)
 	}
 
-	key := n.Version + "-wrpc"
+	key := configKey(n.Version, plugins) + "-wrpc"
 	entry, err := payload.configCache.load(key)
 	if err == errNotFound {
-		content, err := payload.Payload(ctx, n.Version)
+		content, err := payload.Payload(ctx, n.Version, plugins)
 		if err != nil {
 			return content, err
 		}
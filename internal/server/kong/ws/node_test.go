@@ -1,8 +1,14 @@
 package ws
 
 import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/gorilla/websocket"
+	"github.com/kong/koko/internal/log"
 	"github.com/stretchr/testify/require"
 )
 
@@ -30,3 +36,48 @@ func TestSumHash(t *testing.T) {
 		require.Equal(t, sum{}, sumWithHash)
 	})
 }
+
+func TestGetPluginList(t *testing.T) {
+	timeout := pluginListTimeout
+	pluginListTimeout = 100 * time.Millisecond
+	defer func() { pluginListTimeout = timeout }()
+
+	errs := make(chan error, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		if err != nil {
+			errs <- err
+			return
+		}
+		node, err := NewNode(nodeOpts{connection: conn, logger: log.Logger})
+		if err != nil {
+			errs <- err
+			return
+		}
+		_, err = node.GetPluginList()
+		errs <- err
+		_ = node.Close()
+	}))
+	defer server.Close()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+	t.Run("fails when the DP does not send its plugins in time", func(t *testing.T) {
+		conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+		require.NoError(t, err)
+		defer conn.Close()
+		select {
+		case err := <-errs:
+			require.ErrorContains(t, err, "i/o timeout")
+		case <-time.After(5 * time.Second):
+			require.Fail(t, "plugin list read did not time out")
+		}
+	})
+	t.Run("succeeds when the DP sends its plugins", func(t *testing.T) {
+		conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+		require.NoError(t, err)
+		defer conn.Close()
+		require.NoError(t, conn.WriteMessage(websocket.BinaryMessage,
+			[]byte(`{"type":"basic_info","plugins":[{"name":"key-auth"}]}`)))
+		require.NoError(t, <-errs)
+	})
+}
@@ -4,14 +4,18 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/bluele/gcache"
+	"github.com/cespare/xxhash/v2"
 	"github.com/kong/koko/internal/metrics"
 	"github.com/kong/koko/internal/server/kong/ws/config"
 	"github.com/samber/lo"
 	"go.uber.org/zap"
+	"golang.org/x/exp/slices"
 )
 
 const (
@@ -56,11 +60,16 @@ func NewPayload(opts PayloadOpts) (*Payload, error) {
 	}, nil
 }
 
-func (p *Payload) Payload(_ context.Context, version string) (config.Content, error) {
+// Payload returns the configuration for a data-plane node of version.
+// When installedPlugins is not nil, plugins that are not installed on the
+// node are removed from the configuration.
+func (p *Payload) Payload(_ context.Context, version string,
+	installedPlugins []string,
+) (config.Content, error) {
 	p.configCacheLock.Lock()
 	defer p.configCacheLock.Unlock()
 
-	entry, err := p.configForVersion(version)
+	entry, err := p.configForNode(version, installedPlugins)
 	if err != nil {
 		return config.Content{}, err
 	}
@@ -158,8 +167,108 @@ func (p *Payload) configForVersion(version string) (cacheEntry, error) {
 	return cacheEntry{}, err
 }
 
-func (p *Payload) ChangesFor(hash, version string) (config.TrackedChanges, error) {
-	value, err := p.configHashToChanges.Get(hash + version)
+// configKey returns the key under which the configuration for a data-plane
+// node of version with installedPlugins is cached.
+func configKey(version string, installedPlugins []string) string {
+	if installedPlugins == nil {
+		return version
+	}
+	plugins := slices.Clone(installedPlugins)
+	slices.Sort(plugins)
+	return version + "-" + strconv.FormatUint(
+		xxhash.Sum64String(strings.Join(plugins, ",")), 16)
+}
+
+// configForNode builds on top of configForVersion and removes plugins that
+// are not part of installedPlugins. The result is cached per version and
+// plugin set.
+func (p *Payload) configForNode(version string,
+	installedPlugins []string,
+) (cacheEntry, error) {
+	if installedPlugins == nil {
+		return p.configForVersion(version)
+	}
+	key := configKey(version, installedPlugins)
+	contentCacheEntry, err := p.configCache.load(key)
+	if err == nil {
+		// fast path
+		return contentCacheEntry, nil
+	}
+	if !errors.Is(err, errNotFound) {
+		return cacheEntry{}, err
+	}
+
+	versionEntry, err := p.configForVersion(version)
+	if err != nil {
+		return cacheEntry{}, err
+	}
+	if versionEntry.Error != nil {
+		return versionEntry, nil
+	}
+	updatedPayload, changes, err := p.vc.ProcessMissingPlugins(version,
+		versionEntry.CompressedPayload, installedPlugins)
+	entry := cacheEntry{
+		Content: config.Content{
+			CompressedPayload: updatedPayload,
+			// Hash must remain stable across plugin sets.
+			Hash:           versionEntry.Hash,
+			GranularHashes: versionEntry.GranularHashes,
+		},
+		Error: err,
+	}
+	if err != nil {
+		p.logger.Error("failed to remove missing plugins",
+			zap.Error(err),
+			zap.String("kong-dp-version", version),
+		)
+	} else if len(changes.ChangeDetails) > 0 {
+		p.logger.Warn("configured plugins missing on data-plane",
+			zap.Int("total-resource-changes",
+				len(changes.ChangeDetails[0].Resources)),
+			zap.String("kong-dp-version", version))
+	}
+
+	// cache changes along with the ones of the version
+	versionChanges, err := p.ChangesFor(versionEntry.Hash, version, nil)
+	if err != nil && !errors.Is(err, errNotFound) {
+		return cacheEntry{}, err
+	}
+	changes = mergeTrackedChanges(versionChanges, changes)
+	err = p.configHashToChanges.Set(versionEntry.Hash+key, changes)
+	if err != nil {
+		p.logger.Error("failed to track config changes in cache",
+			zap.Error(err))
+	}
+	err = p.configCache.store(key, entry)
+	if err != nil {
+		p.logger.Error("failed to store configuration from cache",
+			zap.Error(err),
+			zap.String("kong-dp-version", version),
+		)
+	}
+	return entry, nil
+}
+
+func mergeTrackedChanges(a, b config.TrackedChanges) config.TrackedChanges {
+	if len(b.ChangeDetails) == 0 {
+		return a
+	}
+	details := make([]config.ChangeDetail, 0,
+		len(a.ChangeDetails)+len(b.ChangeDetails))
+	details = append(details, a.ChangeDetails...)
+	details = append(details, b.ChangeDetails...)
+	slices.SortStableFunc(details, func(x, y config.ChangeDetail) bool {
+		return x.ID < y.ID
+	})
+	return config.TrackedChanges{ChangeDetails: details}
+}
+
+// ChangesFor returns the changes tracked for the configuration with hash sent
+// to a data-plane node of version with installedPlugins.
+func (p *Payload) ChangesFor(hash, version string,
+	installedPlugins []string,
+) (config.TrackedChanges, error) {
+	value, err := p.configHashToChanges.Get(hash + configKey(version, installedPlugins))
 	if err != nil {
 		if !errors.Is(err, gcache.KeyNotFoundError) {
 			p.logger.Error("failed to fetch tracked changes from cache",
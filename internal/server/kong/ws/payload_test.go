@@ -87,7 +87,7 @@ func TestConfigPayload_Cache(t *testing.T) {
 		})
 		require.Nil(t, err)
 
-		updatedPayload, err := payload.Payload(context.Background(), "2.8.0")
+		updatedPayload, err := payload.Payload(context.Background(), "2.8.0", nil)
 		require.Nil(t, err)
 		require.Equal(t, compressedPayload, updatedPayload.CompressedPayload)
 		entry, err := payload.configCache.load("2.8.0")
@@ -108,7 +108,7 @@ func TestConfigPayload_Cache(t *testing.T) {
 		})
 		require.Nil(t, err)
 
-		updatedPayload, err := payload.Payload(context.Background(), "2.8.0")
+		updatedPayload, err := payload.Payload(context.Background(), "2.8.0", nil)
 		require.Nil(t, err)
 		require.Equal(t, compressedPayload, updatedPayload.CompressedPayload)
 		entry, err := payload.configCache.load("2.8.0")
@@ -116,7 +116,7 @@ func TestConfigPayload_Cache(t *testing.T) {
 		require.Greater(t, len(entry.CompressedPayload), 0)
 		require.NoError(t, entry.Error)
 
-		updatedPayload, err = payload.Payload(context.Background(), "2.7.0")
+		updatedPayload, err = payload.Payload(context.Background(), "2.7.0", nil)
 		require.Nil(t, err)
 		require.Equal(t, expectedPayload270, updatedPayload.CompressedPayload)
 		entry, err = payload.configCache.load("2.7.0")
@@ -137,7 +137,7 @@ func TestConfigPayload_Cache(t *testing.T) {
 		})
 		require.Nil(t, err)
 
-		updatedPayload, err := payload.Payload(context.Background(), "2.8.0")
+		updatedPayload, err := payload.Payload(context.Background(), "2.8.0", nil)
 		require.Nil(t, err)
 		require.Equal(t, compressedPayload, updatedPayload.CompressedPayload)
 		entry, err := payload.configCache.load("2.8.0")
@@ -152,4 +152,76 @@ func TestConfigPayload_Cache(t *testing.T) {
 		_, err = payload.configCache.load("2.8.0")
 		require.ErrorIs(t, err, errNotFound)
 	})
+
+	t.Run("ensure plugins missing on the node are removed", func(t *testing.T) {
+		payload, err := NewPayload(PayloadOpts{
+			VersionCompatibilityProcessor: wsvc,
+			Logger:                        log.Logger,
+		})
+		require.Nil(t, err)
+		const hash = "1133ae8be08017e5460160635daa22f2"
+		err = payload.UpdateBinary(context.Background(), config.Content{
+			CompressedPayload: compressedPayload,
+			Hash:              hash,
+		})
+		require.Nil(t, err)
+
+		installedPlugins := []string{"plugin_2"}
+		updatedPayload, err := payload.Payload(context.Background(), "2.7.0",
+			installedPlugins)
+		require.Nil(t, err)
+		require.Equal(t, hash, updatedPayload.Hash)
+		uncompressed, err := config.UncompressPayload(updatedPayload.CompressedPayload)
+		require.Nil(t, err)
+		require.JSONEq(t, `{
+			"config_table": {
+				"plugins": [
+					{
+						"id": "4063da9d-0652-4124-9290-7d9a8428e5c6",
+						"name": "plugin_2",
+						"config": {
+							"plugin_2_field_1": "element_1"
+						}
+					}
+				]
+			}
+		}`, string(uncompressed))
+		_, err = payload.configCache.load(configKey("2.7.0", installedPlugins))
+		require.NoError(t, err)
+
+		changes, err := payload.ChangesFor(hash, "2.7.0", installedPlugins)
+		require.NoError(t, err)
+		require.Equal(t, config.TrackedChanges{
+			ChangeDetails: []config.ChangeDetail{
+				{
+					ID: config.ChangeIDMissingPlugin,
+					Resources: []config.ResourceInfo{
+						{
+							Type: "plugin",
+							ID:   "08d4dbf0-0962-4e07-8843-e885d1b558e3",
+						},
+					},
+				},
+				{
+					ID: "T042",
+					Resources: []config.ResourceInfo{
+						{
+							Type: "plugin",
+							ID:   "08d4dbf0-0962-4e07-8843-e885d1b558e3",
+						},
+					},
+				},
+			},
+		}, changes)
+	})
+}
+
+func TestConfigKey(t *testing.T) {
+	require.Equal(t, "3.0.0", configKey("3.0.0", nil))
+	require.Equal(t, configKey("3.0.0", []string{"a", "b"}),
+		configKey("3.0.0", []string{"b", "a"}))
+	require.NotEqual(t, configKey("3.0.0", []string{}),
+		configKey("3.0.0", nil))
+	require.NotEqual(t, configKey("3.0.0", []string{"a"}),
+		configKey("3.0.0", []string{"a", "b"}))
 }
@@ -0,0 +1,47 @@
+package ws
+
+import (
+	"fmt"
+	"strings"
+
+	grpcKongUtil "github.com/kong/koko/internal/gen/grpc/kong/util/v1"
+	"golang.org/x/exp/slices"
+)
+
+// ErrPrerequisitesNotMet is returned when a data-plane node does not satisfy
+// the configured DataPlanePrerequisite requirements.
+type ErrPrerequisitesNotMet struct {
+	MissingPlugins []string
+}
+
+func (e ErrPrerequisitesNotMet) Error() string {
+	return fmt.Sprintf("data-plane is missing required plugins: '%s'",
+		strings.Join(e.MissingPlugins, "', '"))
+}
+
+// validatePrerequisites evaluates every prerequisite against the plugins
+// reported by a data-plane node.
+// It returns ErrPrerequisitesNotMet if any of them is not satisfied.
+func validatePrerequisites(
+	prerequisites []*grpcKongUtil.DataPlanePrerequisite,
+	plugins []string,
+) error {
+	var missingPlugins []string
+	for _, prerequisite := range prerequisites {
+		switch config := prerequisite.GetConfig().(type) {
+		case *grpcKongUtil.DataPlanePrerequisite_RequiredPlugins:
+			for _, plugin := range config.RequiredPlugins.GetRequiredPlugins() {
+				if !slices.Contains(plugins, plugin) &&
+					!slices.Contains(missingPlugins, plugin) {
+					missingPlugins = append(missingPlugins, plugin)
+				}
+			}
+		default:
+			return fmt.Errorf("unsupported data-plane prerequisite: %T", config)
+		}
+	}
+	if len(missingPlugins) > 0 {
+		return ErrPrerequisitesNotMet{MissingPlugins: missingPlugins}
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package ws
+
+import (
+	"testing"
+
+	grpcKongUtil "github.com/kong/koko/internal/gen/grpc/kong/util/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func requiredPlugins(plugins ...string) *grpcKongUtil.DataPlanePrerequisite {
+	return &grpcKongUtil.DataPlanePrerequisite{
+		Config: &grpcKongUtil.DataPlanePrerequisite_RequiredPlugins{
+			RequiredPlugins: &grpcKongUtil.RequiredPluginsFilter{
+				RequiredPlugins: plugins,
+			},
+		},
+	}
+}
+
+func TestValidatePrerequisites(t *testing.T) {
+	t.Run("no prerequisites are always satisfied", func(t *testing.T) {
+		require.NoError(t, validatePrerequisites(nil, nil))
+	})
+	t.Run("all required plugins present", func(t *testing.T) {
+		err := validatePrerequisites(
+			[]*grpcKongUtil.DataPlanePrerequisite{
+				requiredPlugins("rate-limiting", "key-auth"),
+			},
+			[]string{"key-auth", "rate-limiting", "cors"},
+		)
+		require.NoError(t, err)
+	})
+	t.Run("missing plugins are reported once", func(t *testing.T) {
+		err := validatePrerequisites(
+			[]*grpcKongUtil.DataPlanePrerequisite{
+				requiredPlugins("rate-limiting", "key-auth"),
+				requiredPlugins("rate-limiting", "acl"),
+			},
+			[]string{"key-auth"},
+		)
+		var prerequisitesErr ErrPrerequisitesNotMet
+		require.ErrorAs(t, err, &prerequisitesErr)
+		require.Equal(t, []string{"rate-limiting", "acl"},
+			prerequisitesErr.MissingPlugins)
+		require.EqualError(t, err,
+			"data-plane is missing required plugins: 'rate-limiting', 'acl'")
+	})
+	t.Run("unsupported prerequisite fails", func(t *testing.T) {
+		err := validatePrerequisites(
+			[]*grpcKongUtil.DataPlanePrerequisite{{}},
+			[]string{"rate-limiting"},
+		)
+		require.EqualError(t, err,
+			"unsupported data-plane prerequisite: <nil>")
+	})
+}
@@ -213,12 +213,26 @@ func TestConfigService(t *testing.T) {
 
 	configClient := config_service.ConfigServiceClient{Peer: peer}
 
-	t.Run("send some plugins, get an initial config", func(t *testing.T) {
+	t.Run("missing required plugins, get an error and no config", func(t *testing.T) {
 		configMock.reset()
 		resp, err := configClient.ReportMetadata(context.Background(), &config_service.ReportMetadataRequest{
 			Plugins: []*config_service.PluginVersion{},
 		})
 		require.NoError(t, err)
+		require.EqualValues(t, &config_service.ReportMetadataResponse_Error{
+			Error: "data-plane is missing required plugins: 'rate-limiting'",
+		}, resp.Response)
+		configMock.lock.Lock()
+		defer configMock.lock.Unlock()
+		require.Empty(t, configMock.log)
+	})
+
+	t.Run("send some plugins, get an initial config", func(t *testing.T) {
+		configMock.reset()
+		resp, err := configClient.ReportMetadata(context.Background(), &config_service.ReportMetadataRequest{
+			Plugins: []*config_service.PluginVersion{{Name: "rate-limiting"}},
+		})
+		require.NoError(t, err)
 		require.EqualValues(t, &config_service.ReportMetadataResponse_Ok{Ok: "valid"}, resp.Response)
 		configMock.requireCalls(t, []string{"SyncConfig"})
 	})
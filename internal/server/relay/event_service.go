@@ -2,16 +2,20 @@ package relay
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
+	model "github.com/kong/koko/internal/gen/grpc/kong/admin/model/v1"
 	relay "github.com/kong/koko/internal/gen/grpc/kong/relay/service/v1"
+	"github.com/kong/koko/internal/server/util"
 	"github.com/kong/koko/internal/store"
 	storeEvent "github.com/kong/koko/internal/store/event"
 	"go.uber.org/zap"
 	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
 )
 
 const maxIdleTime = 24 * time.Hour
@@ -19,27 +23,39 @@ const maxIdleTime = 24 * time.Hour
 type EventService struct {
 	relay.UnimplementedEventServiceServer
 
-	store  store.Store
-	logger *zap.Logger
+	store       store.Store
+	storeLoader util.StoreLoader
+	logger      *zap.Logger
 
 	clients sync.Map
+
+	refreshInterval      time.Duration
+	watchRefreshInterval time.Duration
 }
 
 type EventServiceOpts struct {
-	Store  store.Store
-	Logger *zap.Logger
+	Store store.Store
+	// StoreLoader loads the stores of clusters other than the one of Store.
+	// Events of Store are sent to the clients of every cluster when nil.
+	StoreLoader util.StoreLoader
+	Logger      *zap.Logger
 }
 
 func NewEventService(ctx context.Context, opts EventServiceOpts) relay.EventServiceServer {
 	res := &EventService{
-		store:  opts.Store,
-		logger: opts.Logger,
+		store:       opts.Store,
+		storeLoader: opts.StoreLoader,
+		logger:      opts.Logger,
+
+		refreshInterval:      refreshInterval,
+		watchRefreshInterval: watchRefreshInterval,
 	}
 	go res.run(ctx)
 	return res
 }
 
 type client struct {
+	cluster    string
 	done       chan struct{}
 	stream     relay.EventService_FetchReconfigureEventsServer
 	seenID     string
@@ -53,6 +69,12 @@ func (e *EventService) FetchReconfigureEvents(req *relay.FetchReconfigureEventsR
 	if req.Cluster == nil || req.Cluster.Id == "" {
 		return fmt.Errorf("no cluster")
 	}
+	if _, err := e.storeFor(stream.Context(), req.Cluster.Id); err != nil {
+		if storeLoadErr, ok := err.(util.StoreLoadErr); ok {
+			return status.Error(storeLoadErr.Code, storeLoadErr.Message)
+		}
+		return err
+	}
 
 	peer, ok := peer.FromContext(stream.Context())
 	if !ok {
@@ -67,6 +89,7 @@ func (e *EventService) FetchReconfigureEvents(req *relay.FetchReconfigureEventsR
 	done := make(chan struct{})
 	streamID := uuid.NewString()
 	e.clients.Store(streamID, &client{
+		cluster:    req.Cluster.Id,
 		done:       done,
 		stream:     stream,
 		remoteAddr: peer.Addr.String(),
@@ -82,33 +105,129 @@ func (e *EventService) FetchReconfigureEvents(req *relay.FetchReconfigureEventsR
 	return nil
 }
 
-var refreshInterval = 5 * time.Second
+var (
+	refreshInterval = 5 * time.Second
+	// watchRefreshInterval is used instead of refreshInterval when the store
+	// pushes update events. Polling continues at this slower pace to pick up
+	// new clients and any event missed while re-subscribing.
+	watchRefreshInterval = 1 * time.Minute
+)
 
 func (e *EventService) run(ctx context.Context) {
-	var latestID string
+	latestIDs := map[string]string{}
+	watch, canWatch := e.watcher()
+	var events <-chan struct{}
 	for {
+		if canWatch && events == nil {
+			var err error
+			events, err = watch(ctx)
+			if err != nil {
+				if !errors.Is(err, store.ErrEventsNotSupported) {
+					e.logger.With(zap.Error(err)).Error("watch events, " +
+						"falling back to polling")
+				}
+				canWatch = !errors.Is(err, store.ErrEventsNotSupported)
+				events = nil
+			}
+		}
 		// return only when ctx.Done(), otherwise log errors and keep running
-		eventID, err := e.lastEvent(ctx)
-		if err != nil {
-			e.logger.With(zap.Error(err)).Error("fetch event")
-		} else {
-			latestID = eventID
+		clusterIDs := map[string]string{}
+		for _, cluster := range e.clusters() {
+			eventID, err := e.lastEvent(ctx, cluster)
+			if err != nil {
+				e.logger.With(zap.Error(err), zap.String("cluster", cluster)).
+					Error("fetch event")
+				eventID = latestIDs[cluster]
+			}
+			clusterIDs[cluster] = eventID
 		}
+		latestIDs = clusterIDs
 		// update clients unconditionally since there could be new clients
 		// that need to be sent old updates
-		e.updateClients(latestID)
+		e.updateClients(latestIDs)
+		interval := e.refreshInterval
+		if events != nil {
+			interval = e.watchRefreshInterval
+		}
 		select {
 		case <-ctx.Done():
 			e.logger.Info("shutting down due to context cancellation")
 			return
-		case <-time.After(refreshInterval):
+		case _, ok := <-events:
+			if !ok {
+				// subscription ended, poll until re-subscribed
+				events = nil
+				continue
+			}
+			drain(events)
+		case <-time.After(interval):
+		}
+	}
+}
+
+// drain discards events that are ready to be received, since the latest
+// event is read from the store once for all of them.
+func drain(events <-chan struct{}) {
+	for {
+		select {
+		case _, ok := <-events:
+			if !ok {
+				return
+			}
+		default:
+			return
+		}
+	}
+}
+
+// watcher returns the function watching the update events of the clusters
+// of the clients, if the store pushes them.
+func (e *EventService) watcher() (func(ctx context.Context) (<-chan struct{}, error), bool) {
+	if e.storeLoader != nil {
+		watcher, ok := e.store.(store.ClusterEventWatcher)
+		if !ok {
+			return nil, false
 		}
+		return watcher.WatchClusterEvents, true
+	}
+	watcher, ok := e.store.(store.EventWatcher)
+	if !ok {
+		return nil, false
 	}
+	return watcher.WatchEvents, true
+}
+
+// storeFor returns the store of the events of a cluster.
+func (e *EventService) storeFor(ctx context.Context, cluster string) (store.Store, error) {
+	if e.storeLoader == nil || cluster == e.store.Cluster() {
+		return e.store, nil
+	}
+	return e.storeLoader.Load(ctx, &model.RequestCluster{Id: cluster})
+}
+
+// clusters returns the clusters of the clients.
+func (e *EventService) clusters() []string {
+	seen := map[string]bool{}
+	var res []string
+	e.clients.Range(func(_, value interface{}) bool {
+		if node, ok := value.(*client); ok && !seen[node.cluster] {
+			seen[node.cluster] = true
+			res = append(res, node.cluster)
+		}
+		return true
+	})
+	return res
 }
 
-func (e *EventService) lastEvent(ctx context.Context) (string, error) {
+func (e *EventService) lastEvent(ctx context.Context, cluster string) (string, error) {
+	db, err := e.storeFor(ctx, cluster)
+	if err != nil {
+		return "", err
+	}
 	event := storeEvent.New()
-	err := e.store.Read(ctx, event, store.GetByID(storeEvent.ID))
+	// Replicas may lag behind each other, which must not be mistaken for
+	// new events.
+	err = db.Read(ctx, event, store.GetByID(storeEvent.ID), store.ReadFromPrimary())
 	if err != nil {
 		if err == store.ErrNotFound {
 			return "", nil
@@ -118,7 +237,7 @@ func (e *EventService) lastEvent(ctx context.Context) (string, error) {
 	return event.StoreEvent.Value, nil
 }
 
-func (e *EventService) updateClients(eventID string) {
+func (e *EventService) updateClients(eventIDs map[string]string) {
 	e.clients.Range(func(_, value interface{}) bool {
 		node, ok := value.(*client)
 		if !ok {
@@ -126,6 +245,7 @@ func (e *EventService) updateClients(eventID string) {
 				value, client{}))
 		}
 		clientLogger := e.logger.With(zap.String("client", node.remoteAddr))
+		eventID := eventIDs[node.cluster]
 		if node.seenID == eventID && time.Since(node.lastPush) < maxIdleTime {
 			clientLogger.Debug("skipping re-configure as seenID is up-to-date")
 			return true
@@ -4,6 +4,7 @@ import (
 	"context"
 	"net"
 	"testing"
+	"time"
 
 	model "github.com/kong/koko/internal/gen/grpc/kong/admin/model/v1"
 	relay "github.com/kong/koko/internal/gen/grpc/kong/relay/service/v1"
@@ -14,6 +15,7 @@ import (
 	"github.com/kong/koko/internal/test/util"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/status"
 	"google.golang.org/grpc/test/bufconn"
@@ -76,6 +78,74 @@ func TestEventService(t *testing.T) {
 		})
 }
 
+// watchingStore pushes update events on demand.
+type watchingStore struct {
+	store.Store
+	events chan struct{}
+}
+
+func (s watchingStore) WatchEvents(context.Context) (<-chan struct{}, error) {
+	return s.events, nil
+}
+
+func TestEventService_WatchEvents(t *testing.T) {
+	persister, err := util.GetPersister(t)
+	require.Nil(t, err)
+	db := watchingStore{
+		Store:  store.New(persister, log.Logger).ForCluster(store.DefaultCluster),
+		events: make(chan struct{}),
+	}
+	// ensure events are received only through the watcher
+	defer func(refresh, watchRefresh time.Duration) {
+		refreshInterval, watchRefreshInterval = refresh, watchRefresh
+	}(refreshInterval, watchRefreshInterval)
+	refreshInterval, watchRefreshInterval = time.Hour, time.Hour
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	opts := EventServiceOpts{
+		Store:  db,
+		Logger: log.Logger,
+	}
+	server := NewEventService(ctx, opts)
+	l := setup()
+	s := grpc.NewServer()
+	relay.RegisterEventServiceServer(s, server)
+	cc := clientConn(t, l)
+	client := relay.NewEventServiceClient(cc)
+	go func() {
+		_ = s.Serve(l)
+	}()
+	defer s.Stop()
+
+	stream, err := client.FetchReconfigureEvents(ctx,
+		&relay.FetchReconfigureEventsRequest{
+			Cluster: &model.RequestCluster{Id: store.DefaultCluster},
+		})
+	require.Nil(t, err)
+	res := resource.NewService()
+	res.Service.Host = "example.com"
+	res.Service.Path = "/"
+	require.Nil(t, db.Create(ctx, res))
+	// the stream is registered asynchronously, push until it is served
+	received := make(chan error)
+	go func() {
+		_, err := stream.Recv()
+		received <- err
+	}()
+	for {
+		select {
+		case db.events <- struct{}{}:
+			continue
+		case err := <-received:
+			require.Nil(t, err)
+		case <-time.After(5 * time.Second):
+			require.Fail(t, "no event received")
+		}
+		break
+	}
+}
+
 func setup() *bufconn.Listener {
 	const bufSize = 1024 * 1024
 	return bufconn.Listen(bufSize)
@@ -91,3 +161,60 @@ func clientConn(t *testing.T, l *bufconn.Listener) grpc.ClientConnInterface {
 	require.Nil(t, err)
 	return conn
 }
+
+func TestEventService_Clusters(t *testing.T) {
+	persister, err := util.GetPersister(t)
+	require.Nil(t, err)
+	objectStore := store.New(persister, log.Logger)
+	require.Nil(t, objectStore.CreateCluster(context.Background(),
+		&store.ClusterInfo{ID: "east"}))
+	defer func(refresh time.Duration) {
+		refreshInterval = refresh
+	}(refreshInterval)
+	refreshInterval = 100 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	server := NewEventService(ctx, EventServiceOpts{
+		Store:       objectStore.ForCluster(store.DefaultCluster),
+		StoreLoader: serverUtil.ClusterStoreLoader{Store: objectStore},
+		Logger:      log.Logger,
+	})
+	l := setup()
+	s := grpc.NewServer()
+	relay.RegisterEventServiceServer(s, server)
+	client := relay.NewEventServiceClient(clientConn(t, l))
+	go func() {
+		_ = s.Serve(l)
+	}()
+	defer s.Stop()
+
+	t.Run("errors for unknown clusters", func(t *testing.T) {
+		stream, err := client.FetchReconfigureEvents(ctx,
+			&relay.FetchReconfigureEventsRequest{
+				Cluster: &model.RequestCluster{Id: "west"},
+			})
+		require.Nil(t, err)
+		_, err = stream.Recv()
+		s, _ := status.FromError(err)
+		require.Equal(t, codes.NotFound, s.Code())
+		require.Equal(t, "cluster 'west' not found", s.Message())
+	})
+	t.Run("receives the events of its cluster", func(t *testing.T) {
+		stream, err := client.FetchReconfigureEvents(ctx,
+			&relay.FetchReconfigureEventsRequest{
+				Cluster: &model.RequestCluster{Id: "east"},
+			})
+		require.Nil(t, err)
+		// new clients are sent the latest event
+		_, err = stream.Recv()
+		require.Nil(t, err)
+
+		res := resource.NewService()
+		res.Service.Host = "example.com"
+		res.Service.Path = "/"
+		require.Nil(t, objectStore.ForCluster("east").Create(ctx, res))
+		_, err = stream.Recv()
+		require.Nil(t, err)
+	})
+}
@@ -25,6 +25,7 @@ import (
 
 const (
 	StatusCodeKey = "koko-status-code"
+	ETagKey       = "etag"
 )
 
 var referenceRegex = regexp.MustCompile(typedefs.ReferencePattern)
@@ -52,6 +53,46 @@ func HandlerWithLogger(handler http.Handler, logger *zap.Logger) http.Handler {
 	})
 }
 
+// HandlerWithActor is http handler middleware that attributes the writes of
+// requests to the subject of their authorization token in the history of
+// entities. Tokens are verified with verifier, writes are not attributed to
+// any actor when verifier is nil or the token fails verification.
+func HandlerWithActor(handler http.Handler, verifier *JwtService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if actor := actorFromAuthorization(verifier, r.Header.Get("Authorization")); actor != "" {
+			r = r.WithContext(store.WithActor(r.Context(), actor))
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// ActorInterceptor is the gRPC counterpart of HandlerWithActor.
+func ActorInterceptor(verifier *JwtService) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{},
+		_ *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if values := md.Get("authorization"); len(values) > 0 {
+				if actor := actorFromAuthorization(verifier, values[0]); actor != "" {
+					ctx = store.WithActor(ctx, actor)
+				}
+			}
+		}
+		return handler(ctx, req)
+	}
+}
+
+func actorFromAuthorization(verifier *JwtService, authorization string) string {
+	if verifier == nil || authorization == "" {
+		return ""
+	}
+	claims, err := verifier.ParseAuthorization(authorization)
+	if err != nil {
+		return ""
+	}
+	return claims.Subject
+}
+
 // HandlerWithRecovery is http handler middleware that gracefully handles panics by calling a deferred recover
 // for all wrapped handlers. When a panic is encountered, a generic error message and response code 500 are returned
 // to the client. More detailed error information is logged if the service log level is set to Error or higher.
@@ -146,11 +187,32 @@ func ErrorHandler(ctx context.Context,
 		}
 	}
 
+	// Services may override the HTTP status code of errors, e.g. to return
+	// 412 (Precondition Failed) instead of 400 (Bad Request).
+	if md, ok := runtime.ServerMetadataFromContext(ctx); ok {
+		if values := md.HeaderMD.Get(StatusCodeKey); len(values) > 0 {
+			if code, err := strconv.Atoi(values[0]); err == nil {
+				w = statusCodeWriter{ResponseWriter: w, code: code}
+			}
+			md.HeaderMD.Delete(StatusCodeKey)
+		}
+	}
+
 	SetSpanResource(ctx)
 	runtime.DefaultHTTPErrorHandler(ctx, mux, m,
 		w, r, err)
 }
 
+// statusCodeWriter writes code in place of the status code it is given.
+type statusCodeWriter struct {
+	http.ResponseWriter
+	code int
+}
+
+func (w statusCodeWriter) WriteHeader(int) {
+	w.ResponseWriter.WriteHeader(w.code)
+}
+
 func FinishTrace(ctx context.Context,
 	_ http.ResponseWriter, _ proto2.Message,
 ) error {
@@ -166,6 +228,27 @@ func SetHeader(ctx context.Context, code int) {
 	}
 }
 
+// SetETag sets the ETag of the response.
+func SetETag(ctx context.Context, etag string) {
+	err := grpc.SetHeader(ctx, metadata.Pairs(ETagKey, etag))
+	if err != nil {
+		panic(err)
+	}
+}
+
+// SetHTTPETag renames the ETag set by SetETag to the standard ETag header.
+// It must run before SetHTTPStatus, which may write the response headers.
+func SetHTTPETag(_ context.Context, w http.ResponseWriter,
+	_ proto2.Message,
+) error {
+	header := runtime.MetadataHeaderPrefix + ETagKey
+	if etag := w.Header().Get(header); etag != "" {
+		w.Header().Set("ETag", etag)
+		w.Header().Del(header)
+	}
+	return nil
+}
+
 func SetHTTPStatus(ctx context.Context, w http.ResponseWriter,
 	_ proto2.Message,
 ) error {
@@ -208,6 +291,9 @@ func HandleErr(ctx context.Context, logger *zap.Logger, err error) error {
 	if errors.Is(err, store.ErrNotFound) {
 		return status.Error(codes.NotFound, "")
 	}
+	if errors.Is(err, store.ErrInvalidPageToken) {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
 
 	// Do not include types that are aliased to the `error`
 	// interface, as it will leak all errors to clients.
@@ -240,6 +326,28 @@ func HandleErr(ctx context.Context, logger *zap.Logger, err error) error {
 		return s.Err()
 	case store.ErrUnsupportedListOpts:
 		return status.New(codes.FailedPrecondition, e.Error()).Err()
+	case store.ErrRevisionMismatch:
+		// The header is only available to HTTP clients, for which it
+		// replaces the default 400 (Bad Request) status code.
+		_ = grpc.SetHeader(ctx, metadata.Pairs(StatusCodeKey,
+			strconv.Itoa(http.StatusPreconditionFailed)))
+		return status.New(codes.FailedPrecondition, e.Error()).Err()
+	case store.ErrHasDependents:
+		_ = grpc.SetHeader(ctx, metadata.Pairs(StatusCodeKey,
+			strconv.Itoa(http.StatusConflict)))
+		messages := make([]string, 0, len(e.Dependents))
+		for _, dependent := range e.Dependents {
+			messages = append(messages, dependent.String())
+		}
+		s := status.New(codes.FailedPrecondition, e.Error())
+		s, err := s.WithDetails(&pbModel.ErrorDetail{
+			Type:     pbModel.ErrorType_ERROR_TYPE_REFERENCE,
+			Messages: messages,
+		})
+		if err != nil {
+			panic(err)
+		}
+		return s.Err()
 	default:
 		logger.With(zap.Error(err)).Error("error in service")
 		return status.Error(codes.Internal, "")
@@ -0,0 +1,127 @@
+package util
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strconv"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// RevealSecretsKey is the header making the responses of a request carry the
+// values of sensitive fields, e.g.: private keys, instead of redacted
+// placeholders, when set to true.
+const RevealSecretsKey = "koko-reveal-secrets"
+
+type revealSecretsKey struct{}
+
+// WithRevealSecrets returns a context revealing the values of sensitive
+// fields in the responses of the admin API.
+func WithRevealSecrets(ctx context.Context) context.Context {
+	return context.WithValue(ctx, revealSecretsKey{}, true)
+}
+
+// RevealSecrets returns true if the values of sensitive fields are revealed
+// for ctx.
+func RevealSecrets(ctx context.Context) bool {
+	reveal, _ := ctx.Value(revealSecretsKey{}).(bool)
+	return reveal
+}
+
+// HandlerWithRevealSecrets is http handler middleware that reveals the values
+// of sensitive fields to requests setting the Koko-Reveal-Secrets header to
+// true. Such requests are forbidden unless allowed is true, in which case
+// access to the header must be restricted to privileged clients, e.g.: by a
+// proxy in front of the admin API.
+func HandlerWithRevealSecrets(handler http.Handler, allowed bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if reveal, _ := strconv.ParseBool(r.Header.Get(RevealSecretsKey)); reveal {
+			if !allowed {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusForbidden)
+				_, _ = w.Write([]byte(`{"message":"revealing secrets is not allowed"}`))
+				return
+			}
+			r = r.WithContext(WithRevealSecrets(r.Context()))
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// RevealSecretsInterceptor is the gRPC counterpart of HandlerWithRevealSecrets.
+// Calls accepted by an InProcessListener always reveal the values of sensitive
+// fields, as they are made by the control plane itself, e.g.: to build the
+// configuration of data planes.
+func RevealSecretsInterceptor(allowed bool) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{},
+		_ *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		if isInProcess(ctx) {
+			return handler(WithRevealSecrets(ctx), req)
+		}
+		md, _ := metadata.FromIncomingContext(ctx)
+		if values := md.Get(RevealSecretsKey); len(values) > 0 {
+			if reveal, _ := strconv.ParseBool(values[0]); reveal {
+				if !allowed {
+					return nil, status.Error(codes.PermissionDenied,
+						"revealing secrets is not allowed")
+				}
+				ctx = WithRevealSecrets(ctx)
+			}
+		}
+		return handler(ctx, req)
+	}
+}
+
+const inProcessBufferSize = 1024 * 1024
+
+// InProcessListener is a listener of gRPC connections made within the
+// process, through DialContext.
+type InProcessListener struct {
+	*bufconn.Listener
+}
+
+// NewInProcessListener returns a listener of gRPC connections made within the
+// process.
+func NewInProcessListener() *InProcessListener {
+	return &InProcessListener{Listener: bufconn.Listen(inProcessBufferSize)}
+}
+
+// Accept implements the net.Listener interface.
+func (l *InProcessListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return inProcessConn{Conn: conn}, nil
+}
+
+// inProcessConn is a connection accepted by an InProcessListener. Its remote
+// address tells calls made within the process apart from remote ones.
+type inProcessConn struct {
+	net.Conn
+}
+
+func (inProcessConn) RemoteAddr() net.Addr {
+	return inProcessAddr{}
+}
+
+type inProcessAddr struct{}
+
+func (inProcessAddr) Network() string { return "in-process" }
+func (inProcessAddr) String() string  { return "in-process" }
+
+func isInProcess(ctx context.Context) bool {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return false
+	}
+	_, ok = p.Addr.(inProcessAddr)
+	return ok
+}
@@ -0,0 +1,100 @@
+package util
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gavv/httpexpect/v2"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func TestHandlerWithRevealSecrets(t *testing.T) {
+	var reveal bool
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reveal = RevealSecrets(r.Context())
+		w.WriteHeader(http.StatusOK)
+	})
+
+	t.Run("secrets are not revealed by default", func(t *testing.T) {
+		s := httptest.NewServer(HandlerWithRevealSecrets(handler, true))
+		defer s.Close()
+		c := httpexpect.Default(t, s.URL)
+		c.GET("/v1/certificates").Expect().Status(http.StatusOK)
+		require.False(t, reveal)
+		c.GET("/v1/certificates").WithHeader("Koko-Reveal-Secrets", "false").
+			Expect().Status(http.StatusOK)
+		require.False(t, reveal)
+	})
+	t.Run("secrets are revealed on request when allowed", func(t *testing.T) {
+		s := httptest.NewServer(HandlerWithRevealSecrets(handler, true))
+		defer s.Close()
+		c := httpexpect.Default(t, s.URL)
+		c.GET("/v1/certificates").WithHeader("Koko-Reveal-Secrets", "true").
+			Expect().Status(http.StatusOK)
+		require.True(t, reveal)
+	})
+	t.Run("requests revealing secrets are forbidden unless allowed", func(t *testing.T) {
+		reveal = false
+		s := httptest.NewServer(HandlerWithRevealSecrets(handler, false))
+		defer s.Close()
+		c := httpexpect.Default(t, s.URL)
+		res := c.GET("/v1/certificates").WithHeader("Koko-Reveal-Secrets", "true").Expect()
+		res.Status(http.StatusForbidden)
+		res.JSON().Path("$.message").Equal("revealing secrets is not allowed")
+		require.False(t, reveal)
+	})
+}
+
+func TestRevealSecretsInterceptor(t *testing.T) {
+	handler := func(ctx context.Context, _ interface{}) (interface{}, error) {
+		return RevealSecrets(ctx), nil
+	}
+	remote := peer.NewContext(context.Background(), &peer.Peer{
+		Addr: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 3001},
+	})
+	withHeader := metadata.NewIncomingContext(remote,
+		metadata.Pairs(RevealSecretsKey, "true"))
+	call := func(ctx context.Context, allowed bool) (interface{}, error) {
+		return RevealSecretsInterceptor(allowed)(ctx, nil, &grpc.UnaryServerInfo{}, handler)
+	}
+
+	t.Run("secrets are not revealed by default", func(t *testing.T) {
+		reveal, err := call(remote, true)
+		require.Nil(t, err)
+		require.Equal(t, false, reveal)
+	})
+	t.Run("secrets are revealed on request when allowed", func(t *testing.T) {
+		reveal, err := call(withHeader, true)
+		require.Nil(t, err)
+		require.Equal(t, true, reveal)
+	})
+	t.Run("calls revealing secrets are denied unless allowed", func(t *testing.T) {
+		_, err := call(withHeader, false)
+		require.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+	t.Run("secrets are revealed to in-process calls", func(t *testing.T) {
+		l := NewInProcessListener()
+		defer l.Close()
+		go func() {
+			conn, err := l.DialContext(context.Background())
+			if err == nil {
+				defer conn.Close()
+			}
+		}()
+		conn, err := l.Accept()
+		require.Nil(t, err)
+		defer conn.Close()
+		ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: conn.RemoteAddr()})
+		reveal, err := call(ctx, false)
+		require.Nil(t, err)
+		require.Equal(t, true, reveal)
+	})
+}
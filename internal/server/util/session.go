@@ -0,0 +1,118 @@
+package util
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/kong/koko/internal/persistence"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// SessionKey is the header carrying the session token of a client.
+	SessionKey = "koko-session"
+	// PrimaryReadsKey is the header making the reads of a request be served
+	// by the primary database when set to true.
+	PrimaryReadsKey = "koko-primary-reads"
+)
+
+// HandlerWithSession is http handler middleware that tracks the writes of
+// clients across requests, so that their reads are not served by database
+// replicas lagging behind their own writes. Once a client writes, responses
+// carry the token of its session in the Koko-Session header, which the client
+// passes along with its following requests.
+//
+// The reads of requests setting the Koko-Primary-Reads header to true are
+// served by the primary database.
+func HandlerWithSession(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		session, err := persistence.NewSession(r.Header.Get(SessionKey))
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"message":"invalid Koko-Session header"}`))
+			return
+		}
+		ctx := persistence.WithSession(r.Context(), session)
+		if primary, _ := strconv.ParseBool(r.Header.Get(PrimaryReadsKey)); primary {
+			ctx = persistence.WithPrimaryReads(ctx)
+		}
+		handler.ServeHTTP(&sessionResponseWriter{ResponseWriter: w, session: session},
+			r.WithContext(ctx))
+	})
+}
+
+// sessionResponseWriter sets the session header of the response once the
+// request is handled, right before the response headers are written.
+type sessionResponseWriter struct {
+	http.ResponseWriter
+	session     *persistence.Session
+	wroteHeader bool
+}
+
+func (w *sessionResponseWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		if token := w.session.Token(); token != "" {
+			w.Header().Set(SessionKey, token)
+		}
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *sessionResponseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *sessionResponseWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// SessionInterceptor is the gRPC counterpart of HandlerWithSession.
+func SessionInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{},
+		_ *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		var token string
+		if values := md.Get(SessionKey); len(values) > 0 {
+			token = values[0]
+		}
+		session, err := persistence.NewSession(token)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid koko-session metadata")
+		}
+		ctx = persistence.WithSession(ctx, session)
+		if values := md.Get(PrimaryReadsKey); len(values) > 0 {
+			if primary, _ := strconv.ParseBool(values[0]); primary {
+				ctx = persistence.WithPrimaryReads(ctx)
+			}
+		}
+		res, err := handler(ctx, req)
+		if token := session.Token(); token != "" {
+			_ = grpc.SetHeader(ctx, metadata.Pairs(SessionKey, token))
+		}
+		return res, err
+	}
+}
+
+// PrimaryReadsClientInterceptor makes the reads of every call of a gRPC client
+// of the admin API be served by the primary database, e.g.: for the control
+// plane to build the configuration of data planes out of the latest writes.
+func PrimaryReadsClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{},
+		cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption,
+	) error {
+		ctx = metadata.AppendToOutgoingContext(ctx, PrimaryReadsKey, "true")
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
@@ -0,0 +1,43 @@
+package util
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gavv/httpexpect/v2"
+	"github.com/kong/koko/internal/persistence"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandlerWithSession(t *testing.T) {
+	var session *persistence.Session
+	s := httptest.NewServer(HandlerWithSession(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			session = persistence.SessionFromContext(r.Context())
+			w.WriteHeader(http.StatusOK)
+		})))
+	defer s.Close()
+	c := httpexpect.Default(t, s.URL)
+
+	t.Run("requests without a session start one", func(t *testing.T) {
+		res := c.GET("/v1/services").Expect()
+		res.Status(http.StatusOK)
+		res.Headers().NotContainsKey("Koko-Session")
+		require.NotNil(t, session)
+		require.Empty(t, session.Position())
+	})
+	t.Run("requests resume their session", func(t *testing.T) {
+		token := base64.RawURLEncoding.EncodeToString([]byte("16/B374D848"))
+		res := c.GET("/v1/services").WithHeader("Koko-Session", token).Expect()
+		res.Status(http.StatusOK)
+		res.Header("Koko-Session").Equal(token)
+		require.Equal(t, "16/B374D848", session.Position())
+	})
+	t.Run("invalid sessions are rejected", func(t *testing.T) {
+		res := c.GET("/v1/services").WithHeader("Koko-Session", "not a token!").Expect()
+		res.Status(http.StatusBadRequest)
+		res.JSON().Path("$.message").Equal("invalid Koko-Session header")
+	})
+}
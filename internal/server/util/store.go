@@ -46,3 +46,41 @@ func (d DefaultStoreLoader) Load(_ context.Context,
 ) (store.Store, error) {
 	return d.Store, nil
 }
+
+// ClusterStoreLoader loads the store of the cluster of requests, which
+// defaults to store.DefaultCluster. Clusters other than the default one must
+// have been created with store.Store.CreateCluster.
+type ClusterStoreLoader struct {
+	Store *store.ObjectStore
+	// Cache, when set, caches the objects read from the stores loaded.
+	Cache *store.Cache
+}
+
+func (c ClusterStoreLoader) Load(ctx context.Context,
+	cluster *model.RequestCluster,
+) (store.Store, error) {
+	id := cluster.GetId()
+	if id == "" || id == store.DefaultCluster {
+		return c.forCluster(store.DefaultCluster), nil
+	}
+	if err := store.ValidateCluster(id); err != nil {
+		return nil, StoreLoadErr{Code: codes.InvalidArgument, Message: err.Error()}
+	}
+	if _, err := c.Store.ReadCluster(ctx, id); err != nil {
+		if err == store.ErrNotFound {
+			return nil, StoreLoadErr{
+				Code:    codes.NotFound,
+				Message: fmt.Sprintf("cluster '%s' not found", id),
+			}
+		}
+		return nil, err
+	}
+	return c.forCluster(id), nil
+}
+
+func (c ClusterStoreLoader) forCluster(id string) store.Store {
+	if c.Cache == nil {
+		return c.Store.ForCluster(id)
+	}
+	return store.NewCachedStore(c.Store.ForCluster(id), c.Cache)
+}
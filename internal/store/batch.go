@@ -0,0 +1,117 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kong/koko/internal/model"
+	"github.com/kong/koko/internal/persistence"
+)
+
+// BatchOperationType is the kind of write performed by a BatchOperation.
+type BatchOperationType int
+
+const (
+	BatchOperationCreate BatchOperationType = iota + 1
+	BatchOperationUpsert
+	BatchOperationDelete
+)
+
+// BatchOperation is a single write applied by ApplyBatch.
+type BatchOperation struct {
+	Type BatchOperationType
+	// Object is the object to create or upsert.
+	Object model.Object
+	// ObjectType and ID reference the object to delete.
+	ObjectType model.Type
+	ID         string
+	// ExpectedRevision, when set, makes upserts and deletes fail with
+	// ErrRevisionMismatch unless the object has this revision.
+	ExpectedRevision uint64
+	// DeleteMode sets how the dependents of the deleted object are handled.
+	DeleteMode DeleteMode
+}
+
+// ErrBatchOperation is returned by ApplyBatch when an operation fails.
+// None of the operations of the batch are applied in that case.
+type ErrBatchOperation struct {
+	// Index is the position of the failed operation in the batch.
+	Index int
+	Err   error
+}
+
+func (e ErrBatchOperation) Error() string {
+	return fmt.Sprintf("batch operation %d: %v", e.Index, e.Err)
+}
+
+func (e ErrBatchOperation) Unwrap() error {
+	return e.Err
+}
+
+// batchTx is the transaction in which a batch is applied.
+// It defers the update events of the operations so that a single event is
+// emitted once all of them are applied.
+type batchTx struct {
+	persistence.Tx
+	updated bool
+}
+
+// ApplyBatch implements the Store interface.
+func (s *ObjectStore) ApplyBatch(ctx context.Context, ops []BatchOperation) error {
+	ctx, cancel := context.WithTimeout(ctx, DefaultOperationTimeout)
+	defer cancel()
+	for i, op := range ops {
+		if err := validateBatchOperation(ctx, op); err != nil {
+			return ErrBatchOperation{Index: i, Err: err}
+		}
+	}
+
+	return s.withTx(ctx, func(tx persistence.Tx) error {
+		batch := &batchTx{Tx: tx}
+		for i, op := range ops {
+			var err error
+			switch op.Type {
+			case BatchOperationCreate:
+				err = s.create(ctx, batch, op.Object, &CreateOpts{})
+			case BatchOperationUpsert:
+				err = s.upsert(ctx, batch, op.Object, &CreateOpts{
+					expectedRevision: op.ExpectedRevision,
+				})
+			case BatchOperationDelete:
+				if op.ExpectedRevision != 0 {
+					err = s.checkRevision(ctx, batch, op.ObjectType, op.ID, op.ExpectedRevision)
+				}
+				if err == nil {
+					err = s.delete(ctx, batch, op.ObjectType, op.ID, &DeleteOpts{mode: op.DeleteMode})
+				}
+			}
+			if err != nil {
+				return ErrBatchOperation{Index: i, Err: err}
+			}
+		}
+		if !batch.updated {
+			return nil
+		}
+		return s.writeUpdateEvent(ctx, tx)
+	})
+}
+
+func validateBatchOperation(ctx context.Context, op BatchOperation) error {
+	switch op.Type {
+	case BatchOperationCreate, BatchOperationUpsert:
+		if op.Object == nil {
+			return errNoObject
+		}
+		return preProcess(ctx, op.Object)
+	case BatchOperationDelete:
+		if !model.ValidType(op.ObjectType) {
+			return fmt.Errorf("invalid type: '%v'", op.ObjectType)
+		}
+		if op.ID == "" {
+			return fmt.Errorf("no id")
+		}
+		return nil
+	default:
+		return fmt.Errorf("invalid batch operation type: %d", op.Type)
+	}
+}
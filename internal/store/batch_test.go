@@ -0,0 +1,177 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	v1 "github.com/kong/koko/internal/gen/grpc/kong/admin/model/v1"
+	"github.com/kong/koko/internal/log"
+	"github.com/kong/koko/internal/model/json/validation"
+	"github.com/kong/koko/internal/resource"
+	"github.com/kong/koko/internal/store/event"
+	"github.com/kong/koko/internal/test/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyBatch(t *testing.T) {
+	persister, err := util.GetPersister(t)
+	require.Nil(t, err)
+	s := New(persister, log.Logger).ForCluster(DefaultCluster)
+	ctx := context.Background()
+
+	readEvent := func(t *testing.T) string {
+		e := event.New()
+		err := s.Read(ctx, e, GetByID(event.ID))
+		if errors.Is(err, ErrNotFound) {
+			return ""
+		}
+		require.Nil(t, err)
+		return e.StoreEvent.Value
+	}
+
+	sid := uuid.NewString()
+	t.Run("operations are applied in order", func(t *testing.T) {
+		svc := resource.NewService()
+		svc.Service = &v1.Service{
+			Id:   sid,
+			Name: "s0",
+			Host: "foo.com",
+		}
+		route := resource.NewRoute()
+		route.Route = &v1.Route{
+			Name:    "r0",
+			Hosts:   []string{"example.com"},
+			Service: &v1.Service{Id: sid},
+		}
+		err := s.ApplyBatch(ctx, []BatchOperation{
+			{Type: BatchOperationCreate, Object: svc},
+			{Type: BatchOperationCreate, Object: route},
+		})
+		require.Nil(t, err)
+
+		route = resource.NewRoute()
+		require.Nil(t, s.Read(ctx, route, GetByName("r0")))
+		require.Equal(t, sid, route.Route.Service.Id)
+		require.NotEmpty(t, readEvent(t))
+	})
+	t.Run("a failed operation rolls back the whole batch", func(t *testing.T) {
+		eventBefore := readEvent(t)
+		svc := resource.NewService()
+		svc.Service = &v1.Service{
+			Id:   uuid.NewString(),
+			Name: "s1",
+			Host: "foo.com",
+		}
+		route := resource.NewRoute()
+		route.Route = &v1.Route{
+			Name:    "r1",
+			Hosts:   []string{"example.com"},
+			Service: &v1.Service{Id: uuid.NewString()},
+		}
+		err := s.ApplyBatch(ctx, []BatchOperation{
+			{Type: BatchOperationCreate, Object: svc},
+			{Type: BatchOperationDelete, ObjectType: resource.TypeService, ID: sid},
+			{Type: BatchOperationCreate, Object: route},
+		})
+		var batchErr ErrBatchOperation
+		require.ErrorAs(t, err, &batchErr)
+		require.Equal(t, 2, batchErr.Index)
+		require.IsType(t, ErrConstraint{}, batchErr.Err)
+
+		require.Equal(t, ErrNotFound, s.Read(ctx, resource.NewService(),
+			GetByName("s1")))
+		require.Nil(t, s.Read(ctx, resource.NewService(), GetByID(sid)))
+		require.Equal(t, eventBefore, readEvent(t))
+	})
+	t.Run("an invalid object fails the batch before any write", func(t *testing.T) {
+		svc := resource.NewService()
+		svc.Service = &v1.Service{
+			Id:   uuid.NewString(),
+			Name: "s2",
+			Host: "foo.com",
+		}
+		err := s.ApplyBatch(ctx, []BatchOperation{
+			{Type: BatchOperationCreate, Object: svc},
+			{Type: BatchOperationUpsert, Object: resource.NewService()},
+		})
+		var batchErr ErrBatchOperation
+		require.ErrorAs(t, err, &batchErr)
+		require.Equal(t, 1, batchErr.Index)
+		require.IsType(t, validation.Error{}, batchErr.Err)
+		require.Equal(t, ErrNotFound, s.Read(ctx, resource.NewService(),
+			GetByName("s2")))
+	})
+	t.Run("deleting a non-existent object fails", func(t *testing.T) {
+		err := s.ApplyBatch(ctx, []BatchOperation{
+			{
+				Type:       BatchOperationDelete,
+				ObjectType: resource.TypeService,
+				ID:         uuid.NewString(),
+			},
+		})
+		require.ErrorIs(t, err, ErrNotFound)
+	})
+	t.Run("upsert and delete emit a single new event", func(t *testing.T) {
+		eventBefore := readEvent(t)
+		svc := resource.NewService()
+		svc.Service = &v1.Service{
+			Id:   sid,
+			Name: "s0",
+			Host: "bar.com",
+		}
+		route := resource.NewRoute()
+		require.Nil(t, s.Read(ctx, route, GetByName("r0")))
+		err := s.ApplyBatch(ctx, []BatchOperation{
+			{Type: BatchOperationUpsert, Object: svc},
+			{Type: BatchOperationDelete, ObjectType: resource.TypeRoute, ID: route.ID()},
+		})
+		require.Nil(t, err)
+		require.NotEqual(t, eventBefore, readEvent(t))
+
+		svc = resource.NewService()
+		require.Nil(t, s.Read(ctx, svc, GetByID(sid)))
+		require.Equal(t, "bar.com", svc.Service.Host)
+		require.Equal(t, ErrNotFound, s.Read(ctx, resource.NewRoute(),
+			GetByName("r0")))
+	})
+	t.Run("operations fail unless the object has the expected revision", func(t *testing.T) {
+		svc := resource.NewService()
+		var revision uint64
+		require.Nil(t, s.Read(ctx, svc, GetByID(sid), ReadRevision(&revision)))
+		for _, op := range []BatchOperation{
+			{Type: BatchOperationUpsert, Object: svc},
+			{Type: BatchOperationDelete, ObjectType: resource.TypeService, ID: sid},
+		} {
+			op.ExpectedRevision = revision + 1
+			err := s.ApplyBatch(ctx, []BatchOperation{op})
+			require.ErrorAs(t, err, &ErrRevisionMismatch{})
+		}
+	})
+	t.Run("restricted deletes fail while the object has dependents", func(t *testing.T) {
+		route := resource.NewRoute()
+		route.Route = &v1.Route{
+			Id:      uuid.NewString(),
+			Hosts:   []string{"example.com"},
+			Service: &v1.Service{Id: sid},
+		}
+		require.Nil(t, s.Create(ctx, route))
+		err := s.ApplyBatch(ctx, []BatchOperation{{
+			Type:       BatchOperationDelete,
+			ObjectType: resource.TypeService,
+			ID:         sid,
+			DeleteMode: DeleteModeRestrict,
+		}})
+		var dependentsErr ErrHasDependents
+		require.ErrorAs(t, err, &dependentsErr)
+		require.Equal(t, []Dependent{{Type: resource.TypeRoute, ID: route.ID()}},
+			dependentsErr.Dependents)
+		require.Nil(t, s.Read(ctx, resource.NewRoute(), GetByID(route.ID())))
+	})
+	t.Run("an empty batch is a no-op", func(t *testing.T) {
+		eventBefore := readEvent(t)
+		require.Nil(t, s.ApplyBatch(ctx, nil))
+		require.Equal(t, eventBefore, readEvent(t))
+	})
+}
@@ -0,0 +1,525 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	metricsv2 "github.com/kong/koko/internal/metrics/v2"
+	"github.com/kong/koko/internal/model"
+	"github.com/kong/koko/internal/persistence"
+	"github.com/kong/koko/internal/store/event"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// DefaultCacheTTL is the default duration for which objects are cached.
+	DefaultCacheTTL = 30 * time.Second
+	// DefaultCachePollInterval is the default duration between polls of the
+	// update events of the clusters of a Cache.
+	DefaultCachePollInterval = 5 * time.Second
+)
+
+// cacheRequests counts the reads of CachedStore.
+var cacheRequests = metricsv2.NewCounter(prometheus.DefaultRegisterer, metricsv2.CounterOpts{
+	Subsystem:  "store",
+	Name:       "cache_requests_total",
+	Help:       "Number of reads of the store cache, by operation and result (hit, miss or bypass).",
+	LabelNames: []string{"operation", "result"},
+})
+
+// uncachedTypes are the types of the objects that are read from the store
+// as is: update events, and nodes, whose writes are not recorded as update
+// events.
+var uncachedTypes = map[model.Type]bool{
+	event.Type: true,
+	nodeType:   true,
+}
+
+// Cache caches the objects read through CachedStore, for every cluster.
+//
+// The entries of a cluster are invalidated when an object of the cluster is
+// written through a CachedStore, and once an update event recorded by any
+// other store is observed by Run, e.g.: for writes of another instance of
+// Koko. Entries expire after a TTL, which bounds how stale they get until
+// update events are observed. Entries are invalidated for the whole cluster,
+// as writes cascade to the objects of other types, and update events do not
+// record the types written.
+//
+// Entries are read from read replicas, if any, once they have replayed the
+// writes of the primary database up to the invalidation of the entries.
+type Cache struct {
+	ttl time.Duration
+
+	lock     sync.Mutex
+	clusters map[string]*clusterCache
+}
+
+type clusterCache struct {
+	// generation is incremented whenever the entries of the cluster are
+	// invalidated, so that reads started before are not cached.
+	generation uint64
+	// event is the value of the latest update event observed.
+	event string
+	// position is the write position of the primary database the entries
+	// are read at, recorded by the first read following an invalidation.
+	position   string
+	positioned bool
+	entries    map[string]cacheEntry
+}
+
+// cacheEntry is the result of a read, or of a list.
+type cacheEntry struct {
+	resources     []model.Resource
+	revisions     []uint64
+	totalCount    int
+	nextPage      int
+	nextPageToken string
+	expiresAt     time.Time
+}
+
+// NewCache returns a cache holding objects for ttl, or for DefaultCacheTTL if
+// ttl is zero.
+func NewCache(ttl time.Duration) *Cache {
+	if ttl == 0 {
+		ttl = DefaultCacheTTL
+	}
+	return &Cache{
+		ttl:      ttl,
+		clusters: map[string]*clusterCache{},
+	}
+}
+
+// Invalidate drops the entries of cluster.
+func (c *Cache) Invalidate(cluster string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.invalidate(c.cluster(cluster))
+}
+
+// Run keeps the cache up to date with the update events of the clusters of
+// s until ctx is done. Update events are polled for every interval, or every
+// DefaultCachePollInterval if interval is zero, and observed as soon as they
+// are recorded if the persister of s pushes them.
+func (c *Cache) Run(ctx context.Context, s *ObjectStore, interval time.Duration) error {
+	if interval == 0 {
+		interval = DefaultCachePollInterval
+	}
+	var notifications <-chan string
+	if source, ok := s.store.(persistence.EventSource); ok {
+		var err error
+		notifications, err = source.Listen(ctx, event.Channel)
+		if err != nil {
+			s.logger.Warn("failed to listen for update events, polling for them",
+				zap.Error(err))
+		}
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case cluster, ok := <-notifications:
+			if !ok {
+				if ctx.Err() == nil {
+					s.logger.Warn("update events subscription ended, polling for them")
+				}
+				notifications = nil
+				continue
+			}
+			if ValidateCluster(cluster) != nil {
+				continue
+			}
+			if err := c.refresh(ctx, s.ForCluster(cluster)); err != nil {
+				s.logger.Warn("failed to read update event, invalidating cache",
+					zap.String("cluster", cluster), zap.Error(err))
+				c.Invalidate(cluster)
+			}
+		case <-ticker.C:
+			if err := c.poll(ctx, s); err != nil {
+				s.logger.Warn("failed to poll update events", zap.Error(err))
+			}
+		}
+	}
+}
+
+// poll observes the latest update event of every cluster, and drops the
+// entries of the clusters that were deleted along with expired entries.
+func (c *Cache) poll(ctx context.Context, s *ObjectStore) error {
+	clusters, err := s.clusters(ctx)
+	if err != nil {
+		return err
+	}
+	for cluster := range clusters {
+		if err := c.refresh(ctx, s.ForCluster(cluster)); err != nil {
+			return err
+		}
+	}
+	c.prune(clusters)
+	return nil
+}
+
+// refresh observes the latest update event of the cluster of db.
+func (c *Cache) refresh(ctx context.Context, db Store) error {
+	e := event.New()
+	err := db.Read(ctx, e, GetByID(event.ID), ReadFromPrimary())
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	c.observe(db.Cluster(), e.StoreEvent.Value)
+	return nil
+}
+
+// observe records value as the latest update event of cluster, invalidating
+// the entries of the cluster if the update event changed.
+func (c *Cache) observe(cluster, value string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	cc := c.cluster(cluster)
+	if cc.event != value {
+		c.invalidate(cc)
+		cc.event = value
+	}
+}
+
+func (c *Cache) prune(clusters map[string]bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	now := time.Now()
+	for id, cc := range c.clusters {
+		if !clusters[id] {
+			delete(c.clusters, id)
+			continue
+		}
+		for key, entry := range cc.entries {
+			if now.After(entry.expiresAt) {
+				delete(cc.entries, key)
+			}
+		}
+	}
+}
+
+// cluster returns the cache of a cluster. The lock of c must be held.
+func (c *Cache) cluster(id string) *clusterCache {
+	cc, ok := c.clusters[id]
+	if !ok {
+		cc = &clusterCache{entries: map[string]cacheEntry{}}
+		c.clusters[id] = cc
+	}
+	return cc
+}
+
+// invalidate drops the entries of cc. The lock of c must be held.
+func (c *Cache) invalidate(cc *clusterCache) {
+	cc.generation++
+	cc.position, cc.positioned = "", false
+	cc.entries = map[string]cacheEntry{}
+}
+
+// readAt returns the generation of the entries of the cluster of db, along
+// with a copy of ctx in which reads observe every write preceding their
+// invalidation.
+func (c *Cache) readAt(ctx context.Context, db Store) (context.Context, uint64, error) {
+	cluster := db.Cluster()
+	c.lock.Lock()
+	cc := c.cluster(cluster)
+	generation, position, positioned := cc.generation, cc.position, cc.positioned
+	c.lock.Unlock()
+	if !positioned {
+		positioner := replicaPositioner(db)
+		if positioner == nil {
+			return ctx, generation, nil
+		}
+		var err error
+		if position, err = positioner.WritePosition(ctx); err != nil {
+			return nil, 0, err
+		}
+		c.lock.Lock()
+		if cc := c.cluster(cluster); cc.generation == generation {
+			cc.position, cc.positioned = position, true
+		}
+		c.lock.Unlock()
+	}
+	if position == "" {
+		return ctx, generation, nil
+	}
+	return persistence.WithSession(ctx, persistence.NewSessionAt(position)), generation, nil
+}
+
+// replicaPositioner returns the persister of db if its reads may be served
+// by read replicas.
+func replicaPositioner(db Store) persistence.ReplicaPositioner {
+	s, ok := db.(*ObjectStore)
+	if !ok {
+		return nil
+	}
+	positioner, _ := s.store.(persistence.ReplicaPositioner)
+	return positioner
+}
+
+func (c *Cache) get(cluster, key string) (cacheEntry, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	cc := c.cluster(cluster)
+	entry, ok := cc.entries[key]
+	if !ok {
+		return cacheEntry{}, false
+	}
+	if time.Now().After(entry.expiresAt) {
+		delete(cc.entries, key)
+		return cacheEntry{}, false
+	}
+	return entry, true
+}
+
+// put caches entry unless the entries of cluster were invalidated since
+// generation.
+func (c *Cache) put(cluster, key string, generation uint64, entry cacheEntry) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	cc := c.cluster(cluster)
+	if cc.generation != generation {
+		return
+	}
+	entry.expiresAt = time.Now().Add(c.ttl)
+	cc.entries[key] = entry
+}
+
+// CachedStore is a Store reading objects through a Cache.
+//
+// Reads are served by the underlying store, instead of the cache, for the
+// clients of a persistence.Session that wrote objects, so that they keep
+// reading their writes, even if they were done by another instance of Koko,
+// and for reads from the primary database, which must observe every
+// committed write.
+type CachedStore struct {
+	Store
+	cache *Cache
+}
+
+// NewCachedStore returns a store reading the objects of store through cache.
+func NewCachedStore(store Store, cache *Cache) *CachedStore {
+	return &CachedStore{Store: store, cache: cache}
+}
+
+// Read implements the Store interface.
+func (s *CachedStore) Read(ctx context.Context, object model.Object,
+	opts ...ReadOptsFunc,
+) error {
+	opt := NewReadOpts(opts...)
+	if !s.cacheable(ctx, object.Type(), opt.primary) {
+		cacheRequests.Inc(cacheLabels("read", "bypass")...)
+		return s.Store.Read(ctx, object, opts...)
+	}
+	cluster := s.Cluster()
+	key := strings.Join([]string{
+		"read", string(object.Type()), opt.id, opt.name, opt.idxName, opt.idxValue,
+	}, "\x00")
+	if entry, ok := s.cache.get(cluster, key); ok {
+		cacheRequests.Inc(cacheLabels("read", "hit")...)
+		if err := object.SetResource(entry.resources[0]); err != nil {
+			return err
+		}
+		if opt.revision != nil {
+			*opt.revision = entry.revisions[0]
+		}
+		return nil
+	}
+
+	cacheRequests.Inc(cacheLabels("read", "miss")...)
+	readCtx, generation, err := s.cache.readAt(ctx, s.Store)
+	if err != nil {
+		return err
+	}
+	var revision uint64
+	readOpts := append(append([]ReadOptsFunc{}, opts...), ReadRevision(&revision))
+	if err := s.Store.Read(readCtx, object, readOpts...); err != nil {
+		return err
+	}
+	if opt.revision != nil {
+		*opt.revision = revision
+	}
+	s.cache.put(cluster, key, generation, cacheEntry{
+		resources: []model.Resource{proto.Clone(object.Resource())},
+		revisions: []uint64{revision},
+	})
+	return nil
+}
+
+// List implements the Store interface.
+func (s *CachedStore) List(ctx context.Context, list model.ObjectList,
+	opts ...ListOptsFunc,
+) error {
+	opt, err := NewListOpts(opts...)
+	if err != nil {
+		return err
+	}
+	if !s.cacheable(ctx, list.Type(), opt.primary) || len(list.GetAll()) > 0 {
+		cacheRequests.Inc(cacheLabels("list", "bypass")...)
+		return s.Store.List(ctx, list, opts...)
+	}
+	cluster := s.Cluster()
+	key, err := listCacheKey(list.Type(), opt)
+	if err != nil {
+		return err
+	}
+	if entry, ok := s.cache.get(cluster, key); ok {
+		cacheRequests.Inc(cacheLabels("list", "hit")...)
+		for i, resource := range entry.resources {
+			object, err := model.NewObject(list.Type())
+			if err != nil {
+				return err
+			}
+			if err := object.SetResource(resource); err != nil {
+				return err
+			}
+			list.Add(object)
+			if opt.revisions != nil {
+				opt.revisions[object.ID()] = entry.revisions[i]
+			}
+		}
+		list.SetTotalCount(entry.totalCount)
+		list.SetNextPage(entry.nextPage)
+		list.SetNextPageToken(entry.nextPageToken)
+		return nil
+	}
+
+	cacheRequests.Inc(cacheLabels("list", "miss")...)
+	readCtx, generation, err := s.cache.readAt(ctx, s.Store)
+	if err != nil {
+		return err
+	}
+	revisions := map[string]uint64{}
+	listOpts := append(append([]ListOptsFunc{}, opts...), ListRevisions(revisions))
+	if err := s.Store.List(readCtx, list, listOpts...); err != nil {
+		return err
+	}
+	entry := cacheEntry{
+		totalCount:    list.GetTotalCount(),
+		nextPage:      list.GetNextPage(),
+		nextPageToken: list.GetNextPageToken(),
+	}
+	for _, object := range list.GetAll() {
+		entry.resources = append(entry.resources, proto.Clone(object.Resource()))
+		entry.revisions = append(entry.revisions, revisions[object.ID()])
+		if opt.revisions != nil {
+			opt.revisions[object.ID()] = revisions[object.ID()]
+		}
+	}
+	s.cache.put(cluster, key, generation, entry)
+	return nil
+}
+
+// Create implements the Store interface.
+func (s *CachedStore) Create(ctx context.Context, object model.Object,
+	opts ...CreateOptsFunc,
+) error {
+	defer s.invalidate(object.Type())
+	return s.Store.Create(ctx, object, opts...)
+}
+
+// Upsert implements the Store interface.
+func (s *CachedStore) Upsert(ctx context.Context, object model.Object,
+	opts ...CreateOptsFunc,
+) error {
+	defer s.invalidate(object.Type())
+	return s.Store.Upsert(ctx, object, opts...)
+}
+
+// Delete implements the Store interface.
+func (s *CachedStore) Delete(ctx context.Context, opts ...DeleteOptsFunc) error {
+	defer s.invalidate(NewDeleteOpts(opts...).typ)
+	return s.Store.Delete(ctx, opts...)
+}
+
+// UpdateForeignKeys implements the Store interface.
+func (s *CachedStore) UpdateForeignKeys(ctx context.Context, object model.Object) error {
+	defer s.invalidate(object.Type())
+	return s.Store.UpdateForeignKeys(ctx, object)
+}
+
+// ApplyBatch implements the Store interface.
+func (s *CachedStore) ApplyBatch(ctx context.Context, ops []BatchOperation) error {
+	defer s.cache.Invalidate(s.Cluster())
+	return s.Store.ApplyBatch(ctx, ops)
+}
+
+// RestoreSnapshot implements the Store interface.
+func (s *CachedStore) RestoreSnapshot(ctx context.Context, name string,
+	types []model.Type,
+) error {
+	defer s.cache.Invalidate(s.Cluster())
+	return s.Store.RestoreSnapshot(ctx, name, types)
+}
+
+// DeleteCluster implements the Store interface.
+func (s *CachedStore) DeleteCluster(ctx context.Context, id string) error {
+	defer s.cache.Invalidate(id)
+	return s.Store.DeleteCluster(ctx, id)
+}
+
+// WatchEvents implements the EventWatcher interface, if the underlying store
+// does.
+func (s *CachedStore) WatchEvents(ctx context.Context) (<-chan struct{}, error) {
+	if watcher, ok := s.Store.(EventWatcher); ok {
+		return watcher.WatchEvents(ctx)
+	}
+	return nil, ErrEventsNotSupported
+}
+
+// WatchClusterEvents implements the ClusterEventWatcher interface, if the
+// underlying store does.
+func (s *CachedStore) WatchClusterEvents(ctx context.Context) (<-chan struct{}, error) {
+	if watcher, ok := s.Store.(ClusterEventWatcher); ok {
+		return watcher.WatchClusterEvents(ctx)
+	}
+	return nil, ErrEventsNotSupported
+}
+
+// cacheable returns true if objects of typ are read through the cache for
+// ctx, unless they are read from the primary database.
+func (s *CachedStore) cacheable(ctx context.Context, typ model.Type, primary bool) bool {
+	if uncachedTypes[typ] || primary || persistence.PrimaryReadsFromContext(ctx) {
+		return false
+	}
+	session := persistence.SessionFromContext(ctx)
+	return session == nil || session.Position() == ""
+}
+
+// invalidate drops the entries of the cluster of the store once an object
+// of typ is written.
+func (s *CachedStore) invalidate(typ model.Type) {
+	if !uncachedTypes[typ] {
+		s.cache.Invalidate(s.Cluster())
+	}
+}
+
+func listCacheKey(typ model.Type, opt *ListOpts) (string, error) {
+	var filter []byte
+	if opt.Filter != nil {
+		var err error
+		filter, err = proto.MarshalOptions{Deterministic: true}.Marshal(opt.Filter)
+		if err != nil {
+			return "", fmt.Errorf("proto marshal filter: %w", err)
+		}
+	}
+	return strings.Join([]string{
+		"list", string(typ),
+		string(opt.ReferenceType), opt.ReferenceID, fmt.Sprint(opt.ReferenceReverseLookup),
+		fmt.Sprint(opt.PageSize), fmt.Sprint(opt.Page), opt.PageToken,
+		fmt.Sprint(opt.Sort), string(filter),
+	}, "\x00"), nil
+}
+
+func cacheLabels(operation, result string) []metricsv2.Label {
+	return []metricsv2.Label{
+		{Key: "operation", Value: operation},
+		{Key: "result", Value: result},
+	}
+}
@@ -0,0 +1,187 @@
+package store
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	v1 "github.com/kong/koko/internal/gen/grpc/kong/admin/model/v1"
+	"github.com/kong/koko/internal/log"
+	"github.com/kong/koko/internal/persistence"
+	"github.com/kong/koko/internal/resource"
+	"github.com/kong/koko/internal/test/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCachedStore(t *testing.T) {
+	persister, err := util.GetPersister(t)
+	require.Nil(t, err)
+	objectStore := New(persister, log.Logger)
+	// Writes through s are not observed by the cache until its update event
+	// is, as for writes of another instance of Koko.
+	s := objectStore.ForCluster(DefaultCluster)
+	ctx := context.Background()
+
+	consumer := newTestConsumer()
+	require.Nil(t, s.Create(ctx, consumer))
+	username := consumer.Consumer.Username
+	rename := func(t *testing.T) string {
+		t.Helper()
+		consumer.Consumer.Username += "-renamed"
+		require.Nil(t, s.Upsert(ctx, consumer))
+		return consumer.Consumer.Username
+	}
+	readUsername := func(t *testing.T, cached Store, opts ...ReadOptsFunc) string {
+		t.Helper()
+		read := resource.NewConsumer()
+		require.Nil(t, cached.Read(ctx, read,
+			append([]ReadOptsFunc{GetByID(consumer.ID())}, opts...)...))
+		return read.Consumer.Username
+	}
+	listUsername := func(t *testing.T, cached Store) string {
+		t.Helper()
+		list := resource.NewList(resource.TypeConsumer)
+		require.Nil(t, cached.List(ctx, list))
+		require.Len(t, list.GetAll(), 1)
+		require.Equal(t, 1, list.GetTotalCount())
+		return list.GetAll()[0].Resource().(*v1.Consumer).Username
+	}
+
+	t.Run("reads are cached until invalidated", func(t *testing.T) {
+		cache := NewCache(0)
+		cached := NewCachedStore(s, cache)
+		var revision uint64
+		require.Equal(t, username, readUsername(t, cached, ReadRevision(&revision)))
+		require.NotZero(t, revision)
+		require.Equal(t, username, listUsername(t, cached))
+
+		username = rename(t)
+		var cachedRevision uint64
+		require.NotEqual(t, username, readUsername(t, cached, ReadRevision(&cachedRevision)))
+		require.Equal(t, revision, cachedRevision)
+		require.NotEqual(t, username, listUsername(t, cached))
+
+		cache.Invalidate(DefaultCluster)
+		require.Equal(t, username, readUsername(t, cached))
+		require.Equal(t, username, listUsername(t, cached))
+	})
+	t.Run("writes invalidate the cache", func(t *testing.T) {
+		cached := NewCachedStore(s, NewCache(0))
+		require.Equal(t, username, readUsername(t, cached))
+		consumer.Consumer.Username += "-renamed"
+		require.Nil(t, cached.Upsert(ctx, consumer))
+		username = consumer.Consumer.Username
+		require.Equal(t, username, readUsername(t, cached))
+	})
+	t.Run("update events invalidate the cache", func(t *testing.T) {
+		cache := NewCache(0)
+		cached := NewCachedStore(s, cache)
+		require.Nil(t, cache.poll(ctx, objectStore))
+		require.Equal(t, username, readUsername(t, cached))
+		require.Equal(t, username, listUsername(t, cached))
+
+		username = rename(t)
+		require.NotEqual(t, username, readUsername(t, cached))
+		require.Nil(t, cache.poll(ctx, objectStore))
+		require.Equal(t, username, readUsername(t, cached))
+		require.Equal(t, username, listUsername(t, cached))
+	})
+	t.Run("reads from the primary bypass the cache", func(t *testing.T) {
+		cached := NewCachedStore(s, NewCache(0))
+		require.Equal(t, username, readUsername(t, cached))
+		username = rename(t)
+		require.Equal(t, username, readUsername(t, cached, ReadFromPrimary()))
+		read := resource.NewConsumer()
+		require.Nil(t, cached.Read(persistence.WithPrimaryReads(ctx), read,
+			GetByID(consumer.ID())))
+		require.Equal(t, username, read.Consumer.Username)
+		require.NotEqual(t, username, readUsername(t, cached))
+	})
+	t.Run("entries are read at the write position of the primary", func(t *testing.T) {
+		positioned := &positionedPersister{Persister: persister, position: "1"}
+		s := New(positioned, log.Logger).ForCluster(DefaultCluster)
+		cached := NewCachedStore(s, NewCache(0))
+		require.Equal(t, username, readUsername(t, cached))
+		require.Equal(t, username, readUsername(t, cached))
+		require.Equal(t, []string{"1"}, positioned.reads)
+
+		positioned.position = "2"
+		consumer.Consumer.Username += "-renamed"
+		require.Nil(t, cached.Upsert(ctx, consumer))
+		username = consumer.Consumer.Username
+		require.Equal(t, username, listUsername(t, cached))
+		require.Equal(t, []string{"1", "2"}, positioned.reads)
+	})
+	t.Run("entries expire", func(t *testing.T) {
+		cached := NewCachedStore(s, NewCache(time.Millisecond))
+		require.Equal(t, username, readUsername(t, cached))
+		username = rename(t)
+		time.Sleep(5 * time.Millisecond)
+		require.Equal(t, username, readUsername(t, cached))
+	})
+	t.Run("sessions that wrote bypass the cache", func(t *testing.T) {
+		cached := NewCachedStore(s, NewCache(0))
+		require.Equal(t, username, readUsername(t, cached))
+		username = rename(t)
+		session, err := persistence.NewSession(base64.RawURLEncoding.EncodeToString([]byte("1")))
+		require.Nil(t, err)
+		read := resource.NewConsumer()
+		require.Nil(t, cached.Read(persistence.WithSession(ctx, session), read,
+			GetByID(consumer.ID())))
+		require.Equal(t, username, read.Consumer.Username)
+	})
+	t.Run("clusters are cached separately", func(t *testing.T) {
+		cache := NewCache(0)
+		require.Equal(t, username, readUsername(t, NewCachedStore(s, cache)))
+		require.Nil(t, objectStore.CreateCluster(ctx, &ClusterInfo{ID: "east"}))
+		east := NewCachedStore(objectStore.ForCluster("east"), cache)
+		require.Nil(t, east.Create(ctx, newTestConsumer()))
+		username = rename(t)
+		require.NotEqual(t, username, readUsername(t, NewCachedStore(s, cache)))
+	})
+	t.Run("runs until the context is done", func(t *testing.T) {
+		cache := NewCache(0)
+		cached := NewCachedStore(s, cache)
+		require.Equal(t, username, readUsername(t, cached))
+		ctx, cancel := context.WithCancel(ctx)
+		done := make(chan error)
+		go func() { done <- cache.Run(ctx, objectStore, 10*time.Millisecond) }()
+		username = rename(t)
+		require.Eventually(t, func() bool {
+			return readUsername(t, cached) == username
+		}, 5*time.Second, 10*time.Millisecond)
+		cancel()
+		require.Nil(t, <-done)
+	})
+}
+
+// positionedPersister is a persister with read replicas at position, which
+// records the positions its reads observe.
+type positionedPersister struct {
+	persistence.Persister
+	position string
+	reads    []string
+}
+
+func (p *positionedPersister) WritePosition(context.Context) (string, error) {
+	return p.position, nil
+}
+
+func (p *positionedPersister) Get(ctx context.Context, key string) ([]byte, error) {
+	p.read(ctx)
+	return p.Persister.Get(ctx, key)
+}
+
+func (p *positionedPersister) List(ctx context.Context, prefix string,
+	opts *persistence.ListOpts,
+) (persistence.ListResult, error) {
+	p.read(ctx)
+	return p.Persister.List(ctx, prefix, opts)
+}
+
+func (p *positionedPersister) read(ctx context.Context) {
+	if session := persistence.SessionFromContext(ctx); session != nil {
+		p.reads = append(p.reads, session.Position())
+	}
+}
@@ -0,0 +1,185 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kong/koko/internal/json"
+	"github.com/kong/koko/internal/model"
+	"github.com/kong/koko/internal/persistence"
+)
+
+// ClusterInfo describes a cluster, which holds its own objects and serves
+// its own data planes.
+type ClusterInfo struct {
+	ID          string
+	Description string
+	// CreatedAt is the time the cluster was created as a Unix timestamp.
+	CreatedAt int32
+}
+
+// ClusterList is a page of clusters.
+type ClusterList struct {
+	Clusters   []ClusterInfo
+	TotalCount int
+	NextPage   int
+}
+
+// clusterMeta is the persisted form of a ClusterInfo.
+type clusterMeta struct {
+	Description string `json:"description,omitempty"`
+	CreatedAt   int32  `json:"created_at"`
+}
+
+// ValidateCluster returns an error if id is not a valid cluster ID.
+func ValidateCluster(id string) error {
+	if !clusterRegex.MatchString(id) {
+		return fmt.Errorf("invalid cluster ID: '%s'", id)
+	}
+	return nil
+}
+
+// CreateCluster records a cluster. ErrConstraint is returned if a cluster
+// with the same ID exists.
+func (s *ObjectStore) CreateCluster(ctx context.Context, cluster *ClusterInfo) error {
+	ctx, cancel := context.WithTimeout(ctx, DefaultOperationTimeout)
+	defer cancel()
+	if err := ValidateCluster(cluster.ID); err != nil {
+		return err
+	}
+	meta := clusterMeta{
+		Description: cluster.Description,
+		CreatedAt:   int32(time.Now().Unix()),
+	}
+	value, err := json.Marshal(meta)
+	if err != nil {
+		return fmt.Errorf("json marshal cluster: %w", err)
+	}
+	err = s.store.Insert(ctx, clusterMetaKey(cluster.ID), value)
+	if err == persistence.ErrUniqueViolation {
+		return ErrConstraint{
+			Index: model.Index{
+				Name:      "id",
+				FieldName: "id",
+				Type:      model.IndexUnique,
+				Value:     cluster.ID,
+			},
+			Message: "cluster already exists",
+		}
+	}
+	if err != nil {
+		return err
+	}
+	cluster.CreatedAt = meta.CreatedAt
+	return nil
+}
+
+// ReadCluster reads the cluster with id.
+func (s *ObjectStore) ReadCluster(ctx context.Context, id string) (ClusterInfo, error) {
+	ctx, cancel := context.WithTimeout(ctx, DefaultOperationTimeout)
+	defer cancel()
+	value, err := s.store.Get(ctx, clusterMetaKey(id))
+	if err != nil {
+		if errors.As(err, &persistence.ErrNotFound{}) {
+			return ClusterInfo{}, ErrNotFound
+		}
+		return ClusterInfo{}, err
+	}
+	return unwrapClusterMeta(id, value)
+}
+
+// ListClusters lists the clusters ordered by ID.
+func (s *ObjectStore) ListClusters(ctx context.Context,
+	opts ...ListOptsFunc,
+) (ClusterList, error) {
+	ctx, cancel := context.WithTimeout(ctx, DefaultOperationTimeout)
+	defer cancel()
+	opt, err := NewListOpts(opts...)
+	if err != nil {
+		return ClusterList{}, err
+	}
+	prefix := clusterMetaKey("")
+	listResult, err := s.store.List(ctx, prefix, getPersistenceListOptions(opt))
+	if err != nil {
+		return ClusterList{}, err
+	}
+	res := ClusterList{TotalCount: listResult.TotalCount}
+	if toLastPage(opt.PageSize, listResult.TotalCount) > opt.Page {
+		res.NextPage = opt.Page + 1
+	}
+	for _, kv := range listResult.KVList {
+		cluster, err := unwrapClusterMeta(strings.TrimPrefix(string(kv.Key), prefix), kv.Value)
+		if err != nil {
+			return ClusterList{}, err
+		}
+		res.Clusters = append(res.Clusters, cluster)
+	}
+	return res, nil
+}
+
+// clusters returns the IDs of every cluster, the default one included.
+func (s *ObjectStore) clusters(ctx context.Context) (map[string]bool, error) {
+	clusters := map[string]bool{DefaultCluster: true}
+	for page := DefaultPage; page != 0; {
+		list, err := s.ListClusters(ctx, ListWithPageNum(page), ListWithPageSize(MaxPageSize))
+		if err != nil {
+			return nil, err
+		}
+		for _, cluster := range list.Clusters {
+			clusters[cluster.ID] = true
+		}
+		page = list.NextPage
+	}
+	return clusters, nil
+}
+
+// DeleteCluster deletes the cluster with id along with all of its objects,
+// indexes, history and snapshots, within a single transaction.
+func (s *ObjectStore) DeleteCluster(ctx context.Context, id string) error {
+	ctx, cancel := context.WithTimeout(ctx, DefaultOperationTimeout)
+	defer cancel()
+	if err := ValidateCluster(id); err != nil {
+		return err
+	}
+	return s.withTx(ctx, func(tx persistence.Tx) error {
+		err := tx.Delete(ctx, clusterMetaKey(id))
+		if err != nil {
+			if errors.As(err, &persistence.ErrNotFound{}) {
+				return ErrNotFound
+			}
+			return err
+		}
+		listResult, err := getFullList(ctx, tx, s.ForCluster(id).clusterKey(""))
+		if err != nil {
+			return err
+		}
+		for _, kv := range listResult.KVList {
+			if err := tx.Delete(ctx, string(kv.Key)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// clusterMetaKey is the key of a cluster. Clusters are kept apart from the
+// keys of their objects, so that they can be listed on their own. The archive
+// package relies on this key to export a cluster along with its record.
+func clusterMetaKey(id string) string {
+	return "clusters/" + id
+}
+
+func unwrapClusterMeta(id string, value []byte) (ClusterInfo, error) {
+	var meta clusterMeta
+	if err := json.Unmarshal(value, &meta); err != nil {
+		return ClusterInfo{}, fmt.Errorf("json unmarshal cluster: %w", err)
+	}
+	return ClusterInfo{
+		ID:          id,
+		Description: meta.Description,
+		CreatedAt:   meta.CreatedAt,
+	}, nil
+}
@@ -0,0 +1,74 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	v1 "github.com/kong/koko/internal/gen/grpc/kong/admin/model/v1"
+	"github.com/kong/koko/internal/log"
+	"github.com/kong/koko/internal/resource"
+	"github.com/kong/koko/internal/test/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCluster(t *testing.T) {
+	persister, err := util.GetPersister(t)
+	require.Nil(t, err)
+	s := New(persister, log.Logger).ForCluster(DefaultCluster)
+	ctx := context.Background()
+
+	t.Run("creates a cluster", func(t *testing.T) {
+		cluster := ClusterInfo{ID: "east", Description: "east coast"}
+		require.Nil(t, s.CreateCluster(ctx, &cluster))
+		require.NotZero(t, cluster.CreatedAt)
+		require.Nil(t, s.CreateCluster(ctx, &ClusterInfo{ID: "west"}))
+	})
+	t.Run("cluster IDs are unique", func(t *testing.T) {
+		err := s.CreateCluster(ctx, &ClusterInfo{ID: "east"})
+		require.IsType(t, ErrConstraint{}, err)
+	})
+	t.Run("cluster IDs are validated", func(t *testing.T) {
+		err := s.CreateCluster(ctx, &ClusterInfo{ID: "East Coast"})
+		require.EqualError(t, err, "invalid cluster ID: 'East Coast'")
+	})
+	t.Run("reads a cluster", func(t *testing.T) {
+		cluster, err := s.ReadCluster(ctx, "east")
+		require.Nil(t, err)
+		require.Equal(t, "east", cluster.ID)
+		require.Equal(t, "east coast", cluster.Description)
+
+		_, err = s.ReadCluster(ctx, "north")
+		require.Equal(t, ErrNotFound, err)
+	})
+	t.Run("lists clusters", func(t *testing.T) {
+		list, err := s.ListClusters(ctx, ListWithPageSize(1))
+		require.Nil(t, err)
+		require.Equal(t, 2, list.TotalCount)
+		require.Equal(t, 2, list.NextPage)
+		require.Len(t, list.Clusters, 1)
+		require.Equal(t, "east", list.Clusters[0].ID)
+	})
+	t.Run("deletes a cluster along with its objects", func(t *testing.T) {
+		east, west := s.ForCluster("east"), s.ForCluster("west")
+		newService := func() resource.Service {
+			svc := resource.NewService()
+			svc.Service = &v1.Service{Id: uuid.NewString(), Name: "foo", Host: "example.com"}
+			return svc
+		}
+		eastService, westService := newService(), newService()
+		require.Nil(t, east.Create(ctx, eastService))
+		require.Nil(t, west.Create(ctx, westService))
+
+		require.Nil(t, s.DeleteCluster(ctx, "east"))
+		_, err := s.ReadCluster(ctx, "east")
+		require.Equal(t, ErrNotFound, err)
+		err = east.Read(ctx, resource.NewService(), GetByID(eastService.ID()))
+		require.Equal(t, ErrNotFound, err)
+		require.Nil(t, west.Read(ctx, resource.NewService(), GetByID(westService.ID())))
+
+		// The name of the deleted service is available again.
+		require.Nil(t, east.Create(ctx, newService()))
+		require.Equal(t, ErrNotFound, s.DeleteCluster(ctx, "east"))
+	})
+}
@@ -0,0 +1,213 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/kong/koko/internal/model"
+	"github.com/kong/koko/internal/persistence"
+)
+
+// DeleteMode dictates how Delete handles the dependents of the deleted object.
+type DeleteMode int
+
+const (
+	// DeleteModeCascade deletes the dependents of the object recursively,
+	// within the same transaction. This is the default.
+	DeleteModeCascade DeleteMode = iota
+	// DeleteModeRestrict makes Delete fail with ErrHasDependents while the
+	// object has dependents.
+	DeleteModeRestrict
+)
+
+// Dependent references an object depending on another one, as it references
+// it through a foreign index and its type cascades on delete.
+//
+// Objects whose type does not cascade on delete, e.g.: consumer groups, do not
+// depend on the objects they reference: only the references are removed when
+// the referenced object is deleted.
+type Dependent struct {
+	Type model.Type
+	ID   string
+}
+
+func (d Dependent) String() string {
+	return fmt.Sprintf("%s '%s'", d.Type, d.ID)
+}
+
+// ErrHasDependents is returned when restricting the deletion of an object
+// that has dependents.
+type ErrHasDependents struct {
+	Type       model.Type
+	ID         string
+	Dependents []Dependent
+}
+
+func (e ErrHasDependents) Error() string {
+	dependents := make([]string, 0, len(e.Dependents))
+	for _, dependent := range e.Dependents {
+		dependents = append(dependents, dependent.String())
+	}
+	return fmt.Sprintf("%s '%s' is referenced by %s", e.Type, e.ID,
+		strings.Join(dependents, ", "))
+}
+
+// reference is an object referencing another one through a foreign index.
+type reference struct {
+	Dependent
+	// key is the key of the foreign index.
+	key string
+}
+
+// references returns the objects referencing the given object through a
+// foreign index, e.g.: the routes of a service.
+func (s *ObjectStore) references(ctx context.Context, tx persistence.Tx,
+	typ model.Type, id string,
+) ([]reference, error) {
+	prefix := s.clusterKey(fmt.Sprintf("ix/f/%s/%s/", typ, id))
+	listResult, err := getFullList(ctx, tx, prefix)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]reference, 0, len(listResult.KVList))
+	for _, kv := range listResult.KVList {
+		typeAndID := strings.Split(strings.TrimPrefix(string(kv.Key), prefix), "/")
+		res = append(res, reference{
+			Dependent: Dependent{Type: model.Type(typeAndID[0]), ID: typeAndID[1]},
+			key:       string(kv.Key),
+		})
+	}
+	return res, nil
+}
+
+// Referrer is an object referencing another one through a foreign index,
+// regardless of whether it depends on it.
+type Referrer struct {
+	Dependent
+	// Field of the object holding the reference, e.g.: "service.id". It is
+	// empty for references held by the referenced object, e.g.: the consumer
+	// groups a consumer is a member of.
+	Field string
+	// Referrers of the object, when listing referrers recursively.
+	Referrers []Referrer
+}
+
+// ListReferrers lists the objects referencing the given object, and the
+// objects referencing them recursively, up to depth levels. A depth of 1 only
+// lists the objects referencing the given object directly.
+// It returns ErrNotFound if the object does not exist.
+func (s *ObjectStore) ListReferrers(ctx context.Context, typ model.Type, id string,
+	depth int,
+) ([]Referrer, error) {
+	ctx, cancel := context.WithTimeout(ctx, DefaultOperationTimeout)
+	defer cancel()
+	object, err := model.NewObject(typ)
+	if err != nil {
+		return nil, err
+	}
+	var res []Referrer
+	err = s.withTx(ctx, func(tx persistence.Tx) error {
+		if err := s.readByTypeID(ctx, tx, typ, id, object); err != nil {
+			return err
+		}
+		res, err = s.referrers(ctx, tx, typ, id, depth)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (s *ObjectStore) referrers(ctx context.Context, tx persistence.Tx,
+	typ model.Type, id string, depth int,
+) ([]Referrer, error) {
+	if depth <= 0 {
+		return nil, nil
+	}
+	refs, err := s.references(ctx, tx, typ, id)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]Referrer, 0, len(refs))
+	for _, ref := range refs {
+		object, err := model.NewObject(ref.Type)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.readByTypeID(ctx, tx, ref.Type, ref.ID, object); err != nil {
+			return nil, err
+		}
+		referrer := Referrer{Dependent: ref.Dependent}
+		for _, index := range object.Indexes() {
+			if index.Type == model.IndexForeign && index.ForeignType == typ &&
+				index.Value == id {
+				referrer.Field = index.FieldName
+				break
+			}
+		}
+		referrer.Referrers, err = s.referrers(ctx, tx, ref.Type, ref.ID, depth-1)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, referrer)
+	}
+	return res, nil
+}
+
+// ForeignKey is a reference of an object to another object through a foreign
+// index, e.g.: a consumer group referencing one of its members.
+type ForeignKey struct {
+	Type        model.Type
+	ID          string
+	ForeignType model.Type
+	ForeignID   string
+}
+
+// ForeignKeyList is a page of foreign keys.
+type ForeignKeyList struct {
+	ForeignKeys []ForeignKey
+	TotalCount  int
+	NextPage    int
+}
+
+// ListForeignKeys lists the foreign keys of the objects of type typ that
+// reference objects of type foreignType, ordered by the ID of the referenced
+// objects. Only the index rows are listed, so that the references of every
+// object are listed at once, e.g.: the members of every consumer group.
+func (s *ObjectStore) ListForeignKeys(ctx context.Context, typ, foreignType model.Type,
+	opts ...ListOptsFunc,
+) (ForeignKeyList, error) {
+	ctx, cancel := context.WithTimeout(ctx, DefaultOperationTimeout)
+	defer cancel()
+	opt, err := NewListOpts(opts...)
+	if err != nil {
+		return ForeignKeyList{}, err
+	}
+	prefix := s.clusterKey(fmt.Sprintf("ix/f/%s/", foreignType))
+	listResult, err := s.store.List(ctx,
+		prefix+persistence.WildcardOperator+fmt.Sprintf("/%s/", typ),
+		&persistence.ListOpts{Limit: opt.PageSize, Offset: toOffset(opt)})
+	if err != nil {
+		return ForeignKeyList{}, err
+	}
+	res := ForeignKeyList{TotalCount: listResult.TotalCount}
+	if toLastPage(opt.PageSize, listResult.TotalCount) > opt.Page {
+		res.NextPage = opt.Page + 1
+	}
+	for _, kv := range listResult.KVList {
+		// The keys are `ix/f/<foreignType>/<foreignID>/<typ>/<id>`.
+		parts := strings.Split(strings.TrimPrefix(string(kv.Key), prefix), "/")
+		if len(parts) != 3 || parts[1] != string(typ) {
+			return ForeignKeyList{}, fmt.Errorf("invalid foreign index key: '%s'", kv.Key)
+		}
+		res.ForeignKeys = append(res.ForeignKeys, ForeignKey{
+			Type:        typ,
+			ID:          parts[2],
+			ForeignType: foreignType,
+			ForeignID:   parts[0],
+		})
+	}
+	return res, nil
+}
@@ -0,0 +1,275 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/kong/koko/internal/crypto"
+	"github.com/kong/koko/internal/json"
+	"github.com/kong/koko/internal/model"
+	"github.com/kong/koko/internal/persistence"
+)
+
+// encryptedMarker is found in every stored value holding encrypted fields.
+var encryptedMarker = []byte("$koko-enc$")
+
+// WithKeyring returns a copy of the store that encrypts the sensitive fields
+// of objects with keyring, see model.ObjectOptions. Values are stored in
+// plain text when keyring is nil.
+//
+// Encrypted values are decrypted with any master key of keyring, so that
+// values encrypted with a previous master key are read until RotateKeys is
+// done.
+func (s *ObjectStore) WithKeyring(keyring *crypto.Keyring) *ObjectStore {
+	opts := s.objectStoreOpts
+	opts.keyring = keyring
+	return &ObjectStore{
+		objectStoreOpts: opts,
+		cluster:         s.cluster,
+	}
+}
+
+// RotateKeys encrypts every sensitive field of every cluster with the
+// primary master key of the keyring of the store, within a single
+// transaction. Objects stored before encryption was enabled are encrypted,
+// including the objects held by history entries and snapshots, and the data
+// keys of values encrypted with a previous master key are rewrapped.
+// It returns the number of values that were rewritten.
+func (s *ObjectStore) RotateKeys(ctx context.Context) (int, error) {
+	if s.keyring == nil {
+		return 0, errors.New("no encryption key configured")
+	}
+	count := 0
+	err := s.withTx(ctx, func(tx persistence.Tx) error {
+		listResult, err := getFullList(ctx, tx, "c/")
+		if err != nil {
+			return err
+		}
+		for _, kv := range listResult.KVList {
+			key := string(kv.Key)
+			value, err := s.rotateValue(key, kv.Value)
+			if err != nil {
+				return fmt.Errorf("rotate key '%s': %w", key, err)
+			}
+			if value == nil {
+				continue
+			}
+			if err := tx.Put(ctx, key, value); err != nil {
+				return err
+			}
+			count++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// rotateValue returns the value stored under key once rotated, or nil if the
+// value is left unchanged.
+func (s *ObjectStore) rotateValue(key string, value []byte) ([]byte, error) {
+	// Objects are rewritten as a whole, so that fields that are not yet
+	// encrypted get encrypted.
+	if typ, ok := objectKeyType(key); ok && len(model.OptionsForType(typ).SensitiveFields) > 0 {
+		object, err := model.NewObject(typ)
+		if err != nil {
+			return nil, err
+		}
+		revision, err := unwrapObject(value, object, s.keyring)
+		if err != nil {
+			return nil, err
+		}
+		return wrapObject(object, revision, s.keyring)
+	}
+	if typ, ok := historyKeyType(key); ok && len(model.OptionsForType(typ).SensitiveFields) > 0 {
+		return s.rotateHistoryValue(typ, value)
+	}
+	if isSnapshotDataKey(key) {
+		return s.rotateSnapshotData(value)
+	}
+	if !bytes.Contains(value, encryptedMarker) {
+		return nil, nil
+	}
+	value, count, err := s.keyring.Rewrap(value)
+	if err != nil || count == 0 {
+		return nil, err
+	}
+	return value, nil
+}
+
+// rotateHistoryValue rewrites the objects held by a history entry of an
+// object of type typ.
+func (s *ObjectStore) rotateHistoryValue(typ model.Type, value []byte) ([]byte, error) {
+	var entry historyValue
+	if err := json.Unmarshal(value, &entry); err != nil {
+		return nil, fmt.Errorf("json unmarshal history entry: %w", err)
+	}
+	var err error
+	if entry.Old, err = s.rotateObject(typ, entry.Old); err != nil {
+		return nil, err
+	}
+	if entry.New, err = s.rotateObject(typ, entry.New); err != nil {
+		return nil, err
+	}
+	return json.Marshal(entry)
+}
+
+// rotateSnapshotData rewrites the objects of a snapshot holding sensitive
+// fields.
+func (s *ObjectStore) rotateSnapshotData(value []byte) ([]byte, error) {
+	data, err := unwrapSnapshotData(value)
+	if err != nil {
+		return nil, err
+	}
+	for i, object := range data.Objects {
+		if len(model.OptionsForType(object.Type).SensitiveFields) == 0 {
+			continue
+		}
+		if data.Objects[i].Object, err = s.rotateObject(object.Type, object.Object); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(data)
+}
+
+// rotateObject rewrites an object of type typ as wrapped by wrapObject.
+func (s *ObjectStore) rotateObject(typ model.Type, value json.RawMessage) (json.RawMessage, error) {
+	if len(value) == 0 {
+		return value, nil
+	}
+	object, err := model.NewObject(typ)
+	if err != nil {
+		return nil, err
+	}
+	revision, err := unwrapObject(value, object, s.keyring)
+	if err != nil {
+		return nil, err
+	}
+	return wrapObject(object, revision, s.keyring)
+}
+
+// historyKeyType returns the type of the object of the history entry stored
+// under key, a key of the form 'c/<cluster>/h/<type>/<id>/<revision>'.
+func historyKeyType(key string) (model.Type, bool) {
+	parts := strings.Split(key, "/")
+	const historyKeyParts = 6
+	if len(parts) != historyKeyParts || parts[0] != "c" || parts[2] != "h" {
+		return "", false
+	}
+	return model.Type(parts[3]), true
+}
+
+// isSnapshotDataKey returns true if key is of the form
+// 'c/<cluster>/s/data/<name>'.
+func isSnapshotDataKey(key string) bool {
+	parts := strings.Split(key, "/")
+	const snapshotDataKeyParts = 5
+	return len(parts) == snapshotDataKeyParts && parts[0] == "c" &&
+		parts[2] == "s" && parts[3] == "data"
+}
+
+// objectKeyType returns the type of the object stored under key, a key of
+// the form 'c/<cluster>/o/<type>/<id>'.
+func objectKeyType(key string) (model.Type, bool) {
+	parts := strings.Split(key, "/")
+	const objectKeyParts = 5
+	if len(parts) != objectKeyParts || parts[0] != "c" || parts[2] != "o" {
+		return "", false
+	}
+	return model.Type(parts[3]), true
+}
+
+// encryptFields encrypts the sensitive fields of the JSON representation of
+// an object of type typ.
+func encryptFields(keyring *crypto.Keyring, typ model.Type, object []byte) ([]byte, error) {
+	fields := model.OptionsForType(typ).SensitiveFields
+	if len(fields) == 0 {
+		return object, nil
+	}
+	return transformFields(object, fields, func(value json.RawMessage) (json.RawMessage, error) {
+		if isEncryptedValue(value) {
+			return value, nil
+		}
+		envelope, err := keyring.Encrypt(value)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(envelope)
+	})
+}
+
+// decryptFields decrypts the sensitive fields of the JSON representation of
+// an object of type typ. Fields stored in plain text are left as is, as are
+// other fields, whatever they hold.
+func decryptFields(keyring *crypto.Keyring, typ model.Type, object []byte) ([]byte, error) {
+	fields := model.OptionsForType(typ).SensitiveFields
+	if len(fields) == 0 {
+		return object, nil
+	}
+	return transformFields(object, fields, func(value json.RawMessage) (json.RawMessage, error) {
+		if !isEncryptedValue(value) {
+			return value, nil
+		}
+		if keyring == nil {
+			return nil, errors.New("object holds encrypted fields but no encryption key is configured")
+		}
+		var envelope string
+		if err := json.Unmarshal(value, &envelope); err != nil {
+			return nil, err
+		}
+		return keyring.Decrypt(envelope)
+	})
+}
+
+func isEncryptedValue(value json.RawMessage) bool {
+	var s string
+	if len(value) == 0 || value[0] != '"' || json.Unmarshal(value, &s) != nil {
+		return false
+	}
+	return crypto.IsEncrypted(s)
+}
+
+// transformFields replaces the value of each field found at the provided
+// dot-separated paths of a JSON object with the result of fn.
+func transformFields(object []byte, paths []string,
+	fn func(json.RawMessage) (json.RawMessage, error),
+) ([]byte, error) {
+	for _, path := range paths {
+		var err error
+		object, err = transformField(object, strings.Split(path, "."), fn)
+		if err != nil {
+			return nil, fmt.Errorf("field '%s': %w", path, err)
+		}
+	}
+	return object, nil
+}
+
+func transformField(object []byte, path []string,
+	fn func(json.RawMessage) (json.RawMessage, error),
+) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(object, &fields); err != nil || fields == nil {
+		// Not a JSON object, there is no such field.
+		return object, nil //nolint:nilerr
+	}
+	value, ok := fields[path[0]]
+	if !ok || bytes.Equal(value, []byte("null")) {
+		return object, nil
+	}
+	var err error
+	if len(path) > 1 {
+		value, err = transformField(value, path[1:], fn)
+	} else {
+		value, err = fn(value)
+	}
+	if err != nil {
+		return nil, err
+	}
+	fields[path[0]] = value
+	return json.Marshal(fields)
+}
@@ -0,0 +1,186 @@
+package store
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/kong/koko/internal/crypto"
+	v1 "github.com/kong/koko/internal/gen/grpc/kong/admin/model/v1"
+	"github.com/kong/koko/internal/log"
+	"github.com/kong/koko/internal/persistence"
+	"github.com/kong/koko/internal/resource"
+	"github.com/kong/koko/internal/test/certs"
+	"github.com/kong/koko/internal/test/util"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestKeyring(t *testing.T, previous ...crypto.MasterKey) (*crypto.Keyring, crypto.MasterKey) {
+	b := make([]byte, crypto.MasterKeySize)
+	_, err := rand.Read(b)
+	require.Nil(t, err)
+	key, err := crypto.NewMasterKey(base64.StdEncoding.EncodeToString(b))
+	require.Nil(t, err)
+	return crypto.NewKeyring(key, previous...), key
+}
+
+func newTestCertificate() resource.Certificate {
+	cert := resource.NewCertificate()
+	cert.Certificate = &v1.Certificate{
+		Id:   uuid.NewString(),
+		Cert: string(certs.CPCert),
+		Key:  string(certs.CPKey),
+	}
+	return cert
+}
+
+func TestEncryption(t *testing.T) {
+	persister, err := util.GetPersister(t)
+	require.Nil(t, err)
+	plain := New(persister, log.Logger).ForCluster(DefaultCluster)
+	keyring1, key1 := newTestKeyring(t)
+	s := plain.WithKeyring(keyring1)
+	ctx := context.Background()
+
+	rawValue := func(t *testing.T, id string) string {
+		key, err := s.genID(resource.TypeCertificate, id)
+		require.Nil(t, err)
+		value, err := persister.Get(ctx, key)
+		require.Nil(t, err)
+		return string(value)
+	}
+
+	cert := newTestCertificate()
+	require.Nil(t, s.Create(ctx, cert))
+
+	t.Run("stores sensitive fields encrypted", func(t *testing.T) {
+		value := rawValue(t, cert.ID())
+		require.NotContains(t, value, "PRIVATE KEY")
+		require.Contains(t, value, "BEGIN CERTIFICATE")
+		require.Contains(t, value, key1.ID)
+	})
+	t.Run("decrypts sensitive fields on read", func(t *testing.T) {
+		read := resource.NewCertificate()
+		require.Nil(t, s.Read(ctx, read, GetByID(cert.ID())))
+		require.Equal(t, string(certs.CPKey), read.Certificate.Key)
+
+		list := resource.NewList(resource.TypeCertificate)
+		require.Nil(t, s.List(ctx, list))
+		require.Len(t, list.GetAll(), 1)
+		require.Equal(t, string(certs.CPKey),
+			list.GetAll()[0].Resource().(*v1.Certificate).Key)
+	})
+	t.Run("decrypts sensitive fields of history entries", func(t *testing.T) {
+		entries, err := s.ListHistory(ctx, resource.TypeCertificate, cert.ID())
+		require.Nil(t, err)
+		require.Len(t, entries.Entries, 1)
+		require.Equal(t, string(certs.CPKey),
+			entries.Entries[0].New.Resource().(*v1.Certificate).Key)
+	})
+	t.Run("fails to read encrypted fields without a key", func(t *testing.T) {
+		read := resource.NewCertificate()
+		err := plain.Read(ctx, read, GetByID(cert.ID()))
+		require.ErrorContains(t, err, "no encryption key is configured")
+	})
+	t.Run("reads objects stored in plain text", func(t *testing.T) {
+		plainCert := newTestCertificate()
+		require.Nil(t, plain.Create(ctx, plainCert))
+		require.Contains(t, rawValue(t, plainCert.ID()), "PRIVATE KEY")
+		read := resource.NewCertificate()
+		require.Nil(t, s.Read(ctx, read, GetByID(plainCert.ID())))
+		require.Equal(t, string(certs.CPKey), read.Certificate.Key)
+		require.Nil(t, s.Delete(ctx, DeleteByID(plainCert.ID()),
+			DeleteByType(resource.TypeCertificate)))
+	})
+	t.Run("reads objects holding the marker in plain text", func(t *testing.T) {
+		// A plugin, of a type without sensitive fields, and a key whose
+		// sensitive field is not an envelope as a whole.
+		pluginID, keyID := uuid.NewString(), uuid.NewString()
+		const notAnEnvelope = "$koko-enc$v1$0123456789abcdef$not-an-envelope"
+		values := map[string]string{
+			"c/default/o/plugin/" + pluginID: `{"type":3,"revision":1,"object":{"id":"` + pluginID +
+				`","name":"request-transformer","config":{"add":{"headers":["x:$koko-enc$v1$"]}}}}`,
+			"c/default/o/key/" + keyID: `{"type":3,"revision":1,"object":{"id":"` + keyID +
+				`","kid":"kid","jwk":"` + notAnEnvelope + `"}}`,
+		}
+		for key, value := range values {
+			require.Nil(t, persister.Put(ctx, key, []byte(value)))
+		}
+
+		plugin := resource.NewPlugin()
+		require.Nil(t, plain.Read(ctx, plugin, GetByID(pluginID)))
+		require.Equal(t, "x:$koko-enc$v1$", plugin.Plugin.Config.Fields["add"].
+			GetStructValue().Fields["headers"].GetListValue().Values[0].GetStringValue())
+		list := resource.NewList(resource.TypePlugin)
+		require.Nil(t, plain.List(ctx, list))
+		require.Len(t, list.GetAll(), 1)
+
+		for _, s := range []Store{plain, s} {
+			key := resource.NewKey()
+			require.Nil(t, s.Read(ctx, key, GetByID(keyID)))
+			require.Equal(t, notAnEnvelope, key.Key.Jwk)
+		}
+
+		for key := range values {
+			require.Nil(t, persister.Delete(ctx, key))
+		}
+	})
+	t.Run("rotates keys", func(t *testing.T) {
+		// Objects, history entries and snapshots written in plain text.
+		plainCert := newTestCertificate()
+		require.Nil(t, plain.Create(ctx, plainCert))
+		require.Nil(t, persister.Put(ctx, s.snapshotMetaKey("plain"),
+			[]byte(`{"created_at":1,"object_count":1}`)))
+		require.Nil(t, persister.Put(ctx, s.snapshotDataKey("plain"), []byte(
+			`[{"type":"certificate","object":`+rawValue(t, plainCert.ID())+`}]`)))
+
+		keyring2, key2 := newTestKeyring(t, key1)
+		rotated := plain.WithKeyring(keyring2)
+		count, err := rotated.RotateKeys(ctx)
+		require.Nil(t, err)
+		// Both objects, the history entries of those and of the certificate
+		// deleted above, and the snapshot.
+		require.Equal(t, 7, count)
+		for _, prefix := range []string{"c/default/h/", "c/default/s/"} {
+			list, err := persister.List(ctx, prefix, persistence.NewDefaultListOpts())
+			require.Nil(t, err)
+			require.NotEmpty(t, list.KVList)
+			for _, kv := range list.KVList {
+				require.NotContains(t, string(kv.Value), "PRIVATE KEY", string(kv.Key))
+			}
+		}
+
+		for _, id := range []string{cert.ID(), plainCert.ID()} {
+			value := rawValue(t, id)
+			require.NotContains(t, value, "PRIVATE KEY")
+			require.NotContains(t, value, key1.ID)
+			require.Contains(t, value, key2.ID)
+		}
+
+		// The previous key is no longer needed.
+		s := plain.WithKeyring(crypto.NewKeyring(key2))
+		for _, id := range []string{cert.ID(), plainCert.ID()} {
+			read := resource.NewCertificate()
+			require.Nil(t, s.Read(ctx, read, GetByID(id)))
+			require.Equal(t, string(certs.CPKey), read.Certificate.Key)
+		}
+		entries, err := s.ListHistory(ctx, resource.TypeCertificate, cert.ID())
+		require.Nil(t, err)
+		require.Len(t, entries.Entries, 1)
+
+		snapshot, err := s.ReadSnapshot(ctx, "plain")
+		require.Nil(t, err)
+		require.Len(t, snapshot.Objects, 1)
+
+		count, err = s.RotateKeys(ctx)
+		require.Nil(t, err)
+		// Values holding sensitive fields are re-encrypted regardless.
+		require.Equal(t, 7, count)
+	})
+	t.Run("fails to rotate keys without a key", func(t *testing.T) {
+		_, err := plain.RotateKeys(ctx)
+		require.EqualError(t, err, "no encryption key configured")
+	})
+}
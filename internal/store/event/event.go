@@ -9,6 +9,9 @@ import (
 
 const (
 	ID = "last_update"
+	// Channel is the channel on which the cluster of an updated store is
+	// notified, for persisters that support notifications.
+	Channel = "koko_store_event"
 )
 
 var Type = model.Type("store_event")
@@ -2,8 +2,15 @@ package store
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
 
+	"github.com/kong/koko/internal/model"
 	"github.com/kong/koko/internal/persistence"
+	"google.golang.org/protobuf/proto"
 )
 
 // getFullList returns the full list despite pagination.
@@ -14,14 +21,13 @@ func getFullList(ctx context.Context, tx persistence.Tx, keyPrefix string) (pers
 	if err != nil {
 		return persistence.ListResult{}, err
 	}
-	tCount := listResult.TotalCount
-	for kvl := len(listResult.KVList); (kvl > 0) && (tCount > kvl); kvl = len(listResult.KVList) {
-		listOptions.Offset += listOptions.Limit
-		currListRes, err := tx.List(ctx, keyPrefix, listOptions)
+	for page := listResult; page.TotalCount > len(page.KVList) && len(page.KVList) > 0; {
+		listOptions.After = string(page.KVList[len(page.KVList)-1].Key)
+		page, err = tx.List(ctx, keyPrefix, listOptions)
 		if err != nil {
 			return persistence.ListResult{}, err
 		}
-		listResult.KVList = append(listResult.KVList, currListRes.KVList...)
+		listResult.KVList = append(listResult.KVList, page.KVList...)
 	}
 	return listResult, nil
 }
@@ -33,9 +39,149 @@ func getPersistenceListOptions(opts *ListOpts) *persistence.ListOpts {
 		Limit:  opts.PageSize,
 		Offset: toOffset(opts),
 		Filter: opts.Filter,
+		Sort:   opts.Sort,
 	}
 }
 
+// persistenceListOptions is like getPersistenceListOptions but also
+// resolves the page token of opts to the key to list after. Page tokens
+// are only valid for the query listing the keys under prefix they were
+// issued for. The number of keys listed before the page is returned
+// along with the options.
+func (s *ObjectStore) persistenceListOptions(prefix string, opts *ListOpts) (*persistence.ListOpts, int, error) {
+	res := getPersistenceListOptions(opts)
+	if opts.PageToken == "" {
+		return res, res.Offset, nil
+	}
+	query, err := listQuery(prefix, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	token, values, err := s.decodePageToken(opts.PageToken, query, opts.Sort)
+	if err != nil {
+		return nil, 0, err
+	}
+	res.Offset = 0
+	res.After = s.clusterKey(token.Key)
+	res.AfterValues = values
+	return res, token.Offset, nil
+}
+
+// setListPage sets the total count and the next page of list, listed is
+// the number of keys listed before the page. As the total count of a
+// persistence list only includes the keys after the page token, the keys
+// listed before it are added for the total count to be the same on all pages.
+// A next page token is set whenever there is a next page, so that callers
+// can switch to keyset pagination after listing the first page by number.
+func (s *ObjectStore) setListPage(list model.ObjectList, prefix string, opts *ListOpts,
+	listed int, listResult persistence.ListResult,
+) error {
+	count := len(listResult.KVList)
+	hasNext := listResult.TotalCount > count
+	if opts.PageToken == "" {
+		list.SetTotalCount(listResult.TotalCount)
+		hasNext = toLastPage(opts.PageSize, listResult.TotalCount) > opts.Page
+		if hasNext {
+			list.SetNextPage(opts.Page + 1)
+		}
+	} else {
+		list.SetTotalCount(listed + listResult.TotalCount)
+	}
+	if hasNext && count > 0 {
+		query, err := listQuery(prefix, opts)
+		if err != nil {
+			return err
+		}
+		token, err := s.encodePageToken(listResult.KVList[count-1], query, listed+count, opts.Sort)
+		if err != nil {
+			return err
+		}
+		list.SetNextPageToken(token)
+	}
+	return nil
+}
+
+// pageToken is the content of page tokens. The values of the fields to sort
+// by are needed to list the keys sorting after the key when sorting.
+type pageToken struct {
+	Key    string            `json:"key"`
+	Values []json.RawMessage `json:"values,omitempty"`
+	Offset int               `json:"offset"`
+	Query  string            `json:"query"`
+}
+
+// listQuery returns the hash of the query listing the keys under prefix,
+// page tokens are bound to it so that they cannot be replayed with another
+// prefix, filter or sort.
+func listQuery(prefix string, opts *ListOpts) (string, error) {
+	var filter []byte
+	if opts.Filter != nil {
+		var err error
+		filter, err = proto.MarshalOptions{Deterministic: true}.Marshal(opts.Filter)
+		if err != nil {
+			return "", fmt.Errorf("proto marshal filter: %w", err)
+		}
+	}
+	sum := sha256.Sum256([]byte(strings.Join([]string{
+		prefix, fmt.Sprint(opts.Sort), string(filter),
+	}, "\x00")))
+	return base64.RawURLEncoding.EncodeToString(sum[:16]), nil
+}
+
+// encodePageToken encodes the last listed key as an opaque page token.
+// Keys are relative to the cluster so that tokens do not expose it.
+func (s *ObjectStore) encodePageToken(kv persistence.KVResult, query string, offset int,
+	sort []persistence.SortField,
+) (string, error) {
+	token := pageToken{
+		Key:    strings.TrimPrefix(string(kv.Key), s.clusterKey("")),
+		Offset: offset,
+		Query:  query,
+	}
+	if len(sort) > 0 {
+		values, err := persistence.SortValues(sort, kv.Value)
+		if err != nil {
+			return "", err
+		}
+		for _, value := range values {
+			raw, err := json.Marshal(value)
+			if err != nil {
+				return "", err
+			}
+			token.Values = append(token.Values, raw)
+		}
+	}
+	res, err := json.Marshal(token)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(res), nil
+}
+
+// decodePageToken decodes a page token encoded by encodePageToken for query,
+// along with the values of the fields to sort by for the key.
+func (s *ObjectStore) decodePageToken(encoded string, query string,
+	sort []persistence.SortField,
+) (pageToken, []interface{}, error) {
+	var token pageToken
+	decoded, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return pageToken{}, nil, ErrInvalidPageToken
+	}
+	if err := json.Unmarshal(decoded, &token); err != nil || token.Key == "" ||
+		token.Offset < 0 || token.Query != query {
+		return pageToken{}, nil, ErrInvalidPageToken
+	}
+	if len(sort) == 0 {
+		return token, nil, nil
+	}
+	values, ok := persistence.SortValuesFromJSON(sort, token.Values)
+	if !ok {
+		return pageToken{}, nil, ErrInvalidPageToken
+	}
+	return token, values, nil
+}
+
 func toOffset(opts *ListOpts) int {
 	if opts.Page == 1 || opts.Page == 0 {
 		return 0
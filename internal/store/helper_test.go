@@ -9,7 +9,7 @@ import (
 )
 
 func Test_getPersistenceListOptions(t *testing.T) {
-	expr := &exprpb.Expr{Id: 1}
+	expr := &exprpb.CheckedExpr{Expr: &exprpb.Expr{Id: 1}}
 
 	assert.Equal(
 		t,
@@ -0,0 +1,439 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kong/koko/internal/crypto"
+	"github.com/kong/koko/internal/json"
+	"github.com/kong/koko/internal/model"
+	"github.com/kong/koko/internal/persistence"
+	"go.uber.org/zap"
+)
+
+// DefaultHistoryLimit is the number of history entries kept for every object
+// unless configured otherwise with WithHistoryLimit.
+const DefaultHistoryLimit = 50
+
+// DefaultHistoryRetention is how long history entries are kept unless
+// configured otherwise, see RunHistoryRetention.
+const DefaultHistoryRetention = 30 * 24 * time.Hour
+
+// historyRetentionInterval is the duration between prunes of the history
+// entries past their retention.
+const historyRetentionInterval = time.Hour
+
+// HistoryAction is the kind of write recorded by a HistoryEntry.
+type HistoryAction string
+
+const (
+	HistoryActionCreate HistoryAction = "create"
+	HistoryActionUpdate HistoryAction = "update"
+	HistoryActionDelete HistoryAction = "delete"
+)
+
+// HistoryEntry is a recorded write of an object.
+type HistoryEntry struct {
+	Type model.Type
+	ID   string
+	// Revision is the revision of the object produced by the write.
+	// For deletes, it is the revision following the last one of the object.
+	Revision uint64
+	Action   HistoryAction
+	// Actor identifies who performed the write, see WithActor.
+	Actor string
+	// CreatedAt is the time of the write as a Unix timestamp.
+	CreatedAt int32
+	// Old is the object before the write. It is nil for creates.
+	Old model.Object
+	// New is the object after the write. It is nil for deletes.
+	New model.Object
+}
+
+// HistoryList is a page of the history of an object.
+type HistoryList struct {
+	Entries    []HistoryEntry
+	TotalCount int
+	NextPage   int
+}
+
+// historyValue is the persisted form of a HistoryEntry.
+// Old and New hold objects as wrapped by wrapObject.
+type historyValue struct {
+	Action    HistoryAction   `json:"action"`
+	Actor     string          `json:"actor,omitempty"`
+	CreatedAt int32           `json:"created_at"`
+	Old       json.RawMessage `json:"old,omitempty"`
+	New       json.RawMessage `json:"new,omitempty"`
+}
+
+type actorKey struct{}
+
+// WithActor returns a copy of ctx in which writes to the store are
+// attributed to actor in the history of objects.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+func actorFromContext(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
+
+// WithHistoryLimit returns a copy of the store that keeps up to limit
+// history entries for every object. The oldest entries are removed first.
+// History is not recorded when limit is zero or negative.
+func (s *ObjectStore) WithHistoryLimit(limit int) *ObjectStore {
+	opts := s.objectStoreOpts
+	opts.historyLimit = limit
+	return &ObjectStore{
+		objectStoreOpts: opts,
+		cluster:         s.cluster,
+	}
+}
+
+// ListHistory lists the recorded writes of an object, oldest first.
+func (s *ObjectStore) ListHistory(ctx context.Context, typ model.Type, id string,
+	opts ...ListOptsFunc,
+) (HistoryList, error) {
+	ctx, cancel := context.WithTimeout(ctx, DefaultOperationTimeout)
+	defer cancel()
+	opt, err := NewListOpts(opts...)
+	if err != nil {
+		return HistoryList{}, err
+	}
+	prefix, err := s.historyPrefix(typ, id)
+	if err != nil {
+		return HistoryList{}, err
+	}
+	listResult, err := s.store.List(ctx, prefix, getPersistenceListOptions(opt))
+	if err != nil {
+		return HistoryList{}, err
+	}
+	res := HistoryList{TotalCount: listResult.TotalCount}
+	if toLastPage(opt.PageSize, listResult.TotalCount) > opt.Page {
+		res.NextPage = opt.Page + 1
+	}
+	for _, kv := range listResult.KVList {
+		revision, err := strconv.ParseUint(strings.TrimPrefix(string(kv.Key), prefix), 10, 64)
+		if err != nil {
+			return HistoryList{}, fmt.Errorf("invalid history key: '%s'", kv.Key)
+		}
+		entry, err := unwrapHistoryEntry(typ, id, revision, kv.Value, s.keyring)
+		if err != nil {
+			return HistoryList{}, err
+		}
+		res.Entries = append(res.Entries, entry)
+	}
+	return res, nil
+}
+
+// ReadHistory reads the recorded write of an object that produced revision.
+// It returns ErrNotFound if the write is not recorded.
+func (s *ObjectStore) ReadHistory(ctx context.Context, typ model.Type, id string,
+	revision uint64,
+) (HistoryEntry, error) {
+	ctx, cancel := context.WithTimeout(ctx, DefaultOperationTimeout)
+	defer cancel()
+	prefix, err := s.historyPrefix(typ, id)
+	if err != nil {
+		return HistoryEntry{}, err
+	}
+	value, err := s.store.Get(ctx, historyKey(prefix, revision))
+	if err != nil {
+		if errors.As(err, &persistence.ErrNotFound{}) {
+			return HistoryEntry{}, ErrNotFound
+		}
+		return HistoryEntry{}, err
+	}
+	return unwrapHistoryEntry(typ, id, revision, value, s.keyring)
+}
+
+// recordHistory records a write of an object within tx and removes the
+// entries exceeding the history limit.
+// oldObject is nil for creates and newObject is nil for deletes.
+func (s *ObjectStore) recordHistory(ctx context.Context, tx persistence.Tx,
+	oldObject, newObject model.Object, revision uint64,
+) error {
+	if s.historyLimit <= 0 {
+		return nil
+	}
+	object, action := newObject, HistoryActionUpdate
+	switch {
+	case oldObject == nil:
+		action = HistoryActionCreate
+	case newObject == nil:
+		object, action = oldObject, HistoryActionDelete
+	}
+	if !historyEnabled(object.Type()) {
+		return nil
+	}
+
+	value := historyValue{
+		Action:    action,
+		Actor:     actorFromContext(ctx),
+		CreatedAt: int32(time.Now().Unix()),
+	}
+	var err error
+	if oldObject != nil {
+		if value.Old, err = wrapObject(oldObject, 0, s.keyring); err != nil {
+			return err
+		}
+	}
+	if newObject != nil {
+		if value.New, err = wrapObject(newObject, 0, s.keyring); err != nil {
+			return err
+		}
+	}
+	rawValue, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("json marshal history entry: %w", err)
+	}
+	prefix, err := s.historyPrefix(object.Type(), object.ID())
+	if err != nil {
+		return err
+	}
+	if err := tx.Put(ctx, historyKey(prefix, revision), rawValue); err != nil {
+		return err
+	}
+	return s.pruneHistory(ctx, tx, prefix)
+}
+
+// pruneHistory removes the oldest history entries under prefix exceeding
+// the history limit.
+func (s *ObjectStore) pruneHistory(ctx context.Context, tx persistence.Tx,
+	prefix string,
+) error {
+	listResult, err := tx.List(ctx, prefix, &persistence.ListOpts{Limit: 1})
+	if err != nil {
+		return err
+	}
+	excess := listResult.TotalCount - s.historyLimit
+	for excess > 0 {
+		limit := excess
+		if limit > persistence.MaxLimit {
+			limit = persistence.MaxLimit
+		}
+		listResult, err := tx.List(ctx, prefix, &persistence.ListOpts{Limit: limit})
+		if err != nil {
+			return err
+		}
+		for _, kv := range listResult.KVList {
+			if err := tx.Delete(ctx, string(kv.Key)); err != nil {
+				return err
+			}
+		}
+		excess -= limit
+	}
+	return nil
+}
+
+// RunHistoryRetention removes the history entries of every cluster older
+// than retention, until ctx is done. The latest entry of an object is kept
+// for as long as the object exists, while the history of a deleted object is
+// removed entirely once its deletion is older than retention.
+func (s *ObjectStore) RunHistoryRetention(ctx context.Context, retention time.Duration) error {
+	if retention <= 0 {
+		return nil
+	}
+	ticker := time.NewTicker(historyRetentionInterval)
+	defer ticker.Stop()
+	for {
+		clusters, err := s.clusters(ctx)
+		if err != nil {
+			s.logger.Warn("failed to list clusters to prune history", zap.Error(err))
+		}
+		for cluster := range clusters {
+			count, err := s.ForCluster(cluster).PruneHistory(ctx, time.Now().Add(-retention))
+			if err != nil {
+				s.logger.Warn("failed to prune history",
+					zap.String("cluster", cluster), zap.Error(err))
+				continue
+			}
+			if count > 0 {
+				s.logger.Info("pruned history",
+					zap.String("cluster", cluster), zap.Int("count", count))
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// PruneHistory removes the history entries of the cluster of the store
+// recorded before before, and returns the number of entries removed. The
+// latest entry of an object is kept unless it records its deletion.
+func (s *ObjectStore) PruneHistory(ctx context.Context, before time.Time) (int, error) {
+	var (
+		count   int
+		prefix  string
+		entries []persistence.KVResult
+	)
+	// prune removes the entries of the object under prefix.
+	prune := func() error {
+		var expired []string
+		for i, kv := range entries {
+			var value historyValue
+			if err := json.Unmarshal(kv.Value, &value); err != nil {
+				return fmt.Errorf("json unmarshal history entry: %w", err)
+			}
+			if int64(value.CreatedAt) >= before.Unix() {
+				break
+			}
+			if i == len(entries)-1 && value.Action != HistoryActionDelete {
+				break
+			}
+			expired = append(expired, string(kv.Key))
+		}
+		if len(expired) == 0 {
+			return nil
+		}
+		err := s.withTx(ctx, func(tx persistence.Tx) error {
+			for _, key := range expired {
+				err := tx.Delete(ctx, key)
+				if err != nil && !errors.As(err, &persistence.ErrNotFound{}) {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		count += len(expired)
+		return nil
+	}
+
+	opts := &persistence.ListOpts{Limit: persistence.MaxLimit}
+	for {
+		ctx, cancel := context.WithTimeout(ctx, DefaultOperationTimeout)
+		listResult, err := s.store.List(ctx, s.clusterKey("h/"), opts)
+		cancel()
+		if err != nil {
+			return count, err
+		}
+		for _, kv := range listResult.KVList {
+			key := string(kv.Key)
+			keyPrefix := key[:strings.LastIndex(key, "/")+1]
+			if keyPrefix != prefix {
+				if err := prune(); err != nil {
+					return count, err
+				}
+				prefix, entries = keyPrefix, nil
+			}
+			entries = append(entries, kv)
+		}
+		if len(listResult.KVList) < opts.Limit {
+			return count, prune()
+		}
+		opts.After = string(listResult.KVList[len(listResult.KVList)-1].Key)
+	}
+}
+
+// initialRevision returns the revision of an object about to be created.
+// Objects re-created with the ID of a deleted object continue its history,
+// so that the revisions of an ID are never reused while recorded.
+func (s *ObjectStore) initialRevision(ctx context.Context, tx persistence.CRUD,
+	typ model.Type, id string,
+) (uint64, error) {
+	if s.historyLimit <= 0 || !historyEnabled(typ) {
+		return firstRevision, nil
+	}
+	prefix, err := s.historyPrefix(typ, id)
+	if err != nil {
+		return 0, err
+	}
+	listResult, err := tx.List(ctx, prefix, &persistence.ListOpts{Limit: 1})
+	if err != nil {
+		return 0, err
+	}
+	if listResult.TotalCount == 0 {
+		return firstRevision, nil
+	}
+	listResult, err = tx.List(ctx, prefix, &persistence.ListOpts{
+		Limit:  1,
+		Offset: listResult.TotalCount - 1,
+	})
+	if err != nil {
+		return 0, err
+	}
+	if len(listResult.KVList) == 0 {
+		return firstRevision, nil
+	}
+	key := string(listResult.KVList[0].Key)
+	last, err := strconv.ParseUint(strings.TrimPrefix(key, prefix), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid history key: '%s'", key)
+	}
+	return last + 1, nil
+}
+
+// historyEnabled returns false for types written too frequently to keep
+// a history of.
+func historyEnabled(typ model.Type) bool {
+	return typ != "node"
+}
+
+func (s *ObjectStore) historyPrefix(typ model.Type, id string) (string, error) {
+	if id == "" {
+		return "", fmt.Errorf("no ID specified")
+	}
+	if typ == "" {
+		return "", fmt.Errorf("no type specified")
+	}
+	return s.clusterKey(fmt.Sprintf("h/%s/%s/", typ, id)), nil
+}
+
+// historyKey returns the key of an entry. Revisions are zero-padded so that
+// entries are listed in the order of their revisions.
+func historyKey(prefix string, revision uint64) string {
+	return fmt.Sprintf("%s%020d", prefix, revision)
+}
+
+func unwrapHistoryEntry(typ model.Type, id string, revision uint64,
+	rawValue []byte, keyring *crypto.Keyring,
+) (HistoryEntry, error) {
+	var value historyValue
+	if err := json.Unmarshal(rawValue, &value); err != nil {
+		return HistoryEntry{}, fmt.Errorf("json unmarshal history entry: %w", err)
+	}
+	entry := HistoryEntry{
+		Type:      typ,
+		ID:        id,
+		Revision:  revision,
+		Action:    value.Action,
+		Actor:     value.Actor,
+		CreatedAt: value.CreatedAt,
+	}
+	var err error
+	if entry.Old, err = unwrapHistoryObject(typ, value.Old, keyring); err != nil {
+		return HistoryEntry{}, err
+	}
+	if entry.New, err = unwrapHistoryObject(typ, value.New, keyring); err != nil {
+		return HistoryEntry{}, err
+	}
+	return entry, nil
+}
+
+func unwrapHistoryObject(typ model.Type, value json.RawMessage,
+	keyring *crypto.Keyring,
+) (model.Object, error) {
+	if len(value) == 0 {
+		return nil, nil
+	}
+	object, err := model.NewObject(typ)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := unwrapObject(value, object, keyring); err != nil {
+		return nil, err
+	}
+	return object, nil
+}
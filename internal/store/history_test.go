@@ -0,0 +1,135 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	v1 "github.com/kong/koko/internal/gen/grpc/kong/admin/model/v1"
+	"github.com/kong/koko/internal/log"
+	"github.com/kong/koko/internal/resource"
+	"github.com/kong/koko/internal/test/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistory(t *testing.T) {
+	persister, err := util.GetPersister(t)
+	require.Nil(t, err)
+	s := New(persister, log.Logger).ForCluster(DefaultCluster).WithHistoryLimit(3)
+	ctx := WithActor(context.Background(), "alice")
+
+	id := uuid.NewString()
+	newService := func(host string) resource.Service {
+		svc := resource.NewService()
+		svc.Service = &v1.Service{
+			Id:   id,
+			Name: "foo",
+			Host: host,
+		}
+		return svc
+	}
+	hosts := func(entries []HistoryEntry) [][2]string {
+		var res [][2]string
+		for _, entry := range entries {
+			var oldHost, newHost string
+			if entry.Old != nil {
+				oldHost = entry.Old.(resource.Service).Service.Host
+			}
+			if entry.New != nil {
+				newHost = entry.New.(resource.Service).Service.Host
+			}
+			res = append(res, [2]string{oldHost, newHost})
+		}
+		return res
+	}
+
+	t.Run("writes are recorded", func(t *testing.T) {
+		require.Nil(t, s.Create(ctx, newService("a.com")))
+		require.Nil(t, s.Upsert(ctx, newService("b.com")))
+
+		list, err := s.ListHistory(ctx, resource.TypeService, id)
+		require.Nil(t, err)
+		require.Equal(t, 2, list.TotalCount)
+		require.Equal(t, [][2]string{{"", "a.com"}, {"a.com", "b.com"}},
+			hosts(list.Entries))
+		first := list.Entries[0]
+		require.Equal(t, uint64(1), first.Revision)
+		require.Equal(t, HistoryActionCreate, first.Action)
+		require.Equal(t, "alice", first.Actor)
+		require.NotZero(t, first.CreatedAt)
+		require.Equal(t, HistoryActionUpdate, list.Entries[1].Action)
+	})
+	t.Run("a past revision can be read", func(t *testing.T) {
+		entry, err := s.ReadHistory(ctx, resource.TypeService, id, 1)
+		require.Nil(t, err)
+		require.Equal(t, "a.com", entry.New.(resource.Service).Service.Host)
+
+		_, err = s.ReadHistory(ctx, resource.TypeService, id, 10)
+		require.Equal(t, ErrNotFound, err)
+	})
+	t.Run("deletes are recorded", func(t *testing.T) {
+		require.Nil(t, s.Delete(context.Background(),
+			DeleteByType(resource.TypeService), DeleteByID(id)))
+
+		entry, err := s.ReadHistory(ctx, resource.TypeService, id, 3)
+		require.Nil(t, err)
+		require.Equal(t, HistoryActionDelete, entry.Action)
+		require.Empty(t, entry.Actor)
+		require.Nil(t, entry.New)
+		require.Equal(t, "b.com", entry.Old.(resource.Service).Service.Host)
+	})
+	t.Run("re-created objects continue their history", func(t *testing.T) {
+		var revision uint64
+		require.Nil(t, s.Create(ctx, newService("c.com"), WriteRevision(&revision)))
+		require.Equal(t, uint64(4), revision)
+	})
+	t.Run("entries beyond the limit are removed", func(t *testing.T) {
+		list, err := s.ListHistory(ctx, resource.TypeService, id)
+		require.Nil(t, err)
+		require.Equal(t, 3, list.TotalCount)
+		require.Equal(t, [][2]string{{"a.com", "b.com"}, {"b.com", ""}, {"", "c.com"}},
+			hosts(list.Entries))
+		_, err = s.ReadHistory(ctx, resource.TypeService, id, 1)
+		require.Equal(t, ErrNotFound, err)
+	})
+	t.Run("history is paginated", func(t *testing.T) {
+		list, err := s.ListHistory(ctx, resource.TypeService, id,
+			ListWithPageSize(2), ListWithPageNum(2))
+		require.Nil(t, err)
+		require.Equal(t, 3, list.TotalCount)
+		require.Len(t, list.Entries, 1)
+		require.Equal(t, uint64(4), list.Entries[0].Revision)
+	})
+	t.Run("entries past the retention are removed", func(t *testing.T) {
+		deleted := resource.NewService()
+		deleted.Service = &v1.Service{Id: uuid.NewString(), Host: "e.com"}
+		require.Nil(t, s.Create(ctx, deleted))
+		require.Nil(t, s.Delete(ctx, DeleteByType(resource.TypeService),
+			DeleteByID(deleted.ID())))
+
+		count, err := s.PruneHistory(ctx, time.Now().Add(-time.Hour))
+		require.Nil(t, err)
+		require.Zero(t, count)
+
+		count, err = s.PruneHistory(ctx, time.Now().Add(time.Hour))
+		require.Nil(t, err)
+		require.Equal(t, 4, count)
+		// The latest entry of an existing object is kept.
+		list, err := s.ListHistory(ctx, resource.TypeService, id)
+		require.Nil(t, err)
+		require.Equal(t, [][2]string{{"", "c.com"}}, hosts(list.Entries))
+		list, err = s.ListHistory(ctx, resource.TypeService, deleted.ID())
+		require.Nil(t, err)
+		require.Zero(t, list.TotalCount)
+	})
+	t.Run("history is not recorded without a limit", func(t *testing.T) {
+		s := s.WithHistoryLimit(0)
+		svc := resource.NewService()
+		svc.Service = &v1.Service{Id: uuid.NewString(), Host: "d.com"}
+		require.Nil(t, s.Create(ctx, svc))
+		list, err := s.ListHistory(ctx, resource.TypeService, svc.ID())
+		require.Nil(t, err)
+		require.Zero(t, list.TotalCount)
+	})
+}
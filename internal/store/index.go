@@ -4,7 +4,6 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"strings"
 
 	"github.com/kong/koko/internal/model"
 	"github.com/kong/koko/internal/persistence"
@@ -237,41 +236,47 @@ func (s *ObjectStore) deleteIndexes(
 }
 
 func (s *ObjectStore) onDeleteCascade(ctx context.Context,
-	tx persistence.Tx, object model.Object,
+	tx persistence.Tx, object model.Object, opt *DeleteOpts,
 ) error {
-	key := s.clusterKey(fmt.Sprintf("ix/f/%s/%s", object.Type(), object.ID()))
-	listResult, err := getFullList(ctx, tx, key)
+	refs, err := s.references(ctx, tx, object.Type(), object.ID())
 	if err != nil {
 		return err
 	}
-	if len(listResult.KVList) > 0 {
-		for _, ref := range listResult.KVList {
-			refTypeID := strings.TrimPrefix(string(ref.Key), key+"/")
-			typeAndID := strings.Split(refTypeID, "/")
-			typ := model.Type(typeAndID[0])
-			id := typeAndID[1]
-
-			// Handle deletion of the foreign key relation, but skip cascading
-			// the entire object whenever applicable.
-			//
-			// This is useful for one-to-many relationships, like what is used
-			// for consumer groups. As when a consumer is deleted, we'll want
-			// to delete the association, but not the consumer group itself.
-			if !model.OptionsForType(typ).CascadeOnDelete {
-				if err := tx.Delete(ctx, string(ref.Key)); err != nil {
-					return err
-				}
-				continue
+	if opt.mode == DeleteModeRestrict {
+		var dependents []Dependent
+		for _, ref := range refs {
+			if model.OptionsForType(ref.Type).CascadeOnDelete {
+				dependents = append(dependents, ref.Dependent)
 			}
-
-			// Handle deletion of the foreign key relation along with deleting
-			// the entire object.
-			//
-			// For example, when a consumer is deleted that has route(s)
-			// associated to it, those routes will be entirely deleted.
-			if err := s.delete(ctx, tx, typ, id); err != nil {
+		}
+		if len(dependents) > 0 {
+			return ErrHasDependents{Type: object.Type(), ID: object.ID(), Dependents: dependents}
+		}
+	}
+	for _, ref := range refs {
+		// Handle deletion of the foreign key relation, but skip cascading
+		// the entire object whenever applicable.
+		//
+		// This is useful for one-to-many relationships, like what is used
+		// for consumer groups. As when a consumer is deleted, we'll want
+		// to delete the association, but not the consumer group itself.
+		if !model.OptionsForType(ref.Type).CascadeOnDelete {
+			if err := tx.Delete(ctx, ref.key); err != nil {
 				return err
 			}
+			continue
+		}
+
+		// Handle deletion of the foreign key relation along with deleting
+		// the entire object.
+		//
+		// For example, when a consumer is deleted that has route(s)
+		// associated to it, those routes will be entirely deleted.
+		if err := s.delete(ctx, tx, ref.Type, ref.ID, opt); err != nil {
+			return err
+		}
+		if opt.deleted != nil {
+			*opt.deleted = append(*opt.deleted, ref.Dependent)
 		}
 	}
 	return nil
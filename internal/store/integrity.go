@@ -0,0 +1,353 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kong/koko/internal/json"
+	"github.com/kong/koko/internal/model"
+	"github.com/kong/koko/internal/persistence"
+)
+
+// IntegrityIssueKind is the kind of an inconsistency between the objects and
+// the index rows of the store.
+type IntegrityIssueKind string
+
+const (
+	// IntegrityIssueMissingIndex is an index row of an object that is not
+	// stored. Repairing the store creates the row.
+	IntegrityIssueMissingIndex IntegrityIssueKind = "missing-index"
+	// IntegrityIssueOrphanIndex is an index row not matching the indexes of
+	// any object, e.g.: the unique name index of a renamed object. Repairing
+	// the store deletes the row.
+	IntegrityIssueOrphanIndex IntegrityIssueKind = "orphan-index"
+	// IntegrityIssueDanglingReference is an object referencing an object that
+	// does not exist. It is not repaired, as it requires updating the object.
+	IntegrityIssueDanglingReference IntegrityIssueKind = "dangling-reference"
+	// IntegrityIssueIndexConflict is a unique index row shared by multiple
+	// objects. It is not repaired, as it requires updating the objects.
+	IntegrityIssueIndexConflict IntegrityIssueKind = "index-conflict"
+)
+
+// IntegrityIssue is an inconsistency found by CheckIntegrity.
+type IntegrityIssue struct {
+	Kind    IntegrityIssueKind
+	Cluster string
+	// Key of the index row.
+	Key string
+	// Type and ID of the object the issue relates to, if any.
+	Type model.Type
+	ID   string
+	// Message describes the issue.
+	Message string
+	// Repaired is true when the issue has been repaired.
+	Repaired bool
+}
+
+// IntegrityReport is the result of CheckIntegrity.
+type IntegrityReport struct {
+	// Objects is the number of objects checked.
+	Objects int
+	Issues  []IntegrityIssue
+}
+
+// CheckIntegrity checks the objects of every cluster against the index rows
+// of the store, in a single transaction. The index rows of every object must
+// be stored, and every index row must match the indexes of an object.
+//
+// When repair is true, missing index rows are created and orphan index rows
+// are deleted within the same transaction.
+func (s *ObjectStore) CheckIntegrity(ctx context.Context, repair bool) (IntegrityReport, error) {
+	return s.checkIntegrity(ctx, nil, repair)
+}
+
+// CheckClusterIntegrity is like CheckIntegrity, but only checks the objects
+// of the cluster of the store.
+func (s *ObjectStore) CheckClusterIntegrity(ctx context.Context, repair bool) (IntegrityReport, error) {
+	return s.checkIntegrity(ctx, []string{s.Cluster()}, repair)
+}
+
+// checkIntegrity checks the given clusters, or every cluster when nil.
+// Only the object and index rows of the clusters are listed, one cluster at
+// a time, so that their history and snapshots are not loaded.
+func (s *ObjectStore) checkIntegrity(ctx context.Context, clusters []string,
+	repair bool,
+) (IntegrityReport, error) {
+	var res IntegrityReport
+	err := s.withTx(ctx, func(tx persistence.Tx) error {
+		names := clusters
+		if names == nil {
+			var err error
+			if names, err = clustersTx(ctx, tx); err != nil {
+				return err
+			}
+		}
+		for _, cluster := range names {
+			checker := &integrityChecker{
+				store: s.ForCluster(cluster),
+				tx:    tx,
+				rows:  map[string][]byte{},
+			}
+			var kvs []persistence.KVResult
+			for _, prefix := range []string{"o/", "ix/"} {
+				listResult, err := getFullList(ctx, tx, checker.store.clusterKey(prefix))
+				if err != nil {
+					return err
+				}
+				kvs = append(kvs, listResult.KVList...)
+			}
+			if err := checker.check(ctx, kvs, repair); err != nil {
+				return err
+			}
+			res.Objects += checker.objects
+			res.Issues = append(res.Issues, checker.issues...)
+		}
+		return nil
+	})
+	if err != nil {
+		return IntegrityReport{}, err
+	}
+	return res, nil
+}
+
+// clustersTx returns the sorted IDs of every cluster, the default one
+// included, as recorded within tx.
+func clustersTx(ctx context.Context, tx persistence.Tx) ([]string, error) {
+	prefix := clusterMetaKey("")
+	listResult, err := getFullList(ctx, tx, prefix)
+	if err != nil {
+		return nil, err
+	}
+	res := []string{DefaultCluster}
+	for _, kv := range listResult.KVList {
+		if cluster := strings.TrimPrefix(string(kv.Key), prefix); cluster != DefaultCluster {
+			res = append(res, cluster)
+		}
+	}
+	sort.Strings(res)
+	return res, nil
+}
+
+// expectedIndex is an index row rendered from the indexes of an object.
+type expectedIndex struct {
+	index  model.Index
+	object model.Object
+}
+
+// integrityChecker checks the integrity of a single cluster.
+type integrityChecker struct {
+	store *ObjectStore
+	tx    persistence.Tx
+	// rows holds the object and index rows of the cluster by key.
+	rows    map[string][]byte
+	objects int
+	issues  []IntegrityIssue
+}
+
+func (c *integrityChecker) check(ctx context.Context, kvs []persistence.KVResult,
+	repair bool,
+) error {
+	prefix := c.store.clusterKey("")
+	var objects []model.Object
+	var indexKeys []string
+	for _, kv := range kvs {
+		key := string(kv.Key)
+		c.rows[key] = kv.Value
+		switch parts := strings.Split(strings.TrimPrefix(key, prefix), "/"); {
+		case len(parts) == 3 && parts[0] == "o":
+			object, err := model.NewObject(model.Type(parts[1]))
+			if err != nil {
+				// Not an object managed by the store.
+				continue
+			}
+			if _, err := unwrapObject(kv.Value, object, c.store.keyring); err != nil {
+				return fmt.Errorf("read object '%s': %w", key, err)
+			}
+			objects = append(objects, object)
+		case len(parts) > 2 && parts[0] == "ix":
+			indexKeys = append(indexKeys, key)
+		}
+	}
+	c.objects = len(objects)
+
+	expected := map[string][]expectedIndex{}
+	for _, object := range objects {
+		for _, index := range object.Indexes() {
+			if index.Action == model.IndexActionRemove {
+				continue
+			}
+			key, _, err := c.store.indexKV(index, object)
+			if err != nil {
+				return err
+			}
+			expected[key] = append(expected[key], expectedIndex{
+				index:  index,
+				object: object,
+			})
+		}
+	}
+
+	var orphans []string
+	for _, key := range indexKeys {
+		if message := c.checkIndexRow(key, expected[key]); message != "" {
+			orphans = append(orphans, key)
+			c.issues = append(c.issues, IntegrityIssue{
+				Kind:     IntegrityIssueOrphanIndex,
+				Cluster:  c.store.Cluster(),
+				Key:      key,
+				Message:  message,
+				Repaired: repair,
+			})
+		}
+	}
+
+	var missing []expectedIndex
+	keys := make([]string, 0, len(expected))
+	for key := range expected {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		for _, e := range expected[key] {
+			issue := c.checkExpectedIndex(key, e, expected[key])
+			if issue == nil {
+				continue
+			}
+			if issue.Kind == IntegrityIssueMissingIndex {
+				issue.Repaired = repair
+				missing = append(missing, e)
+			}
+			c.issues = append(c.issues, *issue)
+		}
+	}
+
+	if !repair {
+		return nil
+	}
+	// Orphans are deleted first, as they may be replaced by missing rows.
+	for _, key := range orphans {
+		if err := c.tx.Delete(ctx, key); err != nil {
+			return err
+		}
+	}
+	for _, e := range missing {
+		key, value, err := c.store.indexKV(e.index, e.object)
+		if err != nil {
+			return err
+		}
+		if err := c.tx.Put(ctx, key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkIndexRow returns why the index row is an orphan, if it is one.
+func (c *integrityChecker) checkIndexRow(key string, expected []expectedIndex) string {
+	parts := strings.Split(strings.TrimPrefix(key, c.store.clusterKey("ix/")), "/")
+	switch {
+	case len(parts) >= 4 && parts[0] == "u":
+		refID, err := unwrapUniqueIndexRow(c.rows[key])
+		if err != nil {
+			return err.Error()
+		}
+		for _, e := range expected {
+			if e.object.ID() == refID {
+				return ""
+			}
+		}
+		if !c.exists(model.Type(parts[1]), refID) {
+			return fmt.Sprintf("%s '%s' does not exist", parts[1], refID)
+		}
+		return fmt.Sprintf("%s '%s' is not indexed by this row", parts[1], refID)
+	case len(parts) == 5 && parts[0] == "f":
+		foreignType, foreignID := model.Type(parts[1]), parts[2]
+		typ, id := model.Type(parts[3]), parts[4]
+		if !c.exists(typ, id) {
+			return fmt.Sprintf("%s '%s' does not exist", typ, id)
+		}
+		if len(expected) > 0 {
+			return ""
+		}
+		// The references of objects that do not cascade on delete, e.g.:
+		// consumer group members, are managed outside of their indexes, and
+		// are only orphans once the referenced object does not exist.
+		if !model.OptionsForType(typ).CascadeOnDelete {
+			if !c.exists(foreignType, foreignID) {
+				return fmt.Sprintf("%s '%s' does not exist", foreignType, foreignID)
+			}
+			return ""
+		}
+		return fmt.Sprintf("%s '%s' does not reference %s '%s'", typ, id,
+			foreignType, foreignID)
+	default:
+		return "invalid index key"
+	}
+}
+
+// checkExpectedIndex returns the issue of an index row expected by an object,
+// if any.
+func (c *integrityChecker) checkExpectedIndex(key string, e expectedIndex,
+	all []expectedIndex,
+) *IntegrityIssue {
+	issue := &IntegrityIssue{
+		Cluster: c.store.Cluster(),
+		Key:     key,
+		Type:    e.object.Type(),
+		ID:      e.object.ID(),
+	}
+	switch e.index.Type {
+	case model.IndexUnique:
+		if len(all) > 1 {
+			issue.Kind = IntegrityIssueIndexConflict
+			issue.Message = fmt.Sprintf("%s '%s' is shared by %d objects",
+				e.index.Name, e.index.Value, len(all))
+			// Only report the conflict once.
+			if all[0].object.ID() != e.object.ID() {
+				return nil
+			}
+			return issue
+		}
+	case model.IndexForeign:
+		if !c.exists(e.index.ForeignType, e.index.Value) {
+			issue.Kind = IntegrityIssueDanglingReference
+			issue.Message = fmt.Sprintf("%s references %s '%s' which does not exist",
+				e.index.FieldName, e.index.ForeignType, e.index.Value)
+			return issue
+		}
+	}
+	value, ok := c.rows[key]
+	if ok && e.index.Type == model.IndexUnique {
+		refID, err := unwrapUniqueIndexRow(value)
+		ok = err == nil && refID == e.object.ID()
+	}
+	if !ok {
+		issue.Kind = IntegrityIssueMissingIndex
+		issue.Message = fmt.Sprintf("%s (type: %s) index for value '%s' is missing",
+			e.index.Name, e.index.Type, e.index.Value)
+		return issue
+	}
+	return nil
+}
+
+func (c *integrityChecker) exists(typ model.Type, id string) bool {
+	key, err := c.store.genID(typ, id)
+	if err != nil {
+		return false
+	}
+	_, ok := c.rows[key]
+	return ok
+}
+
+// unwrapUniqueIndexRow is like unwrapUniqueIndex, but fails instead of
+// panicking on values that are not unique index rows.
+func unwrapUniqueIndexRow(value []byte) (string, error) {
+	var v valueWrapper
+	if err := json.Unmarshal(value, &v); err != nil || v.RefID == "" {
+		return "", errors.New("invalid unique index value")
+	}
+	return v.RefID, nil
+}
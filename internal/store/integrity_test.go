@@ -0,0 +1,118 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	v1 "github.com/kong/koko/internal/gen/grpc/kong/admin/model/v1"
+	"github.com/kong/koko/internal/log"
+	"github.com/kong/koko/internal/resource"
+	"github.com/kong/koko/internal/test/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckIntegrity(t *testing.T) {
+	persister, err := util.GetPersister(t)
+	require.Nil(t, err)
+	s := New(persister, log.Logger).ForCluster(DefaultCluster)
+	other := s.ForCluster("other")
+	ctx := context.Background()
+	require.Nil(t, s.CreateCluster(ctx, &ClusterInfo{ID: "other"}))
+
+	newService := func(id, name string) resource.Service {
+		svc := resource.NewService()
+		svc.Service = &v1.Service{Id: id, Name: name, Host: "example.com"}
+		return svc
+	}
+	sid1, sid2, sid3, rid := uuid.NewString(), uuid.NewString(), uuid.NewString(), uuid.NewString()
+	require.Nil(t, s.Create(ctx, newService(sid1, "s1")))
+	require.Nil(t, s.Create(ctx, newService(sid2, "s2")))
+	route := resource.NewRoute()
+	route.Route = &v1.Route{
+		Id:      rid,
+		Name:    "r0",
+		Hosts:   []string{"example.com"},
+		Service: &v1.Service{Id: sid2},
+	}
+	require.Nil(t, s.Create(ctx, route))
+	require.Nil(t, other.Create(ctx, newService(sid3, "s3")))
+
+	type issue struct {
+		Kind     IntegrityIssueKind
+		Cluster  string
+		Key      string
+		Repaired bool
+	}
+	check := func(t *testing.T, repair bool, objects int) []issue {
+		report, err := s.CheckIntegrity(ctx, repair)
+		require.Nil(t, err)
+		require.Equal(t, objects, report.Objects)
+		res := make([]issue, 0, len(report.Issues))
+		for _, i := range report.Issues {
+			require.NotEmpty(t, i.Message)
+			res = append(res, issue{Kind: i.Kind, Cluster: i.Cluster, Key: i.Key, Repaired: i.Repaired})
+		}
+		return res
+	}
+
+	t.Run("finds no issues in a consistent store", func(t *testing.T) {
+		require.Empty(t, check(t, false, 4))
+	})
+
+	// Corrupts the store, bypassing the object store.
+	missingKey := s.uniqueIndexKey(resource.TypeService, "name", "s1")
+	require.Nil(t, persister.Delete(ctx, missingKey))
+	orphanKey := other.uniqueIndexKey(resource.TypeService, "name", "ghost")
+	value, err := wrapUniqueIndex(uuid.NewString())
+	require.Nil(t, err)
+	require.Nil(t, persister.Put(ctx, orphanKey, value))
+	serviceKey, err := s.genID(resource.TypeService, sid2)
+	require.Nil(t, err)
+	require.Nil(t, persister.Delete(ctx, serviceKey))
+	danglingKey := s.foreignIndexKey(resource.TypeService, sid2, resource.TypeRoute, rid)
+	staleKey := s.uniqueIndexKey(resource.TypeService, "name", "s2")
+
+	t.Run("reports issues without repairing them", func(t *testing.T) {
+		require.ElementsMatch(t, []issue{
+			{Kind: IntegrityIssueMissingIndex, Cluster: DefaultCluster, Key: missingKey},
+			{Kind: IntegrityIssueOrphanIndex, Cluster: DefaultCluster, Key: staleKey},
+			{Kind: IntegrityIssueDanglingReference, Cluster: DefaultCluster, Key: danglingKey},
+			{Kind: IntegrityIssueOrphanIndex, Cluster: "other", Key: orphanKey},
+		}, check(t, false, 3))
+		_, err := persister.Get(ctx, orphanKey)
+		require.Nil(t, err)
+	})
+	t.Run("checks the cluster of the store only", func(t *testing.T) {
+		report, err := other.CheckClusterIntegrity(ctx, false)
+		require.Nil(t, err)
+		require.Equal(t, 1, report.Objects)
+		require.Len(t, report.Issues, 1)
+		require.Equal(t, "other", report.Issues[0].Cluster)
+		require.Equal(t, orphanKey, report.Issues[0].Key)
+
+		report, err = s.CheckClusterIntegrity(ctx, false)
+		require.Nil(t, err)
+		require.Equal(t, 2, report.Objects)
+		require.Len(t, report.Issues, 3)
+		for _, i := range report.Issues {
+			require.Equal(t, DefaultCluster, i.Cluster)
+		}
+	})
+	t.Run("repairs issues", func(t *testing.T) {
+		require.ElementsMatch(t, []issue{
+			{Kind: IntegrityIssueMissingIndex, Cluster: DefaultCluster, Key: missingKey, Repaired: true},
+			{Kind: IntegrityIssueOrphanIndex, Cluster: DefaultCluster, Key: staleKey, Repaired: true},
+			{Kind: IntegrityIssueDanglingReference, Cluster: DefaultCluster, Key: danglingKey},
+			{Kind: IntegrityIssueOrphanIndex, Cluster: "other", Key: orphanKey, Repaired: true},
+		}, check(t, true, 3))
+		svc := resource.NewService()
+		require.Nil(t, s.Read(ctx, svc, GetByName("s1")))
+		require.Equal(t, sid1, svc.ID())
+
+		// Dangling references are left to be fixed by updating the objects.
+		require.Equal(t, []issue{
+			{Kind: IntegrityIssueDanglingReference, Cluster: DefaultCluster, Key: danglingKey},
+		}, check(t, false, 3))
+	})
+}
@@ -2,18 +2,51 @@ package store
 
 import (
 	"github.com/kong/koko/internal/model"
+	"github.com/kong/koko/internal/persistence"
 	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
 )
 
-type CreateOpts struct{}
+type CreateOpts struct {
+	expectedRevision uint64
+	revision         *uint64
+	// indexesDeleted is true when the indexes of the stored object have
+	// already been deleted, e.g.: by RestoreSnapshot.
+	indexesDeleted bool
+}
 
 type CreateOptsFunc func(*CreateOpts)
 
+func NewCreateOpts(fns ...CreateOptsFunc) *CreateOpts {
+	res := &CreateOpts{}
+	for _, fn := range fns {
+		fn(res)
+	}
+	return res
+}
+
+// UpsertIfRevision makes Upsert fail with ErrRevisionMismatch unless the
+// object exists with the given revision.
+func UpsertIfRevision(revision uint64) CreateOptsFunc {
+	return func(opt *CreateOpts) {
+		opt.expectedRevision = revision
+	}
+}
+
+// WriteRevision sets revision to the revision of the written object.
+func WriteRevision(revision *uint64) CreateOptsFunc {
+	return func(opt *CreateOpts) {
+		opt.revision = revision
+	}
+}
+
 type ReadOpts struct {
 	id   string
 	name string
 
 	idxName, idxValue string
+
+	revision *uint64
+	primary  bool
 }
 
 type ReadOptsFunc func(*ReadOpts)
@@ -45,9 +78,28 @@ func GetByIndex(indexName, indexValue string) ReadOptsFunc {
 	}
 }
 
+// ReadRevision sets revision to the revision of the read object.
+func ReadRevision(revision *uint64) ReadOptsFunc {
+	return func(opt *ReadOpts) {
+		opt.revision = revision
+	}
+}
+
+// ReadFromPrimary reads the object from the primary database instead of a
+// read replica, which may not have replayed the latest writes yet.
+func ReadFromPrimary() ReadOptsFunc {
+	return func(opt *ReadOpts) {
+		opt.primary = true
+	}
+}
+
 type DeleteOpts struct {
 	id  string
 	typ model.Type
+
+	expectedRevision uint64
+	mode             DeleteMode
+	deleted          *[]Dependent
 }
 
 type DeleteOptsFunc func(*DeleteOpts)
@@ -72,6 +124,29 @@ func DeleteByType(typ model.Type) DeleteOptsFunc {
 	}
 }
 
+// DeleteIfRevision makes Delete fail with ErrRevisionMismatch unless the
+// object has the given revision.
+func DeleteIfRevision(revision uint64) DeleteOptsFunc {
+	return func(opt *DeleteOpts) {
+		opt.expectedRevision = revision
+	}
+}
+
+// DeleteWithMode sets how the dependents of the deleted object are handled.
+func DeleteWithMode(mode DeleteMode) DeleteOptsFunc {
+	return func(opt *DeleteOpts) {
+		opt.mode = mode
+	}
+}
+
+// DeletedDependents sets deleted to the dependents deleted along with the
+// object, recursively.
+func DeletedDependents(deleted *[]Dependent) DeleteOptsFunc {
+	return func(opt *DeleteOpts) {
+		opt.deleted = deleted
+	}
+}
+
 type ListOpts struct {
 	ReferenceType          model.Type
 	ReferenceID            string
@@ -79,21 +154,20 @@ type ListOpts struct {
 
 	PageSize int
 	Page     int
+	// PageToken is the token of the page to list, as returned by a previous
+	// list call. When set, Page is ignored.
+	PageToken string
 
-	// CEL expression used for filtering.
+	// Type-checked CEL expression used for filtering.
 	// Read more: https://github.com/google/cel-spec
-	Filter *exprpb.Expr
-}
+	Filter *exprpb.CheckedExpr
 
-func (o *ListOpts) validate() error {
-	// TODO(tjasko): Implement proper support for combining both ListFor() & ListWithFilter().
-	if o.Filter != nil && o.ReferenceType != "" && o.ReferenceID != "" {
-		return ErrUnsupportedListOpts{
-			"listing resources scoped to a resource while applying a filter are not yet supported",
-		}
-	}
+	// Sort is the fields of the objects to sort by, before sorting by ID.
+	Sort []persistence.SortField
 
-	return nil
+	// revisions is filled with the revision of every listed object by ID.
+	revisions map[string]uint64
+	primary   bool
 }
 
 type ListOptsFunc func(*ListOpts)
@@ -111,18 +185,13 @@ const (
 	MaxPageSize     = 1000
 )
 
-// NewListOpts executes the provided list option functions, validates that all set
-// options are compatible with each other, and returns the generated ListOpts.
+// NewListOpts executes the provided list option functions and returns the generated ListOpts.
 func NewListOpts(fns ...ListOptsFunc) (*ListOpts, error) {
 	res := &ListOpts{PageSize: DefaultPageSize, Page: DefaultPage}
 	for _, fn := range fns {
 		fn(res)
 	}
 
-	if err := res.validate(); err != nil {
-		return nil, err
-	}
-
 	return res, nil
 }
 
@@ -192,9 +261,41 @@ func ListWithPageSize(pageSize int) ListOptsFunc {
 	}
 }
 
-// ListWithFilter associates the passed in CEL expression with the current list pagination options.
-func ListWithFilter(expr *exprpb.Expr) ListOptsFunc {
+// ListWithPageToken lists the page identified by the passed in token instead of
+// a page number. Such tokens are returned by model.ObjectList.GetNextPageToken().
+func ListWithPageToken(token string) ListOptsFunc {
+	return func(opt *ListOpts) {
+		opt.PageToken = token
+	}
+}
+
+// ListWithSort sorts the listed objects by the given fields. Objects sorting
+// equally are sorted by ID.
+func ListWithSort(fields ...persistence.SortField) ListOptsFunc {
+	return func(opt *ListOpts) {
+		opt.Sort = fields
+	}
+}
+
+// ListWithFilter associates the passed in type-checked CEL expression with the current list pagination options.
+func ListWithFilter(expr *exprpb.CheckedExpr) ListOptsFunc {
 	return func(opt *ListOpts) {
 		opt.Filter = expr
 	}
 }
+
+// ListRevisions fills revisions with the revision of every listed object
+// keyed by the ID of the object.
+func ListRevisions(revisions map[string]uint64) ListOptsFunc {
+	return func(opt *ListOpts) {
+		opt.revisions = revisions
+	}
+}
+
+// ListFromPrimary lists the objects from the primary database instead of a
+// read replica, which may not have replayed the latest writes yet.
+func ListFromPrimary() ListOptsFunc {
+	return func(opt *ListOpts) {
+		opt.primary = true
+	}
+}
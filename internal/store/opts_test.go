@@ -10,7 +10,7 @@ import (
 )
 
 func TestNewListOpts(t *testing.T) {
-	filter := &expr.Expr{}
+	filter := &expr.CheckedExpr{}
 
 	t.Run("sets default page size", func(t *testing.T) {
 		opts, err := NewListOpts()
@@ -54,14 +54,17 @@ func TestNewListOpts(t *testing.T) {
 	})
 
 	t.Run("with ListWithFilter() & ListFor()", func(t *testing.T) {
-		_, err := NewListOpts(
+		opts, err := NewListOpts(
 			ListWithFilter(filter),
 			ListFor(resource.TypeConsumer, "ref-id"),
 		)
-		assert.EqualError(
-			t,
-			err,
-			"listing resources scoped to a resource while applying a filter are not yet supported",
-		)
+		require.NoError(t, err)
+		assert.Equal(t, &ListOpts{
+			PageSize:      DefaultPageSize,
+			Page:          DefaultPage,
+			ReferenceType: resource.TypeConsumer,
+			ReferenceID:   "ref-id",
+			Filter:        filter,
+		}, opts)
 	})
 }
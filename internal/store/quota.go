@@ -0,0 +1,117 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	v1 "github.com/kong/koko/internal/gen/grpc/kong/admin/model/v1"
+	"github.com/kong/koko/internal/model"
+	"github.com/kong/koko/internal/model/json/validation"
+	"github.com/kong/koko/internal/persistence"
+)
+
+// Quotas limits the number of objects of each type that clusters hold.
+// A limit of zero means there is no limit.
+type Quotas struct {
+	// Entities maps types to the maximum number of objects of the type that
+	// each cluster holds.
+	Entities map[model.Type]int
+	// Clusters maps cluster IDs to limits overriding Entities for the
+	// cluster.
+	Clusters map[string]map[model.Type]int
+}
+
+// Limit returns the maximum number of objects of typ that cluster holds, or
+// zero if there is no limit.
+func (q *Quotas) Limit(cluster string, typ model.Type) int {
+	if q == nil {
+		return 0
+	}
+	if limit, ok := q.Clusters[cluster][typ]; ok {
+		return limit
+	}
+	return q.Entities[typ]
+}
+
+// TypeUsage is the number of objects of a type that a cluster holds.
+type TypeUsage struct {
+	Type  model.Type
+	Count int
+	// Limit is the quota for the type, or zero if there is none.
+	Limit int
+}
+
+// WithQuotas returns a copy of the store that refuses to create objects
+// beyond quotas. Objects are not limited when quotas is nil.
+func (s *ObjectStore) WithQuotas(quotas *Quotas) *ObjectStore {
+	opts := s.objectStoreOpts
+	opts.quotas = quotas
+	return &ObjectStore{
+		objectStoreOpts: opts,
+		cluster:         s.cluster,
+	}
+}
+
+// Usage implements the Store interface.
+func (s *ObjectStore) Usage(ctx context.Context) ([]TypeUsage, error) {
+	ctx, cancel := context.WithTimeout(ctx, DefaultOperationTimeout)
+	defer cancel()
+	types := model.AllTypes()
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+	res := make([]TypeUsage, 0, len(types))
+	err := s.withTx(ctx, func(tx persistence.Tx) error {
+		for _, typ := range types {
+			count, err := s.count(ctx, tx, typ)
+			if err != nil {
+				return err
+			}
+			res = append(res, TypeUsage{
+				Type:  typ,
+				Count: count,
+				Limit: s.quotas.Limit(s.Cluster(), typ),
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// checkQuota returns an error if the cluster of the store holds as many
+// objects of typ as its quota allows, so that no other one can be created.
+func (s *ObjectStore) checkQuota(ctx context.Context, tx persistence.CRUD,
+	typ model.Type,
+) error {
+	limit := s.quotas.Limit(s.Cluster(), typ)
+	if limit == 0 {
+		return nil
+	}
+	count, err := s.count(ctx, tx, typ)
+	if err != nil {
+		return err
+	}
+	if count < limit {
+		return nil
+	}
+	return validation.Error{Errs: []*v1.ErrorDetail{{
+		Type: v1.ErrorType_ERROR_TYPE_ENTITY,
+		Messages: []string{fmt.Sprintf(
+			"quota exceeded: cluster '%s' holds the maximum of %d '%s' entities",
+			s.Cluster(), limit, typ)},
+	}}}
+}
+
+// count returns the number of objects of typ that the cluster of the store
+// holds.
+func (s *ObjectStore) count(ctx context.Context, tx persistence.CRUD,
+	typ model.Type,
+) (int, error) {
+	listResult, err := tx.List(ctx, s.listKey(typ), &persistence.ListOpts{Limit: 1})
+	if err != nil {
+		return 0, err
+	}
+	return listResult.TotalCount, nil
+}
@@ -0,0 +1,118 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	v1 "github.com/kong/koko/internal/gen/grpc/kong/admin/model/v1"
+	"github.com/kong/koko/internal/log"
+	"github.com/kong/koko/internal/model"
+	"github.com/kong/koko/internal/model/json/validation"
+	"github.com/kong/koko/internal/resource"
+	"github.com/kong/koko/internal/test/util"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestConsumer() resource.Consumer {
+	consumer := resource.NewConsumer()
+	consumer.Consumer = &v1.Consumer{
+		Id:       uuid.NewString(),
+		Username: uuid.NewString(),
+	}
+	return consumer
+}
+
+func TestQuotas(t *testing.T) {
+	persister, err := util.GetPersister(t)
+	require.Nil(t, err)
+	quotas := &Quotas{
+		Entities: map[model.Type]int{resource.TypeConsumer: 2},
+		Clusters: map[string]map[model.Type]int{
+			"east": {resource.TypeConsumer: 1},
+			"west": {resource.TypeConsumer: 0},
+		},
+	}
+	objectStore := New(persister, log.Logger).WithQuotas(quotas)
+	s := objectStore.ForCluster(DefaultCluster)
+	ctx := context.Background()
+
+	requireQuotaExceeded := func(t *testing.T, err error, message string) {
+		var validationErr validation.Error
+		require.ErrorAs(t, err, &validationErr)
+		require.Len(t, validationErr.Errs, 1)
+		require.Equal(t, v1.ErrorType_ERROR_TYPE_ENTITY, validationErr.Errs[0].Type)
+		require.Equal(t, []string{message}, validationErr.Errs[0].Messages)
+	}
+
+	consumer := newTestConsumer()
+	require.Nil(t, s.Create(ctx, consumer))
+	require.Nil(t, s.Upsert(ctx, newTestConsumer()))
+
+	t.Run("creating beyond the quota fails", func(t *testing.T) {
+		requireQuotaExceeded(t, s.Create(ctx, newTestConsumer()),
+			"quota exceeded: cluster 'default' holds the maximum of 2 'consumer' entities")
+		requireQuotaExceeded(t, s.Upsert(ctx, newTestConsumer()),
+			"quota exceeded: cluster 'default' holds the maximum of 2 'consumer' entities")
+	})
+	t.Run("batches are limited", func(t *testing.T) {
+		require.Nil(t, s.Delete(ctx, DeleteByID(consumer.ID()),
+			DeleteByType(resource.TypeConsumer)))
+		err := s.ApplyBatch(ctx, []BatchOperation{
+			{Type: BatchOperationCreate, Object: newTestConsumer()},
+			{Type: BatchOperationCreate, Object: newTestConsumer()},
+		})
+		var batchErr ErrBatchOperation
+		require.ErrorAs(t, err, &batchErr)
+		require.Equal(t, 1, batchErr.Index)
+		requireQuotaExceeded(t, err,
+			"quota exceeded: cluster 'default' holds the maximum of 2 'consumer' entities")
+		require.Nil(t, s.Create(ctx, consumer))
+	})
+	t.Run("updating within the quota succeeds", func(t *testing.T) {
+		consumer.Consumer.CustomId = "foo"
+		require.Nil(t, s.Upsert(ctx, consumer))
+	})
+	t.Run("types without a quota are not limited", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			service := resource.NewService()
+			service.Service = &v1.Service{Id: uuid.NewString(), Host: "example.com"}
+			require.Nil(t, s.Create(ctx, service))
+		}
+	})
+	t.Run("clusters override quotas", func(t *testing.T) {
+		east := objectStore.ForCluster("east")
+		require.Nil(t, east.Create(ctx, newTestConsumer()))
+		requireQuotaExceeded(t, east.Create(ctx, newTestConsumer()),
+			"quota exceeded: cluster 'east' holds the maximum of 1 'consumer' entities")
+
+		west := objectStore.ForCluster("west")
+		for i := 0; i < 3; i++ {
+			require.Nil(t, west.Create(ctx, newTestConsumer()))
+		}
+	})
+	t.Run("reports usage", func(t *testing.T) {
+		usage, err := s.Usage(ctx)
+		require.Nil(t, err)
+		require.Len(t, usage, len(model.AllTypes()))
+		counts := map[model.Type]TypeUsage{}
+		for i, u := range usage {
+			if i > 0 {
+				require.Less(t, usage[i-1].Type, u.Type)
+			}
+			counts[u.Type] = u
+		}
+		require.Equal(t, TypeUsage{Type: resource.TypeConsumer, Count: 2, Limit: 2},
+			counts[resource.TypeConsumer])
+		require.Equal(t, TypeUsage{Type: resource.TypeService, Count: 3},
+			counts[resource.TypeService])
+
+		usage, err = objectStore.ForCluster("east").Usage(ctx)
+		require.Nil(t, err)
+		for _, u := range usage {
+			if u.Type == resource.TypeConsumer {
+				require.Equal(t, TypeUsage{Type: resource.TypeConsumer, Count: 1, Limit: 1}, u)
+			}
+		}
+	})
+}
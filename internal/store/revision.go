@@ -0,0 +1,20 @@
+package store
+
+import "fmt"
+
+// firstRevision is the revision of newly created objects.
+const firstRevision = 1
+
+// ErrRevisionMismatch is returned when a conditional write expects a
+// revision other than the current revision of the object.
+type ErrRevisionMismatch struct {
+	Expected uint64
+	// Actual is the current revision of the object, zero when the object
+	// does not exist.
+	Actual uint64
+}
+
+func (e ErrRevisionMismatch) Error() string {
+	return fmt.Sprintf("revision mismatch: expected '%d', got '%d'",
+		e.Expected, e.Actual)
+}
@@ -0,0 +1,107 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	v1 "github.com/kong/koko/internal/gen/grpc/kong/admin/model/v1"
+	"github.com/kong/koko/internal/log"
+	"github.com/kong/koko/internal/resource"
+	"github.com/kong/koko/internal/test/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRevision(t *testing.T) {
+	persister, err := util.GetPersister(t)
+	require.Nil(t, err)
+	s := New(persister, log.Logger).ForCluster(DefaultCluster)
+	ctx := context.Background()
+
+	id := uuid.NewString()
+	newService := func(host string) resource.Service {
+		svc := resource.NewService()
+		svc.Service = &v1.Service{
+			Id:   id,
+			Name: "foo",
+			Host: host,
+		}
+		return svc
+	}
+	readRevision := func(t *testing.T) uint64 {
+		var revision uint64
+		require.Nil(t, s.Read(ctx, resource.NewService(), GetByID(id),
+			ReadRevision(&revision)))
+		return revision
+	}
+
+	t.Run("create sets the first revision", func(t *testing.T) {
+		var revision uint64
+		require.Nil(t, s.Create(ctx, newService("a.com"), WriteRevision(&revision)))
+		require.Equal(t, uint64(1), revision)
+		require.Equal(t, uint64(1), readRevision(t))
+	})
+	t.Run("upsert increments the revision", func(t *testing.T) {
+		var revision uint64
+		require.Nil(t, s.Upsert(ctx, newService("b.com"), WriteRevision(&revision)))
+		require.Equal(t, uint64(2), revision)
+		require.Equal(t, uint64(2), readRevision(t))
+	})
+	t.Run("read by name returns the revision", func(t *testing.T) {
+		var revision uint64
+		require.Nil(t, s.Read(ctx, resource.NewService(), GetByName("foo"),
+			ReadRevision(&revision)))
+		require.Equal(t, uint64(2), revision)
+	})
+	t.Run("list returns the revisions", func(t *testing.T) {
+		revisions := map[string]uint64{}
+		list := resource.NewList(resource.TypeService)
+		require.Nil(t, s.List(ctx, list, ListRevisions(revisions)))
+		require.Equal(t, map[string]uint64{id: 2}, revisions)
+	})
+	t.Run("upsert with a stale revision fails", func(t *testing.T) {
+		err := s.Upsert(ctx, newService("c.com"), UpsertIfRevision(1))
+		require.Equal(t, ErrRevisionMismatch{Expected: 1, Actual: 2}, err)
+		require.Equal(t, uint64(2), readRevision(t))
+	})
+	t.Run("upsert with the current revision succeeds", func(t *testing.T) {
+		require.Nil(t, s.Upsert(ctx, newService("c.com"), UpsertIfRevision(2)))
+		require.Equal(t, uint64(3), readRevision(t))
+	})
+	t.Run("upsert with a revision of a missing object fails", func(t *testing.T) {
+		svc := resource.NewService()
+		svc.Service = &v1.Service{
+			Id:   uuid.NewString(),
+			Host: "d.com",
+		}
+		err := s.Upsert(ctx, svc, UpsertIfRevision(1))
+		require.Equal(t, ErrRevisionMismatch{Expected: 1}, err)
+	})
+	t.Run("delete with a stale revision fails", func(t *testing.T) {
+		err := s.Delete(ctx, DeleteByType(resource.TypeService), DeleteByID(id),
+			DeleteIfRevision(2))
+		require.Equal(t, ErrRevisionMismatch{Expected: 2, Actual: 3}, err)
+	})
+	t.Run("delete with the current revision succeeds", func(t *testing.T) {
+		require.Nil(t, s.Delete(ctx, DeleteByType(resource.TypeService),
+			DeleteByID(id), DeleteIfRevision(3)))
+		require.Equal(t, ErrNotFound, s.Read(ctx, resource.NewService(), GetByID(id)))
+	})
+	t.Run("objects stored without a revision are at the first revision", func(t *testing.T) {
+		legacyID := uuid.NewString()
+		key, err := s.genID(resource.TypeService, legacyID)
+		require.Nil(t, err)
+		require.Nil(t, persister.Put(ctx, key, []byte(
+			`{"type":3,"object":{"id":"`+legacyID+`","host":"legacy.com"}}`)))
+
+		var revision uint64
+		read := resource.NewService()
+		require.Nil(t, s.Read(ctx, read, GetByID(legacyID), ReadRevision(&revision)))
+		require.Equal(t, uint64(1), revision)
+		require.Equal(t, "legacy.com", read.Service.Host)
+
+		read.Service.Host = "e.com"
+		require.Nil(t, s.Upsert(ctx, read, UpsertIfRevision(1), WriteRevision(&revision)))
+		require.Equal(t, uint64(2), revision)
+	})
+}
@@ -0,0 +1,518 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kong/koko/internal/json"
+	"github.com/kong/koko/internal/model"
+	"github.com/kong/koko/internal/persistence"
+	"google.golang.org/protobuf/proto"
+)
+
+// Snapshot is a named copy of the objects of a cluster.
+type Snapshot struct {
+	Name string
+	// CreatedAt is the time the snapshot was taken as a Unix timestamp.
+	CreatedAt int32
+	// ExpectedHash is the hash of the configuration expected to be running
+	// on data planes when the snapshot was taken.
+	ExpectedHash string
+	// ObjectCount is the number of objects in the snapshot.
+	ObjectCount int
+	// Objects are the objects of the snapshot. They are only set by
+	// CreateSnapshot and ReadSnapshot.
+	Objects []model.Object
+
+	references []snapshotReference
+}
+
+// SnapshotList is a page of snapshots.
+type SnapshotList struct {
+	Snapshots  []Snapshot
+	TotalCount int
+	NextPage   int
+}
+
+// snapshotMeta is the persisted form of a Snapshot without its objects,
+// stored apart so that snapshots can be listed without reading them whole.
+type snapshotMeta struct {
+	CreatedAt    int32  `json:"created_at"`
+	ExpectedHash string `json:"expected_hash,omitempty"`
+	ObjectCount  int    `json:"object_count"`
+}
+
+// snapshotData is the persisted form of the objects of a Snapshot.
+type snapshotData struct {
+	Objects    []snapshotObject    `json:"objects"`
+	References []snapshotReference `json:"references,omitempty"`
+}
+
+// snapshotObject is the persisted form of an object of a Snapshot.
+// Object holds the object as wrapped by wrapObject.
+type snapshotObject struct {
+	Type   model.Type      `json:"type"`
+	Object json.RawMessage `json:"object"`
+}
+
+// snapshotReference is a foreign index row of an object of a Snapshot that is
+// managed outside of the indexes of the object, e.g.: the membership of a
+// consumer in a consumer group.
+type snapshotReference struct {
+	ForeignType model.Type `json:"foreign_type"`
+	ForeignID   string     `json:"foreign_id"`
+	Type        model.Type `json:"type"`
+	ID          string     `json:"id"`
+}
+
+// CreateSnapshot saves the objects of the given types under snapshot.Name.
+// The objects are read within a single transaction and set on snapshot.
+// ErrConstraint is returned if a snapshot with the same name exists.
+func (s *ObjectStore) CreateSnapshot(ctx context.Context, snapshot *Snapshot,
+	types []model.Type,
+) error {
+	ctx, cancel := context.WithTimeout(ctx, DefaultOperationTimeout)
+	defer cancel()
+	if snapshot.Name == "" {
+		return fmt.Errorf("no snapshot name specified")
+	}
+	return s.withTx(ctx, func(tx persistence.Tx) error {
+		var objects []model.Object
+		var values []snapshotObject
+		byTypeID := map[model.Type]map[string]model.Object{}
+		for _, typ := range types {
+			typeObjects, err := s.listAll(ctx, tx, typ)
+			if err != nil {
+				return err
+			}
+			for _, object := range typeObjects {
+				value, err := wrapObject(object, 0, s.keyring)
+				if err != nil {
+					return err
+				}
+				values = append(values, snapshotObject{Type: typ, Object: value})
+			}
+			objects = append(objects, typeObjects...)
+			byTypeID[typ] = objectsByID(typeObjects)
+		}
+		references, err := s.unmanagedReferences(ctx, tx, byTypeID)
+		if err != nil {
+			return err
+		}
+		data := snapshotData{Objects: values}
+		for _, key := range sortedKeys(references) {
+			data.References = append(data.References, references[key])
+		}
+
+		meta := snapshotMeta{
+			CreatedAt:    int32(time.Now().Unix()),
+			ExpectedHash: snapshot.ExpectedHash,
+			ObjectCount:  len(objects),
+		}
+		metaValue, err := json.Marshal(meta)
+		if err != nil {
+			return fmt.Errorf("json marshal snapshot: %w", err)
+		}
+		dataValue, err := json.Marshal(data)
+		if err != nil {
+			return fmt.Errorf("json marshal snapshot objects: %w", err)
+		}
+		err = tx.Insert(ctx, s.snapshotMetaKey(snapshot.Name), metaValue)
+		if err == persistence.ErrUniqueViolation {
+			return ErrConstraint{
+				Index: model.Index{
+					Name:      "name",
+					FieldName: "name",
+					Type:      model.IndexUnique,
+					Value:     snapshot.Name,
+				},
+				Message: "snapshot already exists",
+			}
+		}
+		if err != nil {
+			return err
+		}
+		if err := tx.Put(ctx, s.snapshotDataKey(snapshot.Name), dataValue); err != nil {
+			return err
+		}
+
+		snapshot.CreatedAt = meta.CreatedAt
+		snapshot.ObjectCount = meta.ObjectCount
+		snapshot.Objects = objects
+		return nil
+	})
+}
+
+// ListSnapshots lists the snapshots ordered by name, without their objects.
+func (s *ObjectStore) ListSnapshots(ctx context.Context,
+	opts ...ListOptsFunc,
+) (SnapshotList, error) {
+	ctx, cancel := context.WithTimeout(ctx, DefaultOperationTimeout)
+	defer cancel()
+	opt, err := NewListOpts(opts...)
+	if err != nil {
+		return SnapshotList{}, err
+	}
+	prefix := s.snapshotMetaKey("")
+	listResult, err := s.store.List(ctx, prefix, getPersistenceListOptions(opt))
+	if err != nil {
+		return SnapshotList{}, err
+	}
+	res := SnapshotList{TotalCount: listResult.TotalCount}
+	if toLastPage(opt.PageSize, listResult.TotalCount) > opt.Page {
+		res.NextPage = opt.Page + 1
+	}
+	for _, kv := range listResult.KVList {
+		snapshot, err := unwrapSnapshotMeta(strings.TrimPrefix(string(kv.Key), prefix), kv.Value)
+		if err != nil {
+			return SnapshotList{}, err
+		}
+		res.Snapshots = append(res.Snapshots, snapshot)
+	}
+	return res, nil
+}
+
+// ReadSnapshot reads the snapshot with name along with its objects.
+func (s *ObjectStore) ReadSnapshot(ctx context.Context, name string) (Snapshot, error) {
+	ctx, cancel := context.WithTimeout(ctx, DefaultOperationTimeout)
+	defer cancel()
+	return s.readSnapshot(ctx, s.store, name)
+}
+
+// DeleteSnapshot deletes the snapshot with name.
+func (s *ObjectStore) DeleteSnapshot(ctx context.Context, name string) error {
+	ctx, cancel := context.WithTimeout(ctx, DefaultOperationTimeout)
+	defer cancel()
+	return s.withTx(ctx, func(tx persistence.Tx) error {
+		err := tx.Delete(ctx, s.snapshotMetaKey(name))
+		if err != nil {
+			if errors.As(err, &persistence.ErrNotFound{}) {
+				return ErrNotFound
+			}
+			return err
+		}
+		return tx.Delete(ctx, s.snapshotDataKey(name))
+	})
+}
+
+// RestoreSnapshot replaces the objects of the given types with the ones of
+// the snapshot with name, within a single transaction emitting a single
+// update event. Objects that are identical in the snapshot and the store
+// are left untouched.
+// Types must be ordered such that referenced types come before the types
+// referencing them.
+func (s *ObjectStore) RestoreSnapshot(ctx context.Context, name string,
+	types []model.Type,
+) error {
+	ctx, cancel := context.WithTimeout(ctx, DefaultOperationTimeout)
+	defer cancel()
+	return s.withTx(ctx, func(tx persistence.Tx) error {
+		snapshot, err := s.readSnapshot(ctx, tx, name)
+		if err != nil {
+			return err
+		}
+		snapshotObjects := map[model.Type]map[string]model.Object{}
+		for _, object := range snapshot.Objects {
+			if snapshotObjects[object.Type()] == nil {
+				snapshotObjects[object.Type()] = map[string]model.Object{}
+			}
+			snapshotObjects[object.Type()][object.ID()] = object
+		}
+		currentObjects := map[model.Type]map[string]model.Object{}
+		restoredObjects := map[model.Type]map[string]model.Object{}
+		for _, typ := range types {
+			objects, err := s.listAll(ctx, tx, typ)
+			if err != nil {
+				return err
+			}
+			currentObjects[typ] = objectsByID(objects)
+			restoredObjects[typ] = snapshotObjects[typ]
+		}
+
+		batch := &batchTx{Tx: tx}
+		// Delete the referencing objects first. Objects may already have
+		// been removed by the cascading delete of the objects they refer to.
+		for i := len(types) - 1; i >= 0; i-- {
+			typ := types[i]
+			for id := range currentObjects[typ] {
+				if _, ok := snapshotObjects[typ][id]; ok {
+					continue
+				}
+				err := s.delete(ctx, batch, typ, id, &DeleteOpts{})
+				if err != nil && err != ErrNotFound {
+					return err
+				}
+			}
+		}
+		var changed []model.Object
+		for _, object := range snapshot.Objects {
+			if _, ok := currentObjects[object.Type()]; !ok {
+				continue
+			}
+			current, ok := currentObjects[object.Type()][object.ID()]
+			if ok && proto.Equal(current.Resource(), object.Resource()) {
+				continue
+			}
+			changed = append(changed, object)
+		}
+		// The indexes of the changed objects are deleted before any of them
+		// is written, for objects to be able to swap unique values, e.g.:
+		// names. Objects may have been deleted along with the objects they
+		// refer to.
+		existing := map[model.Object]bool{}
+		for _, object := range changed {
+			current, err := model.NewObject(object.Type())
+			if err != nil {
+				return err
+			}
+			err = s.readByTypeID(ctx, tx, object.Type(), object.ID(), current)
+			if err == ErrNotFound {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			if err := s.deleteIndexes(ctx, batch, current, false); err != nil {
+				return err
+			}
+			existing[object] = true
+		}
+		for _, object := range changed {
+			opt := &CreateOpts{indexesDeleted: existing[object]}
+			if err := s.upsert(ctx, batch, object, opt); err != nil {
+				return err
+			}
+		}
+		if err := s.restoreReferences(ctx, batch, snapshot.references,
+			restoredObjects); err != nil {
+			return err
+		}
+		if !batch.updated {
+			return nil
+		}
+		return s.writeUpdateEvent(ctx, tx)
+	})
+}
+
+// restoreReferences makes the references of the restored objects that are
+// managed outside of their indexes match the references of a snapshot.
+// References to objects that no longer exist are not restored.
+func (s *ObjectStore) restoreReferences(ctx context.Context, batch *batchTx,
+	references []snapshotReference, restored map[model.Type]map[string]model.Object,
+) error {
+	current, err := s.unmanagedReferences(ctx, batch, restored)
+	if err != nil {
+		return err
+	}
+	wanted := map[string]snapshotReference{}
+	for _, ref := range references {
+		if _, ok := restored[ref.Type][ref.ID]; ok {
+			wanted[s.foreignIndexKey(ref.ForeignType, ref.ForeignID, ref.Type, ref.ID)] = ref
+		}
+	}
+	for _, key := range sortedKeys(current) {
+		if _, ok := wanted[key]; ok {
+			continue
+		}
+		if err := batch.Delete(ctx, key); err != nil {
+			return err
+		}
+		batch.updated = true
+	}
+	for _, key := range sortedKeys(wanted) {
+		if _, ok := current[key]; ok {
+			continue
+		}
+		ref := wanted[key]
+		foreignKey, err := s.genID(ref.ForeignType, ref.ForeignID)
+		if err != nil {
+			return err
+		}
+		if _, err := batch.Get(ctx, foreignKey); err != nil {
+			if errors.As(err, &persistence.ErrNotFound{}) {
+				continue
+			}
+			return err
+		}
+		value, err := wrapForeignIndex()
+		if err != nil {
+			return err
+		}
+		if err := batch.Put(ctx, key, value); err != nil {
+			return err
+		}
+		batch.updated = true
+	}
+	return nil
+}
+
+// unmanagedReferences returns the foreign index rows of objects that are not
+// rendered out of the indexes of the objects, by key.
+func (s *ObjectStore) unmanagedReferences(ctx context.Context, tx persistence.Tx,
+	objects map[model.Type]map[string]model.Object,
+) (map[string]snapshotReference, error) {
+	prefix := s.clusterKey("ix/f/")
+	listResult, err := getFullList(ctx, tx, prefix)
+	if err != nil {
+		return nil, err
+	}
+	res := map[string]snapshotReference{}
+	for _, kv := range listResult.KVList {
+		key := string(kv.Key)
+		parts := strings.Split(strings.TrimPrefix(key, prefix), "/")
+		if len(parts) != 4 {
+			continue
+		}
+		object, ok := objects[model.Type(parts[2])][parts[3]]
+		if !ok {
+			continue
+		}
+		managed, err := s.managesReference(object, key)
+		if err != nil {
+			return nil, err
+		}
+		if !managed {
+			res[key] = snapshotReference{
+				ForeignType: model.Type(parts[0]),
+				ForeignID:   parts[1],
+				Type:        model.Type(parts[2]),
+				ID:          parts[3],
+			}
+		}
+	}
+	return res, nil
+}
+
+// managesReference returns true if the foreign index row with key is
+// rendered out of the indexes of object.
+func (s *ObjectStore) managesReference(object model.Object, key string) (bool, error) {
+	for _, index := range object.Indexes() {
+		if index.Type != model.IndexForeign || index.Action != model.IndexActionManaged {
+			continue
+		}
+		indexKey, _, err := s.indexKV(index, object)
+		if err != nil {
+			return false, err
+		}
+		if indexKey == key {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *ObjectStore) readSnapshot(ctx context.Context, tx persistence.CRUD,
+	name string,
+) (Snapshot, error) {
+	metaValue, err := tx.Get(ctx, s.snapshotMetaKey(name))
+	if err != nil {
+		if errors.As(err, &persistence.ErrNotFound{}) {
+			return Snapshot{}, ErrNotFound
+		}
+		return Snapshot{}, err
+	}
+	snapshot, err := unwrapSnapshotMeta(name, metaValue)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	dataValue, err := tx.Get(ctx, s.snapshotDataKey(name))
+	if err != nil {
+		return Snapshot{}, err
+	}
+	data, err := unwrapSnapshotData(dataValue)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	snapshot.references = data.References
+	for _, value := range data.Objects {
+		object, err := model.NewObject(value.Type)
+		if err != nil {
+			return Snapshot{}, err
+		}
+		if _, err := unwrapObject(value.Object, object, s.keyring); err != nil {
+			return Snapshot{}, err
+		}
+		snapshot.Objects = append(snapshot.Objects, object)
+	}
+	return snapshot, nil
+}
+
+// listAll lists all objects of typ within tx.
+func (s *ObjectStore) listAll(ctx context.Context, tx persistence.Tx,
+	typ model.Type,
+) ([]model.Object, error) {
+	listResult, err := getFullList(ctx, tx, s.listKey(typ))
+	if err != nil {
+		return nil, err
+	}
+	objects := make([]model.Object, 0, len(listResult.KVList))
+	for _, kv := range listResult.KVList {
+		object, err := model.NewObject(typ)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := unwrapObject(kv.Value, object, s.keyring); err != nil {
+			return nil, err
+		}
+		objects = append(objects, object)
+	}
+	return objects, nil
+}
+
+func (s *ObjectStore) snapshotMetaKey(name string) string {
+	return s.clusterKey("s/meta/" + name)
+}
+
+func (s *ObjectStore) snapshotDataKey(name string) string {
+	return s.clusterKey("s/data/" + name)
+}
+
+func unwrapSnapshotData(value []byte) (snapshotData, error) {
+	var data snapshotData
+	// Snapshots taken before references were saved only hold objects.
+	if bytes.HasPrefix(value, []byte("[")) {
+		if err := json.Unmarshal(value, &data.Objects); err != nil {
+			return snapshotData{}, fmt.Errorf("json unmarshal snapshot objects: %w", err)
+		}
+		return data, nil
+	}
+	if err := json.Unmarshal(value, &data); err != nil {
+		return snapshotData{}, fmt.Errorf("json unmarshal snapshot objects: %w", err)
+	}
+	return data, nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func objectsByID(objects []model.Object) map[string]model.Object {
+	res := make(map[string]model.Object, len(objects))
+	for _, object := range objects {
+		res[object.ID()] = object
+	}
+	return res
+}
+
+func unwrapSnapshotMeta(name string, value []byte) (Snapshot, error) {
+	var meta snapshotMeta
+	if err := json.Unmarshal(value, &meta); err != nil {
+		return Snapshot{}, fmt.Errorf("json unmarshal snapshot: %w", err)
+	}
+	return Snapshot{
+		Name:         name,
+		CreatedAt:    meta.CreatedAt,
+		ExpectedHash: meta.ExpectedHash,
+		ObjectCount:  meta.ObjectCount,
+	}, nil
+}
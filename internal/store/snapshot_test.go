@@ -0,0 +1,204 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	v1 "github.com/kong/koko/internal/gen/grpc/kong/admin/model/v1"
+	"github.com/kong/koko/internal/log"
+	"github.com/kong/koko/internal/model"
+	"github.com/kong/koko/internal/resource"
+	"github.com/kong/koko/internal/store/event"
+	"github.com/kong/koko/internal/test/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshot(t *testing.T) {
+	persister, err := util.GetPersister(t)
+	require.Nil(t, err)
+	s := New(persister, log.Logger).ForCluster(DefaultCluster)
+	ctx := context.Background()
+	types := []model.Type{resource.TypeService, resource.TypeRoute}
+
+	readEvent := func(t *testing.T) string {
+		e := event.New()
+		require.Nil(t, s.Read(ctx, e, GetByID(event.ID)))
+		return e.StoreEvent.Value
+	}
+	newService := func(id, host string) resource.Service {
+		svc := resource.NewService()
+		svc.Service = &v1.Service{Id: id, Name: "s-" + id, Host: host}
+		return svc
+	}
+	readHost := func(t *testing.T, id string) string {
+		svc := resource.NewService()
+		err := s.Read(ctx, svc, GetByID(id))
+		if err == ErrNotFound {
+			return ""
+		}
+		require.Nil(t, err)
+		return svc.Service.Host
+	}
+
+	sid1, sid2, rid := uuid.NewString(), uuid.NewString(), uuid.NewString()
+	require.Nil(t, s.Create(ctx, newService(sid1, "a.com")))
+	require.Nil(t, s.Create(ctx, newService(sid2, "b.com")))
+	route := resource.NewRoute()
+	route.Route = &v1.Route{
+		Id:      rid,
+		Name:    "r0",
+		Hosts:   []string{"example.com"},
+		Service: &v1.Service{Id: sid2},
+	}
+	require.Nil(t, s.Create(ctx, route))
+
+	t.Run("creates a snapshot", func(t *testing.T) {
+		snapshot := Snapshot{Name: "before", ExpectedHash: "abc"}
+		require.Nil(t, s.CreateSnapshot(ctx, &snapshot, types))
+		require.Equal(t, 3, snapshot.ObjectCount)
+		require.Len(t, snapshot.Objects, 3)
+		require.NotZero(t, snapshot.CreatedAt)
+	})
+	t.Run("snapshot names are unique", func(t *testing.T) {
+		err := s.CreateSnapshot(ctx, &Snapshot{Name: "before"}, types)
+		require.IsType(t, ErrConstraint{}, err)
+	})
+	t.Run("reads a snapshot", func(t *testing.T) {
+		snapshot, err := s.ReadSnapshot(ctx, "before")
+		require.Nil(t, err)
+		require.Equal(t, "abc", snapshot.ExpectedHash)
+		require.Equal(t, 3, snapshot.ObjectCount)
+		require.Equal(t, rid, snapshot.Objects[2].ID())
+
+		_, err = s.ReadSnapshot(ctx, "unknown")
+		require.Equal(t, ErrNotFound, err)
+	})
+	t.Run("restores a snapshot", func(t *testing.T) {
+		require.Nil(t, s.Upsert(ctx, newService(sid1, "c.com")))
+		require.Nil(t, s.Delete(ctx, DeleteByType(resource.TypeService),
+			DeleteByID(sid2)))
+		sid3 := uuid.NewString()
+		require.Nil(t, s.Create(ctx, newService(sid3, "d.com")))
+		eventBefore := readEvent(t)
+
+		require.Nil(t, s.RestoreSnapshot(ctx, "before", types))
+		require.Equal(t, "a.com", readHost(t, sid1))
+		require.Equal(t, "b.com", readHost(t, sid2))
+		require.Empty(t, readHost(t, sid3))
+		route := resource.NewRoute()
+		require.Nil(t, s.Read(ctx, route, GetByID(rid)))
+		require.Equal(t, sid2, route.Route.Service.Id)
+		require.NotEqual(t, eventBefore, readEvent(t))
+	})
+	t.Run("restoring an unchanged snapshot is a no-op", func(t *testing.T) {
+		eventBefore := readEvent(t)
+		require.Nil(t, s.RestoreSnapshot(ctx, "before", types))
+		require.Equal(t, eventBefore, readEvent(t))
+	})
+	t.Run("restoring an unknown snapshot fails", func(t *testing.T) {
+		require.Equal(t, ErrNotFound, s.RestoreSnapshot(ctx, "unknown", types))
+	})
+	t.Run("restores swapped unique names", func(t *testing.T) {
+		require.Nil(t, s.CreateSnapshot(ctx, &Snapshot{Name: "names"}, types))
+		rename := func(id, name string) {
+			svc := resource.NewService()
+			require.Nil(t, s.Read(ctx, svc, GetByID(id)))
+			svc.Service.Name = name
+			require.Nil(t, s.Upsert(ctx, svc))
+		}
+		rename(sid1, "tmp")
+		rename(sid2, "s-"+sid1)
+		rename(sid1, "s-"+sid2)
+
+		require.Nil(t, s.RestoreSnapshot(ctx, "names", types))
+		for _, id := range []string{sid1, sid2} {
+			svc := resource.NewService()
+			require.Nil(t, s.Read(ctx, svc, GetByName("s-"+id)))
+			require.Equal(t, id, svc.ID())
+		}
+		require.Nil(t, s.DeleteSnapshot(ctx, "names"))
+	})
+	t.Run("lists and deletes snapshots", func(t *testing.T) {
+		require.Nil(t, s.CreateSnapshot(ctx, &Snapshot{Name: "after"}, types))
+		list, err := s.ListSnapshots(ctx)
+		require.Nil(t, err)
+		require.Equal(t, 2, list.TotalCount)
+		require.Equal(t, "after", list.Snapshots[0].Name)
+		require.Equal(t, "before", list.Snapshots[1].Name)
+		require.Nil(t, list.Snapshots[0].Objects)
+
+		require.Nil(t, s.DeleteSnapshot(ctx, "after"))
+		require.Equal(t, ErrNotFound, s.DeleteSnapshot(ctx, "after"))
+		list, err = s.ListSnapshots(ctx)
+		require.Nil(t, err)
+		require.Equal(t, 1, list.TotalCount)
+	})
+}
+
+func TestSnapshotConsumerGroupMembers(t *testing.T) {
+	persister, err := util.GetPersister(t)
+	require.Nil(t, err)
+	s := New(persister, log.Logger).ForCluster(DefaultCluster)
+	ctx := context.Background()
+	types := []model.Type{resource.TypeConsumerGroup, resource.TypeConsumer}
+
+	// Consumer groups are an enterprise feature, failing validation.
+	groupID := uuid.NewString()
+	groupKey, err := s.genID(resource.TypeConsumerGroup, groupID)
+	require.Nil(t, err)
+	require.Nil(t, persister.Put(ctx, groupKey, []byte(
+		`{"type":3,"revision":1,"object":{"id":"`+groupID+`","name":"group"}}`)))
+	newConsumer := func(username string) string {
+		consumer := resource.NewConsumer()
+		consumer.Consumer = &v1.Consumer{Id: uuid.NewString(), Username: username}
+		require.Nil(t, s.Create(ctx, consumer))
+		return consumer.ID()
+	}
+	memberKey := func(consumerID string) string {
+		return s.foreignIndexKey(resource.TypeConsumer, consumerID,
+			resource.TypeConsumerGroup, groupID)
+	}
+	addMember := func(consumerID string) {
+		value, err := wrapForeignIndex()
+		require.Nil(t, err)
+		require.Nil(t, persister.Put(ctx, memberKey(consumerID), value))
+	}
+	isMember := func(consumerID string) bool {
+		_, err := persister.Get(ctx, memberKey(consumerID))
+		return err == nil
+	}
+	cid1, cid2, cid3 := newConsumer("c1"), newConsumer("c2"), newConsumer("c3")
+	addMember(cid1)
+	addMember(cid2)
+
+	snapshot := Snapshot{Name: "members"}
+	require.Nil(t, s.CreateSnapshot(ctx, &snapshot, types))
+	require.Equal(t, 4, snapshot.ObjectCount)
+
+	require.Nil(t, s.Delete(ctx, DeleteByType(resource.TypeConsumer), DeleteByID(cid1)))
+	require.False(t, isMember(cid1))
+	require.Nil(t, persister.Delete(ctx, memberKey(cid2)))
+	addMember(cid3)
+	require.Nil(t, s.RestoreSnapshot(ctx, "members", types))
+	require.True(t, isMember(cid1))
+	require.True(t, isMember(cid2))
+	require.False(t, isMember(cid3))
+
+	t.Run("restoring an unchanged snapshot is a no-op", func(t *testing.T) {
+		e := event.New()
+		require.Nil(t, s.Read(ctx, e, GetByID(event.ID)))
+		require.Nil(t, s.RestoreSnapshot(ctx, "members", types))
+		after := event.New()
+		require.Nil(t, s.Read(ctx, after, GetByID(event.ID)))
+		require.Equal(t, e.StoreEvent.Value, after.StoreEvent.Value)
+	})
+	t.Run("reads snapshots taken without references", func(t *testing.T) {
+		require.Nil(t, persister.Put(ctx, s.snapshotMetaKey("legacy"),
+			[]byte(`{"created_at":1,"object_count":0}`)))
+		require.Nil(t, persister.Put(ctx, s.snapshotDataKey("legacy"), []byte(`[]`)))
+		snapshot, err := s.ReadSnapshot(ctx, "legacy")
+		require.Nil(t, err)
+		require.Empty(t, snapshot.Objects)
+	})
+}
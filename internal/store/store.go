@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/kong/koko/internal/crypto"
 	nonPublic "github.com/kong/koko/internal/gen/grpc/kong/nonpublic/v1"
 	"github.com/kong/koko/internal/model"
 	"github.com/kong/koko/internal/persistence"
@@ -26,6 +27,9 @@ const DefaultCluster = "default"
 var (
 	errNoObject = fmt.Errorf("no object")
 	ErrNotFound = fmt.Errorf("not found")
+	// ErrInvalidPageToken is returned when listing with a page token that
+	// was not returned by a previous list call.
+	ErrInvalidPageToken = fmt.Errorf("invalid page token")
 )
 
 type Store interface {
@@ -50,11 +54,88 @@ type Store interface {
 	Read(context.Context, model.Object, ...ReadOptsFunc) error
 	Delete(context.Context, ...DeleteOptsFunc) error
 	List(context.Context, model.ObjectList, ...ListOptsFunc) error
+
+	// ApplyBatch applies all operations in order within a single transaction.
+	// Either all operations are applied or none of them, and a single update
+	// event is emitted for the whole batch.
+	ApplyBatch(context.Context, []BatchOperation) error
+
+	// ListHistory lists the recorded writes of an object, oldest first.
+	ListHistory(ctx context.Context, typ model.Type, id string, opts ...ListOptsFunc) (HistoryList, error)
+	// ReadHistory reads the recorded write of an object that produced the
+	// given revision.
+	ReadHistory(ctx context.Context, typ model.Type, id string, revision uint64) (HistoryEntry, error)
+
+	// ListReferrers lists the objects referencing an object through a foreign
+	// index, recursively up to the given depth.
+	ListReferrers(ctx context.Context, typ model.Type, id string, depth int) ([]Referrer, error)
+	// ListForeignKeys lists the foreign keys of the objects of type typ to
+	// the objects of type foreignType.
+	ListForeignKeys(ctx context.Context, typ, foreignType model.Type, opts ...ListOptsFunc) (ForeignKeyList, error)
+
+	// CheckIntegrity checks the objects of every cluster against the index
+	// rows of the store, repairing the index rows if requested.
+	CheckIntegrity(ctx context.Context, repair bool) (IntegrityReport, error)
+	// CheckClusterIntegrity is like CheckIntegrity, but only checks the
+	// objects of the cluster of the store.
+	CheckClusterIntegrity(ctx context.Context, repair bool) (IntegrityReport, error)
+
+	// CreateSnapshot saves the objects of the given types under the name of
+	// the snapshot.
+	CreateSnapshot(ctx context.Context, snapshot *Snapshot, types []model.Type) error
+	// ListSnapshots lists the snapshots without their objects.
+	ListSnapshots(ctx context.Context, opts ...ListOptsFunc) (SnapshotList, error)
+	ReadSnapshot(ctx context.Context, name string) (Snapshot, error)
+	DeleteSnapshot(ctx context.Context, name string) error
+	// RestoreSnapshot atomically replaces the objects of the given types with
+	// the objects of a snapshot.
+	RestoreSnapshot(ctx context.Context, name string, types []model.Type) error
+
+	// CreateCluster records a cluster. Clusters are shared by the stores of
+	// every cluster.
+	CreateCluster(ctx context.Context, cluster *ClusterInfo) error
+	ReadCluster(ctx context.Context, id string) (ClusterInfo, error)
+	ListClusters(ctx context.Context, opts ...ListOptsFunc) (ClusterList, error)
+	// DeleteCluster deletes a cluster along with all of its objects.
+	DeleteCluster(ctx context.Context, id string) error
+
+	// Usage returns the number of objects of every type that the cluster of
+	// the store holds, along with their quotas, ordered by type.
+	Usage(ctx context.Context) ([]TypeUsage, error)
+}
+
+// ErrEventsNotSupported is returned by EventWatcher when events cannot be
+// pushed by the underlying persister and must be polled for instead.
+var ErrEventsNotSupported = errors.New("watching events is not supported")
+
+// nodeType is the type of nodes, whose writes are not recorded as update
+// events.
+const nodeType = model.Type("node")
+
+// EventWatcher is implemented by stores that can push update events as they
+// are recorded.
+type EventWatcher interface {
+	// WatchEvents returns a channel that receives a value whenever an update
+	// event is recorded for the cluster of the store. The channel is closed
+	// once ctx is done or the underlying subscription fails.
+	// It returns ErrEventsNotSupported if events must be polled for.
+	WatchEvents(ctx context.Context) (<-chan struct{}, error)
+}
+
+// ClusterEventWatcher is implemented by stores that can push the update
+// events of every cluster as they are recorded.
+type ClusterEventWatcher interface {
+	// WatchClusterEvents is like EventWatcher.WatchEvents, for the update
+	// events of every cluster.
+	WatchClusterEvents(ctx context.Context) (<-chan struct{}, error)
 }
 
 type objectStoreOpts struct {
-	logger *zap.Logger
-	store  persistence.Persister
+	logger       *zap.Logger
+	store        persistence.Persister
+	historyLimit int
+	keyring      *crypto.Keyring
+	quotas       *Quotas
 }
 
 // ObjectStore stores objects.
@@ -73,8 +154,9 @@ func New(persister persistence.Persister, logger *zap.Logger) *ObjectStore {
 	}
 	return &ObjectStore{
 		objectStoreOpts: objectStoreOpts{
-			logger: logger,
-			store:  persister,
+			logger:       logger,
+			store:        persister,
+			historyLimit: DefaultHistoryLimit,
 		},
 	}
 }
@@ -119,7 +201,7 @@ func (s *ObjectStore) Cluster() string {
 }
 
 func (s *ObjectStore) Create(ctx context.Context, object model.Object,
-	_ ...CreateOptsFunc,
+	opts ...CreateOptsFunc,
 ) error {
 	ctx, cancel := context.WithTimeout(ctx, DefaultOperationTimeout)
 	defer cancel()
@@ -130,27 +212,50 @@ func (s *ObjectStore) Create(ctx context.Context, object model.Object,
 		return err
 	}
 
+	opt := NewCreateOpts(opts...)
+	return s.withTx(ctx, func(tx persistence.Tx) error {
+		return s.create(ctx, tx, object, opt)
+	})
+}
+
+func (s *ObjectStore) create(ctx context.Context, tx persistence.Tx,
+	object model.Object, opt *CreateOpts,
+) error {
 	id, err := s.genID(object.Type(), object.ID())
 	if err != nil {
 		return err
 	}
-	value, err := wrapObject(object)
+
+	if err := s.checkID(ctx, tx, object); err != nil {
+		return err
+	}
+	if err := s.checkQuota(ctx, tx, object.Type()); err != nil {
+		return err
+	}
+	revision, err := s.initialRevision(ctx, tx, object.Type(), object.ID())
 	if err != nil {
 		return err
 	}
-
-	return s.withTx(ctx, func(tx persistence.Tx) error {
-		if err := s.checkID(ctx, tx, object); err != nil {
-			return err
-		}
-		if err := s.createIndexes(ctx, tx, object); err != nil {
-			return err
-		}
-		if err := s.updateEvent(ctx, tx, object); err != nil {
-			return err
-		}
-		return tx.Insert(ctx, id, value)
-	})
+	value, err := wrapObject(object, revision, s.keyring)
+	if err != nil {
+		return err
+	}
+	if err := s.createIndexes(ctx, tx, object); err != nil {
+		return err
+	}
+	if err := s.updateEvent(ctx, tx, object); err != nil {
+		return err
+	}
+	if err := tx.Insert(ctx, id, value); err != nil {
+		return err
+	}
+	if err := s.recordHistory(ctx, tx, nil, object, revision); err != nil {
+		return err
+	}
+	if opt.revision != nil {
+		*opt.revision = revision
+	}
+	return nil
 }
 
 func (s *ObjectStore) checkID(ctx context.Context,
@@ -179,7 +284,7 @@ func (s *ObjectStore) checkID(ctx context.Context,
 }
 
 func (s *ObjectStore) Upsert(ctx context.Context, object model.Object,
-	_ ...CreateOptsFunc,
+	opts ...CreateOptsFunc,
 ) error {
 	ctx, cancel := context.WithTimeout(ctx, DefaultOperationTimeout)
 	defer cancel()
@@ -190,68 +295,100 @@ func (s *ObjectStore) Upsert(ctx context.Context, object model.Object,
 		return err
 	}
 
-	value, err := wrapObject(object)
+	opt := NewCreateOpts(opts...)
+	return s.withTx(ctx, func(tx persistence.Tx) error {
+		return s.upsert(ctx, tx, object, opt)
+	})
+}
+
+func (s *ObjectStore) upsert(ctx context.Context, tx persistence.Tx,
+	object model.Object, opt *CreateOpts,
+) error {
+	// 1.delete old indexes if they exist
+	// no need to delete the object itself since it will be overwritten
+	// anyway
+	oldObject, err := model.NewObject(object.Type())
 	if err != nil {
 		return err
 	}
-
-	return s.withTx(ctx, func(tx persistence.Tx) error {
-		// 1.delete old indexes if they exist
-		// no need to delete the object itself since it will be overwritten
-		// anyway
-		oldObject, err := model.NewObject(object.Type())
-		if err != nil {
-			return err
-		}
-		err = s.readByTypeID(ctx, tx, object.Type(), object.ID(), oldObject)
-		switch err {
-		case nil:
-			// TODO(hbagdi): perf: drop and rebuild index only if changed
-			// object exists, delete the indexes
-			oldCreatedAtTS := getCreationTimestamp(oldObject.Resource())
-			if oldCreatedAtTS != 0 {
-				setCreationTimestamp(object.Resource(), oldCreatedAtTS)
-				value, err = wrapObject(object)
-				if err != nil {
-					return err
-				}
+	oldRevision, err := s.readRevisionByTypeID(ctx, tx, object.Type(), object.ID(), oldObject)
+	if err == ErrNotFound && opt.expectedRevision != 0 {
+		return ErrRevisionMismatch{Expected: opt.expectedRevision}
+	}
+	var revision uint64
+	switch err {
+	case nil:
+		revision = oldRevision + 1
+		if opt.expectedRevision != 0 && opt.expectedRevision != oldRevision {
+			return ErrRevisionMismatch{
+				Expected: opt.expectedRevision,
+				Actual:   oldRevision,
 			}
+		}
+		// TODO(hbagdi): perf: drop and rebuild index only if changed
+		// object exists, delete the indexes
+		oldCreatedAtTS := getCreationTimestamp(oldObject.Resource())
+		if oldCreatedAtTS != 0 {
+			setCreationTimestamp(object.Resource(), oldCreatedAtTS)
+		}
 
-			// Handle deleting explicit indexes when asked, otherwise, default to the managed behavior.
-			objectForIndexDeletions := oldObject
-			if _, ok := model.Indexes(object.Indexes()).Actions()[model.IndexActionRemove]; ok {
-				objectForIndexDeletions = object
-			}
+		// Handle deleting explicit indexes when asked, otherwise, default to the managed behavior.
+		objectForIndexDeletions := oldObject
+		if _, ok := model.Indexes(object.Indexes()).Actions()[model.IndexActionRemove]; ok {
+			objectForIndexDeletions = object
+		}
 
+		if !opt.indexesDeleted {
 			if err := s.deleteIndexes(ctx, tx, objectForIndexDeletions, false); err != nil {
 				return err
 			}
-
-		case ErrNotFound:
-			// object doesn't exist, move on
-
-		default:
-			// some other error
-			return err
-		}
-
-		// 2. create new indexes
-		if err := s.createIndexes(ctx, tx, object); err != nil {
-			return err
 		}
 
-		// 3. fire off new update event
-		if err := s.updateEvent(ctx, tx, object); err != nil {
+	case ErrNotFound:
+		// object doesn't exist, move on
+		oldObject = nil
+		if err := s.checkQuota(ctx, tx, object.Type()); err != nil {
 			return err
 		}
-
-		// 4. write the object
-		key, err := s.genID(object.Type(), object.ID())
+		revision, err = s.initialRevision(ctx, tx, object.Type(), object.ID())
 		if err != nil {
 			return err
 		}
-		return tx.Put(ctx, key, value)
-	})
+
+	default:
+		// some other error
+		return err
+	}
+
+	// 2. create new indexes
+	if err := s.createIndexes(ctx, tx, object); err != nil {
+		return err
+	}
+
+	// 3. fire off new update event
+	if err := s.updateEvent(ctx, tx, object); err != nil {
+		return err
+	}
+
+	// 4. write the object
+	key, err := s.genID(object.Type(), object.ID())
+	if err != nil {
+		return err
+	}
+	value, err := wrapObject(object, revision, s.keyring)
+	if err != nil {
+		return err
+	}
+	if err := tx.Put(ctx, key, value); err != nil {
+		return err
+	}
+	if err := s.recordHistory(ctx, tx, oldObject, object, revision); err != nil {
+		return err
+	}
+	if opt.revision != nil {
+		*opt.revision = revision
+	}
+	return nil
 }
 
 func (s *ObjectStore) updateEvent(ctx context.Context, tx persistence.Tx,
@@ -259,24 +396,79 @@ func (s *ObjectStore) updateEvent(ctx context.Context, tx persistence.Tx,
 ) error {
 	// TODO(fero): create function on interface to determine if updateEvent should be ignored.
 	// this is a stop gap since no other object currently is required.
-	if object.Type() == "node" {
+	if object.Type() == nodeType {
+		return nil
+	}
+	if batch, ok := tx.(*batchTx); ok {
+		// emitted once the whole batch is applied
+		batch.updated = true
 		return nil
 	}
-	event := event.Event{
+	return s.writeUpdateEvent(ctx, tx)
+}
+
+func (s *ObjectStore) writeUpdateEvent(ctx context.Context, tx persistence.Tx) error {
+	updateEvent := event.Event{
 		StoreEvent: &nonPublic.StoreEvent{
 			Id:    s.clusterKey(event.ID),
 			Value: s.clock(),
 		},
 	}
-	value, err := wrapObject(event)
+	value, err := wrapObject(updateEvent, 0, s.keyring)
 	if err != nil {
 		return fmt.Errorf("proto marshal update event: %v", err)
 	}
-	id, err := s.genID(event.Type(), event.ID())
+	id, err := s.genID(updateEvent.Type(), updateEvent.ID())
 	if err != nil {
 		return err
 	}
-	return tx.Put(ctx, id, value)
+	if err := tx.Put(ctx, id, value); err != nil {
+		return err
+	}
+	if notifier, ok := tx.(persistence.Notifier); ok {
+		if err := notifier.Notify(ctx, event.Channel, s.Cluster()); err != nil {
+			return fmt.Errorf("notify update event: %w", err)
+		}
+	}
+	return nil
+}
+
+// WatchEvents implements the EventWatcher interface.
+func (s *ObjectStore) WatchEvents(ctx context.Context) (<-chan struct{}, error) {
+	return s.watchEvents(ctx, s.Cluster())
+}
+
+// WatchClusterEvents implements the ClusterEventWatcher interface.
+func (s *ObjectStore) WatchClusterEvents(ctx context.Context) (<-chan struct{}, error) {
+	return s.watchEvents(ctx, "")
+}
+
+// watchEvents watches the update events of a cluster, or of every cluster
+// when cluster is empty.
+func (s *ObjectStore) watchEvents(ctx context.Context, cluster string) (<-chan struct{}, error) {
+	source, ok := s.store.(persistence.EventSource)
+	if !ok {
+		return nil, ErrEventsNotSupported
+	}
+	notifications, err := source.Listen(ctx, event.Channel)
+	if err != nil {
+		return nil, fmt.Errorf("listen for update events: %w", err)
+	}
+	res := make(chan struct{})
+	go func() {
+		defer close(res)
+		for eventCluster := range notifications {
+			if cluster != "" && eventCluster != cluster {
+				continue
+			}
+			select {
+			case res <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return res, nil
 }
 
 func (s *ObjectStore) clock() string {
@@ -302,65 +494,77 @@ func (s *ObjectStore) Read(ctx context.Context, object model.Object,
 	ctx, cancel := context.WithTimeout(ctx, DefaultOperationTimeout)
 	defer cancel()
 	opt := NewReadOpts(opts...)
+	if opt.primary {
+		ctx = persistence.WithPrimaryReads(ctx)
+	}
+	var revision uint64
+	var err error
 	switch {
 	case opt.id != "":
-		return s.readByTypeID(ctx, s.store, object.Type(), opt.id, object)
+		revision, err = s.readRevisionByTypeID(ctx, s.store, object.Type(), opt.id, object)
 	case opt.name != "":
-		return s.readByName(ctx, s.store, opt.name, object)
+		revision, err = s.readByName(ctx, s.store, opt.name, object)
 	case opt.idxName != "" && opt.idxValue != "":
-		return s.readByIdx(ctx, s.store, opt.idxName, opt.idxValue, object)
+		revision, err = s.readByIdx(ctx, s.store, opt.idxName, opt.idxValue, object)
 	default:
 		return fmt.Errorf("invalid opt")
 	}
+	if err != nil {
+		return err
+	}
+	if opt.revision != nil {
+		*opt.revision = revision
+	}
+	return nil
 }
 
 func (s *ObjectStore) readByIdx(ctx context.Context, tx persistence.CRUD,
 	idxName, idxValue string, object model.Object,
-) error {
+) (uint64, error) {
 	key := s.uniqueIndexKey(object.Type(), idxName, idxValue)
 	value, err := tx.Get(ctx, key)
 	if err != nil {
 		if errors.As(err, &persistence.ErrNotFound{}) {
-			return ErrNotFound
+			return 0, ErrNotFound
 		}
-		return err
+		return 0, err
 	}
 	refID, err := unwrapUniqueIndex(value)
 	if err != nil {
-		return err
+		return 0, err
 	}
-	err = s.readByTypeID(ctx, tx, object.Type(), refID, object)
-	if err != nil {
-		return err
-	}
-	return nil
+	return s.readRevisionByTypeID(ctx, tx, object.Type(), refID, object)
 }
 
 func (s *ObjectStore) readByName(ctx context.Context, tx persistence.CRUD,
 	name string, object model.Object,
-) error {
+) (uint64, error) {
 	return s.readByIdx(ctx, tx, "name", name, object)
 }
 
 func (s *ObjectStore) readByTypeID(ctx context.Context, tx persistence.CRUD,
 	typ model.Type, id string, object model.Object,
 ) error {
+	_, err := s.readRevisionByTypeID(ctx, tx, typ, id, object)
+	return err
+}
+
+// readRevisionByTypeID reads the object and returns its revision.
+func (s *ObjectStore) readRevisionByTypeID(ctx context.Context, tx persistence.CRUD,
+	typ model.Type, id string, object model.Object,
+) (uint64, error) {
 	typeID, err := s.genID(typ, id)
 	if err != nil {
-		return err
+		return 0, err
 	}
 	value, err := tx.Get(ctx, typeID)
 	if err != nil {
 		if errors.As(err, &persistence.ErrNotFound{}) {
-			return ErrNotFound
+			return 0, ErrNotFound
 		}
-		return err
+		return 0, err
 	}
-	err = unwrapObject(value, object)
-	if err != nil {
-		return err
-	}
-	return nil
+	return unwrapObject(value, object, s.keyring)
 }
 
 func (s *ObjectStore) UpdateForeignKeys(
@@ -409,23 +613,48 @@ func (s *ObjectStore) Delete(ctx context.Context,
 	defer cancel()
 	opt := NewDeleteOpts(opts...)
 	return s.withTx(ctx, func(tx persistence.Tx) error {
-		return s.delete(ctx, tx, opt.typ, opt.id)
+		if opt.expectedRevision != 0 {
+			if err := s.checkRevision(ctx, tx, opt.typ, opt.id,
+				opt.expectedRevision); err != nil {
+				return err
+			}
+		}
+		return s.delete(ctx, tx, opt.typ, opt.id, opt)
 	})
 }
 
+// checkRevision returns ErrRevisionMismatch if the object does not have the
+// expected revision.
+func (s *ObjectStore) checkRevision(ctx context.Context, tx persistence.Tx,
+	typ model.Type, id string, expected uint64,
+) error {
+	object, err := model.NewObject(typ)
+	if err != nil {
+		return err
+	}
+	revision, err := s.readRevisionByTypeID(ctx, tx, typ, id, object)
+	if err != nil {
+		return err
+	}
+	if revision != expected {
+		return ErrRevisionMismatch{Expected: expected, Actual: revision}
+	}
+	return nil
+}
+
 func (s *ObjectStore) delete(ctx context.Context, tx persistence.Tx,
-	typ model.Type, id string,
+	typ model.Type, id string, opt *DeleteOpts,
 ) error {
 	object, err := model.NewObject(typ)
 	if err != nil {
 		return err
 	}
-	err = s.readByTypeID(ctx, tx, typ, id, object)
+	revision, err := s.readRevisionByTypeID(ctx, tx, typ, id, object)
 	if err != nil {
 		return err
 	}
 
-	err = s.onDeleteCascade(ctx, tx, object)
+	err = s.onDeleteCascade(ctx, tx, object, opt)
 	if err != nil {
 		return err
 	}
@@ -442,6 +671,9 @@ func (s *ObjectStore) delete(ctx context.Context, tx persistence.Tx,
 	if err := s.deleteIndexes(ctx, tx, object, true); err != nil {
 		return err
 	}
+	if err := s.recordHistory(ctx, tx, object, nil, revision+1); err != nil {
+		return err
+	}
 	return s.updateEvent(ctx, tx, object)
 }
 
@@ -453,18 +685,24 @@ func (s *ObjectStore) List(ctx context.Context, list model.ObjectList, opts ...L
 	if err != nil {
 		return err
 	}
+	if opt.primary {
+		ctx = persistence.WithPrimaryReads(ctx)
+	}
 	if opt != nil && opt.ReferenceType != "" && opt.ReferenceID != "" {
 		return s.referencedList(ctx, list, opt)
 	}
 
-	listResult, err := s.store.List(ctx, s.listKey(typ), getPersistenceListOptions(opt))
+	prefix := s.listKey(typ)
+	persistenceOpts, listed, err := s.persistenceListOptions(prefix, opt)
 	if err != nil {
 		return err
 	}
-	list.SetTotalCount(listResult.TotalCount)
-	lastPage := toLastPage(opt.PageSize, listResult.TotalCount)
-	if lastPage > opt.Page {
-		list.SetNextPage(opt.Page + 1)
+	listResult, err := s.store.List(ctx, prefix, persistenceOpts)
+	if err != nil {
+		return err
+	}
+	if err := s.setListPage(list, prefix, opt, listed, listResult); err != nil {
+		return err
 	}
 	for _, kv := range listResult.KVList {
 		value := kv.Value
@@ -473,10 +711,13 @@ func (s *ObjectStore) List(ctx context.Context, list model.ObjectList, opts ...L
 			return err
 		}
 
-		err = unwrapObject(value, object)
+		revision, err := unwrapObject(value, object, s.keyring)
 		if err != nil {
 			return err
 		}
+		if opt.revisions != nil {
+			opt.revisions[object.ID()] = revision
+		}
 		list.Add(object)
 	}
 	return nil
@@ -484,51 +725,41 @@ func (s *ObjectStore) List(ctx context.Context, list model.ObjectList, opts ...L
 
 func (s *ObjectStore) referencedList(ctx context.Context, list model.ObjectList, opt *ListOpts) error {
 	typ := list.Type()
-	keyPrefix := s.referencedListKey(typ, opt)
-	persistenceOpts := getPersistenceListOptions(opt)
-	listResult, err := s.store.List(ctx, keyPrefix, persistenceOpts)
+	prefix := s.referencedListKey(typ, opt)
+	persistenceOpts, listed, err := s.persistenceListOptions(prefix, opt)
 	if err != nil {
 		return err
 	}
 
-	keyPrefixLen := len(keyPrefix)
-	for _, kv := range listResult.KVList {
-		key := string(kv.Key)
-		value := kv.Value
-		if err := verifyForeignValue(value); err != nil {
-			panic(err)
-		}
+	// The foreign key indexes are listed along with the objects they reference, so that
+	// filtering & pagination are done in the same query. When `opt.ReferenceReverseLookup`
+	// is not true, the <id> of the objects is the suffix of the below key:
+	// `c/.../ix/f/<opt.ReferenceType>/<opt.ReferenceID>/<typ>/<id>`
+	//
+	// When it is true, the <id> is in place of the wildcard operator of the below key:
+	// `c/.../ix/f/<typ>/<id>/<opt.ReferenceType>/<opt.ReferenceID>`
+	persistenceOpts.ReferencedPrefix = s.listKey(typ)
+	listResult, err := s.store.List(ctx, prefix, persistenceOpts)
+	if err != nil {
+		return err
+	}
 
+	for _, kv := range listResult.KVList {
 		object, err := model.NewObject(typ)
 		if err != nil {
 			return err
 		}
 
-		// When `opt.ReferenceReverseLookup` is not true, we're extracting <id> from the below key:
-		// `c/.../ix/f/<typ>/<opt.ReferenceID>/<opt.ReferenceType>/<id>`
-		//
-		// When it is true, we're extracting the <id> from the below key:
-		// `c/.../ix/f/<typ>/<id>/<opt.ReferenceType>/<opt.ReferenceID>`
-		id := key[keyPrefixLen:]
-		if opt.ReferenceReverseLookup {
-			startIdx := strings.Index(keyPrefix, persistence.WildcardOperator)
-			if startIdx < 0 {
-				return errors.New("unable to find wildcard operator in key prefix")
-			}
-			id, _, _ = strings.Cut(key[startIdx:], "/")
-		}
-
-		if err := s.readByTypeID(ctx, s.store, typ, id, object); err != nil {
+		revision, err := unwrapObject(kv.Value, object, s.keyring)
+		if err != nil {
 			return err
 		}
+		if opt.revisions != nil {
+			opt.revisions[object.ID()] = revision
+		}
 		list.Add(object)
 	}
-	list.SetTotalCount(listResult.TotalCount)
-	lastPage := toLastPage(opt.PageSize, list.GetTotalCount())
-	if lastPage > opt.Page {
-		list.SetNextPage(opt.Page + 1)
-	}
-	return nil
+	return s.setListPage(list, prefix, opt, listed, listResult)
 }
 
 func (s *ObjectStore) updateForeignKeysTx(
@@ -538,7 +769,8 @@ func (s *ObjectStore) updateForeignKeysTx(
 	tx persistence.Tx,
 ) error {
 	// Ensure the "parent" entity exists. This is also used to resolve the full object for the caller's use.
-	if err := s.readByTypeID(ctx, tx, obj.Type(), obj.ID(), obj); err != nil {
+	revision, err := s.readRevisionByTypeID(ctx, tx, obj.Type(), obj.ID(), obj)
+	if err != nil {
 		return err
 	}
 
@@ -586,17 +818,29 @@ func (s *ObjectStore) updateForeignKeysTx(
 		return err
 	}
 
+	// The object as of before the update, recorded in its history.
+	oldObject, err := model.NewObject(obj.Type())
+	if err != nil {
+		return err
+	}
+	if err := s.readByTypeID(ctx, tx, obj.Type(), obj.ID(), oldObject); err != nil {
+		return err
+	}
+
 	// Update "parent" entity only so we can update the `updated_at` timestamp.
 	setTSField(obj.Resource(), fieldUpdatedAt, true)
 	id, err := s.genID(obj.Type(), obj.ID())
 	if err != nil {
 		return err
 	}
-	value, err := wrapObject(obj)
+	value, err := wrapObject(obj, revision+1, s.keyring)
 	if err != nil {
 		return err
 	}
-	return tx.Put(ctx, id, value)
+	if err := tx.Put(ctx, id, value); err != nil {
+		return err
+	}
+	return s.recordHistory(ctx, tx, oldObject, obj, revision+1)
 }
 
 func (s *ObjectStore) referencedListKey(typ model.Type, opt *ListOpts) string {
@@ -14,6 +14,7 @@ import (
 	"github.com/kong/koko/internal/log"
 	"github.com/kong/koko/internal/model"
 	"github.com/kong/koko/internal/model/json/validation"
+	"github.com/kong/koko/internal/persistence"
 	"github.com/kong/koko/internal/resource"
 	"github.com/kong/koko/internal/store/event"
 	"github.com/kong/koko/internal/test/util"
@@ -285,6 +286,147 @@ func TestDelete(t *testing.T) {
 		err = s.Read(ctx, resource.NewRoute(), GetByID(rid))
 		require.IsType(t, ErrNotFound, err)
 	})
+	t.Run("deleting an object with dependents by mode", func(t *testing.T) {
+		ctx := context.Background()
+		persister, err := util.GetPersister(t)
+		require.Nil(t, err)
+		s := New(persister, log.Logger).ForCluster(DefaultCluster)
+		sid := uuid.NewString()
+		svc := resource.NewService()
+		svc.Service = &v1.Service{Id: sid, Name: "s1", Host: "foo.com"}
+		require.Nil(t, s.Create(ctx, svc))
+		var rids []string
+		for _, name := range []string{"r0", "r1"} {
+			route := resource.NewRoute()
+			route.Route = &v1.Route{
+				Id:      uuid.NewString(),
+				Name:    name,
+				Hosts:   []string{"example.com"},
+				Service: &v1.Service{Id: sid},
+			}
+			require.Nil(t, s.Create(ctx, route))
+			rids = append(rids, route.ID())
+		}
+
+		err = s.Delete(ctx, DeleteByType(resource.TypeService), DeleteByID(sid),
+			DeleteWithMode(DeleteModeRestrict))
+		var errHasDependents ErrHasDependents
+		require.ErrorAs(t, err, &errHasDependents)
+		require.Equal(t, resource.TypeService, errHasDependents.Type)
+		require.ElementsMatch(t, []Dependent{
+			{Type: resource.TypeRoute, ID: rids[0]},
+			{Type: resource.TypeRoute, ID: rids[1]},
+		}, errHasDependents.Dependents)
+		require.Nil(t, s.Read(ctx, resource.NewService(), GetByID(sid)))
+		require.Nil(t, s.Read(ctx, resource.NewRoute(), GetByID(rids[0])))
+
+		var deleted []Dependent
+		require.Nil(t, s.Delete(ctx, DeleteByType(resource.TypeService), DeleteByID(sid),
+			DeleteWithMode(DeleteModeCascade), DeletedDependents(&deleted)))
+		require.ElementsMatch(t, []Dependent{
+			{Type: resource.TypeRoute, ID: rids[0]},
+			{Type: resource.TypeRoute, ID: rids[1]},
+		}, deleted)
+		for _, rid := range rids {
+			require.IsType(t, ErrNotFound, s.Read(ctx, resource.NewRoute(), GetByID(rid)))
+		}
+	})
+}
+
+func TestListReferrers(t *testing.T) {
+	ctx := context.Background()
+	persister, err := util.GetPersister(t)
+	require.Nil(t, err)
+	s := New(persister, log.Logger).ForCluster(DefaultCluster)
+
+	sid := uuid.NewString()
+	svc := resource.NewService()
+	svc.Service = &v1.Service{Id: sid, Name: "s1", Host: "foo.com"}
+	require.Nil(t, s.Create(ctx, svc))
+	route := resource.NewRoute()
+	route.Route = &v1.Route{
+		Id:      uuid.NewString(),
+		Name:    "r1",
+		Hosts:   []string{"example.com"},
+		Service: &v1.Service{Id: sid},
+	}
+	require.Nil(t, s.Create(ctx, route))
+
+	t.Run("lists the referrers of an object", func(t *testing.T) {
+		referrers, err := s.ListReferrers(ctx, resource.TypeService, sid, 1)
+		require.Nil(t, err)
+		require.Equal(t, []Referrer{{
+			Dependent: Dependent{Type: resource.TypeRoute, ID: route.ID()},
+			Field:     "service.id",
+		}}, referrers)
+	})
+	t.Run("lists no referrers without depth", func(t *testing.T) {
+		referrers, err := s.ListReferrers(ctx, resource.TypeService, sid, 0)
+		require.Nil(t, err)
+		require.Empty(t, referrers)
+	})
+	t.Run("lists the referrers of an object without referrers", func(t *testing.T) {
+		referrers, err := s.ListReferrers(ctx, resource.TypeRoute, route.ID(), 2)
+		require.Nil(t, err)
+		require.Empty(t, referrers)
+	})
+	t.Run("listing the referrers of a non-existent object fails", func(t *testing.T) {
+		_, err := s.ListReferrers(ctx, resource.TypeService, uuid.NewString(), 1)
+		require.Equal(t, ErrNotFound, err)
+	})
+}
+
+func TestListForeignKeys(t *testing.T) {
+	ctx := context.Background()
+	persister, err := util.GetPersister(t)
+	require.Nil(t, err)
+	s := New(persister, log.Logger).ForCluster(DefaultCluster)
+
+	cids := []string{uuid.NewString(), uuid.NewString()}
+	sort.Strings(cids)
+	for i, cid := range cids {
+		consumer := resource.NewConsumer()
+		consumer.Consumer = &v1.Consumer{Id: cid, Username: fmt.Sprintf("c%d", i)}
+		require.Nil(t, s.Create(ctx, consumer))
+	}
+	// Consumer groups are an enterprise feature, failing validation.
+	gid := uuid.NewString()
+	groupKey, err := s.genID(resource.TypeConsumerGroup, gid)
+	require.Nil(t, err)
+	require.Nil(t, persister.Put(ctx, groupKey, []byte(
+		`{"type":3,"revision":1,"object":{"id":"`+gid+`","name":"group"}}`)))
+	for _, cid := range cids {
+		value, err := wrapForeignIndex()
+		require.Nil(t, err)
+		require.Nil(t, persister.Put(ctx, s.foreignIndexKey(resource.TypeConsumer, cid,
+			resource.TypeConsumerGroup, gid), value))
+	}
+
+	t.Run("lists the foreign keys of every object of a type", func(t *testing.T) {
+		list, err := s.ListForeignKeys(ctx, resource.TypeConsumerGroup, resource.TypeConsumer)
+		require.Nil(t, err)
+		require.Equal(t, ForeignKeyList{
+			ForeignKeys: []ForeignKey{
+				{Type: resource.TypeConsumerGroup, ID: gid, ForeignType: resource.TypeConsumer, ForeignID: cids[0]},
+				{Type: resource.TypeConsumerGroup, ID: gid, ForeignType: resource.TypeConsumer, ForeignID: cids[1]},
+			},
+			TotalCount: 2,
+		}, list)
+	})
+	t.Run("lists the foreign keys by page", func(t *testing.T) {
+		list, err := s.ListForeignKeys(ctx, resource.TypeConsumerGroup, resource.TypeConsumer,
+			ListWithPageSize(1), ListWithPageNum(2))
+		require.Nil(t, err)
+		require.Len(t, list.ForeignKeys, 1)
+		require.Equal(t, cids[1], list.ForeignKeys[0].ForeignID)
+		require.Equal(t, 2, list.TotalCount)
+		require.Equal(t, 0, list.NextPage)
+	})
+	t.Run("lists no foreign keys between unrelated types", func(t *testing.T) {
+		list, err := s.ListForeignKeys(ctx, resource.TypeConsumerGroup, resource.TypeService)
+		require.Nil(t, err)
+		require.Empty(t, list.ForeignKeys)
+	})
 }
 
 func TestUpsert(t *testing.T) {
@@ -749,6 +891,57 @@ func TestUpdateEvent(t *testing.T) {
 	})
 }
 
+func TestWatchEvents(t *testing.T) {
+	persister, err := util.GetPersister(t)
+	require.Nil(t, err)
+	s := New(persister, log.Logger).ForCluster(DefaultCluster)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := s.WatchEvents(ctx)
+	if _, ok := persister.(persistence.EventSource); !ok {
+		require.ErrorIs(t, err, ErrEventsNotSupported)
+		require.Nil(t, events)
+		return
+	}
+	require.NoError(t, err)
+
+	t.Run("write to another cluster is not received", func(t *testing.T) {
+		svc := resource.NewService()
+		svc.Service = &v1.Service{
+			Id:   uuid.NewString(),
+			Host: "foo.com",
+		}
+		require.Nil(t, s.ForCluster("other").Create(ctx, svc))
+		select {
+		case <-events:
+			require.Fail(t, "unexpected event")
+		case <-time.After(100 * time.Millisecond):
+		}
+	})
+	t.Run("write to the cluster is received", func(t *testing.T) {
+		svc := resource.NewService()
+		svc.Service = &v1.Service{
+			Id:   uuid.NewString(),
+			Host: "foo.com",
+		}
+		require.Nil(t, s.Create(ctx, svc))
+		select {
+		case _, ok := <-events:
+			require.True(t, ok)
+		case <-time.After(5 * time.Second):
+			require.Fail(t, "no event received")
+		}
+	})
+	t.Run("channel is closed when context is done", func(t *testing.T) {
+		cancel()
+		require.Eventually(t, func() bool {
+			_, ok := <-events
+			return !ok
+		}, 5*time.Second, 10*time.Millisecond)
+	})
+}
+
 func TestUpdateEventForNode(t *testing.T) {
 	persister, err := util.GetPersister(t)
 	require.Nil(t, err)
@@ -958,6 +1151,58 @@ func TestPagination(t *testing.T) {
 		require.Equal(t, 0, svcs.GetNextPage())
 		require.Len(t, svcs.GetAll(), 0)
 	})
+	t.Run("Size 3 by page token success", func(t *testing.T) {
+		var ids []string
+		var token string
+		for i := 0; i < 4; i++ {
+			svcs = resource.NewList(resource.TypeService)
+			err = store.List(ctx, svcs, ListWithPageSize(3), ListWithPageToken(token))
+			require.NoError(t, err)
+			require.Equal(t, 10, svcs.GetTotalCount())
+			for _, svc := range svcs.GetAll() {
+				ids = append(ids, svc.ID())
+			}
+			token = svcs.GetNextPageToken()
+		}
+		require.Empty(t, token)
+		require.Len(t, ids, 10)
+		require.Equal(t, head, ids[0])
+		require.Equal(t, tail, ids[9])
+	})
+	t.Run("Size 3 by page token after page 2 success", func(t *testing.T) {
+		svcs = resource.NewList(resource.TypeService)
+		err = store.List(ctx, svcs, ListWithPageSize(3), ListWithPageNum(2))
+		require.NoError(t, err)
+		svcs2 := resource.NewList(resource.TypeService)
+		err = store.List(ctx, svcs2, ListWithPageSize(3), ListWithPageToken(svcs.GetNextPageToken()))
+		require.NoError(t, err)
+		require.Equal(t, 10, svcs2.GetTotalCount())
+		require.Len(t, svcs2.GetAll(), 3)
+		svcs3 := resource.NewList(resource.TypeService)
+		err = store.List(ctx, svcs3, ListWithPageSize(3), ListWithPageNum(3))
+		require.NoError(t, err)
+		require.Equal(t, svcs3.GetAll(), svcs2.GetAll())
+	})
+	t.Run("page token of another query fails", func(t *testing.T) {
+		svcs = resource.NewList(resource.TypeService)
+		err = store.List(ctx, svcs, ListWithPageSize(3))
+		require.NoError(t, err)
+		token := svcs.GetNextPageToken()
+		require.NotEmpty(t, token)
+
+		routes := resource.NewList(resource.TypeRoute)
+		err = store.List(ctx, routes, ListWithPageToken(token))
+		require.Equal(t, ErrInvalidPageToken, err)
+		svcs = resource.NewList(resource.TypeService)
+		err = store.List(ctx, svcs, ListWithPageToken(token),
+			ListWithSort(persistence.SortField{Name: "name", Kind: persistence.FieldKindString}))
+		require.Equal(t, ErrInvalidPageToken, err)
+	})
+	t.Run("invalid page token fails", func(t *testing.T) {
+		svcs = resource.NewList(resource.TypeService)
+		err = store.List(ctx, svcs, ListWithPageToken("!"))
+		require.Equal(t, ErrInvalidPageToken, err)
+	})
 }
 
 type jsonWrapper struct {
@@ -1,8 +1,10 @@
 package store
 
 import (
+	"bytes"
 	"fmt"
 
+	"github.com/kong/koko/internal/crypto"
 	"github.com/kong/koko/internal/json"
 	"github.com/kong/koko/internal/model"
 )
@@ -17,6 +19,10 @@ type valueWrapper struct {
 	Type   int             `json:"type,omitempty"`
 	Object json.RawMessage `json:"object,omitempty"`
 	RefID  string          `json:"ref_id,omitempty"`
+	// Revision is incremented on every write of an object.
+	// Objects written before revisions were tracked have no revision, and are
+	// read at firstRevision.
+	Revision uint64 `json:"revision,omitempty"`
 }
 
 func wrapUniqueIndex(refID string) ([]byte, error) {
@@ -52,20 +58,9 @@ func wrapForeignIndex() ([]byte, error) {
 	return value, nil
 }
 
-func verifyForeignValue(value []byte) error {
-	var indexValue valueWrapper
-	err := json.Unmarshal(value, &indexValue)
-	if err != nil {
-		return err
-	}
-	if indexValue.Type != valueTypeIndexForeign {
-		return fmt.Errorf("invalid index type for foreign index: '%v'",
-			indexValue.Type)
-	}
-	return nil
-}
-
-func wrapObject(object model.Object) ([]byte, error) {
+// wrapObject marshals object along with its revision. The sensitive fields
+// of the object are encrypted with keyring unless it is nil.
+func wrapObject(object model.Object, revision uint64, keyring *crypto.Keyring) ([]byte, error) {
 	var jsonObject []byte
 	var err error
 	if r, ok := object.(model.ObjectWithResourceDTO); ok {
@@ -78,9 +73,16 @@ func wrapObject(object model.Object) ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
+	if keyring != nil {
+		jsonObject, err = encryptFields(keyring, object.Type(), jsonObject)
+		if err != nil {
+			return nil, fmt.Errorf("encrypt object: %w", err)
+		}
+	}
 	value, err := json.Marshal(valueWrapper{
-		Type:   valueTypeObject,
-		Object: jsonObject,
+		Type:     valueTypeObject,
+		Object:   jsonObject,
+		Revision: revision,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("json marshal object: %w", err)
@@ -88,12 +90,25 @@ func wrapObject(object model.Object) ([]byte, error) {
 	return value, nil
 }
 
-func unwrapObject(value []byte, object model.Object) error {
+// unwrapObject unmarshals value into object and returns the revision of
+// the object. Encrypted fields are decrypted with keyring.
+// Objects written before revisions were tracked are at firstRevision, so that
+// they can be conditionally updated like any other object.
+func unwrapObject(value []byte, object model.Object, keyring *crypto.Keyring) (uint64, error) {
 	var wrappedValue valueWrapper
 
 	err := json.Unmarshal(value, &wrappedValue)
 	if err != nil {
-		return fmt.Errorf("json unmarshal wrapperValue: %w", err)
+		return 0, fmt.Errorf("json unmarshal wrapperValue: %w", err)
+	}
+	// Only the sensitive fields of an object are ever encrypted, other fields
+	// may hold the marker as plain text, e.g.: the configuration of a plugin.
+	if bytes.Contains(wrappedValue.Object, encryptedMarker) &&
+		len(model.OptionsForType(object.Type()).SensitiveFields) > 0 {
+		wrappedValue.Object, err = decryptFields(keyring, object.Type(), wrappedValue.Object)
+		if err != nil {
+			return 0, fmt.Errorf("decrypt object: %w", err)
+		}
 	}
 	if r, ok := object.(model.ObjectWithResourceDTO); ok {
 		// The object has implemented its own resource JSON unmarshaller.
@@ -103,7 +118,10 @@ func unwrapObject(value []byte, object model.Object) error {
 		err = json.ProtoJSONUnmarshal(wrappedValue.Object, object.Resource())
 	}
 	if err != nil {
-		return fmt.Errorf("json unmarshal object: %w", err)
+		return 0, fmt.Errorf("json unmarshal object: %w", err)
+	}
+	if wrappedValue.Revision == 0 {
+		return firstRevision, nil
 	}
-	return nil
+	return wrappedValue.Revision, nil
 }
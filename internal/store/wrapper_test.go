@@ -29,7 +29,7 @@ func (t testObjWithResourceDTO) UnmarshalResourceJSON(b []byte) error {
 func Test_wrapObject(t *testing.T) {
 	t.Run("marshal with default Protobuf marshaller", func(t *testing.T) {
 		obj := resource.Consumer{Consumer: &v1.Consumer{Username: "test"}}
-		objJSON, err := wrapObject(obj)
+		objJSON, err := wrapObject(obj, 0, nil)
 		require.NoError(t, err)
 		assert.JSONEq(
 			t,
@@ -42,7 +42,7 @@ func Test_wrapObject(t *testing.T) {
 		obj := testObjWithResourceDTO{
 			data: map[string]interface{}{"key": "value"},
 		}
-		objJSON, err := wrapObject(obj)
+		objJSON, err := wrapObject(obj, 0, nil)
 		require.NoError(t, err)
 		assert.JSONEq(
 			t,
@@ -50,24 +50,51 @@ func Test_wrapObject(t *testing.T) {
 			string(objJSON),
 		)
 	})
+
+	t.Run("marshal with revision", func(t *testing.T) {
+		obj := resource.Consumer{Consumer: &v1.Consumer{Username: "test"}}
+		objJSON, err := wrapObject(obj, 2, nil)
+		require.NoError(t, err)
+		assert.JSONEq(
+			t,
+			fmt.Sprintf(`{"type": %d, "object": {"username": "test"}, "revision": 2}`, valueTypeObject),
+			string(objJSON),
+		)
+	})
 }
 
 func Test_unwrapObject(t *testing.T) {
 	t.Run("unmarshal with default Protobuf unmarshaller", func(t *testing.T) {
 		obj := resource.NewConsumer()
-		require.NoError(t, unwrapObject(
+		revision, err := unwrapObject(
 			[]byte(fmt.Sprintf(`{"type": %d, "object": {"username": "test"}}`, valueTypeObject)),
 			obj,
-		))
+			nil,
+		)
+		require.NoError(t, err)
 		assert.True(t, proto.Equal(&v1.Consumer{Username: "test"}, obj.Consumer))
+		assert.Equal(t, uint64(firstRevision), revision)
 	})
 
 	t.Run("unmarshal with UnmarshalResourceJSON()", func(t *testing.T) {
 		obj := testObjWithResourceDTO{data: map[string]interface{}{}}
-		require.NoError(t, unwrapObject(
+		_, err := unwrapObject(
 			[]byte(fmt.Sprintf(`{"type": %d, "object": {"key": "value"}}`, valueTypeObject)),
 			obj,
-		))
+			nil,
+		)
+		require.NoError(t, err)
 		assert.Equal(t, map[string]interface{}{"key": "value"}, obj.data)
 	})
+
+	t.Run("unmarshal with revision", func(t *testing.T) {
+		obj := resource.NewConsumer()
+		revision, err := unwrapObject(
+			[]byte(fmt.Sprintf(`{"type": %d, "object": {"username": "test"}, "revision": 3}`, valueTypeObject)),
+			obj,
+			nil,
+		)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(3), revision)
+	})
 }
@@ -3,6 +3,7 @@ package util
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 
 	"github.com/ilyakaznacheev/cleanenv"
 	"github.com/kong/koko/internal/config"
@@ -67,6 +68,14 @@ func setDBConfig(conf *config.Database) error {
 		}
 	case db.DialectSQLite3:
 		conf.SQLite = config.SQLite{InMemory: true}
+	case db.DialectBolt:
+		if conf.Bolt.Filename == "" {
+			// Like in-memory SQLite databases, the database is shared by the
+			// tests of a single process.
+			conf.Bolt = config.Bolt{
+				Filename: filepath.Join(os.TempDir(), fmt.Sprintf("koko-test-%d.db", os.Getpid())),
+			}
+		}
 	case db.DialectPostgres:
 		if conf.Postgres.Hostname == "" {
 			conf.Postgres = config.Postgres{
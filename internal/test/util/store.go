@@ -3,6 +3,7 @@ package util
 import (
 	"context"
 	"fmt"
+	"math"
 	"testing"
 	"time"
 
@@ -58,6 +59,10 @@ func GetPersister(t *testing.T) (persistence.Persister, error) {
 		return nil, fmt.Errorf("unable to gather test DB config: %w", err)
 	}
 
+	if dbConfig.Dialect == db.DialectBolt {
+		return getBoltPersister(ctx, t, dbConfig)
+	}
+
 	dbClient, err := db.NewSQLDBFromConfig(dbConfig)
 	if err != nil {
 		return nil, fmt.Errorf("unable to resolve SQL DB instance from the given config: %w", err)
@@ -87,6 +92,33 @@ func GetPersister(t *testing.T) (persistence.Persister, error) {
 	return persister, nil
 }
 
+// getBoltPersister returns a persister of an empty bolt database.
+func getBoltPersister(ctx context.Context, t *testing.T, dbConfig db.Config) (persistence.Persister, error) {
+	persister, err := db.NewPersister(dbConfig)
+	if err != nil {
+		return nil, err
+	}
+	t.Cleanup(func() {
+		persister.Close()
+	})
+	tx, err := persister.Tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	list, err := tx.List(ctx, "", &persistence.ListOpts{Limit: math.MaxInt})
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+	for _, kv := range list.KVList {
+		if err := tx.Delete(ctx, string(kv.Key)); err != nil {
+			_ = tx.Rollback()
+			return nil, err
+		}
+	}
+	return persister, tx.Commit()
+}
+
 func runMigrations(config db.Config) error {
 	m, err := db.NewMigrator(config)
 	if err != nil {
@@ -5,7 +5,7 @@ if [ $# -eq 0 ]; then
   exit 1
 fi
 
-if [[ $1 == "sqlite3" ]]; then
+if [[ $1 == "sqlite3" || $1 == "bolt" ]]; then
   exit 0
 fi
 